@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/signalnine/conclave/internal/config"
+	"github.com/signalnine/conclave/internal/consensus"
+	"github.com/spf13/cobra"
+)
+
+var agentsCheckCmd = &cobra.Command{
+	Use:   "agents-check",
+	Short: "Probe each consensus agent and report which are usable",
+	Long: `Checks every configured consensus agent before a long run: agents that
+are not configured are reported with the reason, and each available agent is
+sent a tiny prompt with a short timeout. An agent is OK when it answers,
+slow when it answers after --slow, and unavailable when it is not configured
+or the probe fails (an expired key, an unreachable endpoint, a timeout).
+
+Exits non-zero when no agent is usable.`,
+	RunE: runAgentsCheck,
+}
+
+func init() {
+	agentsCheckCmd.Flags().Duration("timeout", 20*time.Second, "How long each probe may take")
+	agentsCheckCmd.Flags().Duration("slow", 5*time.Second, "Report agents that answer slower than this as slow")
+	rootCmd.AddCommand(agentsCheckCmd)
+}
+
+func runAgentsCheck(cmd *cobra.Command, args []string) error {
+	cfg := config.Load()
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	slow, _ := cmd.Flags().GetDuration("slow")
+	if timeout <= 0 {
+		return configError(fmt.Errorf("--timeout must be positive"))
+	}
+
+	checks := consensus.CheckAgents(context.Background(), consensusAgents(cfg), timeout, slow)
+	if err := printAgentChecks(cmd.OutOrStdout(), checks); err != nil {
+		return err
+	}
+	for _, c := range checks {
+		if c.Usable() {
+			return nil
+		}
+	}
+	return fmt.Errorf("no agents available")
+}
+
+// printAgentChecks writes a table of probe results: name, model, status,
+// probe latency and why an agent is unavailable or slow.
+func printAgentChecks(w io.Writer, checks []consensus.AgentCheck) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tMODEL\tSTATUS\tLATENCY\tDETAIL")
+	for _, c := range checks {
+		model, latency, detail := dash(c.Model), "-", dash(c.Detail)
+		if c.Latency > 0 {
+			latency = c.Latency.Round(time.Millisecond).String()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", c.Agent, model, c.Status, latency, detail)
+	}
+	return tw.Flush()
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
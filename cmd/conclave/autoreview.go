@@ -1,26 +1,32 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/signalnine/conclave/internal/config"
 	gitpkg "github.com/signalnine/conclave/internal/git"
+	"github.com/signalnine/conclave/internal/github"
 	"github.com/spf13/cobra"
 )
 
 var autoReviewCmd = &cobra.Command{
 	Use:   "auto-review [description]",
 	Short: "Auto-detect SHAs and run consensus code review",
-	Long:  "Convenience wrapper that auto-detects base/head SHAs from git history, then runs consensus code review.",
-	Args:  cobra.MinimumNArgs(1),
+	Long:  "Convenience wrapper that auto-detects base/head SHAs from git history, then runs consensus code review.\n\nWith --pr, base/head SHAs and the description are resolved from a GitHub pull request\n(repo taken from the origin remote, token from GITHUB_TOKEN or GH_TOKEN).",
 	RunE:  runAutoReview,
 }
 
 func init() {
 	autoReviewCmd.Flags().String("base-sha", "", "Override base SHA (default: auto-detect from origin/main)")
 	autoReviewCmd.Flags().String("head-sha", "", "Override head SHA (default: HEAD)")
-	autoReviewCmd.Flags().String("plan-file", "", "Path to implementation plan file")
+	autoReviewCmd.Flags().Duration("since", 0, "Review commits made within this duration (e.g. 8h) instead of since the branch point")
+	autoReviewCmd.Flags().StringArray("plan-file", nil, "Path to implementation plan file (repeatable)")
+	autoReviewCmd.Flags().Int("pr", 0, "GitHub pull request number to review")
+	autoReviewCmd.Flags().String("chairman", "", "Agent that must synthesize stage 2 (others are fallback)")
 	autoReviewCmd.Flags().Bool("debate", false, "Enable Stage 1.5 debate round between agents")
 	autoReviewCmd.Flags().Int("debate-rounds", 1, "Number of debate rounds (max 2)")
 	autoReviewCmd.Flags().Int("debate-timeout", 60, "Timeout in seconds per debate round")
@@ -33,6 +39,40 @@ func runAutoReview(cmd *cobra.Command, args []string) error {
 
 	baseSHA, _ := cmd.Flags().GetString("base-sha")
 	headSHA, _ := cmd.Flags().GetString("head-sha")
+	prNumber, _ := cmd.Flags().GetInt("pr")
+
+	if prNumber > 0 {
+		pr, err := resolvePullRequest(g, prNumber)
+		if err != nil {
+			if baseSHA == "" || headSHA == "" {
+				return fmt.Errorf("could not resolve PR #%d: %w (pass --base-sha and --head-sha to review without GitHub)", prNumber, err)
+			}
+			fmt.Fprintf(os.Stderr, "Warning: could not resolve PR #%d: %v (using provided SHAs)\n", prNumber, err)
+		} else {
+			if headSHA == "" {
+				headSHA = pr.HeadSHA
+			}
+			if baseSHA == "" {
+				// The base branch tip may have moved on since the PR
+				// branched; diffing against it would show those newer
+				// commits as reverted. Review from the merge base, as
+				// GitHub does.
+				baseSHA = pr.BaseSHA
+				if mb, err := g.MergeBase(pr.BaseSHA, headSHA); err == nil {
+					baseSHA = mb
+				} else {
+					fmt.Fprintf(os.Stderr, "Warning: no merge base of %s and %s (%v); diffing against the base branch tip\n", shortSHA(pr.BaseSHA), shortSHA(headSHA), err)
+				}
+			}
+			if description == "" {
+				description = fmt.Sprintf("PR #%d: %s", pr.Number, pr.Title)
+			}
+			fmt.Fprintf(os.Stderr, "Auto-review: PR #%d (%s <- %s)\n", pr.Number, pr.BaseRef, pr.HeadRef)
+		}
+	}
+	if description == "" {
+		return fmt.Errorf("a description argument is required (or use --pr)")
+	}
 
 	if headSHA == "" {
 		var err error
@@ -42,6 +82,18 @@ func runAutoReview(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if since, _ := cmd.Flags().GetDuration("since"); since > 0 && baseSHA == "" {
+		first, err := g.FirstCommitSince(time.Now().Add(-since), headSHA)
+		if err != nil {
+			return fmt.Errorf("--since %s: %w", since, err)
+		}
+		baseSHA, err = g.ParentOf(first)
+		if err != nil {
+			return fmt.Errorf("--since %s: %w", since, err)
+		}
+		fmt.Fprintf(os.Stderr, "Auto-review: commits since %s\n", time.Now().Add(-since).Format("2006-01-02 15:04"))
+	}
+
 	if baseSHA == "" {
 		// Try origin/main first, fall back to main
 		var err error
@@ -60,26 +112,26 @@ func runAutoReview(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	shortBase := baseSHA
-	if len(shortBase) > 8 {
-		shortBase = shortBase[:8]
-	}
-	shortHead := headSHA
-	if len(shortHead) > 8 {
-		shortHead = shortHead[:8]
+	fmt.Fprintf(os.Stderr, "Auto-review: base=%s head=%s\n", shortSHA(baseSHA), shortSHA(headSHA))
+	if files, err := g.DiffNameOnly(baseSHA, headSHA); err == nil && len(files) == 0 {
+		fmt.Fprintln(cmd.ErrOrStderr(), noChangesMessage(baseSHA, headSHA))
+		return nil
 	}
-	fmt.Fprintf(os.Stderr, "Auto-review: base=%s head=%s\n", shortBase, shortHead)
 
 	// Set flags on consensus command and run it directly
-	planFile, _ := cmd.Flags().GetString("plan-file")
+	planFiles, _ := cmd.Flags().GetStringArray("plan-file")
 	consensusCmd.Flags().Set("mode", "code-review")
 	consensusCmd.Flags().Set("base-sha", baseSHA)
 	consensusCmd.Flags().Set("head-sha", headSHA)
 	consensusCmd.Flags().Set("description", description)
-	if planFile != "" {
+	for _, planFile := range planFiles {
 		consensusCmd.Flags().Set("plan-file", planFile)
 	}
 
+	if chairman, _ := cmd.Flags().GetString("chairman"); chairman != "" {
+		consensusCmd.Flags().Set("chairman", chairman)
+	}
+
 	// Pass through debate flags
 	debate, _ := cmd.Flags().GetBool("debate")
 	if debate {
@@ -92,3 +144,35 @@ func runAutoReview(cmd *cobra.Command, args []string) error {
 
 	return runConsensus(consensusCmd, nil)
 }
+
+// resolvePullRequest looks up a PR on the origin remote's GitHub repo and makes
+// sure its base and head commits are available locally for git diff.
+func resolvePullRequest(g *gitpkg.Git, number int) (*github.PullRequest, error) {
+	cfg := config.Load()
+	remote, err := g.RemoteURL("origin")
+	if err != nil {
+		return nil, fmt.Errorf("no origin remote: %w", err)
+	}
+	owner, repo, err := github.ParseRemote(remote)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.GitHubToken == "" {
+		fmt.Fprintln(os.Stderr, "Warning: GITHUB_TOKEN not set, using unauthenticated GitHub API (public repos only)")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	pr, err := github.New(cfg.GitHubBaseURL, cfg.GitHubToken).PullRequest(ctx, owner, repo, number)
+	if err != nil {
+		return nil, err
+	}
+
+	if !g.HasCommit(pr.HeadSHA) || !g.HasCommit(pr.BaseSHA) {
+		fmt.Fprintf(os.Stderr, "Fetching PR #%d from origin...\n", number)
+		if err := g.Fetch("origin", pr.BaseRef, fmt.Sprintf("pull/%d/head", number)); err != nil {
+			return nil, fmt.Errorf("fetch PR commits: %w", err)
+		}
+	}
+	return pr, nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/signalnine/conclave/internal/config"
+	"github.com/signalnine/conclave/internal/consensus"
+	"github.com/spf13/cobra"
+)
+
+var consensusBenchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Score agent rosters against a suite of prompts with known good answers",
+	Long: `Runs general-prompt consensus for every case in a YAML suite under every
+configured roster, scores each synthesis and prints per-case and per-roster
+scores with their cost (agent calls and estimated tokens).
+
+A case is scored by the fraction of its keywords present in the synthesis
+and/or by the suite's judge agent grading it against the case's rubric (the
+two are averaged when both are given):
+
+  judge: Claude
+  configs:
+    - name: full
+    - name: claude-chair
+      agents: [Claude, Gemini]
+      chairman: Claude
+  cases:
+    - name: sql-injection
+      prompt: How should this endpoint build its SQL query?
+      context: ...
+      keywords: [parameterized, injection]
+      rubric: Recommends parameterized queries and explains why.`,
+	Args: cobra.NoArgs,
+	RunE: runConsensusBench,
+}
+
+func init() {
+	consensusBenchCmd.Flags().String("suite", "", "YAML suite file (required)")
+	consensusBenchCmd.Flags().Int("stage1-timeout", 0, "Stage 1 timeout in seconds")
+	consensusBenchCmd.Flags().Int("stage2-timeout", 0, "Stage 2 timeout in seconds")
+	consensusCmd.AddCommand(consensusBenchCmd)
+}
+
+func runConsensusBench(cmd *cobra.Command, args []string) error {
+	cfg := config.Load()
+	path, _ := cmd.Flags().GetString("suite")
+	if path == "" {
+		return fmt.Errorf("--suite is required")
+	}
+	if v, _ := cmd.Flags().GetInt("stage1-timeout"); v > 0 {
+		cfg.Stage1Timeout = v
+	}
+	if v, _ := cmd.Flags().GetInt("stage2-timeout"); v > 0 {
+		cfg.Stage2Timeout = v
+	}
+	suite, err := consensus.LoadBenchSuite(path)
+	if err != nil {
+		return err
+	}
+
+	roster := consensus.WithAnswerMarkers(consensusAgents(cfg), consensus.AnswerMarkers(cfg))
+	b := &consensus.Bench{
+		Roster: roster,
+		Opts:   consensus.Options{Stage1Timeout: cfg.Stage1Timeout, Stage2Timeout: cfg.Stage2Timeout},
+	}
+	if suite.Judge != "" {
+		judges, err := consensus.PinChairman(roster, suite.Judge)
+		if err != nil {
+			return fmt.Errorf("judge: %w", err)
+		}
+		if !judges[0].Available() {
+			return fmt.Errorf("judge %s is not available", judges[0].Name())
+		}
+		b.Judge = judges[0]
+	}
+
+	results, err := b.Run(context.Background(), suite)
+	if err != nil {
+		return err
+	}
+	return printBenchReport(cmd.OutOrStdout(), results)
+}
+
+// printBenchReport writes the per-case results followed by the per-roster
+// summary.
+func printBenchReport(w io.Writer, results []consensus.BenchResult) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "CONFIG\tCASE\tSCORE\tCALLS\tTOKENS IN/OUT\tDURATION\tNOTES")
+	for _, r := range results {
+		notes := "-"
+		switch {
+		case r.Err != nil:
+			notes = "error: " + r.Err.Error()
+		case len(r.Missing) > 0:
+			notes = "missing: " + strings.Join(r.Missing, ", ")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%.0f%%\t%d\t~%d/~%d\t%.1fs\t%s\n",
+			r.Config, r.Case, r.Score*100, r.Calls, r.TokensIn, r.TokensOut, r.Duration.Seconds(), notes)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "CONFIG\tCASES\tFAILED\tSCORE\tCALLS\tTOKENS IN/OUT\tDURATION")
+	for _, s := range consensus.SummarizeBench(results) {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.0f%%\t%d\t~%d/~%d\t%.1fs\n",
+			s.Config, s.Cases, s.Failed, s.Score*100, s.Calls, s.TokensIn, s.TokensOut, s.Duration.Seconds())
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/signalnine/conclave/internal/consensus"
+)
+
+func TestPrintBenchReport(t *testing.T) {
+	results := []consensus.BenchResult{
+		{Config: "full", Case: "sqli", Score: 1, Calls: 4, TokensIn: 120, TokensOut: 80, Duration: 2 * time.Second},
+		{Config: "full", Case: "xss", Score: 0.5, Missing: []string{"escape"}, Calls: 4},
+		{Config: "solo", Case: "sqli", Err: errors.New("stage 2 failed")},
+	}
+	var out bytes.Buffer
+	if err := printBenchReport(&out, results); err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+	for _, want := range []string{
+		"missing: escape",
+		"error: stage 2 failed",
+		"CONFIG  CASES  FAILED  SCORE",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("report missing %q:\n%s", want, got)
+		}
+	}
+	// Summary rows: full averages 100% and 50%, solo failed its only case.
+	var summary []string
+	_, summaryTable, _ := strings.Cut(got, "\n\n")
+	for _, line := range strings.Split(summaryTable, "\n") {
+		if f := strings.Fields(line); len(f) == 7 && (f[0] == "full" || f[0] == "solo") {
+			summary = append(summary, strings.Join(f[:4], " "))
+		}
+	}
+	if strings.Join(summary, "; ") != "full 2 0 75%; solo 1 1 0%" {
+		t.Errorf("summary rows = %q", summary)
+	}
+}
+
+func TestConsensusBenchRequiresSuite(t *testing.T) {
+	err := runConsensusBench(consensusBenchCmd, nil)
+	if err == nil || !strings.Contains(err.Error(), "--suite is required") {
+		t.Errorf("err = %v", err)
+	}
+}
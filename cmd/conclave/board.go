@@ -0,0 +1,419 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/signalnine/conclave/internal/bus"
+	"github.com/signalnine/conclave/internal/config"
+	"github.com/signalnine/conclave/internal/ralph"
+	"github.com/spf13/cobra"
+)
+
+var boardCmd = &cobra.Command{
+	Use:   "board",
+	Short: "Inspect and maintain ralph bulletin boards",
+}
+
+var boardPostCmd = &cobra.Command{
+	Use:   "post",
+	Short: "Append an entry to a board",
+	Long: `Appends one entry to a board directory, as a running task's
+<!-- BUS:... --> markers would: a bus envelope with a fresh ID, sequence
+number and timestamp, and a {"text": ...} payload.
+
+--type is one of the registered board types, with or without the board.
+prefix: discovery, warning, intent, context, or any added through
+RALPH_BOARD_PREFIXES.
+
+Example:
+  conclave board post --board-dir .conclave/bus/wave-0 --type warning --text "login tests are flaky" --sender task-3`,
+	RunE: runBoardPost,
+}
+
+var boardCompactCmd = &cobra.Command{
+	Use:   "compact",
+	Short: "Drop old entries from a board's files",
+	Long: `Rewrites the board files in a directory to keep only the --keep most
+recent entries, plus every major and critical entry (plain warnings
+included), the same entries ralph reads back from a board. Expired entries
+and malformed lines are dropped.
+
+Tasks may keep appending while it runs: each file is rewritten under the lock
+writers take, and entries posted meanwhile are kept.`,
+	RunE: runBoardCompact,
+}
+
+var boardImportCmd = &cobra.Command{
+	Use:   "import <source.jsonl>",
+	Short: "Seed a board with entries from a prior run's board file",
+	Long: `Reads a board JSONL file from a previous run and appends the selected
+entries to the target board directory, with fresh IDs, sequence numbers and
+timestamps. Senders are re-stamped "<sender> (imported from <run>)".
+
+Use --type (repeatable) to keep only some entry types, e.g. --type warning,
+and --max-age to drop stale entries. Malformed lines are skipped and counted.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBoardImport,
+}
+
+var boardQueryCmd = &cobra.Command{
+	Use:   "query",
+	Short: "Show board entries matching a query",
+	Long: `Prints the unexpired entries of a board directory that match --query, a
+space-separated list of clauses that must all hold:
+
+  key=value   equality
+  key~=regex  regular expression match
+
+Keys:
+  type    entry type (board. prefix optional with =), e.g. type=warning
+  sender  sender ID, e.g. sender=task-3
+  since   newer than a duration ago or an RFC 3339 time (= only), e.g. since=1h
+  text    the entry's text, e.g. text~=timeout
+
+Quote values containing spaces: text~="connection reset".
+
+Example:
+  conclave board query --board-dir .conclave/bus/wave-0 --query 'type=warning since=1h text~=timeout'`,
+	RunE: runBoardQuery,
+}
+
+var boardWatchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Run a consensus review when tasks post warnings about code",
+	Long: `Watches a board directory and, with --on-warning, reacts to new
+board.warning entries that reference source files (a "files" payload field,
+or paths such as internal/auth/login.go in the text). Warnings arriving
+within --debounce of each other are batched into one action.
+
+The default action runs a general-prompt consensus review of the referenced
+files with the warnings as the question. --exec runs a shell command instead,
+with the batch in the environment:
+
+  CONCLAVE_WATCH_FILES    referenced files, space-separated
+  CONCLAVE_WATCH_TEXT     warning texts, one per line
+  CONCLAVE_WATCH_SENDERS  senders of the warnings, space-separated
+
+--match narrows the trigger to warnings whose text matches a regex. Entries
+already on the board when the watch starts are ignored. Stop with Ctrl-C.`,
+	RunE: runBoardWatch,
+}
+
+func init() {
+	boardWatchCmd.Flags().String("board-dir", "", "Bulletin board directory (required)")
+	boardWatchCmd.Flags().Bool("on-warning", false, "Trigger on new board.warning entries that reference files")
+	boardWatchCmd.Flags().String("match", "", "Only trigger on warnings whose text matches this regex")
+	boardWatchCmd.Flags().Duration("debounce", ralph.DefaultWatchDebounce, "Quiet period before acting on a burst of warnings")
+	boardWatchCmd.Flags().String("exec", "", "Shell command to run instead of the consensus review (see CONCLAVE_WATCH_* variables)")
+	boardCmd.AddCommand(boardWatchCmd)
+
+	boardQueryCmd.Flags().String("board-dir", "", "Bulletin board directory (required)")
+	boardQueryCmd.Flags().String("query", "", "Filter, e.g. 'type=warning sender=task-3 since=1h text~=timeout' (empty matches all)")
+	boardQueryCmd.Flags().String("sha", "", "Only entries pinned to this commit (shorthand for the query clause sha=<id>)")
+	boardQueryCmd.Flags().Int("limit", 50, "Show at most this many of the most recent matches (0 = all)")
+	boardQueryCmd.Flags().Bool("json", false, "Print matching envelopes as JSONL instead of formatted text")
+	boardCmd.AddCommand(boardQueryCmd)
+
+	boardPostCmd.Flags().String("board-dir", "", "Bulletin board directory (required)")
+	boardPostCmd.Flags().String("topic", "board", "Topic to append the entry to")
+	boardPostCmd.Flags().String("type", "", "Entry type, e.g. warning or board.warning (required)")
+	boardPostCmd.Flags().String("text", "", "Entry text (required)")
+	boardPostCmd.Flags().String("sender", "", "Sender ID, e.g. task-3 (required)")
+	boardPostCmd.Flags().String("severity", "", "Severity: info, minor, major or critical")
+	boardPostCmd.Flags().String("sha", "", "Commit the finding is pinned to")
+	boardCmd.AddCommand(boardPostCmd)
+
+	boardCompactCmd.Flags().String("board-dir", "", "Bulletin board directory (required)")
+	boardCompactCmd.Flags().Int("keep", ralph.DefaultBoardKeep, "Recent entries to keep besides major and critical ones")
+	boardCmd.AddCommand(boardCompactCmd)
+
+	boardImportCmd.Flags().String("board-dir", "", "Target bulletin board directory (required)")
+	boardImportCmd.Flags().String("topic", "imported", "Topic to append imported entries to")
+	boardImportCmd.Flags().StringArray("type", nil, "Entry type to import, e.g. warning or board.warning (repeatable; default all)")
+	boardImportCmd.Flags().Duration("max-age", 0, "Skip entries older than this (0 = any age)")
+	boardImportCmd.Flags().String("run", "", "Name of the source run for the sender note (default: source file name)")
+	boardCmd.AddCommand(boardImportCmd)
+	rootCmd.AddCommand(boardCmd)
+}
+
+func runBoardQuery(cmd *cobra.Command, args []string) error {
+	boardDir, _ := cmd.Flags().GetString("board-dir")
+	queryStr, _ := cmd.Flags().GetString("query")
+	limit, _ := cmd.Flags().GetInt("limit")
+	asJSON, _ := cmd.Flags().GetBool("json")
+	if boardDir == "" {
+		return fmt.Errorf("--board-dir is required")
+	}
+	if sha, _ := cmd.Flags().GetString("sha"); sha != "" {
+		if !ralph.ValidSHA(sha) {
+			return fmt.Errorf("--sha %q is not a commit ID (4 to 64 hex digits)", sha)
+		}
+		queryStr = strings.TrimSpace(queryStr + " sha=" + sha)
+	}
+	q, err := ralph.ParseBoardQuery(queryStr)
+	if err != nil {
+		return err
+	}
+
+	entries, _, err := ralph.ReadBoardSince(boardDir, 0)
+	if err != nil {
+		return err
+	}
+	matches := q.Filter(entries, time.Now())
+	if limit > 0 && len(matches) > limit {
+		matches = matches[len(matches)-limit:]
+	}
+	if len(matches) == 0 {
+		fmt.Fprintln(cmd.ErrOrStderr(), "No matching board entries")
+		return nil
+	}
+
+	out := cmd.OutOrStdout()
+	if asJSON {
+		enc := json.NewEncoder(out)
+		for _, e := range matches {
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	applyBoardPrefixes(config.Load())
+	fmt.Fprint(out, ralph.FormatBoardContext(matches))
+	return nil
+}
+
+func runBoardWatch(cmd *cobra.Command, args []string) error {
+	boardDir, _ := cmd.Flags().GetString("board-dir")
+	onWarning, _ := cmd.Flags().GetBool("on-warning")
+	match, _ := cmd.Flags().GetString("match")
+	debounce, _ := cmd.Flags().GetDuration("debounce")
+	execCmd, _ := cmd.Flags().GetString("exec")
+	if boardDir == "" {
+		return fmt.Errorf("--board-dir is required")
+	}
+	if !onWarning {
+		return fmt.Errorf("nothing to watch for: pass --on-warning")
+	}
+	trigger := ralph.CodeWarning
+	if match != "" {
+		re, err := regexp.Compile(match)
+		if err != nil {
+			return fmt.Errorf("--match: %w", err)
+		}
+		trigger = ralph.MatchingWarning(re)
+	}
+	action := reviewWarningsAction
+	if execCmd != "" {
+		action = shellWatchAction(execCmd)
+	}
+
+	_, after, err := ralph.ReadBoardSince(boardDir, 0)
+	if err != nil {
+		return err
+	}
+	fileBus, err := bus.NewFileBus(boardDir, 100*time.Millisecond, time.Second)
+	if err != nil {
+		return err
+	}
+	defer fileBus.Close()
+	entries, err := fileBus.Subscribe("")
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	w := &ralph.BoardWatcher{
+		Trigger:  trigger,
+		Action:   action,
+		Debounce: debounce,
+		After:    after,
+		OnError: func(err error) {
+			fmt.Fprintf(os.Stderr, "Warning: watch action failed: %v\n", err)
+		},
+	}
+	fmt.Fprintf(os.Stderr, "Watching %s for code warnings (debounce %s)...\n", boardDir, debounce)
+	if err := w.Watch(ctx, entries); err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return nil
+}
+
+// watchContextBudget caps the file contents the default watch review sends.
+const watchContextBudget = 50000
+
+// reviewWarningsAction runs a general-prompt consensus review of the files a
+// batch of warnings refers to, with the warnings as the question.
+func reviewWarningsAction(ctx context.Context, entries []bus.Envelope, files []string) error {
+	var prompt strings.Builder
+	prompt.WriteString("Tasks working on this codebase posted the warnings below. Review the referenced files for the problems described, say whether each warning is valid, and recommend concrete fixes.\n\nWarnings:\n")
+	for _, e := range entries {
+		fmt.Fprintf(&prompt, "- [%s] %s\n", e.Sender, ralph.EntryText(e))
+	}
+	fmt.Fprintf(&prompt, "\nFiles: %s", strings.Join(files, ", "))
+
+	var contents strings.Builder
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			fmt.Fprintf(&contents, "### %s\n(unreadable: %v)\n\n", f, err)
+			continue
+		}
+		if remaining := watchContextBudget - contents.Len(); len(data) > remaining {
+			data = append(data[:max(remaining, 0)], "\n[... truncated ...]"...)
+		}
+		fmt.Fprintf(&contents, "### %s\n```\n%s\n```\n\n", f, data)
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "\n%d warning(s) about %s: starting consensus review\n", len(entries), strings.Join(files, ", "))
+	review := exec.CommandContext(ctx, exe, "consensus", "--mode=general-prompt", "--prompt="+prompt.String(), "--context="+contents.String())
+	review.Stdout, review.Stderr = os.Stdout, os.Stderr
+	return review.Run()
+}
+
+// shellWatchAction runs command through sh with the batch in
+// CONCLAVE_WATCH_* environment variables.
+func shellWatchAction(command string) ralph.WatchAction {
+	return func(ctx context.Context, entries []bus.Envelope, files []string) error {
+		var texts, senders []string
+		for _, e := range entries {
+			texts = append(texts, ralph.EntryText(e))
+			senders = append(senders, e.Sender)
+		}
+		c := exec.CommandContext(ctx, "sh", "-c", command)
+		c.Env = append(os.Environ(),
+			"CONCLAVE_WATCH_FILES="+strings.Join(files, " "),
+			"CONCLAVE_WATCH_TEXT="+strings.Join(texts, "\n"),
+			"CONCLAVE_WATCH_SENDERS="+strings.Join(senders, " "),
+		)
+		c.Stdout, c.Stderr = os.Stdout, os.Stderr
+		if err := c.Run(); err != nil {
+			return fmt.Errorf("%s: %w", command, err)
+		}
+		return nil
+	}
+}
+
+func runBoardPost(cmd *cobra.Command, args []string) error {
+	boardDir, _ := cmd.Flags().GetString("board-dir")
+	topic, _ := cmd.Flags().GetString("topic")
+	typeName, _ := cmd.Flags().GetString("type")
+	text, _ := cmd.Flags().GetString("text")
+	sender, _ := cmd.Flags().GetString("sender")
+	severity, _ := cmd.Flags().GetString("severity")
+	sha, _ := cmd.Flags().GetString("sha")
+	switch {
+	case boardDir == "":
+		return fmt.Errorf("--board-dir is required")
+	case strings.TrimSpace(text) == "":
+		return fmt.Errorf("--text is required")
+	case sender == "":
+		return fmt.Errorf("--sender is required")
+	}
+	cfg := config.Load()
+	applyBoardPrefixes(cfg)
+	typ, ok := ralph.BoardType(typeName)
+	if typeName == "" || !ok {
+		return fmt.Errorf("invalid --type %q: must be one of %s", typeName, strings.Join(ralph.BoardTypes(), ", "))
+	}
+	marker := ralph.BusMarker{Type: typ, Text: strings.TrimSpace(text)}
+	if severity != "" {
+		if marker.Severity, ok = ralph.ParseSeverity(severity); !ok {
+			return fmt.Errorf("invalid --severity %q: must be info, minor, major or critical", severity)
+		}
+	}
+	if sha != "" {
+		if !ralph.ValidSHA(sha) {
+			return fmt.Errorf("--sha %q is not a commit ID (4 to 64 hex digits)", sha)
+		}
+		marker.SHA = strings.ToLower(sha)
+	}
+
+	fileBus, err := bus.NewBoardBus(boardDir, cfg.BoardMaxWriters)
+	if err != nil {
+		return err
+	}
+	defer fileBus.Close()
+	if err := ralph.PublishMarkers(fileBus, topic, sender, []ralph.BusMarker{marker}); err != nil {
+		return fmt.Errorf("writing board: %w", err)
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "Posted %s to %s\n", typ, boardDir)
+	return nil
+}
+
+func runBoardCompact(cmd *cobra.Command, args []string) error {
+	boardDir, _ := cmd.Flags().GetString("board-dir")
+	keep, _ := cmd.Flags().GetInt("keep")
+	if boardDir == "" {
+		return fmt.Errorf("--board-dir is required")
+	}
+	if keep < 0 {
+		return fmt.Errorf("--keep must be >= 0")
+	}
+	stats, err := ralph.CompactBoard(boardDir, keep)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "Compacted %s: kept %d entries, dropped %d (%d files rewritten, %d bytes freed)\n",
+		boardDir, stats.Kept, stats.Dropped, stats.Files, stats.Freed)
+	return nil
+}
+
+func runBoardImport(cmd *cobra.Command, args []string) error {
+	src := args[0]
+	boardDir, _ := cmd.Flags().GetString("board-dir")
+	topic, _ := cmd.Flags().GetString("topic")
+	types, _ := cmd.Flags().GetStringArray("type")
+	maxAge, _ := cmd.Flags().GetDuration("max-age")
+	run, _ := cmd.Flags().GetString("run")
+	if boardDir == "" {
+		return fmt.Errorf("--board-dir is required")
+	}
+	if run == "" {
+		run = strings.TrimSuffix(filepath.Base(src), ".jsonl")
+	}
+
+	f, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("opening source board: %w", err)
+	}
+	defer f.Close()
+
+	imp, err := ralph.ReadBoardImport(f, run, ralph.BoardImportFilter{Types: types, MaxAge: maxAge}, time.Now())
+	if err != nil {
+		return err
+	}
+	if len(imp.Messages) > 0 {
+		fileBus, err := bus.NewBoardBus(boardDir, config.Load().BoardMaxWriters)
+		if err != nil {
+			return err
+		}
+		defer fileBus.Close()
+		if err := ralph.PublishImport(fileBus, topic, imp.Messages); err != nil {
+			return fmt.Errorf("writing board: %w", err)
+		}
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "Imported %d entries from %s into %s (%d filtered out", len(imp.Messages), src, boardDir, imp.Filtered)
+	if imp.Malformed > 0 {
+		fmt.Fprintf(cmd.ErrOrStderr(), ", %d malformed lines skipped", imp.Malformed)
+	}
+	fmt.Fprintln(cmd.ErrOrStderr(), ")")
+	return nil
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/signalnine/conclave/internal/bus"
+	"github.com/signalnine/conclave/internal/ralph"
+)
+
+func TestBoardImportWarningsOnly(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "prev-run.jsonl")
+	lines := strings.Join([]string{
+		`{"id":"1-1","seq":1,"sender":"task-1","topic":"board","type":"board.warning","payload":{"text":"flaky integration tests"}}`,
+		`{"id":"1-2","seq":2,"sender":"task-2","topic":"board","type":"board.discovery","payload":{"text":"uses cursor pagination"}}`,
+		`{"id":"1-3","seq":3,"sender":"task-2","topic":"board","type":"board.warning","payload":{"text":"v2 of pkg X breaks the API"}}`,
+		`{"id":"1-4", truncated`,
+	}, "\n") + "\n"
+	if err := os.WriteFile(src, []byte(lines), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	boardDir := filepath.Join(dir, "board")
+	setCmdFlags(t, boardImportCmd, map[string]string{"board-dir": boardDir, "type": "warning"})
+	var stderr bytes.Buffer
+	boardImportCmd.SetErr(&stderr)
+	defer boardImportCmd.SetErr(nil)
+
+	if err := runBoardImport(boardImportCmd, []string{src}); err != nil {
+		t.Fatal(err)
+	}
+	entries, _, err := ralph.ReadBoardSince(boardDir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("imported %d entries, want the 2 warnings: %+v", len(entries), entries)
+	}
+	for _, e := range entries {
+		if e.Type != "board.warning" {
+			t.Errorf("imported a %s entry", e.Type)
+		}
+		if !strings.HasSuffix(e.Sender, "(imported from prev-run)") {
+			t.Errorf("sender = %q, want the import note", e.Sender)
+		}
+		if e.ID == "1-1" || e.ID == "1-3" {
+			t.Errorf("entry kept its source ID %s, want a fresh one", e.ID)
+		}
+	}
+	if got := stderr.String(); !strings.Contains(got, "Imported 2 entries") || !strings.Contains(got, "1 malformed lines skipped") {
+		t.Errorf("summary = %q", got)
+	}
+}
+
+func TestBoardPost(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	setCmdFlags(t, boardPostCmd, map[string]string{"board-dir": dir, "type": "warning", "text": "login tests are flaky", "sender": "task-3", "severity": "critical"})
+	boardPostCmd.SetErr(&bytes.Buffer{})
+	defer boardPostCmd.SetErr(nil)
+	if err := runBoardPost(boardPostCmd, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, _, err := ralph.ReadBoardSince(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("board has %d entries, want 1", len(entries))
+	}
+	e := entries[0]
+	if e.ID == "" || e.Seq == 0 || e.Timestamp.IsZero() {
+		t.Errorf("envelope missing ID/seq/timestamp: %+v", e)
+	}
+	if e.Type != "board.warning" || e.Sender != "task-3" || e.Topic != "board" {
+		t.Errorf("envelope = %+v", e)
+	}
+	if !strings.Contains(string(e.Payload), `"text":"login tests are flaky"`) || !strings.Contains(string(e.Payload), `"severity":"critical"`) {
+		t.Errorf("payload = %s", e.Payload)
+	}
+}
+
+func TestBoardPostRejectsUnknownType(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	setCmdFlags(t, boardPostCmd, map[string]string{"board-dir": t.TempDir(), "type": "gossip", "text": "x", "sender": "task-3"})
+	err := runBoardPost(boardPostCmd, nil)
+	if err == nil || !strings.Contains(err.Error(), "board.discovery") {
+		t.Errorf("err = %v, want the known types listed", err)
+	}
+}
+
+func TestBoardQueryCommand(t *testing.T) {
+	dir := t.TempDir()
+	lines := `{"id":"1-1","seq":1,"timestamp":"` + time.Now().Add(-time.Minute).Format(time.RFC3339) + `","sender":"task-3","type":"board.warning","payload":{"text":"login timeout"}}
+{"id":"1-2","seq":2,"timestamp":"` + time.Now().Format(time.RFC3339) + `","sender":"task-4","type":"board.discovery","payload":{"text":"uses cursor pagination"}}
+`
+	if err := os.WriteFile(filepath.Join(dir, "wave.jsonl"), []byte(lines), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", t.TempDir())
+	setCmdFlags(t, boardQueryCmd, map[string]string{"board-dir": dir, "query": "type=warning text~=timeout", "json": "true"})
+	var out bytes.Buffer
+	boardQueryCmd.SetOut(&out)
+	defer boardQueryCmd.SetOut(nil)
+
+	if err := runBoardQuery(boardQueryCmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(out.String()); strings.Count(got, "\n") != 0 || !strings.Contains(got, `"id":"1-1"`) {
+		t.Errorf("output = %q, want only entry 1-1", got)
+	}
+
+	setCmdFlags(t, boardQueryCmd, map[string]string{"query": "type=warning bogus"})
+	if err := runBoardQuery(boardQueryCmd, nil); err == nil || !strings.Contains(err.Error(), "bogus") {
+		t.Errorf("malformed query error = %v", err)
+	}
+}
+
+func TestBoardQueryBySHA(t *testing.T) {
+	dir := t.TempDir()
+	lines := `{"id":"1-1","seq":1,"sender":"task-3","type":"board.warning","payload":{"text":"pager off by one","sha":"9f2c4e1a7b3d"}}
+{"id":"1-2","seq":2,"sender":"task-4","type":"board.warning","payload":{"text":"unpinned"}}
+`
+	if err := os.WriteFile(filepath.Join(dir, "wave.jsonl"), []byte(lines), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	setCmdFlags(t, boardQueryCmd, map[string]string{"board-dir": dir, "sha": "9f2c4e1"})
+	var out bytes.Buffer
+	boardQueryCmd.SetOut(&out)
+	defer boardQueryCmd.SetOut(nil)
+	if err := runBoardQuery(boardQueryCmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); !strings.Contains(got, "pager off by one") || strings.Contains(got, "unpinned") {
+		t.Errorf("output = %q, want only the pinned entry", got)
+	}
+
+	setCmdFlags(t, boardQueryCmd, map[string]string{"sha": "HEAD"})
+	if err := runBoardQuery(boardQueryCmd, nil); err == nil || !strings.Contains(err.Error(), "not a commit ID") {
+		t.Errorf("invalid --sha error = %v", err)
+	}
+}
+
+func TestShellWatchActionExportsBatch(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out.txt")
+	action := shellWatchAction(`printf '%s|%s|%s' "$CONCLAVE_WATCH_FILES" "$CONCLAVE_WATCH_SENDERS" "$CONCLAVE_WATCH_TEXT" > ` + out)
+	entries := []bus.Envelope{
+		{Sender: "task-2", Type: "board.warning", Payload: []byte(`{"text":"login.go leaks tokens"}`)},
+		{Sender: "task-3", Type: "board.warning", Payload: []byte(`{"text":"session.go too"}`)},
+	}
+	if err := action(context.Background(), entries, []string{"login.go", "session.go"}); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := os.ReadFile(out)
+	if want := "login.go session.go|task-2 task-3|login.go leaks tokens\nsession.go too"; string(got) != want {
+		t.Errorf("action saw %q, want %q", got, want)
+	}
+}
+
+func TestBoardWatchRequiresTrigger(t *testing.T) {
+	setCmdFlags(t, boardWatchCmd, map[string]string{"board-dir": t.TempDir()})
+	if err := runBoardWatch(boardWatchCmd, nil); err == nil || !strings.Contains(err.Error(), "--on-warning") {
+		t.Errorf("err = %v, want a hint to pass --on-warning", err)
+	}
+}
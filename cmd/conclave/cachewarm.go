@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/signalnine/conclave/internal/config"
+	"github.com/signalnine/conclave/internal/consensus"
+	"github.com/spf13/cobra"
+)
+
+var cacheWarmCmd = &cobra.Command{
+	Use:   "cache-warm",
+	Short: "Pre-populate the stage 1 response cache for a file of prompts",
+	Long: `Runs stage 1 (no chairman synthesis) for each prompt against the configured
+agents and stores the responses in the response cache. Later
+"conclave consensus --mode=general-prompt" runs with the same prompt
+and context then answer stage 1 from the cache.
+
+The prompts file holds one prompt per line; blank lines and lines starting
+with # are skipped. A line may instead be a JSON object
+{"prompt": "...", "context": "..."} to warm a prompt run with --context.
+
+The summary estimates the tokens each agent's cache misses used, from the
+text length, and prices them per model (CONSENSUS_MODEL_PRICES).`,
+	RunE: runCacheWarm,
+}
+
+func init() {
+	cacheWarmCmd.Flags().String("prompts", "", "File of prompts to warm (required)")
+	cacheWarmCmd.Flags().Int("concurrency", 2, "Number of prompts warmed in parallel")
+	cacheWarmCmd.Flags().Int("stage1-timeout", 0, "Per-prompt timeout in seconds (default: stage 1 timeout)")
+	consensusCmd.AddCommand(cacheWarmCmd)
+}
+
+func runCacheWarm(cmd *cobra.Command, args []string) error {
+	cfg := config.Load()
+	path, _ := cmd.Flags().GetString("prompts")
+	if path == "" {
+		return fmt.Errorf("--prompts is required")
+	}
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+	if v, _ := cmd.Flags().GetInt("stage1-timeout"); v > 0 {
+		cfg.Stage1Timeout = v
+	}
+
+	prompts, err := readWarmPrompts(path)
+	if err != nil {
+		return err
+	}
+	if len(prompts) == 0 {
+		return fmt.Errorf("no prompts in %s", path)
+	}
+
+	cache, err := openResponseCache(cfg, 0)
+	if err != nil {
+		return err
+	}
+	agents := consensus.WithAnswerMarkers(consensusAgents(cfg), consensus.AnswerMarkers(cfg))
+
+	fmt.Fprintf(os.Stderr, "Warming cache for %d prompt(s), concurrency %d...\n", len(prompts), concurrency)
+	start := time.Now()
+	stats := consensus.WarmCache(context.Background(), agents, cache, prompts, concurrency, time.Duration(cfg.Stage1Timeout)*time.Second)
+
+	fmt.Fprintf(os.Stderr, "\nPrompts: %d\nCache hits: %d\nCache misses: %d (%d failed)\n", stats.Prompts, stats.Hits, stats.Misses, stats.Failures)
+	fmt.Fprintf(os.Stderr, "Estimated tokens: ~%d in, ~%d out\n", stats.TokensIn, stats.TokensOut)
+	printWarmCost(os.Stderr, stats, cfg.ModelPrices)
+	fmt.Fprintf(os.Stderr, "Duration: %.1fs\n", time.Since(start).Seconds())
+	return nil
+}
+
+// printWarmCost writes each agent's estimated tokens and what they cost at
+// prices, then the total. Like the tokens, the costs are estimates.
+func printWarmCost(w io.Writer, stats consensus.WarmStats, prices map[string]config.ModelPrice) {
+	var total float64
+	for _, a := range stats.Agents {
+		cost := "no price for model"
+		if _, ok := config.PriceOf(prices, a.Model); ok {
+			usd := consensus.EstimateCost(prices, a.Model, a.Tokens)
+			total += usd
+			cost = fmt.Sprintf("~$%.4f", usd)
+		}
+		fmt.Fprintf(w, "  %s (%s): ~%d in, ~%d out, %s\n", a.Agent, dash(a.Model), a.Tokens.InputTokens, a.Tokens.OutputTokens, cost)
+	}
+	fmt.Fprintf(w, "Estimated cost: ~$%.4f\n", total)
+}
+
+// readWarmPrompts reads a prompts file and builds the stage 1 prompt each
+// entry produces in general-prompt mode, so warmed entries match later runs.
+func readWarmPrompts(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var prompts []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		prompt, ctxStr := line, ""
+		if strings.HasPrefix(line, "{") {
+			var entry struct {
+				Prompt  string `json:"prompt"`
+				Context string `json:"context"`
+			}
+			if err := json.Unmarshal([]byte(line), &entry); err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", path, n, err)
+			}
+			prompt, ctxStr = entry.Prompt, entry.Context
+		}
+		prompts = append(prompts, consensus.BuildGeneralPrompt(prompt, ctxStr))
+	}
+	return prompts, scanner.Err()
+}
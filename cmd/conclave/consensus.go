@@ -3,12 +3,18 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
 	"time"
 
+	"github.com/signalnine/conclave/internal/bus"
 	"github.com/signalnine/conclave/internal/config"
 	"github.com/signalnine/conclave/internal/consensus"
 	gitpkg "github.com/signalnine/conclave/internal/git"
+	"github.com/signalnine/conclave/internal/ralph"
 	"github.com/spf13/cobra"
 )
 
@@ -20,19 +26,59 @@ var consensusCmd = &cobra.Command{
 }
 
 func init() {
-	consensusCmd.Flags().String("mode", "", "Mode: code-review or general-prompt (required)")
+	consensusCmd.Flags().String("mode", "", "Mode: code-review, general-prompt or fact-check (required)")
 	consensusCmd.Flags().String("base-sha", "", "Base commit SHA (code-review mode)")
 	consensusCmd.Flags().String("head-sha", "", "Head commit SHA (code-review mode)")
 	consensusCmd.Flags().String("description", "", "Change description (code-review mode)")
-	consensusCmd.Flags().String("plan-file", "", "Path to implementation plan file")
+	consensusCmd.Flags().String("focus", "", "Targeted review: security, performance, correctness or style (code-review mode; default general)")
+	consensusCmd.Flags().StringArray("plan-file", nil, "Path to implementation plan file (repeatable)")
+	consensusCmd.Flags().Int("plan-budget", consensus.DefaultPlanBudget, "Combined size budget in bytes for plan files (0 = unlimited)")
 	consensusCmd.Flags().String("prompt", "", "Question to analyze (general-prompt mode)")
 	consensusCmd.Flags().String("context", "", "Additional context")
+	consensusCmd.Flags().String("claim", "", "Claim to verify (fact-check mode)")
+	consensusCmd.Flags().StringArray("source", nil, "Source file the claim is checked against (fact-check mode, repeatable)")
+	consensusCmd.Flags().String("board-dir", "", "Fold bulletin board findings from this directory into the context (general-prompt mode)")
+	consensusCmd.Flags().Int("board-max-entries", 20, "Maximum board entries to include with --board-dir")
+	consensusCmd.Flags().Int("board-budget", ralph.DefaultBoardPromptBudget, "Size budget in bytes for board context (0 = unlimited)")
+	consensusCmd.Flags().String("prompt-prefix", "", "Text placed before every stage 1 prompt (default $CONSENSUS_PROMPT_PREFIX)")
+	consensusCmd.Flags().String("prompt-suffix", "", "Text placed after every stage 1 prompt (default $CONSENSUS_PROMPT_SUFFIX)")
 	consensusCmd.Flags().Int("stage1-timeout", 0, "Stage 1 timeout in seconds")
 	consensusCmd.Flags().Int("stage2-timeout", 0, "Stage 2 timeout in seconds")
-	consensusCmd.Flags().Bool("dry-run", false, "Validate arguments only")
+	consensusCmd.Flags().String("chairman", "", "Agent that must synthesize stage 2 (others are fallback), e.g. Claude")
+	consensusCmd.Flags().Bool("fast-fallback", false, "If stage 2 times out, retry synthesis with the fast Claude model (ANTHROPIC_FAST_MODEL) on summarized results")
+	consensusCmd.Flags().Int("retries", -1, "Total retry budget shared by stage 1 agents and stage 2 chairman fallback (0 = no stage 1 retries)")
+	consensusCmd.Flags().Int("agent-retries", -1, "Retries per stage 1 agent, with exponential backoff (default $CONSENSUS_AGENT_RETRIES or 0)")
+	consensusCmd.Flags().Duration("retry-backoff", 0, "Wait before a stage 1 agent's first retry, doubling for each one after (default $CONSENSUS_RETRY_BACKOFF or 1s)")
+	consensusCmd.Flags().Bool("cache", true, "Reuse cached stage 1 responses and cache new ones")
+	consensusCmd.Flags().MarkDeprecated("cache", "stage 1 responses are cached by default; use --no-cache to disable")
+	consensusCmd.Flags().Bool("no-cache", false, "Run stage 1 fresh, neither reusing nor caching responses")
+	consensusCmd.Flags().Duration("cache-ttl", 0, "Ignore cached responses older than this (0 = no expiry)")
+	consensusCmd.Flags().Bool("list-agents", false, "List the configured agents and whether each is available, then exit")
+	consensusCmd.Flags().Bool("dry-run", false, "Validate arguments and print the assembled prompts without calling any agent")
 	consensusCmd.Flags().Bool("debate", false, "Enable Stage 1.5 debate round between agents")
 	consensusCmd.Flags().Int("debate-rounds", 1, "Number of debate rounds (max 2)")
 	consensusCmd.Flags().Int("debate-timeout", 60, "Timeout in seconds per debate round")
+	consensusCmd.Flags().String("order", "fixed", "Order of stage 1 results in the chairman prompt: fixed, shuffle or sorted (mitigates position bias)")
+	consensusCmd.Flags().Int64("seed", 0, "Shuffle seed for --order=shuffle (0 = random, recorded in the report)")
+	consensusCmd.Flags().Int("chairmen", 1, "Number of chairmen that synthesize stage 2 in parallel; 2 or more adds a merge pass reconciling their syntheses (extra API calls)")
+	consensusCmd.Flags().String("merger", "", "Agent that merges the syntheses with --chairmen (default the first chairman to finish in roster order)")
+	consensusCmd.Flags().Bool("fast", false, "Return the first stage 1 answer at once if the agent marks it CONFIDENT, skipping the other agents and stage 2 (less robust)")
+	consensusCmd.Flags().Bool("require-unanimous", false, "Approve only if every successful stage 1 agent approves, otherwise block and exit 7; the chairman cannot override")
+	consensusCmd.Flags().Float64("disagreement-threshold", consensus.DefaultDisagreementThreshold, "Flag the run and warn the chairman when two agents' outputs diverge by more than this (0-1, keyword overlap); 0 disables")
+	consensusCmd.Flags().Bool("quiet", false, "Suppress the periodic stage 1 progress updates")
+	consensusCmd.Flags().String("progress-dir", "", "Publish the run's progress events, tagged with its run ID, to a file bus in this directory (consensus.progress.jsonl)")
+	consensusCmd.Flags().Bool("stream-stage1", false, "With --progress-dir, also publish each stage 1 response as it arrives, on consensus.stream.<agent>")
+	consensusCmd.Flags().Bool("verify", false, "After synthesis, have the chairman check its own output for internal contradictions (extra API call)")
+	consensusCmd.Flags().Bool("critique", false, "Run a single cross-critique pass: each agent reviews all stage 1 outputs before synthesis")
+	consensusCmd.Flags().Int("critique-timeout", 60, "Timeout in seconds for the critique pass")
+	consensusCmd.Flags().Int("rounds", 1, "Stage 1 rounds; from the second on, each agent sees the others' full answers from the round before and rebuts and revises its own (--debate exchanges summaries)")
+	consensusCmd.Flags().Int("round-timeout", 0, "Timeout in seconds per round after the first (default: the stage 1 timeout)")
+	consensusCmd.Flags().Int("diff-context", gitpkg.DefaultDiffContext, "Lines of context around each diff hunk (git diff -U; more context grows the prompt and the per-file size checked by --chunk-threshold)")
+	consensusCmd.Flags().Int("chunk-threshold", consensus.DefaultChunkThreshold, "Per-file diff size in bytes above which files are reviewed hunk-by-hunk (0 disables)")
+	consensusCmd.Flags().Bool("include-stage1", true, "Append each agent's full stage 1 output (or error) to the report")
+	consensusCmd.Flags().String("save-raw", "", "Write each agent's raw stage 1 response to <dir>/<run-id>-<agent>.txt")
+	consensusCmd.Flags().String("label", "", "Save the report under this label for \"consensus list\" and \"consensus show\"")
+	consensusCmd.Flags().String("latest-symlink", "", "Create/update a stable link to the report at this path, e.g. $TMPDIR/consensus-latest.md")
 	rootCmd.AddCommand(consensusCmd)
 }
 
@@ -41,11 +87,22 @@ func runConsensus(cmd *cobra.Command, args []string) error {
 	mode, _ := cmd.Flags().GetString("mode")
 	dryRun, _ := cmd.Flags().GetBool("dry-run")
 
+	if list, _ := cmd.Flags().GetBool("list-agents"); list {
+		return printAgentList(cmd.OutOrStdout(), consensusAgents(cfg))
+	}
+
 	if mode == "" {
 		return fmt.Errorf("--mode is required")
 	}
-	if mode != "code-review" && mode != "general-prompt" {
-		return fmt.Errorf("invalid mode %q: must be code-review or general-prompt", mode)
+	builder, ok := consensus.LookupMode(mode)
+	if !ok {
+		return fmt.Errorf("invalid mode %q: must be one of %s", mode, strings.Join(consensus.Modes(), ", "))
+	}
+	label, _ := cmd.Flags().GetString("label")
+	if label != "" {
+		if err := consensus.ValidateLabel(label); err != nil {
+			return err
+		}
 	}
 
 	// Override timeouts from flags
@@ -55,6 +112,21 @@ func runConsensus(cmd *cobra.Command, args []string) error {
 	if v, _ := cmd.Flags().GetInt("stage2-timeout"); v > 0 {
 		cfg.Stage2Timeout = v
 	}
+	if v, _ := cmd.Flags().GetInt("retries"); v >= 0 {
+		cfg.ConsensusRetries = v
+	}
+	if v, _ := cmd.Flags().GetInt("agent-retries"); v >= 0 {
+		cfg.AgentRetries = v
+	}
+	if cmd.Flags().Changed("retry-backoff") {
+		cfg.AgentRetryBackoff, _ = cmd.Flags().GetDuration("retry-backoff")
+	}
+	if cmd.Flags().Changed("prompt-prefix") {
+		cfg.PromptPrefix, _ = cmd.Flags().GetString("prompt-prefix")
+	}
+	if cmd.Flags().Changed("prompt-suffix") {
+		cfg.PromptSuffix, _ = cmd.Flags().GetString("prompt-suffix")
+	}
 
 	// Debate flags
 	debate, _ := cmd.Flags().GetBool("debate")
@@ -63,110 +135,681 @@ func runConsensus(cmd *cobra.Command, args []string) error {
 	if debateRounds > 2 {
 		debateRounds = 2
 	}
+	critique, _ := cmd.Flags().GetBool("critique")
+	critiqueTimeout, _ := cmd.Flags().GetInt("critique-timeout")
+	if critique && debate {
+		return fmt.Errorf("--critique and --debate are mutually exclusive")
+	}
+	rounds, _ := cmd.Flags().GetInt("rounds")
+	roundTimeout, _ := cmd.Flags().GetInt("round-timeout")
+	if rounds < 1 {
+		return fmt.Errorf("--rounds must be at least 1, got %d", rounds)
+	}
+	if rounds > 1 && (debate || critique) {
+		return fmt.Errorf("--rounds is not supported with --debate or --critique")
+	}
+	numChairmen, _ := cmd.Flags().GetInt("chairmen")
+	mergerName, _ := cmd.Flags().GetString("merger")
+	if numChairmen < 1 {
+		return fmt.Errorf("--chairmen must be at least 1, got %d", numChairmen)
+	}
+	if numChairmen > 1 && (debate || critique) {
+		return fmt.Errorf("--chairmen is not supported with --debate or --critique")
+	}
+	if mergerName != "" && numChairmen < 2 {
+		return fmt.Errorf("--merger requires --chairmen 2 or more")
+	}
+	fast, _ := cmd.Flags().GetBool("fast")
+	if fast && (debate || critique || rounds > 1) {
+		return fmt.Errorf("--fast is not supported with --debate, --critique or --rounds")
+	}
+	unanimous, _ := cmd.Flags().GetBool("require-unanimous")
+	if unanimous && (debate || critique || fast) {
+		return fmt.Errorf("--require-unanimous is not supported with --debate, --critique or --fast")
+	}
+	progressDir, _ := cmd.Flags().GetString("progress-dir")
+	streamStage1, _ := cmd.Flags().GetBool("stream-stage1")
+	if progressDir != "" && (debate || critique) {
+		return fmt.Errorf("--progress-dir is not supported with --debate or --critique")
+	}
+	if streamStage1 && progressDir == "" {
+		return fmt.Errorf("--stream-stage1 requires --progress-dir")
+	}
+	disagreementThreshold, _ := cmd.Flags().GetFloat64("disagreement-threshold")
+	if disagreementThreshold < 0 || disagreementThreshold > 1 {
+		return fmt.Errorf("--disagreement-threshold must be between 0 and 1, got %g", disagreementThreshold)
+	}
+	orderFlag, _ := cmd.Flags().GetString("order")
+	order, err := consensus.ParseResultOrder(orderFlag)
+	if err != nil {
+		return err
+	}
+	seed, _ := cmd.Flags().GetInt64("seed")
 
-	// Build stage 1 prompt and a description string used for debate chairman context
-	var stage1Prompt string
-	var chairmanBuilder func([]consensus.AgentResult) string
-	var debateChairmanBuilder func([]consensus.AgentResult, []consensus.AgentResult) string
+	// Gather the mode's prompt input. Registered modes other than code-review
+	// take their question and context like general-prompt.
+	var in consensus.PromptInput
+
+	// In a dry run, failing to assemble the prompts (e.g. unknown SHAs) still
+	// leaves a successful argument check.
+	out := cmd.OutOrStdout()
+	previewUnavailable := func(err error) error {
+		if !dryRun {
+			return err
+		}
+		fmt.Fprintf(out, "\nPrompt preview unavailable: %v\n", err)
+		return nil
+	}
 
 	if mode == "code-review" {
 		baseSHA, _ := cmd.Flags().GetString("base-sha")
 		headSHA, _ := cmd.Flags().GetString("head-sha")
 		description, _ := cmd.Flags().GetString("description")
-		planFile, _ := cmd.Flags().GetString("plan-file")
+		planFiles, _ := cmd.Flags().GetStringArray("plan-file")
+		focusFlag, _ := cmd.Flags().GetString("focus")
+		diffContext, _ := cmd.Flags().GetInt("diff-context")
 
 		if baseSHA == "" || headSHA == "" || description == "" {
 			return fmt.Errorf("code-review mode requires --base-sha, --head-sha, --description")
 		}
+		focus, err := consensus.ParseReviewFocus(focusFlag)
+		if err != nil {
+			return err
+		}
+		if diffContext < 0 {
+			return fmt.Errorf("--diff-context must be >= 0")
+		}
 
 		if dryRun {
-			fmt.Println("Dry run: Arguments validated successfully")
-			fmt.Printf("Mode: %s\nBase SHA: %s\nHead SHA: %s\nDescription: %s\nDebate: %v\n", mode, baseSHA, headSHA, description, debate)
-			return nil
+			fmt.Fprintln(out, "Dry run: Arguments validated successfully")
+			fmt.Fprintf(out, "Mode: %s\nBase SHA: %s\nHead SHA: %s\nDescription: %s\nDebate: %v\n", mode, baseSHA, headSHA, description, debate)
 		}
 
 		g := gitpkg.New(".")
-		diff, err := g.Diff(baseSHA, headSHA)
+		diff, err := g.DiffContext(baseSHA, headSHA, diffContext)
 		if err != nil {
-			return fmt.Errorf("git diff: %w", err)
+			return previewUnavailable(fmt.Errorf("git diff: %w", err))
+		}
+		if strings.TrimSpace(diff) == "" {
+			fmt.Fprintln(cmd.ErrOrStderr(), noChangesMessage(baseSHA, headSHA))
+			return nil
 		}
 		files, _ := g.DiffNameOnly(baseSHA, headSHA)
 		modifiedFiles := ""
 		for _, f := range files {
 			modifiedFiles += f + "\n"
 		}
-		var planContent string
-		if planFile != "" {
-			data, _ := os.ReadFile(planFile)
-			planContent = string(data)
+		planBudget, _ := cmd.Flags().GetInt("plan-budget")
+		planContent, truncated, err := consensus.ReadPlanFiles(planFiles, planBudget)
+		if err != nil {
+			return previewUnavailable(err)
 		}
-		stage1Prompt = consensus.BuildCodeReviewPrompt(description, diff, modifiedFiles, planContent)
-		chairmanBuilder = func(results []consensus.AgentResult) string {
-			return consensus.BuildCodeReviewChairmanPrompt(description, modifiedFiles, results)
+		if len(truncated) > 0 {
+			fmt.Fprintf(os.Stderr, "Warning: plan files exceed %d bytes, truncated: %s\n", planBudget, strings.Join(truncated, ", "))
 		}
-		debateChairmanBuilder = func(results []consensus.AgentResult, rebuttals []consensus.AgentResult) string {
-			return consensus.BuildDebateChairmanPrompt(description, results, rebuttals)
+		in = consensus.PromptInput{
+			Question:      description,
+			Diff:          diff,
+			ModifiedFiles: modifiedFiles,
+			Plan:          planContent,
+			Focus:         focus,
+		}
+		// Large files are reviewed hunk-by-hunk (debate and critique run on the whole diff)
+		if !debate && !critique {
+			in.ChunkThreshold, _ = cmd.Flags().GetInt("chunk-threshold")
+		}
+	} else if mode == "fact-check" {
+		claim, _ := cmd.Flags().GetString("claim")
+		ctxStr, _ := cmd.Flags().GetString("context")
+		sourcePaths, _ := cmd.Flags().GetStringArray("source")
+		if claim == "" || len(sourcePaths) == 0 {
+			return fmt.Errorf("fact-check mode requires --claim and at least one --source")
+		}
+		if debate || critique {
+			return fmt.Errorf("fact-check mode does not support --debate or --critique")
+		}
+		sources, err := consensus.ReadSources(sourcePaths)
+		if err != nil {
+			return previewUnavailable(err)
 		}
+		if dryRun {
+			fmt.Fprintln(out, "Dry run: Arguments validated successfully")
+			fmt.Fprintf(out, "Mode: %s\nClaim: %s\nSources: %s\n", mode, claim, strings.Join(sourcePaths, ", "))
+		}
+		in = consensus.PromptInput{Question: claim, Context: ctxStr, Sources: sources}
 	} else {
 		prompt, _ := cmd.Flags().GetString("prompt")
 		ctxStr, _ := cmd.Flags().GetString("context")
 		if prompt == "" {
-			return fmt.Errorf("general-prompt mode requires --prompt")
-		}
-		if dryRun {
-			fmt.Println("Dry run: Arguments validated successfully")
-			fmt.Printf("Mode: %s\nPrompt: %s\nDebate: %v\n", mode, prompt, debate)
-			return nil
+			return fmt.Errorf("%s mode requires --prompt", mode)
 		}
-		stage1Prompt = consensus.BuildGeneralPrompt(prompt, ctxStr)
-		chairmanBuilder = func(results []consensus.AgentResult) string {
-			return consensus.BuildGeneralChairmanPrompt(prompt, results)
+		if boardDir, _ := cmd.Flags().GetString("board-dir"); boardDir != "" {
+			applyBoardPrefixes(cfg)
+			maxEntries, _ := cmd.Flags().GetInt("board-max-entries")
+			budget, _ := cmd.Flags().GetInt("board-budget")
+			boardCtx, err := ralph.BoardPromptContext(boardDir, maxEntries, budget)
+			if err != nil {
+				return previewUnavailable(fmt.Errorf("read board: %w", err))
+			}
+			if boardCtx != "" {
+				ctxStr = strings.TrimSpace(boardCtx + "\n" + ctxStr)
+			}
 		}
-		debateChairmanBuilder = func(results []consensus.AgentResult, rebuttals []consensus.AgentResult) string {
-			return consensus.BuildDebateChairmanPrompt(prompt, results, rebuttals)
+		if dryRun {
+			fmt.Fprintln(out, "Dry run: Arguments validated successfully")
+			fmt.Fprintf(out, "Mode: %s\nPrompt: %s\nDebate: %v\n", mode, prompt, debate)
 		}
+		in = consensus.PromptInput{Question: prompt, Context: ctxStr}
 	}
 
+	builder = framedBuilder{PromptBuilder: builder, prefix: cfg.PromptPrefix, suffix: cfg.PromptSuffix}
+	stage1Prompts := builder.BuildStage1(in)
+	if len(stage1Prompts) == 0 {
+		return fmt.Errorf("%s mode produced no stage 1 prompts", mode)
+	}
+	chairmanBuilder := func(results []consensus.AgentResult) string {
+		return builder.BuildChairman(in, results)
+	}
+	debateChairmanBuilder := func(results []consensus.AgentResult, rebuttals []consensus.AgentResult) string {
+		return consensus.BuildDebateChairmanPrompt(in.Question, results, rebuttals)
+	}
+	// Debate and critique never chunk, so they run the single stage 1 prompt
+	stage1Prompt := stage1Prompts[0].Prompt
+
 	// Build agents
-	agents := []consensus.Agent{
-		consensus.NewClaudeAgent(cfg),
-		consensus.NewGeminiAgent(cfg),
-		consensus.NewCodexAgent(cfg),
+	agents := consensusAgents(cfg)
+
+	if dryRun {
+		printPromptPreview(out, agents, stage1Prompts, chairmanBuilder, debateChairmanBuilder, debate)
+		return nil
 	}
 
-	// Run consensus (with or without debate)
+	// Stage 1 outputs are trimmed to their answer marker and go through the
+	// response cache, so repeating a run that died in stage 2 skips straight
+	// to synthesis; chairmen are used as is
+	stage1Agents := consensus.WithAnswerMarkers(agents, consensus.AnswerMarkers(cfg))
+	var cache *consensus.ResponseCache
+	if noCache, _ := cmd.Flags().GetBool("no-cache"); !noCache {
+		ttl, _ := cmd.Flags().GetDuration("cache-ttl")
+		if c, err := openResponseCache(cfg, ttl); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: response cache unavailable, running uncached: %v\n", err)
+		} else {
+			cache = c
+			stage1Agents = consensus.WithCache(stage1Agents, cache)
+		}
+	}
+
+	chairmanName, _ := cmd.Flags().GetString("chairman")
+	chairmen, err := consensus.PinChairman(agents, chairmanName)
+	if err != nil {
+		return err
+	}
+	if chairmanName != "" && !chairmen[0].Available() {
+		fmt.Fprintf(os.Stderr, "Warning: chairman %s is not available, falling back to other agents\n", chairmen[0].Name())
+	}
+
+	// Run consensus (plain, debate, or critique)
 	ctx := context.Background()
 	var result *consensus.ConsensusResult
-	var err error
+
+	// The report file exists from the start so the chairman synthesis can be
+	// streamed into it; it is rewritten with the full report on success.
+	outputFile, err := os.CreateTemp("", "consensus-*.md")
+	if err != nil {
+		return err
+	}
+	defer outputFile.Close()
 
 	if debate {
-		result, err = consensus.RunConsensusWithDebate(ctx, agents, agents, stage1Prompt, debateChairmanBuilder, cfg.Stage1Timeout, debateTimeout, cfg.Stage2Timeout, debateRounds)
+		result, err = consensus.RunConsensusWithDebate(ctx, stage1Agents, chairmen, stage1Prompt, debateChairmanBuilder, cfg.Stage1Timeout, debateTimeout, cfg.Stage2Timeout, debateRounds)
+	} else if critique {
+		buildCritiqueChairman := func(analyses, critiques []consensus.AgentResult) string {
+			return consensus.BuildCritiqueChairmanPrompt(in.Question, analyses, critiques)
+		}
+		result, err = consensus.RunConsensusCritique(ctx, stage1Agents, chairmen, stage1Prompt, in.Question, buildCritiqueChairman, cfg.Stage1Timeout, critiqueTimeout, cfg.Stage2Timeout)
 	} else {
-		result, err = consensus.RunConsensusWithBuilder(ctx, agents, agents, stage1Prompt, chairmanBuilder, cfg.Stage1Timeout, cfg.Stage2Timeout)
+		opts := consensus.Options{
+			Stage1Timeout: cfg.Stage1Timeout,
+			Stage2Timeout: cfg.Stage2Timeout,
+			AgentTimeouts: cfg.AgentTimeouts,
+			Retries:       cfg.ConsensusRetries,
+			RetryPolicy:   consensus.RetryPolicy{Max: cfg.AgentRetries, Backoff: cfg.AgentRetryBackoff},
+			Prices:        cfg.ModelPrices,
+			RunID:         bus.NewID(),
+			StreamTo:      outputFile,
+
+			DisagreementThreshold: disagreementThreshold,
+		}
+		opts.Verify, _ = cmd.Flags().GetBool("verify")
+		opts.Fast = fast
+		opts.RequireUnanimous = unanimous
+		if quiet, _ := cmd.Flags().GetBool("quiet"); !quiet {
+			opts.Progress = os.Stderr
+		}
+		opts.Order, opts.Seed = order, seed
+		if progressDir != "" {
+			fileBus, err := bus.NewFileBus(progressDir, 100*time.Millisecond, time.Second)
+			if err != nil {
+				return fmt.Errorf("progress bus: %w", err)
+			}
+			defer fileBus.Close()
+			opts.Bus, opts.StreamStage1 = fileBus, streamStage1
+		}
+		if rounds > 1 {
+			opts.Rounds, opts.RoundTimeout = rounds, roundTimeout
+		}
+		if numChairmen > 1 {
+			opts.Chairmen = numChairmen
+			if mergerName != "" {
+				pinned, err := consensus.PinChairman(agents, mergerName)
+				if err != nil {
+					return err
+				}
+				opts.Merger = pinned[0]
+			}
+		}
+		fmt.Fprintf(outputFile, "# Multi-Agent Consensus Analysis (in progress)\n\n**Run ID:** %s\n**Mode:** %s\n**Date:** %s\n\n---\n\n## Stage 2: Chairman Consensus (partial)\n\n",
+			opts.RunID, mode, time.Now().Format("2006-01-02 15:04:05"))
+		if fallback, _ := cmd.Flags().GetBool("fast-fallback"); fallback {
+			if fastChairman := consensus.NewFastClaudeAgent(cfg); fastChairman.Available() {
+				opts.FastChairman = fastChairman
+				opts.FastTimeout = cfg.FastChairmanTimeout
+			} else {
+				fmt.Fprintln(os.Stderr, "Warning: --fast-fallback needs ANTHROPIC_API_KEY, continuing without it")
+			}
+		}
+		result, err = consensus.RunConsensusWithBuilder(ctx, stage1Agents, chairmen, builder, in, opts)
+	}
+	if err != nil && result != nil {
+		// Stage 2 failed: keep the stage 1 analyses after any partial synthesis.
+		fmt.Fprintf(outputFile, "\n\n**Synthesis failed:** %v\n", err)
+		consensus.WriteStage1Failures(outputFile, result.Stage1Results)
+		writeStage1Analyses(outputFile, result.Stage1Results)
+		outputFile.Close()
+		fmt.Fprintf(os.Stderr, "Stage 1 analyses saved to: %s\n", outputFile.Name())
+		return err
 	}
 	if err != nil {
+		if info, statErr := outputFile.Stat(); statErr == nil && info.Size() > 0 && !debate && !critique {
+			fmt.Fprintf(os.Stderr, "Partial output saved to: %s\n", outputFile.Name())
+		} else {
+			os.Remove(outputFile.Name())
+		}
 		return err
 	}
+	if cache != nil {
+		fmt.Fprintf(os.Stderr, "  Cache: %d hits, %d misses\n", cache.Hits(), cache.Misses())
+	}
+	if rawDir, _ := cmd.Flags().GetString("save-raw"); rawDir != "" {
+		if paths, err := consensus.SaveRawResults(rawDir, result.RunID, result.Stage1Results); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		} else {
+			fmt.Fprintf(os.Stderr, "Raw responses saved to %s (%d files)\n", rawDir, len(paths))
+		}
+	}
 
 	// Write output file
-	outputFile, err := os.CreateTemp("", "consensus-*.md")
-	if err != nil {
+	if err := outputFile.Truncate(0); err != nil {
 		return err
 	}
-	debateLabel := ""
+	if _, err := outputFile.Seek(0, 0); err != nil {
+		return err
+	}
+	extraHeader := ""
 	if debate {
-		debateLabel = fmt.Sprintf("\n**Debate:** %d round(s)", debateRounds)
+		extraHeader = fmt.Sprintf("\n**Debate:** %d round(s)", debateRounds)
+	}
+	if rounds > 1 {
+		extraHeader = fmt.Sprintf("\n**Rounds:** %d of %d", len(result.Rounds), rounds)
+	}
+	if critique {
+		extraHeader = "\n**Critique:** cross-critique pass"
+		if len(result.Critiques) == 0 {
+			extraHeader += " (skipped, fewer than 2 analyses)"
+		}
+	}
+	switch {
+	case result.Seed != 0:
+		extraHeader += fmt.Sprintf("\n**Chairman Input Order:** %s (shuffled, seed %d)", strings.Join(result.ChairmanOrder, ", "), result.Seed)
+	case order == consensus.OrderSorted:
+		extraHeader += fmt.Sprintf("\n**Chairman Input Order:** %s (sorted)", strings.Join(result.ChairmanOrder, ", "))
+	}
+	if len(result.Syntheses) > 0 {
+		if names := synthesisAgents(result.Syntheses); len(names) > 1 {
+			extraHeader += fmt.Sprintf("\n**Chairmen:** %s (merged by %s)", strings.Join(names, ", "), result.ChairmanName)
+		} else {
+			extraHeader += fmt.Sprintf("\n**Chairmen:** only %s produced a synthesis (no merge)", result.ChairmanName)
+		}
+	}
+	if cached := cachedResults(result.Stage1Results); cached == len(result.Stage1Results) && cached > 0 {
+		extraHeader += "\n**Stage 1:** served from cache (no agent calls)"
+	} else if cached > 0 {
+		extraHeader += fmt.Sprintf("\n**Stage 1:** %d/%d results served from cache", cached, len(result.Stage1Results))
+	}
+	if timedOut := result.TimedOut(); len(timedOut) > 0 {
+		extraHeader += fmt.Sprintf("\n**Timed Out:** %s", strings.Join(timedOut, ", "))
+	}
+	if result.Disagreement {
+		extraHeader += fmt.Sprintf("\n**AGENTS DISAGREE:** %s", result.DisagreementReason)
+	}
+	if g := result.Gate; g != nil {
+		extraHeader += fmt.Sprintf("\n**Gate:** %s (unanimous approval required)", strings.ToUpper(string(g.Verdict)))
+	}
+	if result.ShortCircuited {
+		extraHeader += fmt.Sprintf("\n**Fast Path:** %s answered confidently; other agents canceled, stage 2 skipped", result.ChairmanName)
+	}
+	if result.Escalated {
+		extraHeader += "\n**Escalated:** stage 2 timed out, synthesized by the fast chairman from summarized results"
+	}
+	fmt.Fprintf(outputFile, "# Multi-Agent Consensus Analysis\n\n**Run ID:** %s\n**Mode:** %s\n**Date:** %s\n**Agents Succeeded:** %d/%d\n**Chairman:** %s%s\n\n---\n\n",
+		result.RunID, mode, time.Now().Format("2006-01-02 15:04:05"), result.AgentsSucceeded, len(agents), result.ChairmanName, extraHeader)
+	if result.ShortCircuited {
+		fmt.Fprintf(outputFile, "## Stage 1: Confident Answer (by %s)\n\n%s\n", result.ChairmanName, result.ChairmanOutput)
+	} else {
+		fmt.Fprintf(outputFile, "## Stage 2: Chairman Consensus (by %s)\n\n%s\n", result.ChairmanName, result.ChairmanOutput)
+	}
+	if len(result.Syntheses) > 0 {
+		fmt.Fprintf(outputFile, "\n## Chairman Syntheses\n\n")
+		for _, r := range result.Syntheses {
+			if r.Err != nil {
+				fmt.Fprintf(outputFile, "### %s\n\nFailed: %v\n\n", r.Agent, r.Err)
+			} else {
+				fmt.Fprintf(outputFile, "### %s\n\n%s\n\n", r.Agent, r.Output)
+			}
+		}
+	}
+	if fc := result.FactCheck; fc != nil {
+		writeFactCheck(outputFile, fc)
+	}
+	if g := result.Gate; g != nil {
+		writeGate(outputFile, g)
+	}
+	consensus.WriteStage1Failures(outputFile, result.Stage1Results)
+	if c := result.Consistency; c != nil {
+		switch {
+		case c.Err != nil:
+			fmt.Fprintf(outputFile, "\n## Consistency Check (by %s)\n\nCheck failed: %v\n", c.Agent, c.Err)
+		case c.Flagged:
+			fmt.Fprintf(outputFile, "\n## Consistency Check (by %s)\n\n**WARNING: contradictions flagged**\n\n%s\n", c.Agent, c.Output)
+		default:
+			fmt.Fprintf(outputFile, "\n## Consistency Check (by %s)\n\n%s\n", c.Agent, c.Output)
+		}
+	}
+	if include, _ := cmd.Flags().GetBool("include-stage1"); include {
+		writeStage1Analyses(outputFile, result.Stage1Results)
 	}
-	fmt.Fprintf(outputFile, "# Multi-Agent Consensus Analysis\n\n**Mode:** %s\n**Date:** %s\n**Agents Succeeded:** %d/3\n**Chairman:** %s%s\n\n---\n\n",
-		mode, time.Now().Format("2006-01-02 15:04:05"), result.AgentsSucceeded, result.ChairmanName, debateLabel)
-	fmt.Fprintf(outputFile, "## Stage 2: Chairman Consensus (by %s)\n\n%s\n", result.ChairmanName, result.ChairmanOutput)
 	outputFile.Close()
 
+	if label != "" {
+		if err := saveLabeledRun(cfg, label, mode, result.RunID, outputFile.Name()); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not save labeled run: %v\n", err)
+		}
+	}
+	if linkPath, _ := cmd.Flags().GetString("latest-symlink"); linkPath != "" {
+		if err := linkLatest(outputFile.Name(), linkPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not update latest report link: %v\n", err)
+		}
+	}
+
 	// Print to stdout
 	fmt.Fprintln(os.Stderr, "\n========================================")
 	fmt.Fprintln(os.Stderr, "CONSENSUS COMPLETE")
 	fmt.Fprintln(os.Stderr, "========================================")
 	fmt.Println(result.ChairmanOutput)
 	fmt.Fprintf(os.Stderr, "\nDetailed breakdown saved to: %s\n", outputFile.Name())
+	fmt.Fprintln(os.Stderr, result.Agreement)
+	if result.Disagreement {
+		fmt.Fprintf(os.Stderr, "WARNING: AGENTS DISAGREE (%s)\n", result.DisagreementReason)
+	}
+	if t := result.Tokens; t.InputTokens+t.OutputTokens > 0 {
+		fmt.Fprintf(os.Stderr, "Tokens: %d prompt, %d completion (est. $%.4f)\n", t.InputTokens, t.OutputTokens, result.CostUSD)
+	}
+	if g := result.Gate; g != nil && g.Verdict == consensus.GateBlock {
+		return &exitError{code: ExitBlocked, err: fmt.Errorf("unanimous gate: blocked by %s", strings.Join(g.Blockers(), ", "))}
+	}
 	return nil
 }
+
+// cachedResults counts the stage 1 results served from the response cache.
+func cachedResults(results []consensus.AgentResult) int {
+	n := 0
+	for _, r := range results {
+		if r.Cached {
+			n++
+		}
+	}
+	return n
+}
+
+// synthesisAgents names the chairmen whose syntheses were merged.
+func synthesisAgents(syntheses []consensus.AgentResult) []string {
+	var names []string
+	for _, r := range syntheses {
+		if r.Err == nil {
+			names = append(names, r.Agent)
+		}
+	}
+	return names
+}
+
+// writeStage1Analyses writes every stage 1 result in full, so the report
+// shows what each agent said and not just the synthesis of it.
+func writeStage1Analyses(w io.Writer, results []consensus.AgentResult) {
+	if len(results) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n## Stage 1: Individual Analyses\n\n")
+	for _, r := range results {
+		name := r.Agent
+		if r.Chunk != "" {
+			name += " [" + r.Chunk + "]"
+		}
+		if r.Err != nil {
+			fmt.Fprintf(w, "### %s\n\nFailed: %v\n\n", name, r.Err)
+		} else {
+			fmt.Fprintf(w, "### %s\n\n%s\n\n", name, r.Output)
+		}
+	}
+}
+
+// writeGate writes the unanimous gate verdict and each agent's vote.
+func writeGate(w io.Writer, g *consensus.UnanimousGate) {
+	fmt.Fprintf(w, "\n## Unanimous Gate\n\n**Verdict:** %s\n\n", strings.ToUpper(string(g.Verdict)))
+	for _, v := range g.Votes {
+		note := ""
+		if v.Missing {
+			note = " (no GATE line)"
+		}
+		fmt.Fprintf(w, "- %s: %s%s\n", v.Agent, strings.ToUpper(string(v.Verdict)), note)
+	}
+}
+
+// writeFactCheck writes the fact-check verdict and citations, flagging
+// passages that do not appear in the named source.
+func writeFactCheck(w io.Writer, fc *consensus.FactCheck) {
+	fmt.Fprintf(w, "\n## Fact Check\n\n**Verdict:** %s\n", strings.ToUpper(string(fc.Verdict)))
+	if len(fc.Citations) == 0 {
+		fmt.Fprintf(w, "\nNo citations.\n")
+		return
+	}
+	fmt.Fprintln(w)
+	for _, c := range fc.Citations {
+		kind := "supports"
+		if !c.Supports {
+			kind = "contradicts"
+		}
+		note := ""
+		if !c.Verified {
+			note = " **(not found in source)**"
+		}
+		fmt.Fprintf(w, "- [%s] %s: %q%s\n", c.Source, kind, c.Passage, note)
+	}
+}
+
+// symlink creates links for linkLatest; tests replace it to exercise the
+// copy fallback.
+var symlink = os.Symlink
+
+// linkLatest points linkPath at report, replacing any previous link. The new
+// link is created beside linkPath and renamed into place so readers never see
+// a missing file. Where symlinks are unavailable the report is copied instead.
+func linkLatest(report, linkPath string) error {
+	target, err := filepath.Abs(report)
+	if err != nil {
+		return err
+	}
+	if info, err := os.Lstat(linkPath); err == nil && info.IsDir() {
+		return fmt.Errorf("%s is a directory", linkPath)
+	}
+	if err := os.MkdirAll(filepath.Dir(linkPath), 0755); err != nil {
+		return err
+	}
+
+	tmp := fmt.Sprintf("%s.tmp-%d", linkPath, os.Getpid())
+	os.Remove(tmp)
+	if err := symlink(target, tmp); err != nil {
+		data, readErr := os.ReadFile(target)
+		if readErr != nil {
+			return readErr
+		}
+		if err := os.WriteFile(tmp, data, 0644); err != nil {
+			return err
+		}
+	}
+	if err := os.Rename(tmp, linkPath); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Latest report: %s\n", linkPath)
+	return nil
+}
+
+// noChangesMessage explains why a code review was skipped.
+func noChangesMessage(base, head string) string {
+	return fmt.Sprintf("No changes to review between %s and %s; skipping consensus.", shortSHA(base), shortSHA(head))
+}
+
+// shortSHA abbreviates a commit ID to 8 characters.
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
+
+// saveLabeledRun stores the report in the run history under label.
+func saveLabeledRun(cfg *config.Config, label, mode, runID, report string) error {
+	h, err := openHistory(cfg)
+	if err != nil {
+		return err
+	}
+	if _, err := h.Save(consensus.RunRecord{Label: label, RunID: runID, Mode: mode}, report); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Saved as %q (conclave consensus show %s)\n", label, label)
+	return nil
+}
+
+// printPromptPreview writes the stage 1 prompt(s) every agent would receive
+// and the chairman prompt built from placeholder stage 1 outputs.
+func printPromptPreview(w io.Writer, agents []consensus.Agent, chunks []consensus.ChunkPrompt,
+	chairmanBuilder func([]consensus.AgentResult) string,
+	debateChairmanBuilder func([]consensus.AgentResult, []consensus.AgentResult) string, debate bool) {
+	var placeholders, rebuttals []consensus.AgentResult
+	for _, c := range chunks {
+		label := ""
+		if c.Label != "" {
+			label = fmt.Sprintf(" (%s)", c.Label)
+			fmt.Fprintf(w, "\n===== Stage 1 Prompt: %s =====\n\n%s\n", c.Label, c.Prompt)
+		} else {
+			fmt.Fprintf(w, "\n===== Stage 1 Prompt =====\n\n%s\n", c.Prompt)
+		}
+		for _, a := range agents {
+			placeholders = append(placeholders, consensus.AgentResult{
+				Agent:  a.Name(),
+				Chunk:  c.Label,
+				Output: fmt.Sprintf("<%s stage 1 output%s>", a.Name(), label),
+			})
+		}
+	}
+
+	chairmanPrompt := chairmanBuilder(placeholders)
+	if debate {
+		for _, a := range agents {
+			rebuttals = append(rebuttals, consensus.AgentResult{Agent: a.Name(), Output: fmt.Sprintf("<%s rebuttal>", a.Name())})
+		}
+		chairmanPrompt = debateChairmanBuilder(placeholders, rebuttals)
+	}
+	fmt.Fprintf(w, "\n===== Example Chairman Prompt (placeholder stage 1 outputs) =====\n\n%s\n", chairmanPrompt)
+}
+
+// framedBuilder applies the house-style prompt prefix and suffix to every
+// stage 1 prompt of the mode it wraps.
+type framedBuilder struct {
+	consensus.PromptBuilder
+	prefix, suffix string
+}
+
+func (b framedBuilder) BuildStage1(in consensus.PromptInput) []consensus.ChunkPrompt {
+	prompts := b.PromptBuilder.BuildStage1(in)
+	for i := range prompts {
+		prompts[i].Prompt = consensus.WrapPrompt(b.prefix, prompts[i].Prompt, b.suffix)
+	}
+	return prompts
+}
+
+// ParseResult forwards to the wrapped mode, if it parses results.
+func (b framedBuilder) ParseResult(in consensus.PromptInput, r *consensus.ConsensusResult) {
+	if p, ok := b.PromptBuilder.(consensus.ResultParser); ok {
+		p.ParseResult(in, r)
+	}
+}
+
+// consensusAgents returns the built-in agents followed by any extra
+// OpenAI-compatible agents configured via CONSENSUS_EXTRA_AGENTS.
+func consensusAgents(cfg *config.Config) []consensus.Agent {
+	agents := []consensus.Agent{
+		consensus.NewClaudeAgent(cfg),
+		consensus.NewGeminiAgent(cfg),
+		consensus.NewCodexAgent(cfg),
+		consensus.NewOpenAIAgent(cfg),
+		consensus.NewOllamaAgent(cfg),
+	}
+	return append(agents, consensus.ExtraAgents(cfg)...)
+}
+
+// printAgentList writes a table of the roster: name, model, availability and
+// why an agent is unavailable.
+func printAgentList(w io.Writer, agents []consensus.Agent) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tMODEL\tAVAILABLE\tREASON")
+	for _, a := range agents {
+		model := "-"
+		if m, ok := a.(interface{ Model() string }); ok && m.Model() != "" {
+			model = m.Model()
+		}
+		reason := consensus.UnavailableReason(a)
+		if reason == "" {
+			reason = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", a.Name(), model, yesNo(a.Available()), reason)
+	}
+	return tw.Flush()
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+// openResponseCache opens the stage 1 response cache in the configured
+// directory, falling back to the per-user cache dir.
+func openResponseCache(cfg *config.Config, ttl time.Duration) (*consensus.ResponseCache, error) {
+	dir := cfg.CacheDir
+	if dir == "" {
+		dir = consensus.DefaultCacheDir()
+	}
+	return consensus.NewResponseCache(dir, ttl)
+}
@@ -0,0 +1,541 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/signalnine/conclave/internal/config"
+	"github.com/signalnine/conclave/internal/consensus"
+	"github.com/spf13/cobra"
+)
+
+// setFlags sets consensus flags for one test and restores the defaults.
+func setFlags(t *testing.T, flags map[string]string) {
+	t.Helper()
+	setCmdFlags(t, consensusCmd, flags)
+}
+
+// setCmdFlags sets flags on cmd as if given on the command line (so Changed
+// reports true), restoring their defaults when the test ends.
+func setCmdFlags(t *testing.T, cmd *cobra.Command, flags map[string]string) {
+	t.Helper()
+	for name, value := range flags {
+		f := cmd.Flags().Lookup(name)
+		if f == nil {
+			t.Fatalf("unknown flag %q", name)
+		}
+		if err := cmd.Flags().Set(name, value); err != nil {
+			t.Fatal(err)
+		}
+		def := f.DefValue
+		t.Cleanup(func() {
+			if sv, ok := f.Value.(interface{ Replace([]string) error }); ok {
+				sv.Replace(nil) // Set would append to a slice flag
+			} else {
+				f.Value.Set(def)
+			}
+			f.Changed = false
+		})
+	}
+}
+
+func TestConsensusDryRunPrintsPrompts(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	setFlags(t, map[string]string{
+		"mode":    "general-prompt",
+		"prompt":  "Should we shard the sessions table?",
+		"context": "10M rows, growing 5% a month",
+		"dry-run": "true",
+	})
+	var out bytes.Buffer
+	consensusCmd.SetOut(&out)
+	defer consensusCmd.SetOut(nil)
+
+	if err := runConsensus(consensusCmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+	for _, want := range []string{
+		"Dry run: Arguments validated successfully",
+		"Mode: general-prompt",
+		"===== Stage 1 Prompt =====",
+		"**Question:**\nShould we shard the sessions table?",
+		"10M rows, growing 5% a month",
+		"===== Example Chairman Prompt",
+		"<Claude stage 1 output>",
+		"<Codex stage 1 output>",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("dry-run output missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestConsensusDryRunUnknownSHAs(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	setFlags(t, map[string]string{
+		"mode":        "code-review",
+		"base-sha":    "0000000000000000000000000000000000000001",
+		"head-sha":    "0000000000000000000000000000000000000002",
+		"description": "test change",
+		"dry-run":     "true",
+	})
+	var out bytes.Buffer
+	consensusCmd.SetOut(&out)
+	defer consensusCmd.SetOut(nil)
+
+	if err := runConsensus(consensusCmd, nil); err != nil {
+		t.Fatalf("dry run should still validate: %v", err)
+	}
+	if !strings.Contains(out.String(), "Prompt preview unavailable: git diff") {
+		t.Errorf("output:\n%s", out.String())
+	}
+}
+
+func TestConsensusCodeReviewEmptyDiff(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	head, err := exec.Command("git", "rev-parse", "HEAD").Output()
+	if err != nil {
+		t.Skipf("not in a git checkout: %v", err)
+	}
+	sha := strings.TrimSpace(string(head))
+	setFlags(t, map[string]string{
+		"mode":        "code-review",
+		"base-sha":    sha,
+		"head-sha":    sha,
+		"description": "nothing changed",
+	})
+	var stderr bytes.Buffer
+	consensusCmd.SetErr(&stderr)
+	defer consensusCmd.SetErr(nil)
+
+	if err := runConsensus(consensusCmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stderr.String(), "No changes to review between "+sha[:8]+" and "+sha[:8]) {
+		t.Errorf("stderr = %q", stderr.String())
+	}
+}
+
+func TestConsensusListAgents(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ANTHROPIC_API_KEY", "sk-test")
+	t.Setenv("GEMINI_API_KEY", "")
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("OLLAMA_MODEL", "")
+	t.Setenv("CONSENSUS_EXTRA_AGENTS", "groq=https://api.groq.com/openai/v1,llama-3.3-70b,GROQ_TEST_KEY")
+	setFlags(t, map[string]string{"list-agents": "true"})
+	var out bytes.Buffer
+	consensusCmd.SetOut(&out)
+	defer consensusCmd.SetOut(nil)
+
+	// No --mode needed: listing exits before any validation or stage 1.
+	if err := runConsensus(consensusCmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 7 {
+		t.Fatalf("want header + 6 agents, got:\n%s", out.String())
+	}
+	row := func(name string) []string {
+		for _, l := range lines {
+			if f := strings.Fields(l); len(f) > 0 && f[0] == name {
+				return f
+			}
+		}
+		t.Fatalf("%s not listed:\n%s", name, out.String())
+		return nil
+	}
+	if f := row("Claude"); f[2] != "yes" {
+		t.Errorf("Claude row = %v, want available", f)
+	}
+	if f := row("Gemini"); f[2] != "no" || !strings.Contains(strings.Join(f[3:], " "), "GEMINI_API_KEY not set") {
+		t.Errorf("Gemini row = %v, want unavailable with reason", f)
+	}
+	if f := row("groq"); f[1] != "llama-3.3-70b" || f[2] != "no" || f[3] != "GROQ_TEST_KEY" {
+		t.Errorf("groq row = %v, want its model and missing key", f)
+	}
+}
+
+func TestConsensusPromptPrefixSuffix(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("CONSENSUS_PROMPT_SUFFIX", "Respond in under 500 words.")
+	setFlags(t, map[string]string{
+		"mode":          "general-prompt",
+		"prompt":        "Should we shard the sessions table?",
+		"prompt-prefix": "You are reviewing production Go code.",
+		"dry-run":       "true",
+	})
+	var out bytes.Buffer
+	consensusCmd.SetOut(&out)
+	defer consensusCmd.SetOut(nil)
+
+	if err := runConsensus(consensusCmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	_, rest, ok := strings.Cut(out.String(), "===== Stage 1 Prompt =====\n\n")
+	if !ok {
+		t.Fatalf("no stage 1 prompt in output:\n%s", out.String())
+	}
+	prompt, _, _ := strings.Cut(rest, "\n===== Example Chairman Prompt")
+	prompt = strings.TrimSpace(prompt)
+	if !strings.HasPrefix(prompt, "You are reviewing production Go code.\n\n") {
+		t.Errorf("prompt does not start with the prefix:\n%s", prompt)
+	}
+	if !strings.HasSuffix(prompt, "\n\nRespond in under 500 words.") {
+		t.Errorf("prompt does not end with the configured suffix:\n%s", prompt)
+	}
+	if !strings.Contains(prompt, "Should we shard the sessions table?") {
+		t.Errorf("built prompt missing between prefix and suffix:\n%s", prompt)
+	}
+}
+
+func TestConsensusChairmenFlagValidation(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	tests := []struct {
+		flags map[string]string
+		want  string
+	}{
+		{map[string]string{"chairmen": "0"}, "--chairmen must be at least 1"},
+		{map[string]string{"chairmen": "2", "debate": "true"}, "not supported with --debate or --critique"},
+		{map[string]string{"merger": "Claude"}, "--merger requires --chairmen 2 or more"},
+		{map[string]string{"require-unanimous": "true", "fast": "true"}, "--require-unanimous is not supported"},
+		{map[string]string{"disagreement-threshold": "1.5"}, "--disagreement-threshold must be between 0 and 1"},
+		{map[string]string{"progress-dir": "/tmp/progress", "debate": "true"}, "--progress-dir is not supported"},
+		{map[string]string{"stream-stage1": "true"}, "--stream-stage1 requires --progress-dir"},
+		{map[string]string{"rounds": "0"}, "--rounds must be at least 1"},
+		{map[string]string{"rounds": "2", "critique": "true"}, "--rounds is not supported with --debate or --critique"},
+		{map[string]string{"rounds": "2", "fast": "true"}, "--fast is not supported with --debate, --critique or --rounds"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			flags := map[string]string{"mode": "general-prompt", "prompt": "q", "dry-run": "true"}
+			for k, v := range tt.flags {
+				flags[k] = v
+			}
+			setFlags(t, flags)
+			err := runConsensus(consensusCmd, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestConsensusFactCheckDryRun(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	setFlags(t, map[string]string{"mode": "fact-check", "claim": "x", "dry-run": "true"})
+	if err := runConsensus(consensusCmd, nil); err == nil || !strings.Contains(err.Error(), "requires --claim and at least one --source") {
+		t.Errorf("missing --source: err = %v", err)
+	}
+
+	source := filepath.Join(t.TempDir(), "changelog.md")
+	if err := os.WriteFile(source, []byte("v2.0 dropped support for Go 1.20."), 0644); err != nil {
+		t.Fatal(err)
+	}
+	setFlags(t, map[string]string{"mode": "fact-check", "claim": "v2.0 still supports Go 1.20", "source": source, "dry-run": "true"})
+	var out bytes.Buffer
+	consensusCmd.SetOut(&out)
+	defer consensusCmd.SetOut(nil)
+	if err := runConsensus(consensusCmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Mode: fact-check", "Claim: v2.0 still supports Go 1.20", "--- Source: changelog.md ---", "dropped support for Go 1.20"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("dry-run output missing %q", want)
+		}
+	}
+}
+
+func TestWriteFactCheck(t *testing.T) {
+	var b bytes.Buffer
+	writeFactCheck(&b, &consensus.FactCheck{
+		Verdict: consensus.VerdictUnsupported,
+		Citations: []consensus.Citation{
+			{Source: "a.md", Passage: "real", Supports: false, Verified: true},
+			{Source: "a.md", Passage: "made up", Supports: true},
+		},
+	})
+	got := b.String()
+	for _, want := range []string{"**Verdict:** UNSUPPORTED", `- [a.md] contradicts: "real"` + "\n", `- [a.md] supports: "made up" **(not found in source)**`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestWriteStage1Analyses(t *testing.T) {
+	var b bytes.Buffer
+	writeStage1Analyses(&b, []consensus.AgentResult{
+		{Agent: "Claude", Output: "Looks fine."},
+		{Agent: "Gemini", Chunk: "main.go", Output: "Nil check missing."},
+		{Agent: "Codex", Err: fmt.Errorf("timeout")},
+	})
+	got := b.String()
+	for _, want := range []string{"## Stage 1: Individual Analyses", "### Claude\n\nLooks fine.", "### Gemini [main.go]\n\nNil check missing.", "### Codex\n\nFailed: timeout"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+
+	b.Reset()
+	writeStage1Analyses(&b, nil)
+	if b.Len() != 0 {
+		t.Errorf("no results should write nothing, got %q", b.String())
+	}
+}
+
+func TestWriteGate(t *testing.T) {
+	var b bytes.Buffer
+	writeGate(&b, &consensus.UnanimousGate{
+		Verdict: consensus.GateBlock,
+		Votes: []consensus.GateVote{
+			{Agent: "Claude", Verdict: consensus.GateApprove},
+			{Agent: "Gemini", Verdict: consensus.GateBlock, Missing: true},
+		},
+	})
+	got := b.String()
+	for _, want := range []string{"## Unanimous Gate", "**Verdict:** BLOCK", "- Claude: APPROVE\n", "- Gemini: BLOCK (no GATE line)"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestAgentsCheckNoneUsable(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	for _, k := range []string{"ANTHROPIC_API_KEY", "GEMINI_API_KEY", "OPENAI_API_KEY", "OLLAMA_MODEL", "CONSENSUS_EXTRA_AGENTS"} {
+		t.Setenv(k, "")
+	}
+	var out bytes.Buffer
+	agentsCheckCmd.SetOut(&out)
+	defer agentsCheckCmd.SetOut(nil)
+
+	err := runAgentsCheck(agentsCheckCmd, nil)
+	if err == nil || !strings.Contains(err.Error(), "no agents available") {
+		t.Fatalf("err = %v, want no agents available", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 6 || !strings.HasPrefix(lines[0], "NAME") {
+		t.Fatalf("want header + 5 agents, got:\n%s", out.String())
+	}
+	for _, l := range lines[1:] {
+		if f := strings.Fields(l); f[2] != consensus.CheckUnavailable {
+			t.Errorf("row = %v, want unavailable", f)
+		}
+	}
+}
+
+func TestAutoReviewPRDiffsFromMergeBase(t *testing.T) {
+	dir := t.TempDir()
+	git := func(args ...string) string {
+		t.Helper()
+		out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %s %v", args, out, err)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "-q", "-b", "main")
+	git("config", "user.email", "test@test.com")
+	git("config", "user.name", "Test")
+	git("commit", "-q", "--allow-empty", "-m", "initial")
+	git("branch", "feature")
+	os.WriteFile(filepath.Join(dir, "later.txt"), []byte("main moved on\n"), 0o644)
+	git("add", "later.txt")
+	git("commit", "-q", "-m", "later main commit")
+	git("remote", "add", "origin", "https://github.com/o/r.git")
+	baseTip, head := git("rev-parse", "main"), git("rev-parse", "feature")
+
+	// The PR's head has nothing of its own; only the base branch moved.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"number": 7, "title": "Nothing yet", "base": {"sha": %q, "ref": "main"}, "head": {"sha": %q, "ref": "feature"}}`, baseTip, head)
+	}))
+	defer srv.Close()
+	t.Setenv("GITHUB_API_URL", srv.URL)
+	t.Setenv("GITHUB_TOKEN", "gh-test")
+	t.Setenv("HOME", t.TempDir())
+	t.Chdir(dir)
+	setCmdFlags(t, autoReviewCmd, map[string]string{"pr": "7"})
+	var stderr bytes.Buffer
+	autoReviewCmd.SetErr(&stderr)
+	defer autoReviewCmd.SetErr(nil)
+
+	if err := runAutoReview(autoReviewCmd, nil); err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	if !strings.Contains(stderr.String(), noChangesMessage(head, head)) {
+		t.Errorf("want the review to start at the merge base and find no changes; stderr:\n%s", stderr.String())
+	}
+}
+
+func TestConsensusPublishesProgressToProgressDir(t *testing.T) {
+	// A stand-in for the Anthropic API, answering both plain and streamed
+	// calls.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), `"stream":true`) {
+			fmt.Fprint(w, `{"content":[{"type":"text","text":"Use a cache."}],"usage":{"input_tokens":10,"output_tokens":3}}`)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, ev := range []string{
+			`{"type":"message_start","message":{"usage":{"input_tokens":10}}}`,
+			`{"type":"content_block_delta","delta":{"type":"text_delta","text":"Use a cache."}}`,
+			`{"type":"message_stop"}`,
+		} {
+			fmt.Fprintf(w, "data: %s\n\n", ev)
+		}
+	}))
+	defer srv.Close()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("TMPDIR", t.TempDir())
+	t.Setenv("ANTHROPIC_API_KEY", "test-key")
+	t.Setenv("ANTHROPIC_BASE_URL", srv.URL)
+	for _, key := range []string{"GEMINI_API_KEY", "OPENAI_API_KEY", "OLLAMA_MODEL"} {
+		t.Setenv(key, "")
+	}
+	dir := filepath.Join(t.TempDir(), "progress")
+	setFlags(t, map[string]string{
+		"mode": "general-prompt", "prompt": "How to speed this up?",
+		"progress-dir": dir, "stream-stage1": "true", "no-cache": "true", "quiet": "true",
+	})
+	consensusCmd.SetOut(io.Discard)
+	defer consensusCmd.SetOut(nil)
+
+	if err := runConsensus(consensusCmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, consensus.ProgressTopic+".jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	runIDs := map[string]bool{}
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var env struct {
+			Payload consensus.ProgressEvent `json:"payload"`
+		}
+		if err := json.Unmarshal([]byte(line), &env); err != nil {
+			t.Fatal(err)
+		}
+		runIDs[env.Payload.RunID] = true
+	}
+	if len(runIDs) != 1 || runIDs[""] {
+		t.Errorf("progress events carry run IDs %v, want the run's one ID:\n%s", runIDs, data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, consensus.StreamTopic("Claude")+".jsonl")); err != nil {
+		t.Errorf("stage 1 response was not streamed: %v", err)
+	}
+}
+
+func TestPrintWarmCost(t *testing.T) {
+	stats := consensus.WarmStats{Agents: []consensus.WarmAgentStats{
+		{Agent: "Claude", Model: "claude-opus-4-5", Tokens: consensus.TokenUsage{InputTokens: 100000, OutputTokens: 20000}},
+		{Agent: "Local", Tokens: consensus.TokenUsage{InputTokens: 500}},
+	}}
+	var out bytes.Buffer
+	printWarmCost(&out, stats, config.DefaultModelPrices)
+	for _, want := range []string{
+		"Claude (claude-opus-4-5): ~100000 in, ~20000 out, ~$1.0000",
+		"Local (-): ~500 in, ~0 out, no price for model",
+		"Estimated cost: ~$1.0000",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestLinkLatest(t *testing.T) {
+	dir := t.TempDir()
+	report := func(name, text string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	readLink := func(path string) string {
+		t.Helper()
+		target, err := os.Readlink(path)
+		if err != nil {
+			t.Fatalf("%s is not a link: %v", path, err)
+		}
+		return target
+	}
+	first, second := report("first.md", "first"), report("second.md", "second")
+
+	t.Run("fresh link", func(t *testing.T) {
+		link := filepath.Join(dir, "new", "latest.md")
+		if err := linkLatest(first, link); err != nil {
+			t.Fatal(err)
+		}
+		if got := readLink(link); got != first {
+			t.Errorf("link -> %s, want %s", got, first)
+		}
+	})
+	t.Run("replaces a link", func(t *testing.T) {
+		link := filepath.Join(dir, "latest.md")
+		linkLatest(first, link)
+		if err := linkLatest(second, link); err != nil {
+			t.Fatal(err)
+		}
+		if got := readLink(link); got != second {
+			t.Errorf("link -> %s, want %s", got, second)
+		}
+	})
+	t.Run("replaces a file", func(t *testing.T) {
+		link := report("plain.md", "stale")
+		if err := linkLatest(second, link); err != nil {
+			t.Fatal(err)
+		}
+		if got := readLink(link); got != second {
+			t.Errorf("link -> %s, want %s", got, second)
+		}
+	})
+	t.Run("refuses a directory", func(t *testing.T) {
+		target := filepath.Join(dir, "reports")
+		os.Mkdir(target, 0o755)
+		if err := linkLatest(first, target); err == nil || !strings.Contains(err.Error(), "is a directory") {
+			t.Errorf("err = %v, want a directory error", err)
+		}
+	})
+	t.Run("copies without symlinks", func(t *testing.T) {
+		orig := symlink
+		symlink = func(string, string) error { return fmt.Errorf("symlinks unsupported") }
+		defer func() { symlink = orig }()
+		link := filepath.Join(dir, "copied.md")
+		if err := linkLatest(second, link); err != nil {
+			t.Fatal(err)
+		}
+		if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink != 0 {
+			t.Fatalf("want a regular file copy, got %v (%v)", info, err)
+		}
+		if data, _ := os.ReadFile(link); string(data) != "second" {
+			t.Errorf("copy = %q, want the report", data)
+		}
+	})
+}
+
+func TestLatestSymlinkFlagTakesItsValue(t *testing.T) {
+	f := consensusCmd.Flags().Lookup("latest-symlink")
+	defer f.Value.Set(f.DefValue)
+	if err := consensusCmd.Flags().Parse([]string{"--latest-symlink", "/reports/latest.md"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := f.Value.String(); got != "/reports/latest.md" {
+		t.Errorf("--latest-symlink = %q, want the path after it", got)
+	}
+	if args := consensusCmd.Flags().Args(); len(args) != 0 {
+		t.Errorf("stray args %q", args)
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/signalnine/conclave/internal/ralph"
+)
+
+// Process exit codes. Scripts can tell "couldn't finish" apart from
+// "misconfigured" without parsing stderr.
+const (
+	ExitOK            = 0
+	ExitFailure       = 1   // any other error
+	ExitMaxIterations = 2   // ralph-run exhausted its iterations without passing
+	ExitLockHeld      = 3   // another ralph loop holds the directory lock
+	ExitConfig        = 4   // invalid flags or configuration
+	ExitSuperseded    = 5   // ralph-run stopped because a peer completed the objective
+	ExitUnsafePath    = 6   // ralph-run changed files outside its allowed paths
+	ExitBlocked       = 7   // consensus --require-unanimous: an agent blocked
+	ExitCanceled      = 130 // interrupted by SIGINT/SIGTERM
+)
+
+// exitError attaches an explicit exit code to an error.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+// configError marks err as a configuration problem (ExitConfig).
+func configError(err error) error {
+	return &exitError{code: ExitConfig, err: err}
+}
+
+// exitCode maps an error returned by a command to the process exit code.
+func exitCode(err error) int {
+	var ee *exitError
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.As(err, &ee):
+		return ee.code
+	case errors.Is(err, ralph.ErrMaxIterations):
+		return ExitMaxIterations
+	case errors.Is(err, ralph.ErrLockHeld):
+		return ExitLockHeld
+	case errors.Is(err, ralph.ErrSuperseded):
+		return ExitSuperseded
+	case errors.Is(err, ralph.ErrUnsafePath):
+		return ExitUnsafePath
+	case errors.Is(err, context.Canceled):
+		return ExitCanceled
+	}
+	return ExitFailure
+}
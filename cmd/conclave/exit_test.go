@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/signalnine/conclave/internal/ralph"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"success", nil, ExitOK},
+		{"max iterations", ralph.ErrMaxIterations, ExitMaxIterations},
+		{"wrapped max iterations", fmt.Errorf("task add-auth: %w", ralph.ErrMaxIterations), ExitMaxIterations},
+		{"lock held", fmt.Errorf("%w (PID 42)", ralph.ErrLockHeld), ExitLockHeld},
+		{"superseded", fmt.Errorf("%w: task-b completed", ralph.ErrSuperseded), ExitSuperseded},
+		{"unsafe path", fmt.Errorf("%w: ../README.md", ralph.ErrUnsafePath), ExitUnsafePath},
+		{"config", configError(errors.New("--task is required")), ExitConfig},
+		{"blocked", &exitError{code: ExitBlocked, err: errors.New("unanimous gate: blocked by Gemini")}, ExitBlocked},
+		{"canceled", context.Canceled, ExitCanceled},
+		{"other", errors.New("boom"), ExitFailure},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.want {
+				t.Errorf("exitCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRalphRunMissingTaskIsConfigError(t *testing.T) {
+	err := runRalphRun(ralphRunCmd, nil)
+	if exitCode(err) != ExitConfig {
+		t.Errorf("exitCode = %d, want %d (err: %v)", exitCode(err), ExitConfig, err)
+	}
+}
+
+func TestRalphRunStopsWhenPeerCompletes(t *testing.T) {
+	dir := t.TempDir()
+	boardDir := filepath.Join(dir, "board")
+	os.Mkdir(boardDir, 0o755)
+	peer := `{"seq":1,"type":"board.complete","sender":"task-b","payload":{"objective":"fix-login"}}` + "\n"
+	if err := os.WriteFile(filepath.Join(boardDir, "wave.jsonl"), []byte(peer), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Chdir(dir)
+	setCmdFlags(t, ralphRunCmd, map[string]string{
+		"task":      "Fix the login redirect",
+		"board-dir": boardDir,
+		"task-id":   "task-a",
+		"objective": "fix-login",
+	})
+
+	err := runRalphRun(ralphRunCmd, nil)
+	if !errors.Is(err, ralph.ErrSuperseded) {
+		t.Fatalf("err = %v, want ErrSuperseded", err)
+	}
+	if exitCode(err) != ExitSuperseded {
+		t.Errorf("exitCode = %d, want %d", exitCode(err), ExitSuperseded)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".ralph.lock")); !os.IsNotExist(err) {
+		t.Errorf("lock not released after early exit: %v", err)
+	}
+}
+
+func TestRalphRunJSONLog(t *testing.T) {
+	dir := t.TempDir()
+	boardDir := filepath.Join(dir, "board")
+	os.Mkdir(boardDir, 0o755)
+	peer := `{"seq":1,"type":"board.complete","sender":"task-b","payload":{"objective":"fix-login"}}` + "\n"
+	if err := os.WriteFile(filepath.Join(boardDir, "wave.jsonl"), []byte(peer), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Chdir(dir)
+	setCmdFlags(t, ralphRunCmd, map[string]string{
+		"task":       "Fix the login redirect",
+		"board-dir":  boardDir,
+		"task-id":    "task-a",
+		"objective":  "fix-login",
+		"log-format": "json",
+	})
+	var stderr bytes.Buffer
+	ralphRunCmd.SetErr(&stderr)
+	defer ralphRunCmd.SetErr(nil)
+
+	if err := runRalphRun(ralphRunCmd, nil); !errors.Is(err, ralph.ErrSuperseded) {
+		t.Fatalf("err = %v, want ErrSuperseded", err)
+	}
+	var last map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(stderr.String()), "\n") {
+		last = nil
+		if err := json.Unmarshal([]byte(line), &last); err != nil {
+			t.Fatalf("stderr line is not JSON: %q", line)
+		}
+	}
+	if last["event"] != ralph.EventOutcome || last["outcome"] != ralph.OutcomeSuperseded || !strings.HasPrefix(last["task"].(string), "ralph-") {
+		t.Errorf("last event = %v, want the superseded outcome for the task", last)
+	}
+}
+
+func TestRalphRunUnknownLogFormatIsConfigError(t *testing.T) {
+	setCmdFlags(t, ralphRunCmd, map[string]string{"task": "x", "log-format": "yaml"})
+	if err := runRalphRun(ralphRunCmd, nil); exitCode(err) != ExitConfig {
+		t.Errorf("exitCode = %d, want %d (err: %v)", exitCode(err), ExitConfig, err)
+	}
+}
+
+func TestRalphRunResumeReviewsAndSquashesFromLoopStart(t *testing.T) {
+	dir := t.TempDir()
+	git := func(args ...string) string {
+		t.Helper()
+		out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %s %v", args, out, err)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "-q", "-b", "main")
+	git("config", "user.email", "test@test.com")
+	git("config", "user.name", "Test")
+	git("commit", "-q", "--allow-empty", "-m", "initial")
+
+	// An interrupted run left saved state and one checkpoint commit.
+	if err := ralph.NewStateManager(dir).Init("ralph-7", 5); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(dir, "before.txt"), []byte("before\n"), 0o644)
+	git("add", "before.txt")
+	git("commit", "-q", "-m", "Ralph Loop checkpoint: ralph-7 iteration 1")
+
+	// The implementer writes after.txt; the reviewer passes only if the
+	// diff it is shown includes the pre-interruption work.
+	agent := filepath.Join(t.TempDir(), "agent.sh")
+	os.WriteFile(agent, []byte(`case "$1" in
+*"Review this implementation"*)
+	case "$1" in
+	*+before*) echo '{"verdict": "pass", "reasons": []}' ;;
+	*) echo '{"verdict": "fail", "reasons": ["before.txt is missing"]}' ;;
+	esac ;;
+*) echo after > after.txt ;;
+esac
+`), 0o755)
+	t.Setenv("HOME", t.TempDir())
+	t.Chdir(dir)
+	setCmdFlags(t, ralphRunCmd, map[string]string{
+		"task":           "Write both files",
+		"resume":         "ralph-7",
+		"implement-cmd":  "sh " + agent + " {prompt}",
+		"test-cmd":       "true",
+		"squash":         "true",
+		"max-iterations": "1",
+	})
+
+	if err := runRalphRun(ralphRunCmd, nil); err != nil {
+		t.Fatalf("resumed run failed: %v", err)
+	}
+	if log := git("log", "--format=%s"); log != "Ralph Loop: ralph-7\ninitial" {
+		t.Errorf("log = %q, want both runs' checkpoints squashed onto the loop's start", log)
+	}
+	if files := git("show", "--name-only", "--format=", "HEAD"); !strings.Contains(files, "before.txt") || !strings.Contains(files, "after.txt") {
+		t.Errorf("squashed commit files = %q", files)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/signalnine/conclave/internal/config"
+	"github.com/signalnine/conclave/internal/consensus"
+	"github.com/spf13/cobra"
+)
+
+var consensusListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List labeled consensus runs",
+	Long:  `Lists runs saved with "conclave consensus --label", oldest first.`,
+	Args:  cobra.NoArgs,
+	RunE:  runConsensusList,
+}
+
+var consensusShowCmd = &cobra.Command{
+	Use:   "show <label>",
+	Short: "Print the report of a labeled consensus run",
+	Long:  "Prints the stored report of the newest run saved under the label.",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runConsensusShow,
+}
+
+func init() {
+	consensusCmd.AddCommand(consensusListCmd)
+	consensusCmd.AddCommand(consensusShowCmd)
+}
+
+// openHistory opens the labeled run history configured via
+// CONCLAVE_HISTORY_DIR.
+func openHistory(cfg *config.Config) (*consensus.History, error) {
+	dir := cfg.HistoryDir
+	if dir == "" {
+		dir = consensus.DefaultHistoryDir()
+	}
+	return consensus.OpenHistory(dir)
+}
+
+func runConsensusList(cmd *cobra.Command, args []string) error {
+	h, err := openHistory(config.Load())
+	if err != nil {
+		return err
+	}
+	records, err := h.List()
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	if len(records) == 0 {
+		fmt.Fprintln(out, `No labeled runs. Save one with "conclave consensus --label <name> ..."`)
+		return nil
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "LABEL\tDATE\tMODE\tRUN ID")
+	for _, r := range records {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Label, r.Date.Format("2006-01-02 15:04:05"), r.Mode, r.RunID)
+	}
+	return w.Flush()
+}
+
+func runConsensusShow(cmd *cobra.Command, args []string) error {
+	h, err := openHistory(config.Load())
+	if err != nil {
+		return err
+	}
+	rec, err := h.Latest(args[0])
+	if err != nil {
+		return err
+	}
+	report, err := h.ReadReport(rec)
+	if err != nil {
+		return fmt.Errorf("read report for %q: %w", rec.Label, err)
+	}
+	_, err = cmd.OutOrStdout().Write(report)
+	return err
+}
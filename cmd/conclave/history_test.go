@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/signalnine/conclave/internal/config"
+)
+
+func TestConsensusListAndShow(t *testing.T) {
+	t.Setenv("CONCLAVE_HISTORY_DIR", t.TempDir())
+	report := filepath.Join(t.TempDir(), "consensus-abc.md")
+	if err := os.WriteFile(report, []byte("# Multi-Agent Consensus Analysis\n\nship it\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveLabeledRun(config.Load(), "auth-refactor-round2", "code-review", "run-42", report); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	consensusListCmd.SetOut(&out)
+	defer consensusListCmd.SetOut(nil)
+	if err := runConsensusList(consensusListCmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); !strings.Contains(got, "auth-refactor-round2") || !strings.Contains(got, "run-42") || !strings.Contains(got, "code-review") {
+		t.Errorf("list output = %q", got)
+	}
+
+	out.Reset()
+	consensusShowCmd.SetOut(&out)
+	defer consensusShowCmd.SetOut(nil)
+	if err := runConsensusShow(consensusShowCmd, []string{"auth-refactor-round2"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "ship it") {
+		t.Errorf("show output = %q, want the stored report", out.String())
+	}
+	if err := runConsensusShow(consensusShowCmd, []string{"unknown"}); err == nil {
+		t.Error("show of an unknown label should fail")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/signalnine/conclave/internal/config"
 	"github.com/signalnine/conclave/internal/hook"
 	"github.com/spf13/cobra"
 )
@@ -20,8 +21,20 @@ var hookSessionStartCmd = &cobra.Command{
 	RunE:  runHookSessionStart,
 }
 
+var hookPostToolUseCmd = &cobra.Command{
+	Use:   "post-tool-use",
+	Short: "Handle PostToolUse hook event",
+	Long: `Records the tool use read from stdin (e.g. "edited internal/bus/bus.go") as a
+board.context entry, so agents sharing the board see what the session touched.
+Nothing is recorded unless --board-dir or CONCLAVE_BOARD_DIR is set.`,
+	RunE: runHookPostToolUse,
+}
+
 func init() {
+	hookPostToolUseCmd.Flags().String("board-dir", "", "Board directory to record tool use in (default $CONCLAVE_BOARD_DIR)")
+	hookPostToolUseCmd.Flags().String("topic", "session", "Board topic for the recorded entries")
 	hookCmd.AddCommand(hookSessionStartCmd)
+	hookCmd.AddCommand(hookPostToolUseCmd)
 	rootCmd.AddCommand(hookCmd)
 }
 
@@ -41,6 +54,22 @@ func runHookSessionStart(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func runHookPostToolUse(cmd *cobra.Command, args []string) error {
+	boardDir, _ := cmd.Flags().GetString("board-dir")
+	if boardDir == "" {
+		boardDir = os.Getenv("CONCLAVE_BOARD_DIR")
+	}
+	topic, _ := cmd.Flags().GetString("topic")
+
+	output, err := hook.PostToolUse(cmd.InOrStdin(), boardDir, topic, config.Load().BoardMaxWriters)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(output)
+	return nil
+}
+
 func findPluginRoot() string {
 	// Check if CLAUDE_PLUGIN_ROOT is set
 	if root := os.Getenv("CLAUDE_PLUGIN_ROOT"); root != "" {
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -9,6 +10,7 @@ import (
 	"time"
 
 	"github.com/signalnine/conclave/internal/bus"
+	"github.com/signalnine/conclave/internal/config"
 	gitpkg "github.com/signalnine/conclave/internal/git"
 	"github.com/signalnine/conclave/internal/parallel"
 	"github.com/signalnine/conclave/internal/plan"
@@ -27,6 +29,7 @@ func init() {
 	parallelRunCmd.Flags().String("plan", "", "Path to implementation plan file (required)")
 	parallelRunCmd.Flags().Int("max-concurrent", 3, "Maximum concurrent tasks")
 	parallelRunCmd.Flags().Bool("dry-run", false, "Parse and validate plan only")
+	parallelRunCmd.Flags().Duration("snapshot-interval", 30*time.Second, "How often to snapshot each wave's board for crash recovery (0 disables)")
 	rootCmd.AddCommand(parallelRunCmd)
 }
 
@@ -34,6 +37,7 @@ func runParallelRun(cmd *cobra.Command, args []string) error {
 	planFile, _ := cmd.Flags().GetString("plan")
 	maxConc, _ := cmd.Flags().GetInt("max-concurrent")
 	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	snapshotEvery, _ := cmd.Flags().GetDuration("snapshot-interval")
 
 	if planFile == "" {
 		return fmt.Errorf("--plan is required")
@@ -104,6 +108,21 @@ func runParallelRun(cmd *cobra.Command, args []string) error {
 
 		waveTopic := fmt.Sprintf("parallel.wave-%d.board", wave)
 
+		// Snapshot the wave's board so a restarted coordinator can resume
+		// from the snapshot instead of re-reading every entry.
+		stopSnapshots := func() {}
+		if snapshotEvery > 0 {
+			snapCtx, cancel := context.WithCancel(context.Background())
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				ralph.SnapshotBoardEvery(snapCtx, waveBusDir, filepath.Join(waveBusDir, ralph.BoardSnapshotFile), snapshotEvery, func(err error) {
+					fmt.Fprintf(os.Stderr, "  Warning: board snapshot: %v\n", err)
+				})
+			}()
+			stopSnapshots = func() { cancel(); <-done }
+		}
+
 		for _, taskID := range ready {
 			fmt.Fprintf(os.Stderr, "  Task %d: launching...\n", taskID)
 			sched.MarkRunning(taskID, 0, "")
@@ -116,6 +135,7 @@ func runParallelRun(cmd *cobra.Command, args []string) error {
 			// For now, mark as completed since the actual execution requires claude CLI
 			sched.MarkDone(taskID, parallel.StatusCompleted)
 		}
+		stopSnapshots()
 
 		// Merge completed tasks
 		completedIDs := sched.WaveCompletedIDs(wave)
@@ -143,7 +163,7 @@ func runParallelRun(cmd *cobra.Command, args []string) error {
 			if len(entries) > 0 {
 				nextWaveBusDir := filepath.Join(busDir, fmt.Sprintf("wave-%d", wave+1))
 				if err := os.MkdirAll(nextWaveBusDir, 0755); err == nil {
-					fileBus, busErr := bus.NewFileBus(nextWaveBusDir, 100*time.Millisecond, time.Second)
+					fileBus, busErr := bus.NewBoardBus(nextWaveBusDir, config.Load().BoardMaxWriters)
 					if busErr == nil {
 						summary := ralph.FormatBoardContext(entries)
 						payload, _ := json.Marshal(struct {
@@ -2,13 +2,18 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
 	"os"
-	"os/exec"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/signalnine/conclave/internal/bus"
+	"github.com/signalnine/conclave/internal/config"
 	gitpkg "github.com/signalnine/conclave/internal/git"
 	"github.com/signalnine/conclave/internal/ralph"
 	"github.com/spf13/cobra"
@@ -24,85 +29,209 @@ var ralphRunCmd = &cobra.Command{
 func init() {
 	ralphRunCmd.Flags().String("task", "", "Task description or prompt file (required)")
 	ralphRunCmd.Flags().Int("max-iterations", 5, "Maximum retry iterations")
+	ralphRunCmd.Flags().String("resume", "", "Continue the interrupted loop with this ralph task ID from its saved state")
+	ralphRunCmd.Flags().Int("meta-retries", 0, "Times to start the task over with a fresh context and a new task-level strategy after max iterations")
+	ralphRunCmd.Flags().String("implement-cmd", "", "Implementation gate command; {prompt} is replaced by the prompt, else it is sent on stdin (default: RALPH_IMPLEMENT_CMD or \"claude -p {prompt}\")")
 	ralphRunCmd.Flags().Int("implement-timeout", 300, "Implementation gate timeout (seconds)")
+	ralphRunCmd.Flags().String("test-cmd", "", "Test gate shell command, e.g. \"make check\" (default: RALPH_TEST_CMD, else auto-detect the test runner)")
 	ralphRunCmd.Flags().Int("test-timeout", 120, "Test gate timeout (seconds)")
 	ralphRunCmd.Flags().Int("spec-timeout", 120, "Spec gate timeout (seconds)")
+	ralphRunCmd.Flags().Int("context-limit", -1, "Bytes of previous-attempt context fed into each prompt, keeping the latest error (0 = unlimited; default: RALPH_CONTEXT_LIMIT or 50000)")
 	ralphRunCmd.Flags().Int("stuck-threshold", 3, "Consecutive same-error count before strategy shift")
 	ralphRunCmd.Flags().Bool("skip-spec", false, "Skip spec compliance gate")
+	ralphRunCmd.Flags().Bool("no-commit", false, "Don't commit a checkpoint after each successful implementation")
+	ralphRunCmd.Flags().Bool("squash", false, "Squash the checkpoint commits into one when the task completes")
+	ralphRunCmd.Flags().StringToString("on-failure", nil, "Gate to restart from when a gate fails, e.g. tests=tests,spec=implement (default: implement)")
 	ralphRunCmd.Flags().String("board-dir", "", "Bulletin board directory for cross-task communication")
 	ralphRunCmd.Flags().String("board-topic", "", "Topic to publish board messages to")
 	ralphRunCmd.Flags().String("task-id", "", "Task identifier for board messages")
+	ralphRunCmd.Flags().String("objective", "", "Objective tag shared with peer tasks; stop early when a peer posts board.complete for it (requires --board-dir)")
+	ralphRunCmd.Flags().StringArray("allowed-path", nil, "Directory the loop may change files in, relative to the working directory (repeatable; default: the working directory)")
+	ralphRunCmd.Flags().String("metrics-file", "", "Write Prometheus text-format metrics to this file when the run ends")
+	ralphRunCmd.Flags().String("serve-metrics", "", "Serve Prometheus metrics at http://<addr>/metrics while the run is in progress, e.g. :9464")
+	ralphRunCmd.Flags().String("log-format", ralph.LogText, "Progress output on stderr: text, or json for one JSON object per event")
+	ralphRunCmd.Flags().Duration("lock-wait", 0, "How long to wait for another Ralph loop in this directory to finish (0 = fail immediately)")
+	ralphRunCmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return configError(err)
+	})
 	rootCmd.AddCommand(ralphRunCmd)
 }
 
-func runRalphRun(cmd *cobra.Command, args []string) error {
+func runRalphRun(cmd *cobra.Command, args []string) (err error) {
 	task, _ := cmd.Flags().GetString("task")
 	maxIter, _ := cmd.Flags().GetInt("max-iterations")
 	implTimeout, _ := cmd.Flags().GetInt("implement-timeout")
 	testTimeout, _ := cmd.Flags().GetInt("test-timeout")
+	specTimeout, _ := cmd.Flags().GetInt("spec-timeout")
 	stuckThreshold, _ := cmd.Flags().GetInt("stuck-threshold")
 	skipSpec, _ := cmd.Flags().GetBool("skip-spec")
 	boardDir, _ := cmd.Flags().GetString("board-dir")
 	boardTopic, _ := cmd.Flags().GetString("board-topic")
 	taskID, _ := cmd.Flags().GetString("task-id")
-
+	onFailure, _ := cmd.Flags().GetStringToString("on-failure")
+	objective, _ := cmd.Flags().GetString("objective")
+	metaRetries, _ := cmd.Flags().GetInt("meta-retries")
 	if task == "" {
-		return fmt.Errorf("--task is required")
+		return configError(fmt.Errorf("--task is required"))
+	}
+	if metaRetries < 0 {
+		return configError(fmt.Errorf("--meta-retries must be >= 0"))
+	}
+	if objective != "" && boardDir == "" {
+		return configError(fmt.Errorf("--objective requires --board-dir"))
+	}
+	senderID := taskID
+	if senderID == "" {
+		senderID = "ralph"
+	}
+	cfg := config.Load()
+	applyBoardPrefixes(cfg)
+	gateCfg := ralph.GateConfig{OnFailure: onFailure, Env: cfg.RalphGateEnv}
+	implementCmd := cfg.RalphImplementCmd
+	if v, _ := cmd.Flags().GetString("implement-cmd"); v != "" {
+		implementCmd = v
+	}
+	if strings.TrimSpace(implementCmd) == "" {
+		return configError(fmt.Errorf("--implement-cmd is empty"))
+	}
+	contextLimit := cfg.RalphContextLimit
+	if v, _ := cmd.Flags().GetInt("context-limit"); v >= 0 {
+		contextLimit = v
+	}
+	testCmd := cfg.RalphTestCmd
+	if v, _ := cmd.Flags().GetString("test-cmd"); v != "" {
+		testCmd = v
+	}
+	resumeID, _ := cmd.Flags().GetString("resume")
+	stateTaskID := resumeID
+	if stateTaskID == "" {
+		stateTaskID = fmt.Sprintf("ralph-%d", time.Now().Unix())
+	}
+	logFormat, _ := cmd.Flags().GetString("log-format")
+	elog, err := ralph.NewEventLog(cmd.ErrOrStderr(), logFormat, stateTaskID)
+	if err != nil {
+		return configError(fmt.Errorf("--log-format: %w", err))
 	}
 
 	cwd, _ := os.Getwd()
 	lock := ralph.NewLock(cwd)
-	if err := lock.Acquire(); err != nil {
+	lockWait, _ := cmd.Flags().GetDuration("lock-wait")
+	if err := acquireLock(elog, lock, lockWait); err != nil {
 		return err
 	}
 	defer lock.Release()
 
+	// The lock is ours, so any saved state belongs to a loop that is no
+	// longer running: resume it, or start over.
 	sm := ralph.NewStateManager(cwd)
-	stateTaskID := fmt.Sprintf("ralph-%d", time.Now().Unix())
-	if err := sm.Init(stateTaskID, maxIter); err != nil {
-		return err
-	}
-	defer sm.Cleanup()
-
-	g := gitpkg.New(cwd)
-	ctx := context.Background()
-
-	for {
-		state, err := sm.Load()
+	if resumeID != "" {
+		state, err := sm.Resume(resumeID)
 		if err != nil {
+			return configError(err)
+		}
+		elog.Printf("Resuming %s at iteration %d/%d\n", resumeID, state.Iteration, state.MaxIterations)
+	} else {
+		if old, err := sm.Load(); err == nil {
+			elog.Warnf("discarding saved state of interrupted task %s (resume it with --resume %s)", old.TaskID, old.TaskID)
+		}
+		if err := sm.Init(stateTaskID, maxIter); err != nil {
 			return err
 		}
+	}
+	// The commit the loop started from, recorded by Init and kept across
+	// --resume, when the earlier run's checkpoints are already in HEAD.
+	var startSHA string
+	if state, err := sm.Load(); err == nil {
+		startSHA = state.BaseSHA
+	}
+	// An interrupted loop keeps its state for --resume.
+	defer func() {
+		if errors.Is(err, context.Canceled) {
+			elog.Printf("State kept; continue with --resume %s\n", stateTaskID)
+			return
+		}
+		sm.Cleanup()
+	}()
+	defer func() {
+		fields := ralph.Fields{"outcome": ralph.OutcomeOf(err)}
+		if err != nil {
+			fields["error"] = err.Error()
+		}
+		elog.Event(ralph.EventOutcome, fields, "")
+	}()
 
-		if state.Iteration > state.MaxIterations {
-			fmt.Fprintf(os.Stderr, "\nMax iterations (%d) reached. Branching failed work.\n", maxIter)
-			ralph.BranchFailedWork(g, stateTaskID, state)
-			return fmt.Errorf("max iterations reached")
+	// Deferred after Cleanup so the final metrics still see the state.
+	metrics := ralph.NewMetrics(senderID)
+	metricsFile, _ := cmd.Flags().GetString("metrics-file")
+	if metricsFile != "" {
+		defer func() {
+			metrics.Finish(ralph.OutcomeOf(err))
+			state, _ := sm.Load()
+			if werr := metrics.WriteFile(metricsFile, state); werr != nil {
+				elog.Warnf("could not write metrics: %v", werr)
+			}
+		}()
+	}
+	if addr, _ := cmd.Flags().GetString("serve-metrics"); addr != "" {
+		stopMetrics, err := serveMetrics(elog, addr, metrics.Handler(sm))
+		if err != nil {
+			return configError(fmt.Errorf("--serve-metrics: %w", err))
 		}
+		defer stopMetrics()
+	}
 
-		fmt.Fprintf(os.Stderr, "\n=== Ralph Loop: Iteration %d/%d ===\n", state.Iteration, state.MaxIterations)
+	// Changes outside the allowed paths abort the run before anything, such
+	// as BranchFailedWork, can commit them.
+	allowedPaths, _ := cmd.Flags().GetStringArray("allowed-path")
+	guard, err := ralph.NewPathGuard(cwd, allowedPaths)
+	if err != nil {
+		elog.Warnf("path guard disabled (%v)", err)
+	}
 
-		// Check if stuck
-		stuckDirective := ""
-		if ralph.IsStuck(state.StuckCount, stuckThreshold) {
-			fmt.Fprintln(os.Stderr, "STUCK DETECTED - forcing strategy shift")
-			sm.IncrementStrategyShift()
-			stuckDirective = ralph.StuckDirective
+	g := gitpkg.New(cwd)
+	var checkpoints *ralph.Checkpointer
+	if noCommit, _ := cmd.Flags().GetBool("no-commit"); !noCommit {
+		if cp, err := ralph.NewCheckpointer(cwd, stateTaskID, startSHA, resumeID != ""); err != nil {
+			elog.Warnf("checkpoint commits disabled (%v)", err)
+		} else {
+			checkpoints = cp
+		}
+	}
+	squash, _ := cmd.Flags().GetBool("squash")
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	gateCtl := ralph.GateControl{OnAction: func(gate string, a ralph.GateAction) {
+		msg := gate + " gate restarted by operator (SIGUSR2)"
+		if a == ralph.GateSkip {
+			msg = gate + " gate skipped by operator (SIGUSR1), treating as passed"
 		}
+		elog.Event(ralph.EventWarning, ralph.Fields{"message": msg, "gate": gate, "action": a.String()}, "  "+msg+"\n")
+	}}
+	defer gateCtl.HandleGateSignals()()
 
-		// Gate 1: Implementation
-		fmt.Fprintln(os.Stderr, "Gate 1: Implementation...")
+	// Shared between gates; outputs persist across iterations that restart
+	// past the implement gate. approachDirective carries the task-level
+	// strategy of a meta-retry for the whole approach.
+	var approachDirective, stuckDirective, iterationOutput string
+	var implemented bool // the implement gate passed this iteration
+
+	implementGate := func(ctx context.Context) (string, error) {
+		elog.Printf("Gate 1: Implementation...\n")
 		prompt := task
 		if stuckDirective != "" {
-			prompt = stuckDirective + "\n\n" + task
+			prompt = stuckDirective + "\n\n" + prompt
+		}
+		if approachDirective != "" {
+			prompt = approachDirective + "\n\n" + prompt
 		}
-		ctxContent, _ := os.ReadFile(sm.ContextFile())
+		ctxContent, _ := sm.ReadContext(contextLimit)
 		if len(ctxContent) > 0 {
-			prompt = prompt + "\n\n## Previous Attempt Context\n" + string(ctxContent)
+			prompt = prompt + "\n\n## Previous Attempt Context\n" + ctxContent
 		}
 
 		// Read board at iteration start
 		if boardDir != "" {
-			entries, err := ralph.ReadBoard(boardDir, 20)
+			entries, err := ralph.ReadBoardCtx(ctx, boardDir, 20)
 			if err == nil && len(entries) > 0 {
 				boardCtx := ralph.FormatBoardContext(entries)
 				prompt = prompt + "\n\n" + boardCtx
@@ -110,23 +239,24 @@ func runRalphRun(cmd *cobra.Command, args []string) error {
 		}
 
 		implCtx, implCancel := context.WithTimeout(ctx, time.Duration(implTimeout)*time.Second)
-		implCmd := exec.CommandContext(implCtx, "claude", "-p", prompt)
+		implCmd, err := ralph.ImplementCommand(implCtx, implementCmd, prompt)
+		if err != nil {
+			implCancel()
+			return "", err
+		}
 		implCmd.Dir = cwd
+		implCmd.Env = gateCfg.Environ(ralph.GateImplement)
 		implOut, implErr := implCmd.CombinedOutput()
 		implCancel()
 
-		iterationOutput := string(implOut)
+		iterationOutput = string(implOut)
 
 		// Write board markers from iteration output
 		if boardDir != "" && boardTopic != "" {
 			markers := ralph.ExtractBusMarkers(iterationOutput)
 			if len(markers) > 0 {
-				fileBus, busErr := bus.NewFileBus(boardDir, 100*time.Millisecond, time.Second)
+				fileBus, busErr := bus.NewBoardBus(boardDir, cfg.BoardMaxWriters)
 				if busErr == nil {
-					senderID := taskID
-					if senderID == "" {
-						senderID = "ralph"
-					}
 					ralph.PublishMarkers(fileBus, boardTopic, senderID, markers)
 					fileBus.Close()
 				}
@@ -134,32 +264,232 @@ func runRalphRun(cmd *cobra.Command, args []string) error {
 		}
 
 		if implErr != nil {
-			fmt.Fprintf(os.Stderr, "  Implementation failed: %v\n", implErr)
-			sm.Update("implement", 1, iterationOutput)
-			continue
+			elog.Printf("  Implementation failed: %v\n", implErr)
+			return iterationOutput, implErr
 		}
-		fmt.Fprintln(os.Stderr, "  Implementation complete")
+		elog.Printf("  Implementation complete\n")
+		implemented = true
+		return iterationOutput, nil
+	}
 
-		// Gate 2: Tests
-		fmt.Fprintln(os.Stderr, "Gate 2: Tests...")
-		testOutput, testErr := ralph.RunTestGate(ctx, cwd, testTimeout)
-		if testErr != nil {
-			fmt.Fprintf(os.Stderr, "  Tests failed\n")
-			sm.Update("tests", 1, testOutput)
-			continue
+	testGate := func(ctx context.Context) (string, error) {
+		elog.Printf("Gate 2: Tests...\n")
+		out, err := ralph.RunTestGateCmd(ctx, cwd, testCmd, testTimeout, gateCfg.Environ(ralph.GateTests))
+		if err != nil {
+			elog.Printf("  Tests failed\n")
+			return out, err
+		}
+		elog.Printf("  Tests passed\n")
+		return out, nil
+	}
+
+	// The spec gate reviews everything changed since the loop started.
+	specBase := startSHA
+	if specBase == "" {
+		specBase = gitpkg.EmptyTree
+		if head, err := g.RevParse("HEAD"); err == nil {
+			specBase = head
+		}
+	}
+	specGate := func(ctx context.Context) (string, error) {
+		elog.Printf("Gate 3: Spec compliance...\n")
+		diff, err := g.WorkingDiff(specBase)
+		if err != nil {
+			elog.Printf("  Spec review failed: %v\n", err)
+			return "", err
 		}
-		fmt.Fprintln(os.Stderr, "  Tests passed")
+		out, err := ralph.RunSpecGate(ctx, implementCmd, cwd, task, diff, specTimeout, gateCfg.Environ(ralph.GateSpec))
+		if err != nil {
+			elog.Printf("  Spec compliance failed: %v\n", err)
+			return out, err
+		}
+		elog.Printf("  Spec compliance confirmed\n")
+		return out, nil
+	}
 
-		// Gate 3: Spec (optional)
-		if !skipSpec {
-			fmt.Fprintln(os.Stderr, "Gate 3: Spec compliance...")
-			if strings.Contains(testOutput, "SPEC_PASS") || strings.Contains(iterationOutput, "SPEC_PASS") {
-				fmt.Fprintln(os.Stderr, "  Spec compliance confirmed")
+	gates := []ralph.Gate{
+		{Name: ralph.GateImplement, Run: implementGate},
+		{Name: ralph.GateTests, Run: testGate},
+	}
+	if !skipSpec {
+		gates = append(gates, ralph.Gate{Name: ralph.GateSpec, Run: specGate})
+	}
+	if err := gateCfg.ValidateOnFailure(gates); err != nil {
+		return configError(err)
+	}
+	var iterTimer ralph.IterationTimer
+	gates = elog.LogGates(iterTimer.TimeGates(metrics.TimeGates(gates)))
+	// recordIteration appends the iteration that started at state to the
+	// iterations file.
+	recordIteration := func(state *ralph.State, failed string) {
+		after, err := sm.Load()
+		if err != nil {
+			after = state
+		}
+		if err := sm.AppendIteration(iterTimer.Record(state, after, failed)); err != nil {
+			elog.Warnf("could not record iteration metrics: %v", err)
+		}
+	}
+	from := ralph.GateImplement
+	if resumeID != "" {
+		state, err := sm.Load()
+		if err != nil {
+			return err
+		}
+		if state.LastGate != "" {
+			from = gateCfg.RetryFrom(gates, state.LastGate)
+		}
+		if state.CurrentApproach() > 1 {
+			if strategy, ok := ralph.TaskStrategyNamed(state.Approaches[len(state.Approaches)-1].Strategy); ok {
+				approachDirective = strategy.Directive
 			}
 		}
+	}
+
+	for {
+		state, err := sm.Load()
+		if err != nil {
+			return err
+		}
+
+		if state.Iteration > state.MaxIterations {
+			if approach := state.CurrentApproach(); approach <= metaRetries {
+				strategy := ralph.NextTaskStrategy(approach)
+				elog.Event(ralph.EventStrategyShift, ralph.Fields{"kind": "meta_retry", "approach": approach + 1, "strategy": strategy.Name},
+					fmt.Sprintf("\nMax iterations (%d) reached on approach %d. Meta-retry %d/%d: starting over with the %q strategy.\n",
+						maxIter, approach, approach, metaRetries, strategy.Name))
+				ralph.BranchFailedWork(g, fmt.Sprintf("%s-approach%d", stateTaskID, approach), state)
+				if _, err := sm.StartApproach(strategy.Name); err != nil {
+					return err
+				}
+				approachDirective = strategy.Directive
+				from = gates[0].Name
+				continue
+			}
+			elog.Printf("\nMax iterations (%d) reached. Branching failed work.\n", maxIter)
+			ralph.BranchFailedWork(g, stateTaskID, state)
+			return ralph.ErrMaxIterations
+		}
+
+		if objective != "" {
+			if peer, ok, _ := ralph.PeerCompletion(boardDir, objective, senderID); ok {
+				elog.Printf("\nPeer %s completed objective %q. Stopping.\n", peer.Sender, objective)
+				return fmt.Errorf("%w: %s completed %q", ralph.ErrSuperseded, peer.Sender, objective)
+			}
+		}
+
+		banner := fmt.Sprintf("\n=== Ralph Loop: Iteration %d/%d ===\n", state.Iteration, state.MaxIterations)
+		if approach := state.CurrentApproach(); approach > 1 {
+			banner = fmt.Sprintf("\n=== Ralph Loop: Approach %d, Iteration %d/%d ===\n", approach, state.Iteration, state.MaxIterations)
+		}
+		elog.StartIteration(state.CurrentApproach(), state.Iteration, state.MaxIterations, banner)
+
+		// Check if stuck
+		stuckDirective = ""
+		if ralph.IsStuck(state.StuckCount, stuckThreshold) {
+			elog.Event(ralph.EventStuckDetected, ralph.Fields{"gate": state.LastGate, "class": state.FailureClass, "stuck_count": state.StuckCount},
+				"STUCK DETECTED - forcing strategy shift\n")
+			elog.Event(ralph.EventStrategyShift, ralph.Fields{"kind": "stuck", "strategy_shifts": state.StrategyShifts + 1}, "")
+			sm.IncrementStrategyShift()
+			stuckDirective = ralph.StuckDirectiveFor(ralph.FailureClass(state.FailureClass))
+			from = gates[0].Name // a strategy shift needs a fresh implementation
+		}
+
+		if from != "" && from != gates[0].Name {
+			elog.Printf("Resuming from %s gate (on-failure target)\n", from)
+		}
+		implemented = false
+		iterTimer.Start()
+		failed, output, gateErr := ralph.RunGatesControlled(ctx, gates, from, &gateCtl)
+		if ctx.Err() != nil {
+			elog.Printf("\nInterrupted, stopping ralph loop.\n")
+			return ctx.Err()
+		}
+		if guard != nil {
+			if err := guard.Enforce(); errors.Is(err, ralph.ErrUnsafePath) {
+				elog.Printf("\nWARNING: %v\nAborting without committing; review and revert these changes by hand.\n", err)
+				return err
+			} else if err != nil {
+				elog.Warnf("%v", err)
+			}
+		}
+		// Checkpoint only after the guard, so unsafe changes are never committed.
+		if implemented && checkpoints != nil {
+			if ok, err := checkpoints.Commit(state.Iteration); err != nil {
+				elog.Warnf("checkpoint commit failed: %v", err)
+			} else if ok {
+				elog.Printf("Checkpoint committed for iteration %d\n", state.Iteration)
+			}
+		}
+		if failed != "" {
+			class := ralph.ClassifyFailure(output, gateErr)
+			elog.Printf("  Failure class: %s\n", class)
+			sm.UpdateFailure(failed, 1, output, class)
+			recordIteration(state, failed)
+			from = gateCfg.RetryFrom(gates, failed)
+			continue
+		}
 
 		// All gates passed
-		fmt.Fprintln(os.Stderr, "\nAll gates passed! Task complete.")
+		recordIteration(state, "")
+		elog.Printf("\nAll gates passed! Task complete.\n")
+		if squash && checkpoints != nil && checkpoints.Commits() > 1 {
+			if err := checkpoints.Squash(state.Iteration); err != nil {
+				elog.Warnf("could not squash checkpoints: %v", err)
+			} else {
+				elog.Printf("Checkpoints squashed into one commit\n")
+			}
+		}
+		if objective != "" && boardTopic != "" {
+			if fileBus, err := bus.NewBoardBus(boardDir, cfg.BoardMaxWriters); err == nil {
+				ralph.PublishCompletion(fileBus, boardTopic, senderID, objective)
+				fileBus.Close()
+			}
+		}
 		return nil
 	}
 }
+
+// serveMetrics serves handler at /metrics on addr until the returned stop
+// function is called.
+func serveMetrics(elog *ralph.EventLog, addr string, handler http.Handler) (func(), error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", handler)
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go server.Serve(ln)
+	elog.Printf("Serving metrics at http://%s/metrics\n", ln.Addr())
+	return func() { server.Close() }, nil
+}
+
+// applyBoardPrefixes registers board type prefixes configured via
+// RALPH_BOARD_PREFIXES.
+func applyBoardPrefixes(cfg *config.Config) {
+	for typ, prefix := range cfg.BoardPrefixes {
+		ralph.RegisterBoardPrefix(typ, prefix)
+	}
+}
+
+// acquireLock takes the ralph lock, waiting up to wait for a running loop to
+// release it. The error reports the holder and how long we waited.
+func acquireLock(elog *ralph.EventLog, lock *ralph.Lock, wait time.Duration) error {
+	if wait <= 0 {
+		return lock.Acquire()
+	}
+	if pid := lock.Holder(); pid > 0 && pid != os.Getpid() {
+		elog.Printf("Waiting up to %s for Ralph loop PID %d to finish...\n", wait, pid)
+	}
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), wait)
+	defer cancel()
+	if err := lock.AcquireWithTimeout(ctx); err != nil {
+		if errors.Is(err, ralph.ErrLockHeld) {
+			return fmt.Errorf("%w; gave up after waiting %s", err, time.Since(start).Round(time.Second))
+		}
+		return err
+	}
+	return nil
+}
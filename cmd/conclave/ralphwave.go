@@ -0,0 +1,280 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/signalnine/conclave/internal/config"
+	gitpkg "github.com/signalnine/conclave/internal/git"
+	"github.com/signalnine/conclave/internal/ralph"
+	"github.com/spf13/cobra"
+)
+
+var ralphWaveCmd = &cobra.Command{
+	Use:   "ralph-wave",
+	Short: "Run several ralph loops concurrently, sharing a bulletin board",
+	Long: `Runs every task in a task list through its own ralph-run loop, up to
+--concurrency at a time. Each loop gets a git worktree on its own branch,
+ralph/<wave>/<task-id>, branched from HEAD, so it has its own lock, state
+and checkpoints. The loops share one bulletin board: discoveries one task
+posts reach the others at their next iteration.
+
+The task list has one task per line, either the task itself or the path
+(relative to the list) of a file holding it; blank lines and # comments are
+skipped. Tasks are numbered task-1, task-2, ... in list order.
+
+Each loop's output goes to a log under .conclave/ralph-wave/<wave>/. A
+task's worktree is removed when its loop ends, failed or not, since its
+commits are on a branch. A worktree with uncommitted changes is kept, as is
+an interrupted loop's, for ralph-run --resume, and one stopped by the path
+guard, for review. When all loops end, a summary reports which tasks
+completed, which hit max iterations and which failed. Exits non-zero unless
+every task completed.`,
+	RunE: runRalphWave,
+}
+
+func init() {
+	ralphWaveCmd.Flags().String("tasks", "", "Task list file, one task or task file per line (required)")
+	ralphWaveCmd.Flags().Int("concurrency", 0, "Loops to run at once (default: PARALLEL_MAX_CONCURRENT or 3)")
+	ralphWaveCmd.Flags().Int("max-iterations", 5, "Maximum retry iterations per task")
+	rootCmd.AddCommand(ralphWaveCmd)
+}
+
+// waveTopic is the board topic a wave's loops publish to.
+const waveTopic = "ralph-wave.board"
+
+// ralphExecutable locates the binary the wave's loops run; tests replace
+// it.
+var ralphExecutable = os.Executable
+
+func runRalphWave(cmd *cobra.Command, args []string) error {
+	tasksFile, _ := cmd.Flags().GetString("tasks")
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+	maxIter, _ := cmd.Flags().GetInt("max-iterations")
+	if tasksFile == "" {
+		return configError(fmt.Errorf("--tasks is required"))
+	}
+	cfg := config.Load()
+	if concurrency == 0 {
+		concurrency = cfg.MaxConcurrent
+	}
+	if concurrency <= 0 {
+		return configError(fmt.Errorf("--concurrency must be positive"))
+	}
+
+	f, err := os.Open(tasksFile)
+	if err != nil {
+		return configError(err)
+	}
+	tasks, err := ralph.ParseTaskList(f, filepath.Dir(tasksFile))
+	f.Close()
+	if err != nil {
+		return configError(fmt.Errorf("%s: %w", tasksFile, err))
+	}
+	if len(tasks) == 0 {
+		return configError(fmt.Errorf("%s lists no tasks", tasksFile))
+	}
+
+	g := gitpkg.New(".")
+	top, err := g.TopLevel()
+	if err != nil {
+		return configError(fmt.Errorf("ralph-wave runs each task in a git worktree: %w", err))
+	}
+	base, err := g.RevParse("HEAD")
+	if err != nil {
+		return configError(fmt.Errorf("ralph-wave needs a commit to branch the tasks from: %w", err))
+	}
+	exe, err := ralphExecutable()
+	if err != nil {
+		return err
+	}
+
+	wave := fmt.Sprintf("wave-%d", time.Now().Unix())
+	runDir := filepath.Join(top, ".conclave", "ralph-wave", wave)
+	boardDir := filepath.Join(runDir, "board")
+	if err := os.MkdirAll(boardDir, 0755); err != nil {
+		return fmt.Errorf("creating board directory: %w", err)
+	}
+	worktrees := cfg.WorktreeDir
+	if !filepath.IsAbs(worktrees) {
+		worktrees = filepath.Join(top, worktrees)
+	}
+	w := &waveRunner{
+		exe:       exe,
+		git:       gitpkg.New(top),
+		base:      base,
+		wave:      wave,
+		runDir:    runDir,
+		boardDir:  boardDir,
+		worktrees: filepath.Join(worktrees, wave),
+		maxIter:   maxIter,
+		progress:  cmd.ErrOrStderr(),
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	w.printf("Ralph wave %s: %d tasks, %d at a time, board %s\n", wave, len(tasks), concurrency, boardDir)
+	results := ralph.RunWave(ctx, tasks, concurrency, w.run)
+
+	if err := printWaveSummary(cmd.OutOrStdout(), results); err != nil {
+		return err
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	var completed int
+	for _, r := range results {
+		if r.Outcome == ralph.OutcomeSuccess {
+			completed++
+		}
+	}
+	if completed < len(results) {
+		return fmt.Errorf("%d of %d tasks did not complete", len(results)-completed, len(results))
+	}
+	return nil
+}
+
+// waveRunner runs one wave's tasks, each as a ralph-run subprocess in its
+// own worktree.
+type waveRunner struct {
+	exe       string
+	git       *gitpkg.Git
+	base      string // commit the task branches start from
+	wave      string
+	runDir    string // logs and board
+	boardDir  string
+	worktrees string
+	maxIter   int
+
+	mu       sync.Mutex // serializes the loops' progress lines
+	progress io.Writer
+}
+
+// printf writes a progress line; the wave's loops report concurrently.
+func (w *waveRunner) printf(format string, args ...any) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	fmt.Fprintf(w.progress, format, args...)
+}
+
+func (w *waveRunner) run(ctx context.Context, t ralph.WaveTask) ralph.WaveResult {
+	res := ralph.WaveResult{
+		Task:     t,
+		Branch:   fmt.Sprintf("ralph/%s/%s", w.wave, t.ID),
+		Worktree: filepath.Join(w.worktrees, t.ID),
+		Log:      filepath.Join(w.runDir, t.ID+".log"),
+	}
+	fail := func(err error) ralph.WaveResult {
+		res.Outcome, res.Err = ralph.OutcomeError, err
+		w.printf("%s: could not start: %v\n", t.ID, err)
+		return res
+	}
+	if err := w.git.WorktreeAdd(res.Worktree, res.Branch, w.base); err != nil {
+		return fail(err)
+	}
+	// A loop's committed work is on its branch, as checkpoints or, at max
+	// iterations, a failure branch, so the worktree goes however the loop
+	// ended, unless it holds state to resume, unsafe changes to review, or
+	// changes no commit has (a loop that crashed mid-iteration).
+	defer func() {
+		if res.Outcome == ralph.OutcomeInterrupted || res.Outcome == ralph.OutcomeUnsafePath {
+			return
+		}
+		if work, err := ralph.UncommittedWork(res.Worktree); err != nil || len(work) > 0 {
+			w.printf("%s: keeping worktree %s, it has uncommitted changes\n", t.ID, res.Worktree)
+			return
+		}
+		if err := w.git.WorktreeRemove(res.Worktree); err != nil {
+			w.printf("%s: could not remove worktree: %v\n", t.ID, err)
+		}
+	}()
+	log, err := os.Create(res.Log)
+	if err != nil {
+		return fail(err)
+	}
+	defer log.Close()
+
+	loop := exec.CommandContext(ctx, w.exe, "ralph-run",
+		"--task", t.Text,
+		"--task-id", t.ID,
+		"--board-dir", w.boardDir,
+		"--board-topic", waveTopic,
+		"--max-iterations", strconv.Itoa(w.maxIter))
+	loop.Dir = res.Worktree
+	loop.Stdout, loop.Stderr = log, log
+	// Interrupt rather than kill, so the loop keeps its state for --resume.
+	loop.Cancel = func() error { return loop.Process.Signal(os.Interrupt) }
+	loop.WaitDelay = 30 * time.Second
+
+	w.printf("%s: started in %s\n", t.ID, res.Worktree)
+	start := time.Now()
+	err = loop.Run()
+	res.Duration = time.Since(start)
+	var ee *exec.ExitError
+	switch {
+	case err == nil:
+		res.Outcome = ralph.OutcomeSuccess
+	case errors.As(err, &ee):
+		res.Outcome = outcomeOfExit(ee.ExitCode())
+	default:
+		return fail(err)
+	}
+	w.printf("%s: %s after %s\n", t.ID, res.Outcome, res.Duration.Round(time.Second))
+	return res
+}
+
+// outcomeOfExit names the outcome of a ralph-run that exited with code.
+func outcomeOfExit(code int) string {
+	switch code {
+	case ExitOK:
+		return ralph.OutcomeSuccess
+	case ExitMaxIterations:
+		return ralph.OutcomeMaxIterations
+	case ExitSuperseded:
+		return ralph.OutcomeSuperseded
+	case ExitUnsafePath:
+		return ralph.OutcomeUnsafePath
+	case ExitCanceled:
+		return ralph.OutcomeInterrupted
+	}
+	return ralph.OutcomeError
+}
+
+// printWaveSummary writes a table of the wave's tasks and how each ended,
+// then the counts per outcome.
+func printWaveSummary(w io.Writer, results []ralph.WaveResult) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TASK\tOUTCOME\tDURATION\tBRANCH\tLOG")
+	counts := map[string]int{}
+	for _, r := range results {
+		counts[r.Outcome]++
+		duration := "-"
+		if r.Duration > 0 {
+			duration = r.Duration.Round(time.Second).String()
+		}
+		log := dash(r.Log)
+		if r.Err != nil {
+			log, _, _ = strings.Cut(r.Err.Error(), "\n")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", r.Task.ID, r.Outcome, duration, dash(r.Branch), log)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\n%d/%d completed, %d hit max iterations, %d other\n",
+		counts[ralph.OutcomeSuccess], len(results), counts[ralph.OutcomeMaxIterations],
+		len(results)-counts[ralph.OutcomeSuccess]-counts[ralph.OutcomeMaxIterations])
+	return err
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRalphWave(t *testing.T) {
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "-q", "--allow-empty", "-m", "init"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Skipf("git unavailable: %v: %s", err, out)
+		}
+	}
+	// A stand-in for ralph-run: it logs its arguments and working directory
+	// and ends the way the task asks.
+	fake := filepath.Join(t.TempDir(), "conclave")
+	script := `#!/bin/sh
+echo "args: $*"
+echo "pwd: $(pwd)"
+case "$*" in
+*stuck*) exit 2 ;;
+*interrupted*) exit 130 ;;
+*Crash*) echo half > half-done.txt; exit 1 ;;
+esac
+`
+	if err := os.WriteFile(fake, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	orig := ralphExecutable
+	ralphExecutable = func() (string, error) { return fake, nil }
+	defer func() { ralphExecutable = orig }()
+
+	tasks := filepath.Join(dir, "tasks.txt")
+	os.WriteFile(tasks, []byte("Fix the login redirect\nGet stuck on the parser\nGet interrupted\nCrash mid-iteration\n"), 0o644)
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("PARALLEL_WORKTREE_DIR", filepath.Join(dir, ".worktrees"))
+	t.Chdir(dir)
+	setCmdFlags(t, ralphWaveCmd, map[string]string{"tasks": tasks, "concurrency": "2"})
+	var stdout, stderr bytes.Buffer
+	ralphWaveCmd.SetOut(&stdout)
+	ralphWaveCmd.SetErr(&stderr)
+	defer ralphWaveCmd.SetOut(nil)
+	defer ralphWaveCmd.SetErr(nil)
+
+	err := runRalphWave(ralphWaveCmd, nil)
+	if err == nil || !strings.Contains(err.Error(), "3 of 4 tasks did not complete") {
+		t.Errorf("err = %v, want three incomplete tasks", err)
+	}
+	summary := stdout.String()
+	for _, want := range []string{"task-1  success", "task-2  max_iterations", "task-3  interrupted", "task-4  error", "1/4 completed, 1 hit max iterations, 2 other"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("summary missing %q:\n%s", want, summary)
+		}
+	}
+
+	logs, _ := filepath.Glob(filepath.Join(dir, ".conclave", "ralph-wave", "*", "task-1.log"))
+	if len(logs) != 1 {
+		t.Fatalf("task-1 logs = %v", logs)
+	}
+	log, _ := os.ReadFile(logs[0])
+	board := filepath.Join(filepath.Dir(logs[0]), "board")
+	for _, want := range []string{"ralph-run --task Fix the login redirect --task-id task-1", "--board-dir " + board, "pwd: " + filepath.Join(dir, ".worktrees")} {
+		if !strings.Contains(string(log), want) {
+			t.Errorf("task-1 log missing %q:\n%s", want, log)
+		}
+	}
+	if out, err := exec.Command("git", "-C", dir, "branch", "--list", "ralph/*").Output(); err != nil || strings.Count(string(out), "ralph/wave-") != 4 {
+		t.Errorf("task branches = %q (%v), want one per task", out, err)
+	}
+	// Finished loops' worktrees are removed, failed or not; the interrupted
+	// one is kept for --resume, the crashed one for its uncommitted work.
+	out, _ := exec.Command("git", "-C", dir, "worktree", "list").Output()
+	if strings.Contains(string(out), "task-1") || strings.Contains(string(out), "task-2") ||
+		!strings.Contains(string(out), "task-3") || !strings.Contains(string(out), "task-4") {
+		t.Errorf("worktrees left:\n%s", out)
+	}
+}
+
+func TestRalphWaveNeedsTasks(t *testing.T) {
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "tasks.txt")
+	os.WriteFile(empty, []byte("# nothing yet\n"), 0o644)
+	setCmdFlags(t, ralphWaveCmd, map[string]string{"tasks": empty})
+	if err := runRalphWave(ralphWaveCmd, nil); exitCode(err) != ExitConfig {
+		t.Errorf("exitCode = %d, want %d (err: %v)", exitCode(err), ExitConfig, err)
+	}
+}
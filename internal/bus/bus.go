@@ -2,6 +2,7 @@ package bus
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -17,11 +18,19 @@ type MessageBus interface {
 	Close() error
 }
 
+// ErrBusClosed is returned by operations on a closed bus.
+var ErrBusClosed = errors.New("bus is closed")
+
 // Message is the input to Publish, before envelope wrapping.
 type Message struct {
 	Type    string          `json:"type"`
 	Sender  string          `json:"sender"`
 	Payload json.RawMessage `json:"payload"`
+
+	// CorrelationID and ReplyTo link a request to its reply, see
+	// ChannelBus.Request.
+	CorrelationID string `json:"correlation_id,omitempty"`
+	ReplyTo       string `json:"reply_to,omitempty"`
 }
 
 // Envelope is the on-wire message format.
@@ -33,14 +42,26 @@ type Envelope struct {
 	Topic     string          `json:"topic"`
 	Type      string          `json:"type"`
 	Payload   json.RawMessage `json:"payload"`
+
+	CorrelationID string `json:"correlation_id,omitempty"`
+	ReplyTo       string `json:"reply_to,omitempty"` // topic the sender awaits a reply on
 }
 
 var seqCounter atomic.Uint64
 var pidPrefix = fmt.Sprintf("%d", os.Getpid())
 
+// NewID returns a process-unique identifier in the same pid-seq form as
+// envelope IDs, for correlating related messages and artifacts.
+func NewID() string {
+	return fmt.Sprintf("%s-%d", pidPrefix, seqCounter.Add(1))
+}
+
 // NewEnvelope wraps a Message into an Envelope with generated ID and sequence.
 func NewEnvelope(topic string, msg Message) Envelope {
-	seq := seqCounter.Add(1)
+	return newEnvelopeWithSeq(topic, msg, seqCounter.Add(1))
+}
+
+func newEnvelopeWithSeq(topic string, msg Message, seq uint64) Envelope {
 	return Envelope{
 		ID:        fmt.Sprintf("%s-%d", pidPrefix, seq),
 		Seq:       seq,
@@ -49,10 +70,17 @@ func NewEnvelope(topic string, msg Message) Envelope {
 		Topic:     topic,
 		Type:      msg.Type,
 		Payload:   msg.Payload,
+
+		CorrelationID: msg.CorrelationID,
+		ReplyTo:       msg.ReplyTo,
 	}
 }
 
-// TopicMatch returns true if topic matches or starts with the pattern prefix.
+// TopicMatch returns true if topic matches or starts with the pattern prefix,
+// segment by segment. A pattern with wildcard segments matches whole topics
+// instead: "*" matches exactly one segment and "**" any number of segments,
+// so "parallel.*.board" matches "parallel.wave-0.board" but not
+// "parallel.wave-0.board.detail", which "parallel.*.board.**" does.
 func TopicMatch(pattern, topic string) bool {
 	if pattern == "" {
 		return true
@@ -60,5 +88,41 @@ func TopicMatch(pattern, topic string) bool {
 	if topic == pattern {
 		return true
 	}
-	return strings.HasPrefix(topic, pattern+".")
+	if !hasWildcard(pattern) {
+		return strings.HasPrefix(topic, pattern+".")
+	}
+	return matchSegments(strings.Split(pattern, "."), strings.Split(topic, "."))
+}
+
+func hasWildcard(pattern string) bool {
+	for _, seg := range strings.Split(pattern, ".") {
+		if seg == "*" || seg == "**" {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSegments(pattern, topic []string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case "**":
+			for i := 0; i <= len(topic); i++ {
+				if matchSegments(pattern[1:], topic[i:]) {
+					return true
+				}
+			}
+			return false
+		case "*":
+			if len(topic) == 0 {
+				return false
+			}
+		default:
+			if len(topic) == 0 || topic[0] != pattern[0] {
+				return false
+			}
+		}
+		pattern, topic = pattern[1:], topic[1:]
+	}
+	return len(topic) == 0
 }
@@ -1,7 +1,9 @@
 package bus
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -112,6 +114,21 @@ func TestTopicMatch(t *testing.T) {
 		{"parallel.wave-0", "parallel.wave-0.board", true},
 		{"parallel.wave-1", "parallel.wave-0.board", false},
 		{"", "anything", true},
+		{"parallel.*.board", "parallel.wave-0.board", true},
+		{"parallel.*.board", "parallel.wave-1.board", true},
+		{"parallel.*.board", "parallel.wave-0.board.detail", false},
+		{"parallel.*.board", "parallel.board", false},
+		{"consensus.*.debate", "consensus.s1.debate", true},
+		{"consensus.*.debate", "consensus.s1.rebuttal", false},
+		{"parallel.**", "parallel.wave-0.board.detail", true},
+		{"parallel.**", "parallel", true},
+		{"parallel.*.board.**", "parallel.wave-0.board.detail", true},
+		{"**.board", "parallel.wave-0.board", true},
+		{"**.board", "parallel.wave-0.board.detail", false},
+		{"*", "consensus", true},
+		{"*", "consensus.s1", false},
+		{"consensus.**.debate", "consensus.debate", true},
+		{"consensus*", "consensus.s1", false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.pattern+"_"+tt.topic, func(t *testing.T) {
@@ -123,6 +140,29 @@ func TestTopicMatch(t *testing.T) {
 	}
 }
 
+func TestChannelBusWildcardSubscribe(t *testing.T) {
+	bus := NewChannelBus()
+	defer bus.Close()
+
+	ch, _ := bus.Subscribe("parallel.*.board")
+	for _, topic := range []string{"parallel.wave-0.board", "parallel.wave-0.board.detail", "parallel.wave-1.board"} {
+		bus.Publish(topic, Message{Type: "msg", Sender: "s", Payload: json.RawMessage(`{}`)})
+	}
+	for _, want := range []string{"parallel.wave-0.board", "parallel.wave-1.board"} {
+		select {
+		case env := <-ch:
+			if env.Topic != want {
+				t.Errorf("topic = %q, want %q", env.Topic, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for %q", want)
+		}
+	}
+	if n := drain(ch); n != 0 {
+		t.Errorf("received %d unexpected envelopes", n)
+	}
+}
+
 func TestChannelBusPublishSubscribe(t *testing.T) {
 	bus := NewChannelBus()
 	defer bus.Close()
@@ -226,6 +266,457 @@ done:
 	}
 }
 
+// drain counts the envelopes buffered in ch.
+func drain(ch <-chan Envelope) int {
+	count := 0
+	for {
+		select {
+		case <-ch:
+			count++
+		case <-time.After(50 * time.Millisecond):
+			return count
+		}
+	}
+}
+
+func TestChannelBusSubscribeWithBuffer(t *testing.T) {
+	bus := NewChannelBus()
+	defer bus.Close()
+
+	small, _ := bus.SubscribeWithBuffer("flood", 4)
+	large, _ := bus.SubscribeWithBuffer("flood", 100)
+	dflt, _ := bus.Subscribe("flood")
+
+	for i := 0; i < 80; i++ {
+		bus.Publish("flood", Message{Type: "msg", Sender: "s", Payload: json.RawMessage(`{}`)})
+	}
+
+	if n := drain(small); n != 4 {
+		t.Errorf("small subscriber received %d, want 4", n)
+	}
+	if n := drain(large); n != 80 {
+		t.Errorf("large subscriber received %d, want all 80", n)
+	}
+	if n := drain(dflt); n != 64 {
+		t.Errorf("default subscriber received %d, want 64", n)
+	}
+}
+
+func TestNewChannelBusWithOptions(t *testing.T) {
+	bus := NewChannelBusWithOptions(BusOptions{BufferSize: 8})
+	defer bus.Close()
+
+	ch, _ := bus.Subscribe("t")
+	override, _ := bus.SubscribeWithBuffer("t", 16)
+	tap, _ := bus.Tap(0)
+	for i := 0; i < 20; i++ {
+		bus.Publish("t", Message{Type: "msg", Sender: "s", Payload: json.RawMessage(`{}`)})
+	}
+	if n := drain(ch); n != 8 {
+		t.Errorf("Subscribe received %d, want the bus default of 8", n)
+	}
+	if n := drain(override); n != 16 {
+		t.Errorf("SubscribeWithBuffer received %d, want 16", n)
+	}
+	if n := drain(tap); n != 8 {
+		t.Errorf("Tap(0) received %d, want the bus default of 8", n)
+	}
+}
+
+func TestChannelBusPublishBlockingDeliversToSlowSubscribers(t *testing.T) {
+	bus := NewChannelBus()
+	defer bus.Close()
+
+	fast, _ := bus.SubscribeWithBuffer("board", 1)
+	slow, _ := bus.SubscribeWithBuffer("board", 1)
+
+	const n = 50
+	counts := make(chan int, 2)
+	consume := func(ch <-chan Envelope, delay time.Duration) {
+		got := 0
+		for range ch {
+			got++
+			if got == n {
+				break
+			}
+			time.Sleep(delay)
+		}
+		counts <- got
+	}
+	go consume(fast, 0)
+	go consume(slow, time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for i := 0; i < n; i++ {
+		if err := bus.PublishBlocking("board", Message{Type: "discovery", Sender: "s", Payload: json.RawMessage(`{}`)}, ctx); err != nil {
+			t.Fatalf("publish %d: %v", i, err)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if got := <-counts; got != n {
+			t.Errorf("subscriber received %d, want all %d", got, n)
+		}
+	}
+}
+
+func TestChannelBusPublishBlockingTimeout(t *testing.T) {
+	bus := NewChannelBus()
+	defer bus.Close()
+
+	ch, _ := bus.SubscribeWithBuffer("board", 1)
+	msg := Message{Type: "discovery", Sender: "s", Payload: json.RawMessage(`{}`)}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := bus.PublishBlocking("board", msg, ctx); err != nil {
+		t.Fatalf("first publish fits the buffer: %v", err)
+	}
+	err := bus.PublishBlocking("board", msg, ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want deadline exceeded", err)
+	}
+	if errors.Is(err, ErrBusClosed) {
+		t.Error("timeout reported as a closed bus")
+	}
+	if n := drain(ch); n != 1 {
+		t.Errorf("received %d, want 1", n)
+	}
+}
+
+func TestChannelBusPublishBlockingClose(t *testing.T) {
+	bus := NewChannelBus()
+	bus.SubscribeWithBuffer("board", 1)
+	msg := Message{Type: "discovery", Sender: "s", Payload: json.RawMessage(`{}`)}
+	bus.Publish("board", msg)
+
+	errc := make(chan error, 1)
+	go func() { errc <- bus.PublishBlocking("board", msg, context.Background()) }()
+	time.Sleep(20 * time.Millisecond)
+	bus.Close()
+
+	select {
+	case err := <-errc:
+		if !errors.Is(err, ErrBusClosed) {
+			t.Errorf("err = %v, want ErrBusClosed", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("PublishBlocking still blocked after Close")
+	}
+	if err := bus.PublishBlocking("board", msg, context.Background()); !errors.Is(err, ErrBusClosed) {
+		t.Errorf("publish after close: err = %v, want ErrBusClosed", err)
+	}
+}
+
+func TestChannelBusStats(t *testing.T) {
+	bus := NewChannelBus()
+	defer bus.Close()
+
+	bus.Subscribe("flood")
+	bus.SubscribeCoalesced("flood")
+	for i := 0; i < 70; i++ {
+		bus.Publish("flood", Message{Type: "msg", Sender: "s", Payload: json.RawMessage(`{}`)})
+	}
+	bus.Publish("quiet", Message{Type: "msg", Sender: "s", Payload: json.RawMessage(`{}`)})
+
+	stats := bus.Stats()
+	flood := stats["flood"]
+	want := TopicStats{Published: 70, Delivered: 64 + 70, Dropped: 6}
+	if flood != want {
+		t.Errorf("flood stats = %+v, want %+v", flood, want)
+	}
+	if got := fmt.Sprintf("dropped %d of %d messages on topic flood", flood.Dropped, flood.Published); got != "dropped 6 of 70 messages on topic flood" {
+		t.Errorf("summary = %q", got)
+	}
+	if quiet := stats["quiet"]; quiet != (TopicStats{Published: 1}) {
+		t.Errorf("quiet stats = %+v, want one publish without subscribers", quiet)
+	}
+
+	bus.ResetStats()
+	if stats := bus.Stats(); len(stats) != 0 {
+		t.Errorf("stats after reset = %v, want none", stats)
+	}
+	bus.Publish("flood", Message{Type: "msg", Sender: "s", Payload: json.RawMessage(`{}`)})
+	if flood := bus.Stats()["flood"]; flood.Published != 1 || flood.Dropped != 1 {
+		t.Errorf("stats after reset and one publish = %+v", flood)
+	}
+}
+
+func TestChannelBusStatsConcurrentPublish(t *testing.T) {
+	bus := NewChannelBus()
+	defer bus.Close()
+
+	bus.SubscribeWithBuffer("race", 10)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			bus.Publish("race", Message{Type: "test", Sender: "s", Payload: json.RawMessage(`{}`)})
+		}()
+	}
+	wg.Wait()
+
+	race := bus.Stats()["race"]
+	if race.Published != 50 || race.Delivered != 10 || race.Dropped != 40 {
+		t.Errorf("race stats = %+v, want 50 published, 10 delivered, 40 dropped", race)
+	}
+}
+
+func TestChannelBusStatsPublishBlockingTimeout(t *testing.T) {
+	bus := NewChannelBus()
+	defer bus.Close()
+
+	bus.SubscribeWithBuffer("board", 1)
+	bus.SubscribeWithBuffer("board", 1)
+	msg := Message{Type: "discovery", Sender: "s", Payload: json.RawMessage(`{}`)}
+	bus.Publish("board", msg)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	bus.PublishBlocking("board", msg, ctx)
+
+	if board := bus.Stats()["board"]; board != (TopicStats{Published: 2, Delivered: 2, Dropped: 2}) {
+		t.Errorf("board stats = %+v, want the abandoned deliveries counted as dropped", board)
+	}
+}
+
+func TestChannelBusDeadLetters(t *testing.T) {
+	bus := NewChannelBus()
+
+	bus.SubscribeWithBuffer("flood", 2)
+	bus.SubscribeWithBuffer("flood", 3)
+	dead := bus.DeadLetters()
+	if bus.DeadLetters() != dead {
+		t.Error("DeadLetters should return the same channel every call")
+	}
+	for i := 0; i < 5; i++ {
+		bus.Publish("flood", Message{Type: "msg", Sender: "s", Payload: json.RawMessage(`{}`)})
+	}
+
+	// The last three overflow the first subscriber and the last two the
+	// second: each is dead-lettered once.
+	if n := drain(dead); n != 3 {
+		t.Errorf("dead letters = %d, want 3 (one per envelope any subscriber dropped)", n)
+	}
+
+	bus.Close()
+	if _, ok := <-dead; ok {
+		t.Error("dead-letter channel should be closed by Close")
+	}
+}
+
+func TestChannelBusDeadLettersBounded(t *testing.T) {
+	bus := NewChannelBusWithOptions(BusOptions{BufferSize: 1})
+	defer bus.Close()
+
+	bus.Subscribe("flood")
+	dead := bus.DeadLetters()
+	for i := 0; i < 10; i++ {
+		bus.Publish("flood", Message{Type: "msg", Sender: "s", Payload: json.RawMessage(`{}`)})
+	}
+	if n := drain(dead); n != 1 {
+		t.Errorf("dead letters = %d, want 1 (the dead-letter buffer drops when full)", n)
+	}
+}
+
+func TestChannelBusDeadLettersDisabledByDefault(t *testing.T) {
+	bus := NewChannelBus()
+	defer bus.Close()
+
+	bus.SubscribeWithBuffer("flood", 1)
+	for i := 0; i < 3; i++ {
+		bus.Publish("flood", Message{Type: "msg", Sender: "s", Payload: json.RawMessage(`{}`)})
+	}
+	if bus.deadLetters != nil {
+		t.Error("dead-letter channel allocated without DeadLetters")
+	}
+	// Drops before opting in are not replayed.
+	if n := drain(bus.DeadLetters()); n != 0 {
+		t.Errorf("dead letters = %d, want 0", n)
+	}
+}
+
+func TestChannelBusSubscribeMany(t *testing.T) {
+	bus := NewChannelBus()
+	defer bus.Close()
+
+	ch, err := bus.SubscribeMany("consensus", "parallel", "parallel.wave-0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	single, _ := bus.Subscribe("parallel")
+	for _, topic := range []string{"consensus.s1", "parallel.wave-0.board", "ralph", "parallel.wave-1"} {
+		bus.Publish(topic, Message{Type: "msg", Sender: "s", Payload: json.RawMessage(`{}`)})
+	}
+
+	// parallel.wave-0.board matches two patterns but arrives once.
+	envs := recv(t, ch, 3)
+	seen := make(map[string]bool)
+	for _, env := range envs {
+		if seen[env.ID] {
+			t.Errorf("envelope %s delivered twice", env.ID)
+		}
+		seen[env.ID] = true
+	}
+	if envs[0].Topic != "consensus.s1" || envs[1].Topic != "parallel.wave-0.board" || envs[2].Topic != "parallel.wave-1" {
+		t.Errorf("topics = %s, %s, %s", envs[0].Topic, envs[1].Topic, envs[2].Topic)
+	}
+	if n := drain(ch); n != 0 {
+		t.Errorf("received %d extra envelopes", n)
+	}
+
+	// Unsubscribe by topic leaves the multi-pattern subscription alone.
+	bus.Unsubscribe("parallel")
+	n := 0
+	for range single { // ends once the closed channel is drained
+		n++
+	}
+	if n != 2 {
+		t.Errorf("single subscription received %d, want 2", n)
+	}
+	if err := bus.UnsubscribeChannel(ch); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := <-ch; ok {
+		t.Error("UnsubscribeChannel should close the channel")
+	}
+	bus.Publish("consensus", Message{Type: "msg", Sender: "s", Payload: json.RawMessage(`{}`)})
+	if len(bus.subscribers) != 0 {
+		t.Errorf("%d subscribers left, want 0", len(bus.subscribers))
+	}
+}
+
+func TestChannelBusSubscribeManyRequiresPatterns(t *testing.T) {
+	bus := NewChannelBus()
+	defer bus.Close()
+	if _, err := bus.SubscribeMany(); err == nil {
+		t.Error("SubscribeMany with no patterns should fail")
+	}
+}
+
+func TestChannelBusUnsubscribeChannelCoalesced(t *testing.T) {
+	bus := NewChannelBus()
+	defer bus.Close()
+
+	ch, _ := bus.SubscribeCoalesced("board")
+	bus.UnsubscribeChannel(ch)
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Error("coalesced channel should be closed")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("coalesced channel not closed")
+	}
+}
+
+func TestChannelBusSubscriptionClose(t *testing.T) {
+	bus := NewChannelBus()
+	defer bus.Close()
+
+	first, err := bus.SubscribeHandle("topic")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := bus.SubscribeHandle("topic")
+
+	// Publish concurrently with Close: closing one subscription must not
+	// race a send on its channel.
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				bus.Publish("topic", Message{Type: "msg", Sender: "s", Payload: json.RawMessage(`{}`)})
+			}
+		}
+	}()
+	time.Sleep(10 * time.Millisecond)
+	if err := first.Close(); err != nil {
+		t.Fatal(err)
+	}
+	close(stop)
+	wg.Wait()
+
+	for range first.C { // drains, then ends because C is closed
+	}
+	drain(second.C)
+	bus.Publish("topic", Message{Type: "after", Sender: "s", Payload: json.RawMessage(`{}`)})
+	if env := recv(t, second.C, 1)[0]; env.Type != "after" {
+		t.Errorf("sibling received %q, want the later publish", env.Type)
+	}
+
+	if err := first.Close(); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+	bus.Unsubscribe("topic")
+	if err := second.Close(); err != nil {
+		t.Errorf("Close after Unsubscribe: %v", err)
+	}
+}
+
+func TestChannelBusDedup(t *testing.T) {
+	bus := NewChannelBusWithOptions(BusOptions{DedupWindow: 2})
+	defer bus.Close()
+
+	ch, _ := bus.Subscribe("board")
+	discovery := NewEnvelope("board", Message{Type: "discovery", Sender: "task-1", Payload: json.RawMessage(`{}`)})
+	for i := 0; i < 3; i++ {
+		bus.PublishEnvelope(discovery) // a retried task re-emitting its finding
+	}
+	if n := drain(ch); n != 1 {
+		t.Errorf("received %d copies, want 1", n)
+	}
+	if st := bus.Stats()["board"]; st.Published != 1 || st.Duplicates != 2 {
+		t.Errorf("stats = %+v, want 1 published, 2 duplicates", st)
+	}
+
+	// The window holds 2 IDs: after two newer envelopes the first is
+	// forgotten and delivered again.
+	bus.Publish("board", Message{Type: "msg", Sender: "s", Payload: json.RawMessage(`{}`)})
+	bus.Publish("board", Message{Type: "msg", Sender: "s", Payload: json.RawMessage(`{}`)})
+	bus.PublishEnvelope(discovery)
+	if n := drain(ch); n != 3 {
+		t.Errorf("received %d, want 3 (two new, one evicted ID)", n)
+	}
+	if got := len(bus.dedup.ids); got != 2 {
+		t.Errorf("window holds %d IDs, want 2", got)
+	}
+}
+
+func TestChannelBusDedupOffByDefault(t *testing.T) {
+	bus := NewChannelBus()
+	defer bus.Close()
+
+	ch, _ := bus.Subscribe("board")
+	env := NewEnvelope("board", Message{Type: "discovery", Sender: "task-1", Payload: json.RawMessage(`{}`)})
+	bus.PublishEnvelope(env)
+	bus.PublishEnvelope(env)
+	if n := drain(ch); n != 2 {
+		t.Errorf("received %d, want both copies without DedupWindow", n)
+	}
+}
+
+func TestIDWindowEvictsLeastRecentlySeen(t *testing.T) {
+	w := newIDWindow(2)
+	w.seen("a")
+	w.seen("b")
+	if !w.seen("a") { // touches a, so b is now the oldest
+		t.Fatal("a should be a duplicate")
+	}
+	w.seen("c")
+	if w.seen("b") {
+		t.Error("b should have been evicted")
+	}
+	if !w.seen("c") {
+		t.Error("c should still be remembered")
+	}
+}
+
 func TestChannelBusClose(t *testing.T) {
 	bus := NewChannelBus()
 	ch, _ := bus.Subscribe("topic")
@@ -295,6 +786,66 @@ finished:
 	t.Logf("received %d of 50 messages (some may be dropped due to backpressure)", count)
 }
 
+func TestChannelBusTap(t *testing.T) {
+	bus := NewChannelBus()
+	defer bus.Close()
+
+	tap, err := bus.Tap(16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A subscriber on one topic must not limit what the tap sees
+	bus.Subscribe("consensus")
+
+	topics := []string{"consensus.s1", "parallel.wave-0.board", "misc"}
+	for _, topic := range topics {
+		bus.Publish(topic, Message{Type: "t", Sender: "s", Payload: json.RawMessage(`{}`)})
+	}
+
+	for _, want := range topics {
+		select {
+		case env := <-tap:
+			if env.Topic != want {
+				t.Errorf("tap topic = %q, want %q", env.Topic, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("tap missed %q", want)
+		}
+	}
+}
+
+func TestChannelBusTapDoesNotBlockSubscribers(t *testing.T) {
+	bus := NewChannelBus()
+	defer bus.Close()
+
+	bus.Tap(1) // never drained
+	ch, _ := bus.Subscribe("topic")
+
+	for i := 0; i < 5; i++ {
+		bus.Publish("topic", Message{Type: "t", Sender: "s", Payload: json.RawMessage(`{}`)})
+	}
+	for i := 0; i < 5; i++ {
+		select {
+		case <-ch:
+		case <-time.After(time.Second):
+			t.Fatalf("subscriber got %d messages, want 5", i)
+		}
+	}
+}
+
+func TestChannelBusTapClose(t *testing.T) {
+	bus := NewChannelBus()
+	tap, _ := bus.Tap(0)
+	bus.Close()
+
+	if _, ok := <-tap; ok {
+		t.Error("tap should be closed after bus.Close()")
+	}
+	if _, err := bus.Tap(0); err == nil {
+		t.Error("Tap on closed bus should error")
+	}
+}
+
 func TestFileBusPublishSubscribe(t *testing.T) {
 	dir := t.TempDir()
 	bus, err := NewFileBus(dir, 50*time.Millisecond, 200*time.Millisecond)
@@ -449,3 +1000,522 @@ func TestFileBusFileFormat(t *testing.T) {
 		t.Errorf("type = %q, want test", env.Type)
 	}
 }
+
+func readFileSeqs(t *testing.T, path string) []uint64 {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var seqs []uint64
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var env Envelope
+		if err := json.Unmarshal([]byte(line), &env); err != nil {
+			t.Fatalf("invalid JSON line: %v", err)
+		}
+		seqs = append(seqs, env.Seq)
+	}
+	return seqs
+}
+
+func TestFileBusSeqSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	for run := 0; run < 3; run++ {
+		bus, err := NewFileBus(dir, 50*time.Millisecond, 200*time.Millisecond)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 3; i++ {
+			bus.Publish("restart.test", Message{Type: "t", Sender: "s", Payload: json.RawMessage(`{}`)})
+		}
+		bus.Close()
+	}
+
+	seqs := readFileSeqs(t, filepath.Join(dir, "restart.test.jsonl"))
+	if len(seqs) != 9 {
+		t.Fatalf("got %d messages, want 9", len(seqs))
+	}
+	for i := 1; i < len(seqs); i++ {
+		if seqs[i] <= seqs[i-1] {
+			t.Fatalf("seq not increasing across restarts: %v", seqs)
+		}
+	}
+}
+
+func TestFileBusSeqSeedsFromExistingFiles(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "old.jsonl"), []byte(`{"id":"1-100","seq":100,"topic":"old"}`+"\n"), 0644)
+
+	bus, _ := NewFileBus(dir, 50*time.Millisecond, 200*time.Millisecond)
+	bus.Publish("new", Message{Type: "t", Sender: "s", Payload: json.RawMessage(`{}`)})
+	bus.Close()
+
+	seqs := readFileSeqs(t, filepath.Join(dir, "new.jsonl"))
+	if seqs[0] != 101 {
+		t.Errorf("seq = %d, want 101 (continuing from existing files)", seqs[0])
+	}
+}
+
+func TestFileBusSeqConcurrentPublishersShareCounter(t *testing.T) {
+	dir := t.TempDir()
+	a, _ := NewFileBus(dir, 50*time.Millisecond, 200*time.Millisecond)
+	b, _ := NewFileBus(dir, 50*time.Millisecond, 200*time.Millisecond)
+	defer a.Close()
+	defer b.Close()
+
+	var wg sync.WaitGroup
+	for _, fb := range []*FileBus{a, b} {
+		wg.Add(1)
+		go func(fb *FileBus) {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				fb.Publish("shared", Message{Type: "t", Sender: "s", Payload: json.RawMessage(`{}`)})
+			}
+		}(fb)
+	}
+	wg.Wait()
+
+	seqs := readFileSeqs(t, filepath.Join(dir, "shared.jsonl"))
+	for i := 1; i < len(seqs); i++ {
+		if seqs[i] != seqs[i-1]+1 {
+			t.Fatalf("seqs should be gap-free and ordered in the file: %v", seqs)
+		}
+	}
+}
+
+type syncBuffer struct {
+	mu sync.Mutex
+	b  strings.Builder
+}
+
+func (s *syncBuffer) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.b.Write(p)
+}
+
+func (s *syncBuffer) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.b.String()
+}
+
+func TestChannelBusExplainDrops(t *testing.T) {
+	bus := NewChannelBus()
+	defer bus.Close()
+	var log syncBuffer
+	bus.ExplainDrops(&log, 20*time.Millisecond)
+
+	bus.Subscribe("flood")
+	for i := 0; i < 70; i++ {
+		bus.Publish("flood.board", Message{Type: "board.warning", Sender: "s", Payload: json.RawMessage(`{}`)})
+	}
+
+	lines := strings.Split(strings.TrimSpace(log.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("want 1 rate-limited line for 6 drops, got %d:\n%s", len(lines), log.String())
+	}
+	for _, want := range []string{`subscriber "flood"`, "board.warning", `"flood.board"`, "buffer full (64/64)"} {
+		if !strings.Contains(lines[0], want) {
+			t.Errorf("drop log missing %q: %s", want, lines[0])
+		}
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	bus.Publish("flood.board", Message{Type: "board.warning", Sender: "s", Payload: json.RawMessage(`{}`)})
+	if !strings.Contains(log.String(), "(5 more suppressed)") {
+		t.Errorf("suppressed drops should be reported with the next line:\n%s", log.String())
+	}
+}
+
+func TestChannelBusExplainDropsOff(t *testing.T) {
+	bus := NewChannelBus()
+	defer bus.Close()
+	var log syncBuffer
+	bus.ExplainDrops(&log, 0)
+	bus.ExplainDrops(nil, 0)
+
+	bus.Subscribe("flood")
+	for i := 0; i < 70; i++ {
+		bus.Publish("flood", Message{Type: "msg", Sender: "s", Payload: json.RawMessage(`{}`)})
+	}
+	if log.String() != "" {
+		t.Errorf("explanations should be off: %s", log.String())
+	}
+}
+
+func TestFileBusExplainDrops(t *testing.T) {
+	dir := t.TempDir()
+	bus, _ := NewFileBus(dir, 10*time.Millisecond, 50*time.Millisecond)
+	defer bus.Close()
+	var log syncBuffer
+	bus.ExplainDrops(&log, time.Hour)
+
+	for i := 0; i < 70; i++ {
+		bus.Publish("flood", Message{Type: "board.discovery", Sender: "s", Payload: json.RawMessage(`{}`)})
+	}
+	bus.Subscribe("flood")
+
+	deadline := time.Now().Add(2 * time.Second)
+	for log.String() == "" && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !strings.Contains(log.String(), `subscriber "flood" dropped board.discovery`) {
+		t.Errorf("expected a drop explanation, got %q", log.String())
+	}
+}
+
+func TestChannelBusCoalescedKeepsLatest(t *testing.T) {
+	bus := NewChannelBus()
+	defer bus.Close()
+	var log syncBuffer
+	bus.ExplainDrops(&log, time.Millisecond)
+
+	ch, err := bus.SubscribeCoalesced("board")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Flood far past the normal buffer while the consumer isn't reading
+	for i := 0; i < 500; i++ {
+		bus.Publish("board", Message{Type: "board.status", Sender: "task-1", Payload: json.RawMessage(fmt.Sprintf(`{"n":%d}`, i))})
+	}
+	bus.Publish("board", Message{Type: "board.status", Sender: "task-2", Payload: json.RawMessage(`{"n":-1}`)})
+
+	var got []Envelope
+	for len(got) < 2 {
+		select {
+		case env := <-ch:
+			got = append(got, env)
+		case <-time.After(time.Second):
+			t.Fatalf("timed out after %d envelopes", len(got))
+		}
+	}
+	// The consumer may have caught one early task-1 envelope in flight; the
+	// last one it sees for each sender must be the latest published.
+	latest := map[string]string{}
+	for _, env := range got {
+		latest[env.Sender] = string(env.Payload)
+	}
+	select {
+	case env := <-ch:
+		latest[env.Sender] = string(env.Payload)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if latest["task-1"] != `{"n":499}` || latest["task-2"] != `{"n":-1}` {
+		t.Errorf("latest = %v, want task-1 n=499 and task-2 n=-1", latest)
+	}
+	if log.String() != "" {
+		t.Errorf("coalesced subscription should never overflow, got drops:\n%s", log.String())
+	}
+}
+
+func TestChannelBusCoalescedClose(t *testing.T) {
+	bus := NewChannelBus()
+	ch, _ := bus.SubscribeCoalesced("t")
+	bus.Publish("t", Message{Type: "x", Sender: "s", Payload: json.RawMessage(`{}`)})
+	bus.Close()
+	deadline := time.After(time.Second)
+	for {
+		select {
+		case _, ok := <-ch:
+			if !ok {
+				return
+			}
+		case <-deadline:
+			t.Fatal("coalesced channel not closed after bus Close")
+		}
+	}
+}
+
+func TestFileBusCoalesced(t *testing.T) {
+	dir := t.TempDir()
+	pub, _ := NewFileBus(dir, 10*time.Millisecond, 50*time.Millisecond)
+	defer pub.Close()
+	for i := 0; i < 200; i++ {
+		pub.Publish("board", Message{Type: "board.status", Sender: "task-1", Payload: json.RawMessage(fmt.Sprintf(`{"n":%d}`, i))})
+	}
+
+	sub, _ := NewFileBus(dir, 10*time.Millisecond, 50*time.Millisecond)
+	defer sub.Close()
+	ch, err := sub.SubscribeCoalesced("board")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Let the first poll read the whole file before consuming
+	time.Sleep(50 * time.Millisecond)
+	var last string
+	count := 0
+	for {
+		select {
+		case env := <-ch:
+			last = string(env.Payload)
+			count++
+			continue
+		case <-time.After(100 * time.Millisecond):
+		}
+		break
+	}
+	if last != `{"n":199}` {
+		t.Errorf("last = %s, want n=199", last)
+	}
+	if count >= 200 {
+		t.Errorf("received %d envelopes, want bursts coalesced", count)
+	}
+}
+
+func TestFileBusMaxWritersBoundsConcurrency(t *testing.T) {
+	dir := t.TempDir()
+	var mu sync.Mutex
+	active, peak := 0, 0
+	slotHeld := func() {
+		mu.Lock()
+		active++
+		if active > peak {
+			peak = active
+		}
+		mu.Unlock()
+		time.Sleep(2 * time.Millisecond) // widen the window for overlap
+		mu.Lock()
+		active--
+		mu.Unlock()
+	}
+
+	const writers, batch, limit = 16, 5, 2
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			fb, _ := NewFileBus(dir, 50*time.Millisecond, 200*time.Millisecond)
+			defer fb.Close()
+			fb.SetMaxWriters(limit)
+			fb.setSlotHeld(slotHeld)
+			msgs := make([]Message, batch)
+			for i := range msgs {
+				msgs[i] = Message{Type: "finding", Sender: fmt.Sprintf("task-%d", w), Payload: json.RawMessage(fmt.Sprintf(`{"i":%d}`, i))}
+			}
+			if err := fb.PublishBatch("wave", msgs); err != nil {
+				t.Error(err)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	if peak > limit {
+		t.Errorf("peak concurrent writers = %d, want <= %d", peak, limit)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "wave.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != writers*batch {
+		t.Fatalf("got %d lines, want %d", len(lines), writers*batch)
+	}
+	// Every line is intact, seqs are gap-free in file order, and each task's
+	// batch is contiguous.
+	count := make(map[string]int)
+	for i, line := range lines {
+		var env Envelope
+		if err := json.Unmarshal([]byte(line), &env); err != nil {
+			t.Fatalf("line %d corrupt: %v", i+1, err)
+		}
+		if env.Seq != uint64(i+1) {
+			t.Fatalf("line %d has seq %d", i+1, env.Seq)
+		}
+		if i%batch == 0 {
+			count[env.Sender]++
+		} else if prev := lines[i-1]; !strings.Contains(prev, `"sender":"`+env.Sender+`"`) {
+			t.Fatalf("batch from %s interleaved at line %d", env.Sender, i+1)
+		}
+	}
+	if len(count) != writers {
+		t.Errorf("batches from %d writers, want %d", len(count), writers)
+	}
+}
+
+func TestPublishAllFallsBackToPublish(t *testing.T) {
+	b := NewChannelBus()
+	defer b.Close()
+	ch, _ := b.Subscribe("t")
+	msgs := []Message{{Type: "a"}, {Type: "b"}}
+	if err := PublishAll(b, "t", msgs); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"a", "b"} {
+		select {
+		case env := <-ch:
+			if env.Type != want {
+				t.Errorf("got %q, want %q", env.Type, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+	}
+}
+
+// recv reads n envelopes from ch or fails.
+func recv(t *testing.T, ch <-chan Envelope, n int) []Envelope {
+	t.Helper()
+	var envs []Envelope
+	for len(envs) < n {
+		select {
+		case env := <-ch:
+			envs = append(envs, env)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("received %d envelopes, want %d", len(envs), n)
+		}
+	}
+	return envs
+}
+
+func TestFileBackedBusReplaysHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "board.jsonl")
+	b, err := NewFileBackedBus(path, FileBackedOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.Publish("board.discovery", Message{Type: "discovery", Sender: "task-1", Payload: json.RawMessage(`{"n":1}`)})
+	b.Publish("other", Message{Type: "x", Sender: "task-1", Payload: json.RawMessage(`{}`)})
+	b.Publish("board.warning", Message{Type: "warning", Sender: "task-2", Payload: json.RawMessage(`{"n":2}`)})
+
+	ch, err := b.Subscribe("board")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.Publish("board.discovery", Message{Type: "discovery", Sender: "task-3", Payload: json.RawMessage(`{"n":3}`)})
+
+	envs := recv(t, ch, 3)
+	for i, want := range []string{"task-1", "task-2", "task-3"} {
+		if envs[i].Sender != want {
+			t.Errorf("envelope %d from %q, want %q (history, then live)", i, envs[i].Sender, want)
+		}
+	}
+	if envs[0].Seq != 1 || envs[2].Seq != 4 {
+		t.Errorf("seqs = %d..%d, want 1..4", envs[0].Seq, envs[2].Seq)
+	}
+	b.Close()
+	if _, ok := <-ch; ok {
+		t.Error("subscription should be closed by Close")
+	}
+
+	// Every line is an envelope, as in board files.
+	data, _ := os.ReadFile(path)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("file has %d lines, want 4", len(lines))
+	}
+	for _, line := range lines {
+		var env Envelope
+		if err := json.Unmarshal([]byte(line), &env); err != nil || env.ID == "" {
+			t.Errorf("bad line %q: %v", line, err)
+		}
+	}
+}
+
+func TestFileBackedBusRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "board.jsonl")
+	b, _ := NewFileBackedBus(path, FileBackedOptions{})
+	for i := 0; i < 3; i++ {
+		b.Publish("board", Message{Type: "discovery", Sender: fmt.Sprintf("task-%d", i), Payload: json.RawMessage(`{}`)})
+	}
+	b.Close()
+
+	// Simulate a crash mid-write.
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	f.WriteString(`{"id":"partial`)
+	f.Close()
+
+	b, err := NewFileBackedBus(path, FileBackedOptions{ReplayFrom: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	b.Publish("board", Message{Type: "discovery", Sender: "task-3", Payload: json.RawMessage(`{}`)})
+
+	ch, _ := b.Subscribe("board")
+	envs := recv(t, ch, 2)
+	if envs[0].Seq != 3 || envs[1].Seq != 4 {
+		t.Errorf("seqs = %d, %d; want 3, 4 (after ReplayFrom, continuing across the restart)", envs[0].Seq, envs[1].Seq)
+	}
+	if n := drain(ch); n != 0 {
+		t.Errorf("received %d more envelopes, want none", n)
+	}
+}
+
+func TestFileBackedBusUnsubscribeDuringReplay(t *testing.T) {
+	b, _ := NewFileBackedBus(filepath.Join(t.TempDir(), "board.jsonl"), FileBackedOptions{})
+	defer b.Close()
+	for i := 0; i < channelBufferSize*2; i++ {
+		b.Publish("board", Message{Type: "discovery", Sender: "s", Payload: json.RawMessage(`{}`)})
+	}
+	ch, _ := b.Subscribe("board")
+	b.Unsubscribe("board")
+	// The channel closes even though the replay never finished.
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case _, ok := <-ch:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("subscription not closed after Unsubscribe")
+		}
+	}
+}
+
+func TestChannelBusRequestReply(t *testing.T) {
+	bus := NewChannelBus()
+	defer bus.Close()
+
+	requests, _ := bus.Subscribe("agents.claude")
+	go func() {
+		for req := range requests {
+			// A stray reply with another correlation ID is ignored.
+			bus.Publish(req.ReplyTo, Message{Type: "answer", Sender: "other", Payload: json.RawMessage(`"wrong"`), CorrelationID: "x"})
+			bus.Reply(req, Message{Type: "answer", Sender: "claude", Payload: json.RawMessage(`"42"`)})
+		}
+	}()
+
+	reply, err := bus.Request("agents.claude", Message{Type: "question", Sender: "chair", Payload: json.RawMessage(`"?"`)}, 2*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply.Sender != "claude" || string(reply.Payload) != `"42"` {
+		t.Errorf("reply = %+v, want claude's answer", reply)
+	}
+	if reply.CorrelationID == "" || !strings.HasPrefix(reply.Topic, ReplyTopicPrefix+".") {
+		t.Errorf("reply correlation = %q, topic = %q", reply.CorrelationID, reply.Topic)
+	}
+
+	bus.mu.RLock()
+	defer bus.mu.RUnlock()
+	for _, sub := range bus.subscribers {
+		if strings.HasPrefix(sub.pattern, ReplyTopicPrefix) {
+			t.Errorf("reply subscription %q left behind", sub.pattern)
+		}
+	}
+}
+
+func TestChannelBusRequestTimeout(t *testing.T) {
+	bus := NewChannelBus()
+	defer bus.Close()
+
+	_, err := bus.Request("nobody.home", Message{Type: "question", Sender: "chair", Payload: json.RawMessage(`{}`)}, 20*time.Millisecond)
+	if !errors.Is(err, ErrNoReply) {
+		t.Errorf("err = %v, want ErrNoReply", err)
+	}
+}
+
+func TestChannelBusReplyRequiresRequest(t *testing.T) {
+	bus := NewChannelBus()
+	defer bus.Close()
+
+	env := NewEnvelope("board", Message{Type: "discovery", Sender: "s", Payload: json.RawMessage(`{}`)})
+	if err := bus.Reply(env, Message{Type: "answer", Sender: "s", Payload: json.RawMessage(`{}`)}); err == nil {
+		t.Error("replying to a plain envelope should fail")
+	}
+}
@@ -1,73 +1,390 @@
 package bus
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 const channelBufferSize = 64
 
 type subscriber struct {
-	pattern string
-	ch      chan Envelope
+	pattern  string
+	patterns []string // set by SubscribeMany, with pattern naming them in logs
+	ch       chan Envelope
+	co       *coalescer // set for coalesced subscriptions, which don't use ch
+}
+
+// matches reports whether the subscriber wants envelopes on topic.
+func (sub subscriber) matches(topic string) bool {
+	if sub.patterns == nil {
+		return TopicMatch(sub.pattern, topic)
+	}
+	for _, p := range sub.patterns {
+		if TopicMatch(p, topic) {
+			return true
+		}
+	}
+	return false
+}
+
+// out is the channel the subscriber's consumer reads.
+func (sub subscriber) out() <-chan Envelope {
+	if sub.co != nil {
+		return sub.co.out
+	}
+	return sub.ch
 }
 
 // ChannelBus implements MessageBus using Go channels for in-process communication.
 type ChannelBus struct {
 	mu          sync.RWMutex
 	subscribers []subscriber
+	taps        []chan Envelope
 	closed      bool
+	explain     atomic.Pointer[dropExplainer]
+	bufferSize  int
+	dedup       *idWindow // nil unless BusOptions.DedupWindow is set
+	stats       busStats
+	deadLetters chan Envelope // nil until DeadLetters is called
+
+	// done is closed when Close starts, releasing blocked PublishBlocking
+	// calls before Close waits for the lock they hold.
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// BusOptions configures a ChannelBus.
+type BusOptions struct {
+	// BufferSize is the default subscription (and tap) buffer capacity;
+	// <= 0 uses 64. Deliveries to a full buffer are dropped.
+	BufferSize int
+
+	// DedupWindow, when > 0, suppresses an envelope whose ID is among the
+	// last DedupWindow IDs published, so an envelope republished with
+	// PublishEnvelope (a relay or a retried dead letter) is delivered once.
+	DedupWindow int
 }
 
 // NewChannelBus creates a new in-process message bus.
 func NewChannelBus() *ChannelBus {
-	return &ChannelBus{}
+	return NewChannelBusWithOptions(BusOptions{})
+}
+
+// NewChannelBusWithOptions creates a new in-process message bus configured
+// by opts.
+func NewChannelBusWithOptions(opts BusOptions) *ChannelBus {
+	b := &ChannelBus{bufferSize: opts.BufferSize, done: make(chan struct{})}
+	if opts.DedupWindow > 0 {
+		b.dedup = newIDWindow(opts.DedupWindow)
+	}
+	return b
+}
+
+// defaultBufferSize is the buffer capacity of Subscribe and Tap channels.
+func (b *ChannelBus) defaultBufferSize() int {
+	if b.bufferSize > 0 {
+		return b.bufferSize
+	}
+	return channelBufferSize
 }
 
 func (b *ChannelBus) Publish(topic string, msg Message) error {
-	env := NewEnvelope(topic, msg)
+	return b.deliver(NewEnvelope(topic, msg))
+}
+
+// PublishEnvelope delivers an existing envelope as is, keeping its ID and
+// Seq, e.g. to relay envelopes from another bus or retry dead letters. With
+// BusOptions.DedupWindow set, an ID seen recently is silently skipped.
+func (b *ChannelBus) PublishEnvelope(env Envelope) error {
+	return b.deliver(env)
+}
+
+// deliver fans env out to the matching subscribers and taps.
+func (b *ChannelBus) deliver(env Envelope) error {
+	topic := env.Topic
 
 	b.mu.RLock()
 	defer b.mu.RUnlock()
 
 	if b.closed {
-		return fmt.Errorf("bus is closed")
+		return ErrBusClosed
 	}
 
+	counters := b.stats.topic(topic)
+	if b.dedup != nil && b.dedup.seen(env.ID) {
+		counters.duplicates.Add(1)
+		return nil
+	}
+	counters.published.Add(1)
+	dropped := false
 	for _, sub := range b.subscribers {
-		if TopicMatch(sub.pattern, topic) {
+		if sub.matches(topic) {
+			if sub.co != nil {
+				sub.co.offer(env)
+				counters.delivered.Add(1)
+				continue
+			}
 			select {
 			case sub.ch <- env:
+				counters.delivered.Add(1)
 			default:
-				fmt.Fprintf(os.Stderr, "[bus] dropped message for %q (buffer full)\n", sub.pattern)
+				counters.dropped.Add(1)
+				dropped = true
+				if d := b.explain.Load(); d != nil {
+					d.explain(sub.pattern, env, len(sub.ch), cap(sub.ch))
+				} else {
+					fmt.Fprintf(os.Stderr, "[bus] dropped message for %q (buffer full)\n", sub.pattern)
+				}
 			}
 		}
 	}
+	if dropped {
+		b.deadLetter(env)
+	}
+	for _, tap := range b.taps {
+		select {
+		case tap <- env:
+		default:
+			// A slow tap never holds up delivery; drop silently
+		}
+	}
 	return nil
 }
 
+// PublishBlocking is like Publish, but instead of dropping it waits until
+// every matching subscriber with a full buffer accepts the envelope. It
+// returns ctx's error, wrapped, if ctx ends first and ErrBusClosed if the bus
+// is closed first; subscribers may then have received the envelope or not.
+// Subscribers that have room get it at once, whatever the slower ones do.
+// Coalesced subscribers and taps never block, as with Publish. Unsubscribe
+// waits for blocked calls to return.
+func (b *ChannelBus) PublishBlocking(topic string, msg Message, ctx context.Context) error {
+	env := NewEnvelope(topic, msg)
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		return ErrBusClosed
+	}
+
+	counters := b.stats.topic(topic)
+	counters.published.Add(1)
+	var pending []chan Envelope
+	for _, sub := range b.subscribers {
+		if !sub.matches(topic) {
+			continue
+		}
+		if sub.co != nil {
+			sub.co.offer(env)
+			counters.delivered.Add(1)
+			continue
+		}
+		select {
+		case sub.ch <- env:
+			counters.delivered.Add(1)
+		default:
+			pending = append(pending, sub.ch)
+		}
+	}
+	for _, tap := range b.taps {
+		select {
+		case tap <- env:
+		default:
+		}
+	}
+
+	for i, ch := range pending {
+		select {
+		case ch <- env:
+			counters.delivered.Add(1)
+		case <-b.done:
+			counters.dropped.Add(uint64(len(pending) - i))
+			return ErrBusClosed
+		case <-ctx.Done():
+			counters.dropped.Add(uint64(len(pending) - i))
+			b.deadLetter(env)
+			return fmt.Errorf("publish %s: %w", topic, ctx.Err())
+		}
+	}
+	return nil
+}
+
+// DeadLetters turns on the dead-letter channel and returns it. From then on
+// it receives one copy of every envelope that at least one subscriber's full
+// buffer refused, including those PublishBlocking gave up on. Its buffer is
+// the bus default and, when full, further dead letters are dropped. Every
+// call returns the same channel; Close closes it.
+func (b *ChannelBus) DeadLetters() <-chan Envelope {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.deadLetters == nil {
+		b.deadLetters = make(chan Envelope, b.defaultBufferSize())
+		if b.closed {
+			close(b.deadLetters)
+		}
+	}
+	return b.deadLetters
+}
+
+// deadLetter offers env to the dead-letter channel, if there is one. The
+// caller holds b.mu.
+func (b *ChannelBus) deadLetter(env Envelope) {
+	if b.deadLetters == nil {
+		return
+	}
+	select {
+	case b.deadLetters <- env:
+	default:
+	}
+}
+
+// ExplainDrops logs every dropped delivery to w with the subscriber, topic,
+// envelope type and buffer occupancy, at most once per interval for each
+// subscriber and topic (interval <= 0 uses DefaultExplainInterval). A nil w
+// turns explanations off, which is the default.
+func (b *ChannelBus) ExplainDrops(w io.Writer, interval time.Duration) {
+	if w == nil {
+		b.explain.Store(nil)
+		return
+	}
+	b.explain.Store(newDropExplainer(w, interval))
+}
+
+// Tap returns a channel that receives a copy of every published envelope,
+// regardless of topic. Taps have their own buffer (bufferSize <= 0 uses the
+// default) and drop when full without affecting other subscribers. Tap
+// channels are closed by Close.
+func (b *ChannelBus) Tap(bufferSize int) (<-chan Envelope, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, ErrBusClosed
+	}
+	if bufferSize <= 0 {
+		bufferSize = b.defaultBufferSize()
+	}
+	ch := make(chan Envelope, bufferSize)
+	b.taps = append(b.taps, ch)
+	return ch, nil
+}
+
 func (b *ChannelBus) Subscribe(topic string) (<-chan Envelope, error) {
+	return b.SubscribeWithBuffer(topic, 0)
+}
+
+// SubscribeWithBuffer is like Subscribe with a buffer of size envelopes
+// (<= 0 uses the bus default), so a bursty topic can buffer more than a
+// low-volume one. Each subscriber drops independently when its own buffer
+// is full.
+func (b *ChannelBus) SubscribeWithBuffer(topic string, size int) (<-chan Envelope, error) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
 	if b.closed {
-		return nil, fmt.Errorf("bus is closed")
+		return nil, ErrBusClosed
 	}
 
-	ch := make(chan Envelope, channelBufferSize)
+	if size <= 0 {
+		size = b.defaultBufferSize()
+	}
+	ch := make(chan Envelope, size)
 	b.subscribers = append(b.subscribers, subscriber{pattern: topic, ch: ch})
 	return ch, nil
 }
 
+// Subscription is one subscriber's channel, closable on its own: unlike
+// Unsubscribe, Close leaves other subscribers to the same topic alone.
+type Subscription struct {
+	C <-chan Envelope
+
+	bus  *ChannelBus
+	once sync.Once
+}
+
+// Close removes the subscription and closes C. It is safe to call more than
+// once, concurrently with Publish, and after Unsubscribe or Close on the bus.
+func (s *Subscription) Close() error {
+	var err error
+	s.once.Do(func() { err = s.bus.UnsubscribeChannel(s.C) })
+	return err
+}
+
+// SubscribeHandle is like Subscribe, but returns a Subscription that can be
+// closed without affecting sibling subscribers to topic.
+func (b *ChannelBus) SubscribeHandle(topic string) (*Subscription, error) {
+	ch, err := b.Subscribe(topic)
+	if err != nil {
+		return nil, err
+	}
+	return &Subscription{C: ch, bus: b}, nil
+}
+
+// SubscribeMany is like Subscribe for several topic patterns at once: every
+// envelope matching any of them arrives, once, on the returned channel. Tear
+// it down with UnsubscribeChannel; Unsubscribe only removes single-pattern
+// subscriptions.
+func (b *ChannelBus) SubscribeMany(patterns ...string) (<-chan Envelope, error) {
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("subscribe: no topic patterns")
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, ErrBusClosed
+	}
+
+	ch := make(chan Envelope, b.defaultBufferSize())
+	b.subscribers = append(b.subscribers, subscriber{
+		pattern:  strings.Join(patterns, ","),
+		patterns: append([]string(nil), patterns...),
+		ch:       ch,
+	})
+	return ch, nil
+}
+
+// SubscribeCoalesced is like Subscribe, but while the consumer is behind only
+// the most recent envelope per (topic, type, sender) is kept; superseded ones
+// are dropped instead of filling a buffer. Suited to consumers that render
+// current state rather than process every update.
+func (b *ChannelBus) SubscribeCoalesced(topic string) (<-chan Envelope, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, ErrBusClosed
+	}
+
+	co := newCoalescer()
+	b.subscribers = append(b.subscribers, subscriber{pattern: topic, co: co})
+	return co.out, nil
+}
+
+func (sub subscriber) close() {
+	if sub.co != nil {
+		sub.co.close()
+		return
+	}
+	close(sub.ch)
+}
+
 func (b *ChannelBus) Unsubscribe(topic string) error {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
 	filtered := b.subscribers[:0]
 	for _, sub := range b.subscribers {
-		if sub.pattern == topic {
-			close(sub.ch)
+		if sub.patterns == nil && sub.pattern == topic {
+			sub.close()
 		} else {
 			filtered = append(filtered, sub)
 		}
@@ -76,7 +393,24 @@ func (b *ChannelBus) Unsubscribe(topic string) error {
 	return nil
 }
 
+// UnsubscribeChannel removes the subscription that returned ch, whichever
+// Subscribe method created it, and closes ch. Unknown channels are ignored.
+func (b *ChannelBus) UnsubscribeChannel(ch <-chan Envelope) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for i, sub := range b.subscribers {
+		if sub.out() == ch {
+			sub.close()
+			b.subscribers = append(b.subscribers[:i], b.subscribers[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
 func (b *ChannelBus) Close() error {
+	b.closeOnce.Do(func() { close(b.done) })
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
@@ -85,8 +419,15 @@ func (b *ChannelBus) Close() error {
 	}
 	b.closed = true
 	for _, sub := range b.subscribers {
-		close(sub.ch)
+		sub.close()
 	}
 	b.subscribers = nil
+	for _, tap := range b.taps {
+		close(tap)
+	}
+	b.taps = nil
+	if b.deadLetters != nil {
+		close(b.deadLetters)
+	}
 	return nil
 }
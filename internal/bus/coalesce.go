@@ -0,0 +1,92 @@
+package bus
+
+import "sync"
+
+// coalesceKey identifies envelopes that supersede each other in a coalesced
+// subscription.
+type coalesceKey struct {
+	topic, typ, sender string
+}
+
+// coalescer delivers envelopes to a consumer, keeping only the most recent
+// envelope per (topic, type, sender) while the consumer is behind. Offering
+// never blocks and never drops a key outright, so a slow consumer always
+// catches up to the latest state instead of overflowing a buffer with stale
+// updates. Keys are delivered in the order they first became pending.
+type coalescer struct {
+	mu      sync.Mutex
+	pending map[coalesceKey]Envelope
+	order   []coalesceKey
+	notify  chan struct{}
+	done    chan struct{}
+	once    sync.Once
+	out     chan Envelope
+}
+
+func newCoalescer() *coalescer {
+	c := &coalescer{
+		pending: make(map[coalesceKey]Envelope),
+		notify:  make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		out:     make(chan Envelope),
+	}
+	go c.run()
+	return c
+}
+
+// offer queues env, replacing any pending envelope with the same key.
+func (c *coalescer) offer(env Envelope) {
+	key := coalesceKey{env.Topic, env.Type, env.Sender}
+	c.mu.Lock()
+	if _, ok := c.pending[key]; !ok {
+		c.order = append(c.order, key)
+	}
+	c.pending[key] = env
+	c.mu.Unlock()
+
+	select {
+	case c.notify <- struct{}{}:
+	default:
+	}
+}
+
+// close stops delivery and closes the output channel. Pending envelopes are
+// discarded.
+func (c *coalescer) close() {
+	c.once.Do(func() { close(c.done) })
+}
+
+func (c *coalescer) run() {
+	defer close(c.out)
+	for {
+		c.mu.Lock()
+		if len(c.order) == 0 {
+			c.mu.Unlock()
+			select {
+			case <-c.notify:
+				continue
+			case <-c.done:
+				return
+			}
+		}
+		key := c.order[0]
+		env := c.pending[key]
+		c.mu.Unlock()
+
+		select {
+		case c.out <- env:
+			c.mu.Lock()
+			// A newer envelope for key may have arrived while we were
+			// sending; if so it stays at the head and goes out next.
+			if c.pending[key].ID == env.ID {
+				delete(c.pending, key)
+				c.order = c.order[1:]
+			}
+			c.mu.Unlock()
+		case <-c.notify:
+			// Re-read the head in case it was superseded
+		case <-c.done:
+			return
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package bus
+
+import (
+	"container/list"
+	"sync"
+)
+
+// idWindow remembers the most recently seen envelope IDs, up to size, in
+// least-recently-seen order.
+type idWindow struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List // of string IDs, most recent first
+	ids   map[string]*list.Element
+}
+
+func newIDWindow(size int) *idWindow {
+	return &idWindow{size: size, order: list.New(), ids: make(map[string]*list.Element)}
+}
+
+// seen records id and reports whether it was already in the window.
+func (w *idWindow) seen(id string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if e, ok := w.ids[id]; ok {
+		w.order.MoveToFront(e)
+		return true
+	}
+	w.ids[id] = w.order.PushFront(id)
+	if w.order.Len() > w.size {
+		oldest := w.order.Back()
+		w.order.Remove(oldest)
+		delete(w.ids, oldest.Value.(string))
+	}
+	return false
+}
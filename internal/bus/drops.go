@@ -0,0 +1,59 @@
+package bus
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// DefaultExplainInterval is the minimum gap between drop explanations for the
+// same subscriber and topic when ExplainDrops is given no interval.
+const DefaultExplainInterval = time.Second
+
+// dropExplainer logs dropped deliveries with enough context to diagnose them:
+// which subscriber, which topic and envelope type, and how full its buffer
+// was. Logging is rate-limited per subscriber and topic; suppressed drops are
+// counted and reported with the next line.
+type dropExplainer struct {
+	w        io.Writer
+	interval time.Duration
+
+	mu         sync.Mutex
+	last       map[string]time.Time
+	suppressed map[string]int
+}
+
+func newDropExplainer(w io.Writer, interval time.Duration) *dropExplainer {
+	if interval <= 0 {
+		interval = DefaultExplainInterval
+	}
+	return &dropExplainer{
+		w:          w,
+		interval:   interval,
+		last:       make(map[string]time.Time),
+		suppressed: make(map[string]int),
+	}
+}
+
+// explain records one dropped delivery of env to the subscriber registered
+// for pattern, whose buffer held occupancy of capacity envelopes.
+func (d *dropExplainer) explain(pattern string, env Envelope, occupancy, capacity int) {
+	key := pattern + "\x00" + env.Topic
+	now := time.Now()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if last, ok := d.last[key]; ok && now.Sub(last) < d.interval {
+		d.suppressed[key]++
+		return
+	}
+	d.last[key] = now
+	more := ""
+	if n := d.suppressed[key]; n > 0 {
+		more = fmt.Sprintf(" (%d more suppressed)", n)
+		delete(d.suppressed, key)
+	}
+	fmt.Fprintf(d.w, "[bus] subscriber %q dropped %s on %q (id %s): buffer full (%d/%d)%s\n",
+		pattern, env.Type, env.Topic, env.ID, occupancy, capacity, more)
+}
@@ -0,0 +1,4 @@
+package bus
+
+// setSlotHeld makes f run while b's publishers hold a writer slot.
+func (b *FileBus) setSlotHeld(f func()) { b.slotHeld = f }
@@ -4,10 +4,13 @@ import (
 	"bufio"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 )
@@ -15,7 +18,9 @@ import (
 type fileSubscriber struct {
 	pattern string
 	ch      chan Envelope
+	co      *coalescer       // set for coalesced subscriptions, which don't use ch
 	offsets map[string]int64 // per-file byte offsets (keyed by filename)
+	seqs    map[string]uint64 // per-file highest Seq read, to skip on a re-read
 	stop    chan struct{}
 }
 
@@ -28,6 +33,10 @@ type FileBus struct {
 	mu      sync.Mutex
 	subscribers []*fileSubscriber
 	closed  bool
+	explain atomic.Pointer[dropExplainer]
+
+	maxWriters int    // see SetMaxWriters
+	slotHeld   func() // set by tests; runs while a writer slot is held
 }
 
 // NewFileBus creates a cross-process message bus backed by files in dir.
@@ -36,9 +45,10 @@ func NewFileBus(dir string, pollMin, pollMax time.Duration) (*FileBus, error) {
 		return nil, fmt.Errorf("create bus dir: %w", err)
 	}
 	return &FileBus{
-		dir:     dir,
-		pollMin: pollMin,
-		pollMax: pollMax,
+		dir:        dir,
+		pollMin:    pollMin,
+		pollMax:    pollMax,
+		maxWriters: DefaultMaxWriters,
 	}, nil
 }
 
@@ -46,13 +56,52 @@ func (b *FileBus) topicFile(topic string) string {
 	return filepath.Join(b.dir, topic+".jsonl")
 }
 
+// seqFileName holds the last sequence number used in the bus directory. The
+// name deliberately lacks the .jsonl suffix so pollers ignore it.
+const seqFileName = "bus.seq"
+
 func (b *FileBus) Publish(topic string, msg Message) error {
-	env := NewEnvelope(topic, msg)
-	data, err := json.Marshal(env)
+	return b.PublishBatch(topic, []Message{msg})
+}
+
+// PublishBatch publishes msgs to topic in order with consecutive sequence
+// numbers, in a single locked append, so a task's findings land together and
+// peers contend for the board once rather than once per message.
+func (b *FileBus) PublishBatch(topic string, msgs []Message) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	release, err := b.acquireWriterSlot()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	// Hold the sequence lock across the write so file order matches Seq order
+	// for every publisher sharing the directory, across process restarts.
+	sf, err := os.OpenFile(filepath.Join(b.dir, seqFileName), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return fmt.Errorf("open seq file: %w", err)
+	}
+	defer sf.Close()
+	if err := syscall.Flock(int(sf.Fd()), syscall.LOCK_EX); err != nil {
+		return fmt.Errorf("flock seq: %w", err)
+	}
+	defer syscall.Flock(int(sf.Fd()), syscall.LOCK_UN)
+
+	last, err := b.reserveSeqs(sf, uint64(len(msgs)))
 	if err != nil {
-		return fmt.Errorf("marshal envelope: %w", err)
+		return err
+	}
+	var lines []byte
+	for i, msg := range msgs {
+		env := newEnvelopeWithSeq(topic, msg, last-uint64(len(msgs)-1-i))
+		data, err := json.Marshal(env)
+		if err != nil {
+			return fmt.Errorf("marshal envelope: %w", err)
+		}
+		lines = append(append(lines, data...), '\n')
 	}
-	line := append(data, '\n')
 
 	path := b.topicFile(topic)
 	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
@@ -67,34 +116,118 @@ func (b *FileBus) Publish(topic string, msg Message) error {
 	}
 	defer syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
 
-	if _, err := f.Write(line); err != nil {
+	if _, err := f.Write(lines); err != nil {
 		return fmt.Errorf("write: %w", err)
 	}
 	return nil
 }
 
+// reserveSeqs reads the last used sequence from the locked seq file, records
+// n more as used and returns the last of them. A missing or empty seq file is
+// seeded from the highest Seq already in the topic files, so existing buses
+// keep counting up.
+func (b *FileBus) reserveSeqs(sf *os.File, n uint64) (uint64, error) {
+	data, err := io.ReadAll(sf)
+	if err != nil {
+		return 0, fmt.Errorf("read seq file: %w", err)
+	}
+	var last uint64
+	if text := strings.TrimSpace(string(data)); text != "" {
+		last, err = strconv.ParseUint(text, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("parse seq file: %w", err)
+		}
+	} else {
+		last = b.maxFileSeq()
+	}
+
+	next := last + n
+	if err := sf.Truncate(0); err != nil {
+		return 0, fmt.Errorf("truncate seq file: %w", err)
+	}
+	if _, err := sf.WriteAt([]byte(strconv.FormatUint(next, 10)+"\n"), 0); err != nil {
+		return 0, fmt.Errorf("write seq file: %w", err)
+	}
+	return next, nil
+}
+
+// maxFileSeq returns the highest Seq found in the bus directory's topic files.
+func (b *FileBus) maxFileSeq() uint64 {
+	var max uint64
+	entries, err := os.ReadDir(b.dir)
+	if err != nil {
+		return 0
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".jsonl") {
+			continue
+		}
+		f, err := os.Open(filepath.Join(b.dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			var env struct {
+				Seq uint64 `json:"seq"`
+			}
+			if json.Unmarshal(scanner.Bytes(), &env) == nil && env.Seq > max {
+				max = env.Seq
+			}
+		}
+		f.Close()
+	}
+	return max
+}
+
 func (b *FileBus) Subscribe(topic string) (<-chan Envelope, error) {
+	return b.subscribe(topic, false)
+}
+
+// SubscribeCoalesced is like Subscribe, but keeps only the most recent
+// envelope per (topic, type, sender) while the consumer is behind. See
+// ChannelBus.SubscribeCoalesced.
+func (b *FileBus) SubscribeCoalesced(topic string) (<-chan Envelope, error) {
+	return b.subscribe(topic, true)
+}
+
+func (b *FileBus) subscribe(topic string, coalesce bool) (<-chan Envelope, error) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
 	if b.closed {
-		return nil, fmt.Errorf("bus is closed")
+		return nil, ErrBusClosed
 	}
 
 	sub := &fileSubscriber{
 		pattern: topic,
-		ch:      make(chan Envelope, channelBufferSize),
 		offsets: make(map[string]int64),
+		seqs:    make(map[string]uint64),
 		stop:    make(chan struct{}),
 	}
+	var out <-chan Envelope
+	if coalesce {
+		sub.co = newCoalescer()
+		out = sub.co.out
+	} else {
+		sub.ch = make(chan Envelope, channelBufferSize)
+		out = sub.ch
+	}
 	b.subscribers = append(b.subscribers, sub)
 
 	go b.pollLoop(sub)
-	return sub.ch, nil
+	return out, nil
 }
 
 func (b *FileBus) pollLoop(sub *fileSubscriber) {
-	defer close(sub.ch)
+	defer func() {
+		if sub.co != nil {
+			sub.co.close()
+		} else {
+			close(sub.ch)
+		}
+	}()
 
 	interval := b.pollMin
 	idleCount := 0
@@ -139,6 +272,12 @@ func (b *FileBus) pollFiles(sub *fileSubscriber) int {
 			continue
 		}
 		fileOffset := sub.offsets[name]
+		if info.Size() < fileOffset {
+			// The file was rewritten shorter (board compaction): read it
+			// again from the start. Seqs only grow within a file, so the
+			// entries already read are the ones up to sub.seqs[name].
+			fileOffset = 0
+		}
 		if info.Size() <= fileOffset {
 			continue
 		}
@@ -165,12 +304,26 @@ func (b *FileBus) pollFiles(sub *fileSubscriber) int {
 			if err := json.Unmarshal(lineBytes, &env); err != nil {
 				continue
 			}
+			if env.Seq != 0 {
+				if env.Seq <= sub.seqs[name] {
+					continue
+				}
+				sub.seqs[name] = env.Seq
+			}
 			if TopicMatch(sub.pattern, env.Topic) {
+				if sub.co != nil {
+					sub.co.offer(env)
+					found++
+					continue
+				}
 				select {
 				case sub.ch <- env:
 					found++
 				default:
 					// Drop on full buffer
+					if d := b.explain.Load(); d != nil {
+						d.explain(sub.pattern, env, len(sub.ch), cap(sub.ch))
+					}
 				}
 			}
 		}
@@ -182,6 +335,16 @@ func (b *FileBus) pollFiles(sub *fileSubscriber) int {
 	return found
 }
 
+// ExplainDrops logs dropped deliveries to w, rate-limited per subscriber and
+// topic. See ChannelBus.ExplainDrops.
+func (b *FileBus) ExplainDrops(w io.Writer, interval time.Duration) {
+	if w == nil {
+		b.explain.Store(nil)
+		return
+	}
+	b.explain.Store(newDropExplainer(w, interval))
+}
+
 func (b *FileBus) Unsubscribe(topic string) error {
 	b.mu.Lock()
 	defer b.mu.Unlock()
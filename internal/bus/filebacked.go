@@ -0,0 +1,186 @@
+package bus
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileBackedOptions configures a FileBackedBus.
+type FileBackedOptions struct {
+	// ReplayFrom skips history up to and including this Seq when replaying
+	// to new subscribers, so a consumer restarting after a crash resumes
+	// after the last envelope it handled. 0 replays everything.
+	ReplayFrom uint64
+}
+
+// FileBackedBus is an in-process bus like ChannelBus that also appends every
+// published envelope to a JSONL file, in the format ReadBoard reads, and
+// replays the matching history to each new subscriber before its live
+// envelopes. Seq continues from the highest Seq in the file, so it stays
+// monotonic across restarts. Only one process should write a given file; use
+// FileBus to share a bus between processes.
+type FileBackedBus struct {
+	path string
+	opts FileBackedOptions
+	live *ChannelBus
+
+	mu     sync.Mutex // serializes publishing with subscribing
+	f      *os.File
+	seq    uint64
+	stops  map[string][]chan struct{} // per pattern, closed by Unsubscribe
+	closed bool
+}
+
+// NewFileBackedBus opens (creating if needed) the JSONL file at path and
+// returns a bus that persists to it.
+func NewFileBackedBus(path string, opts FileBackedOptions) (*FileBackedBus, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("create bus dir: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("open bus file: %w", err)
+	}
+	b := &FileBackedBus{path: path, opts: opts, live: NewChannelBus(), f: f, stops: make(map[string][]chan struct{})}
+	envs, err := b.history("", 0)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	for _, env := range envs {
+		b.seq = max(b.seq, env.Seq)
+	}
+	// A crash mid-write can leave a partial last line; end it so the next
+	// append starts a fresh one.
+	if info, err := f.Stat(); err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := f.ReadAt(last, info.Size()-1); err == nil && last[0] != '\n' {
+			if _, err := f.Write([]byte("\n")); err != nil {
+				f.Close()
+				return nil, fmt.Errorf("write: %w", err)
+			}
+		}
+	}
+	return b, nil
+}
+
+func (b *FileBackedBus) Publish(topic string, msg Message) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrBusClosed
+	}
+	env := newEnvelopeWithSeq(topic, msg, b.seq+1)
+	data, err := json.Marshal(env)
+	if err != nil {
+		return fmt.Errorf("marshal envelope: %w", err)
+	}
+	if _, err := b.f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+	b.seq = env.Seq
+	return b.live.deliver(env)
+}
+
+// Subscribe replays the envelopes in the file matching topic (after
+// FileBackedOptions.ReplayFrom), then streams live ones. Replay waits for the
+// consumer; live envelopes are buffered and dropped like ChannelBus.
+func (b *FileBackedBus) Subscribe(topic string) (<-chan Envelope, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, ErrBusClosed
+	}
+	// Holding mu keeps publishes out between reading the history and
+	// subscribing, so nothing is missed or seen twice.
+	history, err := b.history(topic, b.opts.ReplayFrom)
+	if err != nil {
+		return nil, err
+	}
+	live, err := b.live.Subscribe(topic)
+	if err != nil {
+		return nil, err
+	}
+	stop := make(chan struct{})
+	b.stops[topic] = append(b.stops[topic], stop)
+
+	out := make(chan Envelope, channelBufferSize)
+	go func() {
+		defer close(out)
+		for _, env := range history {
+			select {
+			case out <- env:
+			case <-stop:
+				return
+			}
+		}
+		for env := range live {
+			select {
+			case out <- env:
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// history reads the file's envelopes matching pattern with Seq above after.
+// Lines that do not parse, such as a partial last write, are skipped.
+func (b *FileBackedBus) history(pattern string, after uint64) ([]Envelope, error) {
+	if _, err := b.f.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("read bus file: %w", err)
+	}
+	var envs []Envelope
+	scanner := bufio.NewScanner(b.f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var env Envelope
+		if json.Unmarshal(scanner.Bytes(), &env) != nil {
+			continue
+		}
+		if env.Seq > after && TopicMatch(pattern, env.Topic) {
+			envs = append(envs, env)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read bus file: %w", err)
+	}
+	return envs, nil
+}
+
+func (b *FileBackedBus) Unsubscribe(topic string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, stop := range b.stops[topic] {
+		close(stop)
+	}
+	delete(b.stops, topic)
+	return b.live.Unsubscribe(topic)
+}
+
+func (b *FileBackedBus) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+	for _, stops := range b.stops {
+		for _, stop := range stops {
+			close(stop)
+		}
+	}
+	b.stops = nil
+	b.live.Close()
+	return b.f.Close()
+}
@@ -0,0 +1,59 @@
+package bus
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ReplyTopicPrefix starts the private topic each Request awaits its reply
+// on. It sits outside every other topic so a responder subscribed to the
+// request topic never sees replies.
+const ReplyTopicPrefix = "reply"
+
+// ErrNoReply is returned by Request when no reply arrives in time.
+var ErrNoReply = errors.New("no reply")
+
+// Request publishes msg to topic with a fresh correlation ID and a private
+// reply topic, then waits up to timeout for the first reply carrying that
+// correlation ID (see Reply). Its reply subscription is removed on return.
+func (b *ChannelBus) Request(topic string, msg Message, timeout time.Duration) (Envelope, error) {
+	msg.CorrelationID = NewID()
+	msg.ReplyTo = ReplyTopicPrefix + "." + msg.CorrelationID
+
+	sub, err := b.SubscribeHandle(msg.ReplyTo)
+	if err != nil {
+		return Envelope{}, err
+	}
+	defer sub.Close()
+	if err := b.Publish(topic, msg); err != nil {
+		return Envelope{}, err
+	}
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	for {
+		select {
+		case env, ok := <-sub.C:
+			if !ok {
+				return Envelope{}, ErrBusClosed
+			}
+			if env.CorrelationID == msg.CorrelationID {
+				return env, nil
+			}
+		case <-deadline.C:
+			return Envelope{}, fmt.Errorf("request %s: %w within %s", topic, ErrNoReply, timeout)
+		}
+	}
+}
+
+// Reply publishes msg as the reply to the request to, on the topic and with
+// the correlation ID the requester is waiting for.
+func (b *ChannelBus) Reply(to Envelope, msg Message) error {
+	if to.ReplyTo == "" || to.CorrelationID == "" {
+		return fmt.Errorf("reply: envelope %s is not a request", to.ID)
+	}
+	msg.CorrelationID = to.CorrelationID
+	msg.ReplyTo = ""
+	return b.Publish(to.ReplyTo, msg)
+}
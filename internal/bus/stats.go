@@ -0,0 +1,61 @@
+package bus
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// TopicStats counts a topic's traffic on a ChannelBus since it was created or
+// last reset. One envelope is delivered (or dropped) once per matching
+// subscriber, so Delivered+Dropped can exceed Published. Taps are not
+// counted.
+type TopicStats struct {
+	Published  uint64 // envelopes published on the topic
+	Delivered  uint64 // deliveries accepted by a subscriber
+	Dropped    uint64 // deliveries lost to a full buffer, or abandoned by PublishBlocking
+	Duplicates uint64 // envelopes suppressed by BusOptions.DedupWindow, not in Published
+}
+
+func (s TopicStats) String() string {
+	return fmt.Sprintf("published %d, delivered %d, dropped %d", s.Published, s.Delivered, s.Dropped)
+}
+
+type topicCounters struct {
+	published, delivered, dropped, duplicates atomic.Uint64
+}
+
+// busStats holds the per-topic counters of a ChannelBus.
+type busStats struct {
+	topics sync.Map // topic -> *topicCounters
+}
+
+func (s *busStats) topic(topic string) *topicCounters {
+	if c, ok := s.topics.Load(topic); ok {
+		return c.(*topicCounters)
+	}
+	c, _ := s.topics.LoadOrStore(topic, &topicCounters{})
+	return c.(*topicCounters)
+}
+
+// Stats returns the counters of every topic published on since the bus was
+// created or ResetStats was last called.
+func (b *ChannelBus) Stats() map[string]TopicStats {
+	stats := make(map[string]TopicStats)
+	b.stats.topics.Range(func(k, v any) bool {
+		c := v.(*topicCounters)
+		stats[k.(string)] = TopicStats{
+			Published:  c.published.Load(),
+			Delivered:  c.delivered.Load(),
+			Dropped:    c.dropped.Load(),
+			Duplicates: c.duplicates.Load(),
+		}
+		return true
+	})
+	return stats
+}
+
+// ResetStats zeroes every topic's counters, e.g. between ralph waves.
+func (b *ChannelBus) ResetStats() {
+	b.stats.topics.Clear()
+}
@@ -0,0 +1,103 @@
+package bus
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// DefaultMaxWriters is how many publishers may queue for a board directory's
+// locks at once; see FileBus.SetMaxWriters.
+const DefaultMaxWriters = 4
+
+// SetMaxWriters limits how many publishers, across every process sharing the
+// directory, contend for its locks at once; the rest wait for a writer slot.
+// With a large wave of tasks this keeps a bounded queue on the append lock
+// instead of a thundering herd. n <= 0 removes the limit.
+func (b *FileBus) SetMaxWriters(n int) { b.maxWriters = n }
+
+// NewBoardBus opens a FileBus for publishing to a shared board directory,
+// with at most maxWriters publishers contending for its locks. Every board
+// writer uses it so the cap holds however an entry reaches the board.
+func NewBoardBus(dir string, maxWriters int) (*FileBus, error) {
+	b, err := NewFileBus(dir, 100*time.Millisecond, time.Second)
+	if err != nil {
+		return nil, err
+	}
+	b.SetMaxWriters(maxWriters)
+	return b, nil
+}
+
+// writerSlotFile is one writer slot's lock file. Like seqFileName it lacks
+// the .jsonl suffix so pollers ignore it.
+func (b *FileBus) writerSlotFile(i int) string {
+	return filepath.Join(b.dir, fmt.Sprintf("bus.writer.%d", i))
+}
+
+// acquireWriterSlot takes a free writer slot, or queues on one when all are
+// held, and returns its release function. Slots are flocks, so a crashed
+// writer's slot frees itself.
+func (b *FileBus) acquireWriterSlot() (func(), error) {
+	n := b.maxWriters
+	if n <= 0 {
+		return func() {}, nil
+	}
+	// Start the scan at a per-process slot so waiters spread across slots.
+	first := os.Getpid() % n
+	for k := 0; k < n; k++ {
+		f, err := os.OpenFile(b.writerSlotFile((first+k)%n), os.O_CREATE|os.O_RDWR, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("open writer slot: %w", err)
+		}
+		err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+		if err == nil {
+			return b.holdSlot(f), nil
+		}
+		f.Close()
+		if !errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, fmt.Errorf("flock writer slot: %w", err)
+		}
+	}
+
+	f, err := os.OpenFile(b.writerSlotFile(first), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("open writer slot: %w", err)
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("flock writer slot: %w", err)
+	}
+	return b.holdSlot(f), nil
+}
+
+func (b *FileBus) holdSlot(f *os.File) func() {
+	if b.slotHeld != nil {
+		b.slotHeld()
+	}
+	return func() {
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}
+}
+
+// BatchPublisher is a bus that can publish several messages as one write.
+type BatchPublisher interface {
+	PublishBatch(topic string, msgs []Message) error
+}
+
+// PublishAll publishes msgs to topic in order, as one batch when b supports
+// it.
+func PublishAll(b MessageBus, topic string, msgs []Message) error {
+	if bp, ok := b.(BatchPublisher); ok {
+		return bp.PublishBatch(topic, msgs)
+	}
+	for _, msg := range msgs {
+		if err := b.Publish(topic, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
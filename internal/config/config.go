@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type Config struct {
@@ -13,22 +14,69 @@ type Config struct {
 	AnthropicAPIKey string
 	GeminiAPIKey    string
 	OpenAIAPIKey    string
+	GitHubToken     string
+
+	// Fallback key sources (<VAR>_FILE, then <VAR>_CMD) used when a key's
+	// environment variable is unset; see AnthropicKey and friends
+	AnthropicKeySource KeySource
+	GeminiKeySource    KeySource
+	OpenAIKeySource    KeySource
 
 	// Model config
 	AnthropicModel     string
+	AnthropicFastModel string
 	AnthropicMaxTokens int
 	GeminiModel        string
 	OpenAIModel        string
+	OpenAIChatModel    string // model of the GPT chat completions agent
 	OpenAIMaxTokens    int
+	OllamaModel        string // local Ollama model; the Ollama agent is unavailable without one
 
 	// Timeouts (seconds)
 	Stage1Timeout int
 	Stage2Timeout int
 
+	// Per-agent stage 1 timeouts in seconds (CONSENSUS_AGENT_TIMEOUTS=
+	// "Ollama=300"), overriding Stage1Timeout for the named agents
+	AgentTimeouts map[string]int
+
+	// Per-model prices for cost estimates: DefaultModelPrices overridden by
+	// CONSENSUS_MODEL_PRICES="gpt-4o=2.5/10" (USD per million tokens)
+	ModelPrices map[string]ModelPrice
+
+	// Total retry budget shared across consensus stages
+	ConsensusRetries int
+
+	// Per-agent stage 1 retry cap and initial backoff (doubling per retry)
+	AgentRetries      int
+	AgentRetryBackoff time.Duration
+
+	// Fast chairman timeout (seconds) when stage 2 escalates
+	FastChairmanTimeout int
+
+	// Stage 1 response cache directory (empty uses the user cache dir)
+	CacheDir string
+
+	// Labeled consensus run history directory (empty uses the user cache dir)
+	HistoryDir string
+
+	// Extra OpenAI-compatible consensus agents (CONSENSUS_EXTRA_AGENTS)
+	ExtraAgents []ExtraAgent
+
+	// Per-agent answer markers (CONSENSUS_ANSWER_MARKERS="Gemini=## Answer"):
+	// stage 1 output before the marker is dropped
+	AnswerMarkers map[string]string
+
+	// House-style framing around every stage 1 prompt
+	PromptPrefix string
+	PromptSuffix string
+
 	// Base URLs (for testing - override API endpoints)
 	AnthropicBaseURL string
 	GeminiBaseURL    string
 	OpenAIBaseURL    string
+	OllamaBaseURL    string
+	GitHubBaseURL    string
 
 	// Parallel runner
 	MaxConcurrent     int
@@ -42,6 +90,30 @@ type Config struct {
 	RalphTimeoutQuality   int
 	RalphTimeoutGlobal    int
 	RalphStuckThreshold   int
+
+	// Extra board type → display prefix mappings (RALPH_BOARD_PREFIXES)
+	BoardPrefixes map[string]string
+
+	// Publishers allowed to contend for a board's locks at once, across
+	// processes (RALPH_BOARD_MAX_WRITERS; 0 = unlimited)
+	BoardMaxWriters int
+
+	// Per-gate subprocess environment, keyed by gate name, from
+	// RALPH_GATE_ENV_<GATE>="KEY=value,KEY2=value2"
+	RalphGateEnv map[string]map[string]string
+
+	// Command template of the implementation gate; {prompt} is replaced by
+	// the prompt, which goes to stdin when there is no placeholder
+	// (RALPH_IMPLEMENT_CMD)
+	RalphImplementCmd string
+
+	// Shell command of the test gate; empty auto-detects the test runner
+	// (RALPH_TEST_CMD)
+	RalphTestCmd string
+
+	// Bytes of the context file fed back into each implementation prompt
+	// (RALPH_CONTEXT_LIMIT; 0 = unlimited)
+	RalphContextLimit int
 }
 
 func Load() *Config {
@@ -51,19 +123,42 @@ func Load() *Config {
 		AnthropicAPIKey: os.Getenv("ANTHROPIC_API_KEY"),
 		GeminiAPIKey:    coalesce(os.Getenv("GEMINI_API_KEY"), os.Getenv("GOOGLE_API_KEY")),
 		OpenAIAPIKey:    os.Getenv("OPENAI_API_KEY"),
+		GitHubToken:     coalesce(os.Getenv("GITHUB_TOKEN"), os.Getenv("GH_TOKEN")),
+
+		AnthropicKeySource: NewKeySource("ANTHROPIC_API_KEY"),
+		GeminiKeySource:    NewKeySource("GEMINI_API_KEY"),
+		OpenAIKeySource:    NewKeySource("OPENAI_API_KEY"),
 
 		AnthropicModel:     envOr("ANTHROPIC_MODEL", "claude-opus-4-5-20251101"),
+		AnthropicFastModel: envOr("ANTHROPIC_FAST_MODEL", "claude-haiku-4-5"),
 		AnthropicMaxTokens: envInt("ANTHROPIC_MAX_TOKENS", 16000),
 		GeminiModel:        envOr("GEMINI_MODEL", "gemini-3-pro-preview"),
 		OpenAIModel:        envOr("OPENAI_MODEL", "gpt-5.1-codex-max"),
+		OpenAIChatModel:    envOr("OPENAI_CHAT_MODEL", "gpt-4o"),
+		OllamaModel:        os.Getenv("OLLAMA_MODEL"),
 		OpenAIMaxTokens:    envInt("OPENAI_MAX_TOKENS", 16000),
 
 		Stage1Timeout: envInt("CONSENSUS_STAGE1_TIMEOUT", 60),
 		Stage2Timeout: envInt("CONSENSUS_STAGE2_TIMEOUT", 60),
+		AgentTimeouts: parseSeconds(os.Getenv("CONSENSUS_AGENT_TIMEOUTS")),
+		ModelPrices:   parsePrices(os.Getenv("CONSENSUS_MODEL_PRICES")),
+
+		ConsensusRetries:    envInt("CONSENSUS_RETRIES", 0),
+		AgentRetries:        envInt("CONSENSUS_AGENT_RETRIES", 0),
+		AgentRetryBackoff:   envDuration("CONSENSUS_RETRY_BACKOFF", time.Second),
+		FastChairmanTimeout: envInt("CONSENSUS_FAST_TIMEOUT", 30),
+		CacheDir:            os.Getenv("CONCLAVE_CACHE_DIR"),
+		HistoryDir:          os.Getenv("CONCLAVE_HISTORY_DIR"),
+		ExtraAgents:         ParseExtraAgents(os.Getenv("CONSENSUS_EXTRA_AGENTS")),
+		AnswerMarkers:       parsePairs(os.Getenv("CONSENSUS_ANSWER_MARKERS")),
+		PromptPrefix:        os.Getenv("CONSENSUS_PROMPT_PREFIX"),
+		PromptSuffix:        os.Getenv("CONSENSUS_PROMPT_SUFFIX"),
 
 		AnthropicBaseURL: envOr("ANTHROPIC_BASE_URL", "https://api.anthropic.com"),
 		GeminiBaseURL:    envOr("GEMINI_BASE_URL", "https://generativelanguage.googleapis.com"),
 		OpenAIBaseURL:    envOr("OPENAI_BASE_URL", "https://api.openai.com"),
+		OllamaBaseURL:    envOr("OLLAMA_BASE_URL", "http://localhost:11434"),
+		GitHubBaseURL:    envOr("GITHUB_API_URL", "https://api.github.com"),
 
 		MaxConcurrent:     envInt("PARALLEL_MAX_CONCURRENT", 3),
 		WorktreeDir:       envOr("PARALLEL_WORKTREE_DIR", ".worktrees"),
@@ -75,7 +170,126 @@ func Load() *Config {
 		RalphTimeoutQuality:   envInt("RALPH_TIMEOUT_QUALITY", 180),
 		RalphTimeoutGlobal:    envInt("RALPH_TIMEOUT_GLOBAL", 3600),
 		RalphStuckThreshold:   envInt("RALPH_STUCK_THRESHOLD", 3),
+		BoardPrefixes:         parsePairs(os.Getenv("RALPH_BOARD_PREFIXES")),
+		BoardMaxWriters:       envInt("RALPH_BOARD_MAX_WRITERS", 4),
+		RalphGateEnv:          gateEnv(),
+		RalphImplementCmd:     envOr("RALPH_IMPLEMENT_CMD", "claude -p {prompt}"),
+		RalphTestCmd:          os.Getenv("RALPH_TEST_CMD"),
+		RalphContextLimit:     envInt("RALPH_CONTEXT_LIMIT", 50000),
+	}
+}
+
+// AnthropicKey returns the Anthropic API key: ANTHROPIC_API_KEY, else its
+// file or command source, resolved on first use.
+func (c *Config) AnthropicKey() string { return ResolveKey(c.AnthropicAPIKey, c.AnthropicKeySource) }
+
+// GeminiKey returns the Gemini API key: GEMINI_API_KEY or GOOGLE_API_KEY,
+// else the GEMINI_API_KEY file or command source.
+func (c *Config) GeminiKey() string { return ResolveKey(c.GeminiAPIKey, c.GeminiKeySource) }
+
+// OpenAIKey returns the OpenAI API key: OPENAI_API_KEY, else its file or
+// command source.
+func (c *Config) OpenAIKey() string { return ResolveKey(c.OpenAIAPIKey, c.OpenAIKeySource) }
+
+// ExtraAgent describes an OpenAI-compatible endpoint added to the consensus
+// roster alongside the built-in agents.
+type ExtraAgent struct {
+	Name      string
+	BaseURL   string
+	Model     string
+	APIKeyEnv string
+
+	// AnswerMarker, when set, keeps only the output after its last
+	// occurrence (e.g. "</think>"), dropping a reasoning preamble.
+	AnswerMarker string
+}
+
+// ParseExtraAgents parses a roster of the form
+// "name=base_url,model,API_KEY_ENV[,answer_marker];name2=...". Malformed
+// entries are skipped.
+func ParseExtraAgents(s string) []ExtraAgent {
+	var agents []ExtraAgent
+	for _, entry := range strings.Split(s, ";") {
+		name, spec, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			continue
+		}
+		parts := strings.Split(spec, ",")
+		if len(parts) != 3 && len(parts) != 4 {
+			continue
+		}
+		a := ExtraAgent{
+			Name:      strings.TrimSpace(name),
+			BaseURL:   strings.TrimSpace(parts[0]),
+			Model:     strings.TrimSpace(parts[1]),
+			APIKeyEnv: strings.TrimSpace(parts[2]),
+		}
+		if len(parts) == 4 {
+			a.AnswerMarker = strings.TrimSpace(parts[3])
+		}
+		if a.Name == "" || a.BaseURL == "" || a.Model == "" || a.APIKeyEnv == "" {
+			continue
+		}
+		agents = append(agents, a)
+	}
+	return agents
+}
+
+// parsePairs parses "key=value,key2=value2" into a map, skipping malformed
+// pairs. Returns nil for an empty string.
+func parsePairs(s string) map[string]string {
+	var m map[string]string
+	for _, pair := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(pair, "=")
+		k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+		if !ok || k == "" || v == "" {
+			continue
+		}
+		if m == nil {
+			m = make(map[string]string)
+		}
+		m[k] = v
 	}
+	return m
+}
+
+// parseSeconds parses "name=seconds,..." pairs, skipping values that are not
+// positive integers. Returns nil for an empty string.
+func parseSeconds(s string) map[string]int {
+	var m map[string]int
+	for k, v := range parsePairs(s) {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			continue
+		}
+		if m == nil {
+			m = make(map[string]int)
+		}
+		m[k] = n
+	}
+	return m
+}
+
+const gateEnvPrefix = "RALPH_GATE_ENV_"
+
+// gateEnv collects RALPH_GATE_ENV_<GATE> variables into per-gate maps keyed
+// by the lowercased gate name.
+func gateEnv() map[string]map[string]string {
+	var m map[string]map[string]string
+	for _, kv := range os.Environ() {
+		k, v, _ := strings.Cut(kv, "=")
+		gate, ok := strings.CutPrefix(k, gateEnvPrefix)
+		if !ok || gate == "" {
+			continue
+		}
+		if pairs := parsePairs(v); pairs != nil {
+			if m == nil {
+				m = make(map[string]map[string]string)
+			}
+			m[strings.ToLower(gate)] = pairs
+		}
+	}
+	return m
 }
 
 func loadDotEnv() {
@@ -132,6 +346,15 @@ func envInt(key string, fallback int) int {
 	return fallback
 }
 
+func envDuration(key string, fallback time.Duration) time.Duration {
+	if v := os.Getenv(key); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			return d
+		}
+	}
+	return fallback
+}
+
 func coalesce(vals ...string) string {
 	for _, v := range vals {
 		if v != "" {
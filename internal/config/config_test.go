@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestLoadAPIKeys(t *testing.T) {
@@ -114,7 +115,7 @@ func TestLoadDotEnvLocalOverridesHome(t *testing.T) {
 
 func TestDefaults(t *testing.T) {
 	// Clear env vars that might interfere
-	for _, k := range []string{"ANTHROPIC_MODEL", "GEMINI_MODEL", "OPENAI_MODEL", "CONSENSUS_STAGE1_TIMEOUT"} {
+	for _, k := range []string{"ANTHROPIC_MODEL", "GEMINI_MODEL", "OPENAI_MODEL", "CONSENSUS_STAGE1_TIMEOUT", "RALPH_IMPLEMENT_CMD"} {
 		t.Setenv(k, "")
 	}
 	cfg := Load()
@@ -130,4 +131,97 @@ func TestDefaults(t *testing.T) {
 	if cfg.Stage1Timeout != 60 {
 		t.Errorf("Stage1Timeout = %d", cfg.Stage1Timeout)
 	}
+	if cfg.RalphImplementCmd != "claude -p {prompt}" {
+		t.Errorf("RalphImplementCmd = %q", cfg.RalphImplementCmd)
+	}
+}
+
+func TestParseExtraAgents(t *testing.T) {
+	got := ParseExtraAgents("groq=https://api.groq.com/openai/v1,llama-3.3-70b,GROQ_API_KEY; local=http://localhost:8000/v1, qwen2.5 ,VLLM_KEY;broken;bad=only,two")
+	if len(got) != 2 {
+		t.Fatalf("got %d agents, want 2: %+v", len(got), got)
+	}
+	want := ExtraAgent{Name: "local", BaseURL: "http://localhost:8000/v1", Model: "qwen2.5", APIKeyEnv: "VLLM_KEY"}
+	if got[1] != want {
+		t.Errorf("got %+v, want %+v", got[1], want)
+	}
+	if ParseExtraAgents("") != nil {
+		t.Error("empty roster should parse to nil")
+	}
+}
+
+func TestParseExtraAgentsAnswerMarker(t *testing.T) {
+	got := ParseExtraAgents("deepseek=https://api.deepseek.com/v1,deepseek-reasoner,DEEPSEEK_API_KEY,</think>")
+	if len(got) != 1 || got[0].AnswerMarker != "</think>" || got[0].APIKeyEnv != "DEEPSEEK_API_KEY" {
+		t.Errorf("got %+v, want the marker as the fourth field", got)
+	}
+}
+
+func TestBoardPrefixesFromEnv(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("RALPH_BOARD_PREFIXES", "board.question=QUESTION, board.todo = TODO ,bad")
+	cfg := Load()
+	if len(cfg.BoardPrefixes) != 2 || cfg.BoardPrefixes["board.question"] != "QUESTION" || cfg.BoardPrefixes["board.todo"] != "TODO" {
+		t.Errorf("BoardPrefixes = %v", cfg.BoardPrefixes)
+	}
+}
+
+func TestAgentTimeoutsFromEnv(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("CONSENSUS_AGENT_TIMEOUTS", "ollama=300, Gemini = 90,bad=soon,zero=0")
+	cfg := Load()
+	if len(cfg.AgentTimeouts) != 2 || cfg.AgentTimeouts["ollama"] != 300 || cfg.AgentTimeouts["Gemini"] != 90 {
+		t.Errorf("AgentTimeouts = %v", cfg.AgentTimeouts)
+	}
+}
+
+func TestAgentRetriesFromEnv(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cfg := Load()
+	if cfg.AgentRetries != 0 || cfg.AgentRetryBackoff != time.Second {
+		t.Errorf("defaults = %d, %s; want 0, 1s", cfg.AgentRetries, cfg.AgentRetryBackoff)
+	}
+	t.Setenv("CONSENSUS_AGENT_RETRIES", "2")
+	t.Setenv("CONSENSUS_RETRY_BACKOFF", "250ms")
+	cfg = Load()
+	if cfg.AgentRetries != 2 || cfg.AgentRetryBackoff != 250*time.Millisecond {
+		t.Errorf("got %d, %s; want 2, 250ms", cfg.AgentRetries, cfg.AgentRetryBackoff)
+	}
+}
+
+func TestGateEnvFromEnv(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("RALPH_GATE_ENV_TESTS", "CI=true,GOFLAGS=-race")
+	t.Setenv("RALPH_GATE_ENV_IMPLEMENT", "")
+	cfg := Load()
+	if len(cfg.RalphGateEnv) != 1 {
+		t.Fatalf("RalphGateEnv = %v, want only tests", cfg.RalphGateEnv)
+	}
+	if env := cfg.RalphGateEnv["tests"]; env["CI"] != "true" || env["GOFLAGS"] != "-race" {
+		t.Errorf("tests env = %v", env)
+	}
+}
+
+func TestModelPricesFromEnv(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("CONSENSUS_MODEL_PRICES", "gpt-4o=3/12, llama3=0/0,bad=cheap,neg=-1/2")
+	cfg := Load()
+	if p := cfg.ModelPrices["gpt-4o"]; p != (ModelPrice{Prompt: 3, Completion: 12}) {
+		t.Errorf("gpt-4o = %+v, want the override", p)
+	}
+	if _, ok := cfg.ModelPrices["llama3"]; !ok {
+		t.Error("free models should be priced at zero, not dropped")
+	}
+	if _, ok := cfg.ModelPrices["bad"]; ok {
+		t.Error("malformed prices should be skipped")
+	}
+	if _, ok := cfg.ModelPrices["neg"]; ok {
+		t.Error("negative prices should be skipped")
+	}
+	if p, ok := PriceOf(cfg.ModelPrices, "claude-opus-4-5-20251101"); !ok || p != DefaultModelPrices["claude-opus-4-5"] {
+		t.Errorf("dated model = %+v, %v; want the default opus price by prefix", p, ok)
+	}
+	if DefaultModelPrices["gpt-4o"] == cfg.ModelPrices["gpt-4o"] {
+		t.Error("override should not modify DefaultModelPrices")
+	}
 }
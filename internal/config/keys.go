@@ -0,0 +1,125 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// keyCommandTimeout bounds a key helper command such as "op read ...".
+const keyCommandTimeout = 30 * time.Second
+
+// KeySource is where an API key comes from when its environment variable is
+// unset: a file holding the key (<NAME>_FILE) or a helper command printing it
+// (<NAME>_CMD, e.g. "op read op://vault/anthropic/credential"). The file wins
+// over the command. Nothing is read until the key is first needed.
+type KeySource struct {
+	Name    string // the key's environment variable, for messages
+	File    string
+	Command string
+}
+
+// NewKeySource returns the file and command sources configured for the
+// environment variable name.
+func NewKeySource(name string) KeySource {
+	return KeySource{
+		Name:    name,
+		File:    os.Getenv(name + "_FILE"),
+		Command: os.Getenv(name + "_CMD"),
+	}
+}
+
+// IsSet reports whether a file or command is configured.
+func (s KeySource) IsSet() bool { return s.File != "" || s.Command != "" }
+
+type resolvedKey struct {
+	once sync.Once
+	key  string
+	err  error
+}
+
+var (
+	keyCacheMu sync.Mutex
+	keyCache   = make(map[KeySource]*resolvedKey)
+)
+
+// Key reads the key from the source, or returns "" when none is configured.
+// The result, including a failure, is cached for the process lifetime so a
+// helper command runs at most once.
+func (s KeySource) Key() (string, error) {
+	if !s.IsSet() {
+		return "", nil
+	}
+	keyCacheMu.Lock()
+	r, ok := keyCache[s]
+	if !ok {
+		r = &resolvedKey{}
+		keyCache[s] = r
+	}
+	keyCacheMu.Unlock()
+	r.once.Do(func() { r.key, r.err = s.read() })
+	return r.key, r.err
+}
+
+func (s KeySource) read() (string, error) {
+	if s.File != "" {
+		data, err := os.ReadFile(s.File)
+		if err != nil {
+			return "", fmt.Errorf("%s_FILE: %w", s.Name, err)
+		}
+		if key := strings.TrimSpace(string(data)); key != "" {
+			return key, nil
+		}
+		return "", fmt.Errorf("%s_FILE: %s is empty", s.Name, s.File)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), keyCommandTimeout)
+	defer cancel()
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", s.Command)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s_CMD failed: %w: %s", s.Name, err, msg)
+		}
+		return "", fmt.Errorf("%s_CMD failed: %w", s.Name, err)
+	}
+	if key := strings.TrimSpace(string(out)); key != "" {
+		return key, nil
+	}
+	return "", fmt.Errorf("%s_CMD printed no key", s.Name)
+}
+
+// ResolveKey returns value when set, otherwise the key from src. A source
+// that fails yields "".
+func ResolveKey(value string, src KeySource) string {
+	if value != "" {
+		return value
+	}
+	key, _ := src.Key()
+	return key
+}
+
+// LookupKey returns the API key for the environment variable name, read at
+// call time: the variable itself, else its _FILE or _CMD source.
+func LookupKey(name string) (string, error) {
+	if v := os.Getenv(name); v != "" {
+		return v, nil
+	}
+	return NewKeySource(name).Key()
+}
+
+// KeyUnavailableReason explains why the key in environment variable name is
+// missing: its source's failure, or that nothing provides it.
+func KeyUnavailableReason(name string, src KeySource) string {
+	if _, err := src.Key(); err != nil {
+		return err.Error()
+	}
+	return name + " not set"
+}
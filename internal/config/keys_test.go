@@ -0,0 +1,107 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeKeyCommand writes a helper script that prints key and counts its runs
+// in a file, returning the command and a func reading the run count.
+func fakeKeyCommand(t *testing.T, key string) (string, func() int) {
+	t.Helper()
+	dir := t.TempDir()
+	runs := filepath.Join(dir, "runs")
+	script := filepath.Join(dir, "fetch-key.sh")
+	content := "#!/bin/sh\necho run >> " + runs + "\necho " + key + "\n"
+	if err := os.WriteFile(script, []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return script + " read op://vault/item", func() int {
+		data, _ := os.ReadFile(runs)
+		return strings.Count(string(data), "run")
+	}
+}
+
+func TestKeySourceCommandCachedForProcess(t *testing.T) {
+	cmd, runs := fakeKeyCommand(t, "sk-from-vault")
+	src := KeySource{Name: "TEST_API_KEY", Command: cmd}
+
+	if runs() != 0 {
+		t.Fatal("command ran before the key was needed")
+	}
+	for i := 0; i < 3; i++ {
+		key, err := src.Key()
+		if err != nil || key != "sk-from-vault" {
+			t.Fatalf("Key() = %q, %v", key, err)
+		}
+	}
+	if runs() != 1 {
+		t.Errorf("command ran %d times, want 1", runs())
+	}
+}
+
+func TestKeySourcePrecedence(t *testing.T) {
+	cmd, runs := fakeKeyCommand(t, "from-command")
+	file := filepath.Join(t.TempDir(), "key")
+	os.WriteFile(file, []byte("from-file\n"), 0600)
+
+	cfg := &Config{AnthropicAPIKey: "from-env", AnthropicKeySource: KeySource{Name: "ANTHROPIC_API_KEY", File: file, Command: cmd}}
+	if got := cfg.AnthropicKey(); got != "from-env" {
+		t.Errorf("env: got %q", got)
+	}
+	cfg.AnthropicAPIKey = ""
+	if got := cfg.AnthropicKey(); got != "from-file" {
+		t.Errorf("file: got %q", got)
+	}
+	cfg.AnthropicKeySource.File = ""
+	if got := cfg.AnthropicKey(); got != "from-command" {
+		t.Errorf("command: got %q", got)
+	}
+	if runs() != 1 {
+		t.Errorf("command ran %d times, want 1", runs())
+	}
+}
+
+func TestKeySourceFailures(t *testing.T) {
+	tests := []struct {
+		src  KeySource
+		want string
+	}{
+		{KeySource{Name: "K1", Command: "echo locked >&2; exit 3"}, "K1_CMD failed: exit status 3: locked"},
+		{KeySource{Name: "K2", Command: "true"}, "K2_CMD printed no key"},
+		{KeySource{Name: "K3", File: filepath.Join(t.TempDir(), "missing")}, "K3_FILE:"},
+	}
+	for _, tt := range tests {
+		key, err := tt.src.Key()
+		if err == nil || key != "" || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Key() = %q, %v; want error %q", key, err, tt.want)
+		}
+		if got := KeyUnavailableReason(tt.src.Name, tt.src); !strings.Contains(got, tt.want) {
+			t.Errorf("KeyUnavailableReason = %q", got)
+		}
+	}
+	if got := KeyUnavailableReason("K4", KeySource{}); got != "K4 not set" {
+		t.Errorf("unset source: %q", got)
+	}
+}
+
+func TestLoadKeySources(t *testing.T) {
+	cmd, _ := fakeKeyCommand(t, "sk-openai")
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("OPENAI_API_KEY_CMD", cmd)
+	cfg := Load()
+	if cfg.OpenAIKeySource.Command != cmd {
+		t.Errorf("OpenAIKeySource = %+v", cfg.OpenAIKeySource)
+	}
+	if got := cfg.OpenAIKey(); got != "sk-openai" {
+		t.Errorf("OpenAIKey() = %q", got)
+	}
+
+	t.Setenv("EXTRA_KEY", "")
+	t.Setenv("EXTRA_KEY_CMD", cmd)
+	if key, err := LookupKey("EXTRA_KEY"); err != nil || key != "sk-openai" {
+		t.Errorf("LookupKey = %q, %v", key, err)
+	}
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ModelPrice is a model's price in USD per million tokens.
+type ModelPrice struct {
+	Prompt     float64
+	Completion float64
+}
+
+// DefaultModelPrices are list prices for the default models, used to
+// estimate run costs. CONSENSUS_MODEL_PRICES overrides or extends them.
+var DefaultModelPrices = map[string]ModelPrice{
+	"claude-opus-4-5":      {5, 25},
+	"claude-sonnet-4-5":    {3, 15},
+	"claude-haiku-4-5":     {1, 5},
+	"gemini-3-pro-preview": {2, 12},
+	"gpt-5.1-codex-max":    {1.25, 10},
+	"gpt-4o":               {2.5, 10},
+}
+
+// PriceOf returns the price of model: an exact entry, else the longest entry
+// that prefixes it, so "claude-opus-4-5" covers "claude-opus-4-5-20251101".
+func PriceOf(prices map[string]ModelPrice, model string) (ModelPrice, bool) {
+	if p, ok := prices[model]; ok {
+		return p, true
+	}
+	var best string
+	for m := range prices {
+		if strings.HasPrefix(model, m) && len(m) > len(best) {
+			best = m
+		}
+	}
+	if best == "" {
+		return ModelPrice{}, false
+	}
+	return prices[best], true
+}
+
+// parsePrices parses "model=prompt/completion,..." pairs of USD per million
+// tokens over DefaultModelPrices, skipping malformed or negative prices.
+func parsePrices(s string) map[string]ModelPrice {
+	prices := make(map[string]ModelPrice, len(DefaultModelPrices))
+	for m, p := range DefaultModelPrices {
+		prices[m] = p
+	}
+	for model, v := range parsePairs(s) {
+		in, out, ok := strings.Cut(v, "/")
+		if !ok {
+			continue
+		}
+		p, err1 := strconv.ParseFloat(strings.TrimSpace(in), 64)
+		c, err2 := strconv.ParseFloat(strings.TrimSpace(out), 64)
+		if err1 != nil || err2 != nil || p < 0 || c < 0 {
+			continue
+		}
+		prices[model] = ModelPrice{Prompt: p, Completion: c}
+	}
+	return prices
+}
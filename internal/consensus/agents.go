@@ -1,6 +1,7 @@
 package consensus
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -20,6 +21,23 @@ type Agent interface {
 	Available() bool
 }
 
+// unavailableReasoner is implemented by agents that can explain why they are
+// unavailable.
+type unavailableReasoner interface {
+	UnavailableReason() string
+}
+
+// UnavailableReason returns why a is unavailable, or "" when it is available.
+func UnavailableReason(a Agent) string {
+	if a.Available() {
+		return ""
+	}
+	if r, ok := a.(unavailableReasoner); ok {
+		return r.UnavailableReason()
+	}
+	return "not available"
+}
+
 // --- Claude ---
 
 type ClaudeAgent struct {
@@ -30,8 +48,21 @@ func NewClaudeAgent(cfg *config.Config) *ClaudeAgent {
 	return &ClaudeAgent{cfg: cfg}
 }
 
+// NewFastClaudeAgent returns a Claude agent that uses the configured fast
+// model, for stage 2 escalation.
+func NewFastClaudeAgent(cfg *config.Config) *ClaudeAgent {
+	fast := *cfg
+	fast.AnthropicModel = cfg.AnthropicFastModel
+	return &ClaudeAgent{cfg: &fast}
+}
+
 func (a *ClaudeAgent) Name() string   { return "Claude" }
-func (a *ClaudeAgent) Available() bool { return a.cfg.AnthropicAPIKey != "" }
+func (a *ClaudeAgent) Model() string  { return a.cfg.AnthropicModel }
+func (a *ClaudeAgent) Available() bool { return a.cfg.AnthropicKey() != "" }
+
+func (a *ClaudeAgent) UnavailableReason() string {
+	return config.KeyUnavailableReason("ANTHROPIC_API_KEY", a.cfg.AnthropicKeySource)
+}
 
 func (a *ClaudeAgent) Run(ctx context.Context, prompt string) (string, error) {
 	body := map[string]any{
@@ -44,34 +75,140 @@ func (a *ClaudeAgent) Run(ctx context.Context, prompt string) (string, error) {
 	url := strings.TrimRight(a.cfg.AnthropicBaseURL, "/") + "/v1/messages"
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(data))
 	if err != nil {
-		return "", err
+		return "", newAgentError(a.Name(), 0, err)
 	}
-	req.Header.Set("x-api-key", a.cfg.AnthropicAPIKey)
+	req.Header.Set("x-api-key", a.cfg.AnthropicKey())
 	req.Header.Set("anthropic-version", "2023-06-01")
 	req.Header.Set("content-type", "application/json")
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return "", err
+		return "", newAgentError(a.Name(), 0, err)
 	}
 	defer resp.Body.Close()
 
 	var result struct {
 		Content []struct{ Text string } `json:"content"`
-		Error   *struct{ Message string } `json:"error"`
+		Usage   struct {
+			InputTokens  int64 `json:"input_tokens"`
+			OutputTokens int64 `json:"output_tokens"`
+		} `json:"usage"`
+		Error *struct{ Message string } `json:"error"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", fmt.Errorf("decode: %w", err)
+		return "", responseError(a.Name(), resp, fmt.Errorf("decode: %w", err))
 	}
 	if result.Error != nil {
-		return "", fmt.Errorf("API error: %s", result.Error.Message)
+		return "", responseError(a.Name(), resp, fmt.Errorf("API error: %s", result.Error.Message))
 	}
+	recordUsage(ctx, result.Usage.InputTokens, result.Usage.OutputTokens)
 	if len(result.Content) == 0 || result.Content[0].Text == "" {
-		return "", fmt.Errorf("empty response")
+		return "", responseError(a.Name(), resp, fmt.Errorf("empty response"))
 	}
 	return result.Content[0].Text, nil
 }
 
+// RunStream runs the prompt with server-sent events, delivering text deltas
+// as they arrive.
+func (a *ClaudeAgent) RunStream(ctx context.Context, prompt string) (<-chan StreamChunk, error) {
+	body := map[string]any{
+		"model":      a.cfg.AnthropicModel,
+		"max_tokens": a.cfg.AnthropicMaxTokens,
+		"stream":     true,
+		"messages":   []map[string]any{{"role": "user", "content": prompt}},
+	}
+	data, _ := json.Marshal(body)
+
+	url := strings.TrimRight(a.cfg.AnthropicBaseURL, "/") + "/v1/messages"
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(data))
+	if err != nil {
+		return nil, newAgentError(a.Name(), 0, err)
+	}
+	req.Header.Set("x-api-key", a.cfg.AnthropicKey())
+	req.Header.Set("anthropic-version", "2023-06-01")
+	req.Header.Set("content-type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, newAgentError(a.Name(), 0, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		var result struct {
+			Error *struct{ Message string } `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&result)
+		msg := resp.Status
+		if result.Error != nil {
+			msg = result.Error.Message
+		}
+		return nil, responseError(a.Name(), resp, fmt.Errorf("API error: %s", msg))
+	}
+
+	ch := make(chan StreamChunk)
+	go func() {
+		defer close(ch)
+		defer resp.Body.Close()
+		send := func(c StreamChunk) bool {
+			select {
+			case ch <- c:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if !strings.HasPrefix(line, "data:") {
+				continue
+			}
+			var ev struct {
+				Type  string `json:"type"`
+				Delta struct {
+					Type string `json:"type"`
+					Text string `json:"text"`
+				} `json:"delta"`
+				Message struct {
+					Usage struct {
+						InputTokens int64 `json:"input_tokens"`
+					} `json:"usage"`
+				} `json:"message"`
+				Usage struct {
+					OutputTokens int64 `json:"output_tokens"`
+				} `json:"usage"`
+				Error *struct{ Message string } `json:"error"`
+			}
+			if err := json.Unmarshal([]byte(strings.TrimSpace(strings.TrimPrefix(line, "data:"))), &ev); err != nil {
+				continue
+			}
+			switch {
+			case ev.Type == "error" && ev.Error != nil:
+				send(StreamChunk{Err: responseError(a.Name(), resp, fmt.Errorf("API error: %s", ev.Error.Message))})
+				return
+			case ev.Type == "content_block_delta" && ev.Delta.Text != "":
+				if !send(StreamChunk{Text: ev.Delta.Text}) {
+					return
+				}
+			case ev.Type == "message_start":
+				recordUsage(ctx, ev.Message.Usage.InputTokens, 0)
+			case ev.Type == "message_delta":
+				recordUsage(ctx, 0, ev.Usage.OutputTokens)
+			case ev.Type == "message_stop":
+				return
+			}
+		}
+		err := scanner.Err()
+		if err == nil {
+			err = fmt.Errorf("stream ended before message_stop")
+		}
+		send(StreamChunk{Err: newAgentError(a.Name(), 0, err)})
+	}()
+	return ch, nil
+}
+
 // --- Gemini ---
 
 type GeminiAgent struct {
@@ -83,7 +220,12 @@ func NewGeminiAgent(cfg *config.Config) *GeminiAgent {
 }
 
 func (a *GeminiAgent) Name() string   { return "Gemini" }
-func (a *GeminiAgent) Available() bool { return a.cfg.GeminiAPIKey != "" }
+func (a *GeminiAgent) Model() string  { return a.cfg.GeminiModel }
+func (a *GeminiAgent) Available() bool { return a.cfg.GeminiKey() != "" }
+
+func (a *GeminiAgent) UnavailableReason() string {
+	return config.KeyUnavailableReason("GEMINI_API_KEY", a.cfg.GeminiKeySource)
+}
 
 func (a *GeminiAgent) Run(ctx context.Context, prompt string) (string, error) {
 	body := map[string]any{
@@ -94,16 +236,16 @@ func (a *GeminiAgent) Run(ctx context.Context, prompt string) (string, error) {
 	data, _ := json.Marshal(body)
 
 	url := fmt.Sprintf("%s/v1beta/models/%s:generateContent?key=%s",
-		strings.TrimRight(a.cfg.GeminiBaseURL, "/"), a.cfg.GeminiModel, a.cfg.GeminiAPIKey)
+		strings.TrimRight(a.cfg.GeminiBaseURL, "/"), a.cfg.GeminiModel, a.cfg.GeminiKey())
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(data))
 	if err != nil {
-		return "", err
+		return "", newAgentError(a.Name(), 0, err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return "", err
+		return "", newAgentError(a.Name(), 0, err)
 	}
 	defer resp.Body.Close()
 
@@ -113,16 +255,21 @@ func (a *GeminiAgent) Run(ctx context.Context, prompt string) (string, error) {
 				Parts []struct{ Text string } `json:"parts"`
 			} `json:"content"`
 		} `json:"candidates"`
+		UsageMetadata struct {
+			PromptTokenCount     int64 `json:"promptTokenCount"`
+			CandidatesTokenCount int64 `json:"candidatesTokenCount"`
+		} `json:"usageMetadata"`
 		Error *struct{ Message string } `json:"error"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", fmt.Errorf("decode: %w", err)
+		return "", responseError(a.Name(), resp, fmt.Errorf("decode: %w", err))
 	}
 	if result.Error != nil {
-		return "", fmt.Errorf("API error: %s", result.Error.Message)
+		return "", responseError(a.Name(), resp, fmt.Errorf("API error: %s", result.Error.Message))
 	}
+	recordUsage(ctx, result.UsageMetadata.PromptTokenCount, result.UsageMetadata.CandidatesTokenCount)
 	if len(result.Candidates) == 0 || len(result.Candidates[0].Content.Parts) == 0 {
-		return "", fmt.Errorf("empty response")
+		return "", responseError(a.Name(), resp, fmt.Errorf("empty response"))
 	}
 	return result.Candidates[0].Content.Parts[0].Text, nil
 }
@@ -138,7 +285,12 @@ func NewCodexAgent(cfg *config.Config) *CodexAgent {
 }
 
 func (a *CodexAgent) Name() string   { return "Codex" }
-func (a *CodexAgent) Available() bool { return a.cfg.OpenAIAPIKey != "" }
+func (a *CodexAgent) Model() string  { return a.cfg.OpenAIModel }
+func (a *CodexAgent) Available() bool { return a.cfg.OpenAIKey() != "" }
+
+func (a *CodexAgent) UnavailableReason() string {
+	return config.KeyUnavailableReason("OPENAI_API_KEY", a.cfg.OpenAIKeySource)
+}
 
 var codexModelRe = regexp.MustCompile(`^gpt-5.*-codex`)
 var chatModelRe = regexp.MustCompile(`^(gpt-4|gpt-3\.5-turbo|o1|o3)`)
@@ -174,19 +326,35 @@ func (a *CodexAgent) Run(ctx context.Context, prompt string) (string, error) {
 	data, _ := json.Marshal(body)
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(data))
 	if err != nil {
-		return "", err
+		return "", newAgentError(a.Name(), 0, err)
 	}
-	req.Header.Set("Authorization", "Bearer "+a.cfg.OpenAIAPIKey)
+	req.Header.Set("Authorization", "Bearer "+a.cfg.OpenAIKey())
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return "", err
+		return "", newAgentError(a.Name(), 0, err)
 	}
 	defer resp.Body.Close()
 
 	respBody, _ := io.ReadAll(resp.Body)
-	return a.extractResponse(respBody)
+	output, err := a.extractResponse(respBody)
+	if err == nil {
+		// The Responses API reports input/output tokens, the others
+		// prompt/completion tokens.
+		var usage struct {
+			Usage struct {
+				InputTokens      int64 `json:"input_tokens"`
+				OutputTokens     int64 `json:"output_tokens"`
+				PromptTokens     int64 `json:"prompt_tokens"`
+				CompletionTokens int64 `json:"completion_tokens"`
+			} `json:"usage"`
+		}
+		json.Unmarshal(respBody, &usage)
+		u := usage.Usage
+		recordUsage(ctx, u.InputTokens+u.PromptTokens, u.OutputTokens+u.CompletionTokens)
+	}
+	return output, responseError(a.Name(), resp, err)
 }
 
 func (a *CodexAgent) extractResponse(body []byte) (string, error) {
@@ -3,9 +3,13 @@ package consensus
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/signalnine/conclave/internal/config"
 )
@@ -41,6 +45,7 @@ func TestClaudeAgent_Run(t *testing.T) {
 			"content": []map[string]any{
 				{"type": "text", "text": "claude response"},
 			},
+			"usage": map[string]any{"input_tokens": 12, "output_tokens": 3},
 		})
 	}))
 	defer srv.Close()
@@ -52,13 +57,17 @@ func TestClaudeAgent_Run(t *testing.T) {
 		AnthropicBaseURL:   srv.URL,
 	}
 	a := NewClaudeAgent(cfg)
-	got, err := a.Run(context.Background(), "test prompt")
+	ctx, sink := withUsageSink(context.Background())
+	got, err := a.Run(ctx, "test prompt")
 	if err != nil {
 		t.Fatal(err)
 	}
 	if got != "claude response" {
 		t.Errorf("got %q", got)
 	}
+	if u := sink.usage(); u != (TokenUsage{InputTokens: 12, OutputTokens: 3}) {
+		t.Errorf("usage = %+v, want 12 in / 3 out", u)
+	}
 }
 
 func TestClaudeAgent_APIError(t *testing.T) {
@@ -92,6 +101,7 @@ func TestGeminiAgent_Run(t *testing.T) {
 					},
 				}},
 			},
+			"usageMetadata": map[string]any{"promptTokenCount": 20, "candidatesTokenCount": 4},
 		})
 	}))
 	defer srv.Close()
@@ -102,13 +112,17 @@ func TestGeminiAgent_Run(t *testing.T) {
 		GeminiBaseURL: srv.URL,
 	}
 	a := NewGeminiAgent(cfg)
-	got, err := a.Run(context.Background(), "test prompt")
+	ctx, sink := withUsageSink(context.Background())
+	got, err := a.Run(ctx, "test prompt")
 	if err != nil {
 		t.Fatal(err)
 	}
 	if got != "gemini response" {
 		t.Errorf("got %q", got)
 	}
+	if u := sink.usage(); u != (TokenUsage{InputTokens: 20, OutputTokens: 4}) {
+		t.Errorf("usage = %+v, want 20 in / 4 out", u)
+	}
 }
 
 func TestGeminiAgent_Available(t *testing.T) {
@@ -164,6 +178,7 @@ func TestCodexAgent_Run_ChatCompletions(t *testing.T) {
 			"choices": []map[string]any{
 				{"message": map[string]any{"content": "chat response"}},
 			},
+			"usage": map[string]any{"prompt_tokens": 9, "completion_tokens": 2},
 		})
 	}))
 	defer srv.Close()
@@ -173,13 +188,17 @@ func TestCodexAgent_Run_ChatCompletions(t *testing.T) {
 		OpenAIModel:   "gpt-4o",
 		OpenAIBaseURL: srv.URL,
 	}
-	got, err := NewCodexAgent(cfg).Run(context.Background(), "test")
+	ctx, sink := withUsageSink(context.Background())
+	got, err := NewCodexAgent(cfg).Run(ctx, "test")
 	if err != nil {
 		t.Fatal(err)
 	}
 	if got != "chat response" {
 		t.Errorf("got %q", got)
 	}
+	if u := sink.usage(); u != (TokenUsage{InputTokens: 9, OutputTokens: 2}) {
+		t.Errorf("usage = %+v, want 9 in / 2 out", u)
+	}
 }
 
 func TestAgent_ContextCancellation(t *testing.T) {
@@ -199,3 +218,189 @@ func TestAgent_ContextCancellation(t *testing.T) {
 		t.Error("expected error from cancelled context")
 	}
 }
+
+func TestAgentError_Kinds(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		want   ErrorKind
+	}{
+		{"unauthorized", http.StatusUnauthorized, KindAuth},
+		{"forbidden", http.StatusForbidden, KindAuth},
+		{"rate limited", http.StatusTooManyRequests, KindRateLimit},
+		{"gateway timeout", http.StatusGatewayTimeout, KindTimeout},
+		{"server error", http.StatusInternalServerError, KindOther},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				json.NewEncoder(w).Encode(map[string]any{
+					"error": map[string]any{"message": tt.name},
+				})
+			}))
+			defer srv.Close()
+
+			cfg := &config.Config{AnthropicAPIKey: "sk-test", AnthropicBaseURL: srv.URL}
+			_, err := NewClaudeAgent(cfg).Run(context.Background(), "test")
+
+			var ae *AgentError
+			if !errors.As(err, &ae) {
+				t.Fatalf("errors.As failed for %T: %v", err, err)
+			}
+			if ae.Agent != "Claude" {
+				t.Errorf("Agent = %q", ae.Agent)
+			}
+			if ae.Kind != tt.want {
+				t.Errorf("Kind = %q, want %q", ae.Kind, tt.want)
+			}
+		})
+	}
+}
+
+func TestAgentError_CodexRateLimit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{"message": "slow down"}})
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{OpenAIAPIKey: "op-test", OpenAIModel: "gpt-4o", OpenAIBaseURL: srv.URL}
+	_, err := NewCodexAgent(cfg).Run(context.Background(), "test")
+	if ErrorKindOf(err) != KindRateLimit {
+		t.Errorf("kind = %q, want ratelimit (err: %v)", ErrorKindOf(err), err)
+	}
+}
+
+func TestAgentError_RetryAfterHeader(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusTooManyRequests)
+		json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{"message": "rate limited"}})
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{AnthropicAPIKey: "sk-test", AnthropicModel: "m", AnthropicBaseURL: srv.URL}
+	_, err := NewClaudeAgent(cfg).Run(context.Background(), "test")
+	if ErrorKindOf(err) != KindRateLimit {
+		t.Errorf("kind = %q, want ratelimit", ErrorKindOf(err))
+	}
+	if got := RetryAfterOf(err); got != 7*time.Second {
+		t.Errorf("RetryAfter = %s, want 7s", got)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"", 0},
+		{"12", 12 * time.Second},
+		{"-3", 0},
+		{"soon", 0},
+		{now.Add(30 * time.Second).Format(http.TimeFormat), 30 * time.Second},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.in, now); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestAgentError_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	cfg := &config.Config{AnthropicAPIKey: "sk-test", AnthropicBaseURL: srv.URL}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := NewClaudeAgent(cfg).Run(ctx, "test")
+
+	var ae *AgentError
+	if !errors.As(err, &ae) || ae.Kind != KindTimeout {
+		t.Errorf("want timeout AgentError, got %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("AgentError should unwrap to the underlying error")
+	}
+}
+
+func TestRunStage1_StoresAgentError(t *testing.T) {
+	agents := []Agent{&mockAgent{name: "A", available: true, err: fmt.Errorf("boom")}}
+	results := RunStage1(context.Background(), agents)
+
+	var ae *AgentError
+	if !errors.As(results[0].Err, &ae) {
+		t.Fatalf("AgentResult.Err should be an AgentError, got %T", results[0].Err)
+	}
+	if ae.Agent != "A" || ae.Kind != KindOther {
+		t.Errorf("AgentError = %+v", ae)
+	}
+}
+
+func TestClaudeAgent_RunStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["stream"] != true {
+			t.Error("request should set stream: true")
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"usage\":{\"input_tokens\":7}}}\n\n")
+		fmt.Fprint(w, "event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"hello \"}}\n\n")
+		fmt.Fprint(w, "event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"world\"}}\n\n")
+		fmt.Fprint(w, "event: message_delta\ndata: {\"type\":\"message_delta\",\"usage\":{\"output_tokens\":2}}\n\n")
+		fmt.Fprint(w, "event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n")
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{AnthropicAPIKey: "sk-test", AnthropicBaseURL: srv.URL}
+	ctx, sink := withUsageSink(context.Background())
+	chunks, err := NewClaudeAgent(cfg).RunStream(ctx, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got string
+	for c := range chunks {
+		if c.Err != nil {
+			t.Fatal(c.Err)
+		}
+		got += c.Text
+	}
+	if got != "hello world" {
+		t.Errorf("got %q", got)
+	}
+	if u := sink.usage(); u != (TokenUsage{InputTokens: 7, OutputTokens: 2}) {
+		t.Errorf("usage = %+v, want 7 in / 2 out", u)
+	}
+}
+
+func TestClaudeAgent_RunStreamError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"partial\"}}\n\n")
+		fmt.Fprint(w, "event: error\ndata: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n")
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{AnthropicAPIKey: "sk-test", AnthropicBaseURL: srv.URL}
+	chunks, err := NewClaudeAgent(cfg).RunStream(context.Background(), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var streamErr error
+	for c := range chunks {
+		if c.Err != nil {
+			streamErr = c.Err
+		}
+	}
+	if streamErr == nil || !strings.Contains(streamErr.Error(), "Overloaded") {
+		t.Errorf("want Overloaded stream error, got %v", streamErr)
+	}
+}
@@ -0,0 +1,178 @@
+package consensus
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Agreement is a quick signal of how closely the stage 1 agents agreed, for
+// readers who won't open the full report.
+type Agreement struct {
+	Succeeded int // agents with at least one successful response
+	Total     int // agents that ran
+
+	// Similarity is the mean pairwise word overlap (Jaccard index, 0..1)
+	// between the successful agents' outputs. It is only meaningful when
+	// Pairs > 0, i.e. at least two agents succeeded.
+	Similarity float64
+	Pairs      int
+
+	// Reviewed counts outputs in the code review format; of those, Blockers
+	// counts agents that listed at least one critical issue.
+	Reviewed int
+	Blockers int
+}
+
+// MeasureAgreement computes the agreement metric over stage 1 results. total
+// is the number of agents that ran; chunked results are combined per agent.
+func MeasureAgreement(results []AgentResult, total int) Agreement {
+	order, outputs := agentOutputs(results)
+	a := Agreement{Succeeded: len(order), Total: total}
+	sets := make([]map[string]bool, len(order))
+	for i, name := range order {
+		sets[i] = wordSet(outputs[name])
+		if issues, ok := criticalIssues(outputs[name]); ok {
+			a.Reviewed++
+			if issues {
+				a.Blockers++
+			}
+		}
+	}
+	var sum float64
+	for i := range sets {
+		for j := i + 1; j < len(sets); j++ {
+			sum += jaccard(sets[i], sets[j])
+			a.Pairs++
+		}
+	}
+	if a.Pairs > 0 {
+		a.Similarity = sum / float64(a.Pairs)
+	}
+	return a
+}
+
+// agentOutputs joins each successful agent's chunk outputs, returning the
+// agents in result order.
+func agentOutputs(results []AgentResult) ([]string, map[string]string) {
+	var order []string
+	outputs := map[string]string{}
+	for _, r := range results {
+		if r.Err != nil {
+			continue
+		}
+		if _, ok := outputs[r.Agent]; !ok {
+			order = append(order, r.Agent)
+		}
+		outputs[r.Agent] += r.Output + "\n"
+	}
+	return order, outputs
+}
+
+// DefaultDisagreementThreshold is the pairwise divergence above which the
+// consensus CLI flags stage 1 agents as disagreeing.
+const DefaultDisagreementThreshold = 0.85
+
+// KeywordDivergence is the default Options.Divergence: one minus the word
+// overlap (Jaccard index) of two outputs, from 0 (same words) to 1 (none
+// shared).
+func KeywordDivergence(a, b string) float64 {
+	return 1 - jaccard(wordSet(a), wordSet(b))
+}
+
+// disagreementDirective is prepended to the chairman prompt when stage 1
+// agents disagree, so the synthesis reports the split instead of smoothing
+// it over.
+const disagreementDirective = `AGENTS DISAGREE: the analyses below reach conflicting conclusions (%s). Do not present a consensus the agents did not reach. State each side's position and reasoning, say which you find better supported and why, and list the disagreement under Areas of Disagreement.`
+
+// detectDisagreement reports why the stage 1 agents disagree, or "" when they
+// do not: a pair of agents whose outputs diverge by more than threshold under
+// score, or reviewers split on whether there is a critical issue.
+func detectDisagreement(results []AgentResult, threshold float64, score func(a, b string) float64) string {
+	if score == nil {
+		score = KeywordDivergence
+	}
+	order, outputs := agentOutputs(results)
+	var reasons []string
+	worst, pair := 0.0, [2]string{}
+	for i := range order {
+		for j := i + 1; j < len(order); j++ {
+			if d := score(outputs[order[i]], outputs[order[j]]); d > worst {
+				worst, pair = d, [2]string{order[i], order[j]}
+			}
+		}
+	}
+	if worst > threshold {
+		reasons = append(reasons, fmt.Sprintf("%s and %s diverge by %.0f%%", pair[0], pair[1], worst*100))
+	}
+	if a := MeasureAgreement(results, len(order)); a.Blockers > 0 && a.Blockers < a.Reviewed {
+		reasons = append(reasons, fmt.Sprintf("%d of %d reviewers flagged a critical issue", a.Blockers, a.Reviewed))
+	}
+	return strings.Join(reasons, "; ")
+}
+
+// String renders the one-line summary, e.g.
+// "Agreement: 3/3 agents, 82% similarity; 2 agents flagged a blocker".
+func (a Agreement) String() string {
+	s := fmt.Sprintf("Agreement: %d/%d agents", a.Succeeded, a.Total)
+	if a.Pairs > 0 {
+		s += fmt.Sprintf(", %.0f%% similarity", a.Similarity*100)
+	}
+	switch {
+	case a.Reviewed == 0:
+	case a.Blockers == 0:
+		s += "; no blockers flagged"
+	case a.Blockers == 1:
+		s += "; 1 agent flagged a blocker"
+	default:
+		s += fmt.Sprintf("; %d agents flagged a blocker", a.Blockers)
+	}
+	return s
+}
+
+func wordSet(text string) map[string]bool {
+	set := map[string]bool{}
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		set[w] = true
+	}
+	return set
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	shared := 0
+	for w := range a {
+		if b[w] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// criticalIssues reports whether a code review output lists anything under
+// its "## Critical Issues" heading; ok is false when there is no such
+// heading. A section holding only 'None' counts as no issues.
+func criticalIssues(output string) (issues, ok bool) {
+	lines := strings.Split(output, "\n")
+	for i, line := range lines {
+		if !strings.EqualFold(strings.TrimSpace(line), "## Critical Issues") {
+			continue
+		}
+		for _, l := range lines[i+1:] {
+			l = strings.TrimSpace(l)
+			if strings.HasPrefix(l, "#") {
+				break
+			}
+			item := strings.Trim(strings.TrimLeft(l, "-*• "), "'\"*_.")
+			if item != "" && !strings.EqualFold(item, "none") {
+				return true, true
+			}
+		}
+		return false, true
+	}
+	return false, false
+}
@@ -0,0 +1,187 @@
+package consensus
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const (
+	reviewWithBlocker = "## Critical Issues\n- SQL injection in handler.go\n\n## Important Issues\n- None\n"
+	reviewClean       = "## Critical Issues\n- None\n\n## Important Issues\n- Missing test\n"
+)
+
+func TestMeasureAgreement(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []AgentResult
+		total   int
+		want    string
+	}{
+		{
+			name: "identical outputs",
+			results: []AgentResult{
+				{Agent: "Claude", Output: "use a mutex here"},
+				{Agent: "Gemini", Output: "Use a mutex here."},
+				{Agent: "Codex", Output: "use a MUTEX here"},
+			},
+			total: 3,
+			want:  "Agreement: 3/3 agents, 100% similarity",
+		},
+		{
+			name: "failed agent is excluded",
+			results: []AgentResult{
+				{Agent: "Claude", Output: "alpha beta"},
+				{Agent: "Gemini", Output: "alpha gamma"},
+				{Agent: "Codex", Err: errors.New("timeout")},
+			},
+			total: 3,
+			want:  "Agreement: 2/3 agents, 33% similarity",
+		},
+		{
+			name:    "single agent has no similarity",
+			results: []AgentResult{{Agent: "Claude", Output: "alpha"}},
+			total:   2,
+			want:    "Agreement: 1/2 agents",
+		},
+		{
+			name: "code review blockers",
+			results: []AgentResult{
+				{Agent: "Claude", Output: reviewWithBlocker},
+				{Agent: "Gemini", Output: reviewWithBlocker},
+				{Agent: "Codex", Output: reviewClean},
+			},
+			total: 3,
+			want:  "2 agents flagged a blocker",
+		},
+		{
+			name: "chunked blocker counts once per agent",
+			results: []AgentResult{
+				{Agent: "Claude", Chunk: "part 1/2", Output: reviewWithBlocker},
+				{Agent: "Claude", Chunk: "part 2/2", Output: reviewWithBlocker},
+				{Agent: "Gemini", Chunk: "part 1/2", Output: reviewClean},
+			},
+			total: 2,
+			want:  "1 agent flagged a blocker",
+		},
+		{
+			name: "clean review",
+			results: []AgentResult{
+				{Agent: "Claude", Output: reviewClean},
+				{Agent: "Gemini", Output: reviewClean},
+			},
+			total: 2,
+			want:  "Agreement: 2/2 agents, 100% similarity; no blockers flagged",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MeasureAgreement(tt.results, tt.total).String()
+			if !strings.HasSuffix(got, tt.want) {
+				t.Errorf("summary = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunRecordsAgreement(t *testing.T) {
+	agents := []Agent{
+		&mockAgent{name: "Claude", available: true, response: reviewWithBlocker},
+		&mockAgent{name: "Gemini", available: true, response: reviewClean},
+		&mockAgent{name: "Codex", available: true, err: errors.New("boom")},
+		&mockAgent{name: "Offline", available: false},
+	}
+	chairman := &mockAgent{name: "Chair", available: true, response: "done"}
+	build := func([]AgentResult) string { return "synthesize" }
+	result, err := Run(context.Background(), agents, []Agent{chairman}, []ChunkPrompt{{Prompt: "p"}}, build, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := result.Agreement
+	if a.Succeeded != 2 || a.Total != 3 || a.Pairs != 1 || a.Blockers != 1 || a.Reviewed != 2 {
+		t.Errorf("agreement = %+v, want 2/3 succeeded, 1 pair, 1 of 2 reviews blocking", a)
+	}
+}
+
+func TestDetectDisagreement(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []AgentResult
+		want    string
+	}{
+		{
+			name: "agreeing agents",
+			results: []AgentResult{
+				{Agent: "Claude", Output: "use a mutex here"},
+				{Agent: "Gemini", Output: "Use a mutex here."},
+			},
+		},
+		{
+			name: "divergent pair",
+			results: []AgentResult{
+				{Agent: "Claude", Output: "use a mutex here"},
+				{Agent: "Gemini", Output: "use a mutex here"},
+				{Agent: "Codex", Output: "switch to channels instead"},
+			},
+			want: "Claude and Codex diverge by 100%",
+		},
+		{
+			name: "blocker split",
+			results: []AgentResult{
+				{Agent: "Claude", Output: reviewWithBlocker},
+				{Agent: "Gemini", Output: reviewClean},
+			},
+			want: "1 of 2 reviewers flagged a critical issue",
+		},
+		{
+			name: "failed agents are ignored",
+			results: []AgentResult{
+				{Agent: "Claude", Output: "use a mutex here"},
+				{Agent: "Codex", Err: errors.New("timeout")},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectDisagreement(tt.results, 0.9, nil); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunFlagsDisagreement(t *testing.T) {
+	agents := []Agent{
+		&mockAgent{name: "A", available: true, response: "ship it"},
+		&mockAgent{name: "B", available: true, response: "do not merge"},
+	}
+	chair := &recordingAgent{mockAgent: mockAgent{name: "Chair", available: true, response: "synthesis"}}
+	// A pluggable scorer that calls any two different outputs opposed.
+	opposed := func(a, b string) float64 {
+		if a == b {
+			return 0
+		}
+		return 1
+	}
+	result, err := Run(context.Background(), agents, []Agent{chair}, []ChunkPrompt{{Prompt: "q"}},
+		func([]AgentResult) string { return "chairman prompt" }, Options{DisagreementThreshold: 0.5, Divergence: opposed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Disagreement || result.DisagreementReason != "A and B diverge by 100%" {
+		t.Errorf("Disagreement = %v (%q), want flagged", result.Disagreement, result.DisagreementReason)
+	}
+	if !strings.HasPrefix(chair.prompt, "AGENTS DISAGREE") || !strings.HasSuffix(chair.prompt, "chairman prompt") {
+		t.Errorf("chairman prompt should lead with the directive:\n%s", chair.prompt)
+	}
+
+	result, err = Run(context.Background(), agents, []Agent{chair}, []ChunkPrompt{{Prompt: "q"}},
+		func([]AgentResult) string { return "chairman prompt" }, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Disagreement || chair.prompt != "chairman prompt" {
+		t.Errorf("without a threshold nothing should be flagged, got %v and prompt %q", result.Disagreement, chair.prompt)
+	}
+}
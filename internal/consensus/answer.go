@@ -0,0 +1,77 @@
+package consensus
+
+import (
+	"context"
+	"strings"
+
+	"github.com/signalnine/conclave/internal/config"
+)
+
+// ExtractAnswer returns the part of output after the last occurrence of
+// marker, e.g. "</think>" or "## Answer", dropping a reasoning preamble. The
+// full output is kept when marker is empty or absent, or nothing follows it.
+func ExtractAnswer(output, marker string) string {
+	if marker == "" {
+		return output
+	}
+	i := strings.LastIndex(output, marker)
+	if i < 0 {
+		return output
+	}
+	answer := strings.TrimSpace(output[i+len(marker):])
+	if answer == "" {
+		return output
+	}
+	return answer
+}
+
+// AnswerAgent trims an agent's output to the answer after its marker.
+type AnswerAgent struct {
+	Agent
+	marker string
+}
+
+// WithAnswerMarkers wraps the agents that have a marker in markers (keyed by
+// agent name) so only the content after the marker is kept. Other agents are
+// returned as is.
+func WithAnswerMarkers(agents []Agent, markers map[string]string) []Agent {
+	if len(markers) == 0 {
+		return agents
+	}
+	wrapped := make([]Agent, len(agents))
+	for i, a := range agents {
+		if m := markers[a.Name()]; m != "" {
+			wrapped[i] = &AnswerAgent{Agent: a, marker: m}
+		} else {
+			wrapped[i] = a
+		}
+	}
+	return wrapped
+}
+
+func (a *AnswerAgent) Run(ctx context.Context, prompt string) (string, error) {
+	out, err := a.Agent.Run(ctx, prompt)
+	if err != nil {
+		return out, err
+	}
+	return ExtractAnswer(out, a.marker), nil
+}
+
+// Model reports the wrapped agent's model, so cache keys are unchanged.
+func (a *AnswerAgent) Model() string { return agentModel(a.Agent) }
+
+// AnswerMarkers collects the roster's answer markers: CONSENSUS_ANSWER_MARKERS
+// for any agent, overridden by the marker field of CONSENSUS_EXTRA_AGENTS
+// entries.
+func AnswerMarkers(cfg *config.Config) map[string]string {
+	markers := make(map[string]string, len(cfg.AnswerMarkers))
+	for name, m := range cfg.AnswerMarkers {
+		markers[name] = m
+	}
+	for _, e := range cfg.ExtraAgents {
+		if e.AnswerMarker != "" {
+			markers[e.Name] = e.AnswerMarker
+		}
+	}
+	return markers
+}
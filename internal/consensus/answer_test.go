@@ -0,0 +1,62 @@
+package consensus
+
+import (
+	"context"
+	"testing"
+
+	"github.com/signalnine/conclave/internal/config"
+)
+
+func TestExtractAnswer(t *testing.T) {
+	tests := []struct {
+		name, output, marker, want string
+	}{
+		{"think tag", "<think>\nThe user wants X. Maybe Y?\n</think>\n\nUse Y.", "</think>", "Use Y."},
+		{"heading", "Let me reason step by step...\n\n## Answer\nShip it.", "## Answer", "Ship it."},
+		{"last marker wins", "I'll end with ## Answer.\n## Answer\nNo.", "## Answer", "No."},
+		{"marker absent", "Plain answer.", "</think>", "Plain answer."},
+		{"nothing after marker", "all thinking </think>", "</think>", "all thinking </think>"},
+		{"no marker configured", "<think>x</think>y", "", "<think>x</think>y"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractAnswer(tt.output, tt.marker); got != tt.want {
+				t.Errorf("ExtractAnswer() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnswerMarkersTrimStage1Output(t *testing.T) {
+	agents := []Agent{
+		&mockAgent{name: "deepseek", available: true, response: "<think>\nFirst, consider the cache... no wait.\n</think>\nThe race is in Put."},
+		&mockAgent{name: "Claude", available: true, response: "<think>kept</think> as is"},
+	}
+	agents = WithAnswerMarkers(agents, map[string]string{"deepseek": "</think>"})
+	chairman := &recordingAgent{mockAgent: mockAgent{name: "Chair", available: true, response: "done"}}
+	result, err := RunConsensus(context.Background(), agents, []Agent{chairman}, "prompt", 10, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputs := map[string]string{}
+	for _, r := range result.Stage1Results {
+		outputs[r.Agent] = r.Output
+	}
+	if outputs["deepseek"] != "The race is in Put." {
+		t.Errorf("deepseek output = %q, want only the answer", outputs["deepseek"])
+	}
+	if outputs["Claude"] != "<think>kept</think> as is" {
+		t.Errorf("agent without a marker was trimmed: %q", outputs["Claude"])
+	}
+}
+
+func TestAnswerMarkersFromRoster(t *testing.T) {
+	cfg := &config.Config{
+		AnswerMarkers: map[string]string{"Gemini": "## Answer", "groq": "## Final"},
+		ExtraAgents:   []config.ExtraAgent{{Name: "groq", AnswerMarker: "</think>"}, {Name: "local"}},
+	}
+	got := AnswerMarkers(cfg)
+	if len(got) != 2 || got["Gemini"] != "## Answer" || got["groq"] != "</think>" {
+		t.Errorf("AnswerMarkers() = %v", got)
+	}
+}
@@ -0,0 +1,301 @@
+package consensus
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BenchSuite is a fixed set of prompts with known good answers, run against
+// one or more agent configurations to compare the syntheses they produce.
+type BenchSuite struct {
+	Configs []BenchConfig `yaml:"configs"` // none benchmarks the full roster
+	Judge   string        `yaml:"judge"`   // agent that scores rubrics
+	Cases   []BenchCase   `yaml:"cases"`
+}
+
+// BenchConfig is one roster to benchmark.
+type BenchConfig struct {
+	Name     string   `yaml:"name"`
+	Agents   []string `yaml:"agents"`   // roster agent names; none uses the full roster
+	Chairman string   `yaml:"chairman"` // see PinChairman
+}
+
+// BenchCase is a general-prompt question and how to score its synthesis:
+// the fraction of Keywords present, and/or the judge's score against Rubric.
+type BenchCase struct {
+	Name     string   `yaml:"name"`
+	Prompt   string   `yaml:"prompt"`
+	Context  string   `yaml:"context"`
+	Keywords []string `yaml:"keywords"`
+	Rubric   string   `yaml:"rubric"`
+}
+
+// LoadBenchSuite reads and validates a YAML suite file.
+func LoadBenchSuite(path string) (*BenchSuite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s BenchSuite
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parse suite %s: %w", path, err)
+	}
+	if len(s.Cases) == 0 {
+		return nil, fmt.Errorf("suite %s has no cases", path)
+	}
+	if len(s.Configs) == 0 {
+		s.Configs = []BenchConfig{{Name: "default"}}
+	}
+	for i, c := range s.Configs {
+		if c.Name == "" {
+			return nil, fmt.Errorf("suite %s: config %d has no name", path, i+1)
+		}
+	}
+	for i, c := range s.Cases {
+		if c.Name == "" {
+			s.Cases[i].Name = fmt.Sprintf("case-%d", i+1)
+		}
+		if c.Prompt == "" {
+			return nil, fmt.Errorf("suite %s: case %q has no prompt", path, s.Cases[i].Name)
+		}
+		if len(c.Keywords) == 0 && c.Rubric == "" {
+			return nil, fmt.Errorf("suite %s: case %q needs keywords or a rubric", path, s.Cases[i].Name)
+		}
+		if c.Rubric != "" && s.Judge == "" {
+			return nil, fmt.Errorf("suite %s: case %q has a rubric but the suite names no judge", path, s.Cases[i].Name)
+		}
+	}
+	return &s, nil
+}
+
+// BenchResult is the outcome of one case under one configuration. Score is
+// in [0, 1]; cost counts the configuration's calls, not the judge's.
+type BenchResult struct {
+	Config    string
+	Case      string
+	Score     float64
+	Missing   []string // expected keywords absent from the synthesis
+	Err       error
+	Calls     int
+	TokensIn  int // estimated from prompt and output length
+	TokensOut int
+	Duration  time.Duration
+}
+
+// Bench runs suites through the consensus flow.
+type Bench struct {
+	Roster []Agent // agents configurations pick from by name
+	Judge  Agent   // scores rubrics; nil skips rubric scoring
+	Opts   Options // stage timeouts and the like for every run
+}
+
+// Run runs every case under every configuration, one at a time.
+func (b *Bench) Run(ctx context.Context, suite *BenchSuite) ([]BenchResult, error) {
+	var results []BenchResult
+	for _, cfg := range suite.Configs {
+		agents, err := selectRoster(b.Roster, cfg.Agents)
+		if err != nil {
+			return nil, fmt.Errorf("config %q: %w", cfg.Name, err)
+		}
+		for _, c := range suite.Cases {
+			if ctx.Err() != nil {
+				return results, ctx.Err()
+			}
+			fmt.Fprintf(os.Stderr, "\n=== Bench: %s / %s ===\n", cfg.Name, c.Name)
+			results = append(results, b.runCase(ctx, cfg, agents, c))
+		}
+	}
+	return results, nil
+}
+
+func (b *Bench) runCase(ctx context.Context, cfg BenchConfig, roster []Agent, c BenchCase) BenchResult {
+	res := BenchResult{Config: cfg.Name, Case: c.Name}
+	var meter benchMeter
+	agents := make([]Agent, len(roster))
+	for i, a := range roster {
+		agents[i] = &meteredAgent{Agent: a, meter: &meter}
+	}
+	chairmen, err := PinChairman(agents, cfg.Chairman)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+
+	start := time.Now()
+	result, err := RunConsensusWithBuilder(ctx, agents, chairmen, GeneralBuilder{}, PromptInput{Question: c.Prompt, Context: c.Context}, b.Opts)
+	res.Duration = time.Since(start)
+	res.Calls, res.TokensIn, res.TokensOut = int(meter.calls.Load()), int(meter.tokensIn.Load()), int(meter.tokensOut.Load())
+	if err != nil {
+		res.Err = err
+		return res
+	}
+
+	var scores []float64
+	if len(c.Keywords) > 0 {
+		score, missing := KeywordScore(result.ChairmanOutput, c.Keywords)
+		scores = append(scores, score)
+		res.Missing = missing
+	}
+	if c.Rubric != "" && b.Judge != nil {
+		score, err := b.judge(ctx, c, result.ChairmanOutput)
+		if err != nil {
+			res.Err = fmt.Errorf("judge: %w", err)
+			return res
+		}
+		scores = append(scores, score)
+	}
+	for _, s := range scores {
+		res.Score += s / float64(len(scores))
+	}
+	return res
+}
+
+func (b *Bench) judge(ctx context.Context, c BenchCase, synthesis string) (float64, error) {
+	timeout := b.Opts.Stage2Timeout
+	if timeout <= 0 {
+		timeout = DefaultStageTimeout
+	}
+	jctx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	defer cancel()
+	out, err := b.Judge.Run(jctx, BuildBenchJudgePrompt(c.Prompt, c.Rubric, synthesis))
+	if err != nil {
+		return 0, err
+	}
+	return ParseJudgeScore(out)
+}
+
+// selectRoster returns the roster agents with the given names, in that
+// order; no names selects the whole roster.
+func selectRoster(roster []Agent, names []string) ([]Agent, error) {
+	if len(names) == 0 {
+		return roster, nil
+	}
+	var selected []Agent
+	for _, name := range names {
+		var found Agent
+		for _, a := range roster {
+			if strings.EqualFold(a.Name(), name) {
+				found = a
+				break
+			}
+		}
+		if found == nil {
+			return nil, fmt.Errorf("agent %q not in roster", name)
+		}
+		selected = append(selected, found)
+	}
+	return selected, nil
+}
+
+// KeywordScore returns the fraction of keywords found in output (case
+// insensitive) and the ones that were not.
+func KeywordScore(output string, keywords []string) (float64, []string) {
+	if len(keywords) == 0 {
+		return 0, nil
+	}
+	lower := strings.ToLower(output)
+	var missing []string
+	for _, k := range keywords {
+		if !strings.Contains(lower, strings.ToLower(k)) {
+			missing = append(missing, k)
+		}
+	}
+	return float64(len(keywords)-len(missing)) / float64(len(keywords)), missing
+}
+
+// BuildBenchJudgePrompt asks a judge to grade a synthesis against a rubric.
+func BuildBenchJudgePrompt(question, rubric, synthesis string) string {
+	var b strings.Builder
+	b.WriteString("# Grade a Consensus Answer\n\n")
+	fmt.Fprintf(&b, "**Question:**\n%s\n\n", question)
+	fmt.Fprintf(&b, "**Rubric:**\n%s\n\n", rubric)
+	fmt.Fprintf(&b, "**Answer:**\n%s\n\n", synthesis)
+	b.WriteString("**Instructions:**\nGrade the answer strictly against the rubric. Briefly justify the grade, then end with a final line of the form:\nSCORE: <0-10>/10\n")
+	return b.String()
+}
+
+var judgeScoreRe = regexp.MustCompile(`(?i)SCORE:\s*(\d+(?:\.\d+)?)\s*/\s*10`)
+
+// ParseJudgeScore reads the last "SCORE: n/10" line of a judge's output as a
+// score in [0, 1].
+func ParseJudgeScore(output string) (float64, error) {
+	m := judgeScoreRe.FindAllStringSubmatch(output, -1)
+	if len(m) == 0 {
+		return 0, fmt.Errorf("no SCORE line in judge output")
+	}
+	n, err := strconv.ParseFloat(m[len(m)-1][1], 64)
+	if err != nil || n > 10 {
+		return 0, fmt.Errorf("invalid judge score %q", m[len(m)-1][1])
+	}
+	return n / 10, nil
+}
+
+// BenchSummary aggregates a configuration's results.
+type BenchSummary struct {
+	Config    string
+	Cases     int
+	Failed    int
+	Score     float64 // mean over all cases; failed cases score 0
+	Calls     int
+	TokensIn  int
+	TokensOut int
+	Duration  time.Duration
+}
+
+// SummarizeBench aggregates results per configuration, in the order the
+// configurations first appear.
+func SummarizeBench(results []BenchResult) []BenchSummary {
+	var summaries []BenchSummary
+	index := make(map[string]int)
+	for _, r := range results {
+		i, ok := index[r.Config]
+		if !ok {
+			i = len(summaries)
+			index[r.Config] = i
+			summaries = append(summaries, BenchSummary{Config: r.Config})
+		}
+		s := &summaries[i]
+		s.Cases++
+		if r.Err != nil {
+			s.Failed++
+		}
+		s.Score += r.Score
+		s.Calls += r.Calls
+		s.TokensIn += r.TokensIn
+		s.TokensOut += r.TokensOut
+		s.Duration += r.Duration
+	}
+	for i := range summaries {
+		summaries[i].Score /= float64(summaries[i].Cases)
+	}
+	return summaries
+}
+
+// benchMeter counts the calls and estimated tokens of one bench run.
+type benchMeter struct {
+	calls, tokensIn, tokensOut atomic.Int64
+}
+
+// meteredAgent records every call of the agent it wraps in a benchMeter.
+type meteredAgent struct {
+	Agent
+	meter *benchMeter
+}
+
+func (m *meteredAgent) Run(ctx context.Context, prompt string) (string, error) {
+	out, err := m.Agent.Run(ctx, prompt)
+	m.meter.calls.Add(1)
+	m.meter.tokensIn.Add(int64(estimateTokens(prompt)))
+	m.meter.tokensOut.Add(int64(estimateTokens(out)))
+	return out, err
+}
+
+func (m *meteredAgent) Model() string { return agentModel(m.Agent) }
@@ -0,0 +1,154 @@
+package consensus
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBenchKeywordRubric(t *testing.T) {
+	roster := []Agent{
+		&mockAgent{name: "Claude", available: true, response: "use parameterized queries"},
+		&mockAgent{name: "Gemini", available: true, response: "escape input"},
+		&mockAgent{name: "Codex", available: true, response: "synthesis: use parameterized queries to stop SQL injection"},
+	}
+	suite := &BenchSuite{
+		Configs: []BenchConfig{
+			{Name: "codex-chair", Agents: []string{"Claude", "Gemini", "codex"}, Chairman: "Codex"},
+			{Name: "gemini-solo", Agents: []string{"Gemini"}},
+		},
+		Cases: []BenchCase{
+			{Name: "sqli", Prompt: "How do I prevent SQL injection?", Keywords: []string{"parameterized", "injection"}},
+		},
+	}
+	b := &Bench{Roster: roster, Opts: Options{Stage1Timeout: 5, Stage2Timeout: 5}}
+	results, err := b.Run(context.Background(), suite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	if r := results[0]; r.Err != nil || r.Score != 1 || len(r.Missing) != 0 {
+		t.Errorf("codex-chair = %+v, want score 1", r)
+	}
+	if r := results[0]; r.Calls != 4 || r.TokensIn == 0 || r.TokensOut == 0 {
+		t.Errorf("codex-chair cost = %d calls, %d/%d tokens; want 3 stage 1 calls + 1 chairman", r.Calls, r.TokensIn, r.TokensOut)
+	}
+	if r := results[1]; r.Score != 0 || strings.Join(r.Missing, ",") != "parameterized,injection" {
+		t.Errorf("gemini-solo = %+v, want score 0 with both keywords missing", r)
+	}
+
+	summaries := SummarizeBench(results)
+	if len(summaries) != 2 || summaries[0].Config != "codex-chair" || summaries[0].Score != 1 || summaries[1].Score != 0 {
+		t.Errorf("summaries = %+v", summaries)
+	}
+}
+
+func TestBenchJudgeRubric(t *testing.T) {
+	roster := []Agent{&mockAgent{name: "Claude", available: true, response: "an answer"}}
+	judge := &recordingAgent{mockAgent: mockAgent{name: "Judge", available: true, response: "Covers most of it.\nSCORE: 8/10"}}
+	suite := &BenchSuite{
+		Judge:   "Judge",
+		Cases:   []BenchCase{{Name: "c", Prompt: "q", Rubric: "mentions X", Keywords: []string{"answer"}}},
+		Configs: []BenchConfig{{Name: "default"}},
+	}
+	b := &Bench{Roster: roster, Judge: judge}
+	results, err := b.Run(context.Background(), suite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Keyword score 1 and judge score 0.8 average to 0.9.
+	if r := results[0]; r.Err != nil || r.Score < 0.899 || r.Score > 0.901 {
+		t.Errorf("result = %+v, want score 0.9", r)
+	}
+	if !strings.Contains(judge.prompt, "mentions X") || !strings.Contains(judge.prompt, "an answer") {
+		t.Errorf("judge prompt missing rubric or answer:\n%s", judge.prompt)
+	}
+	if results[0].Calls != 2 {
+		t.Errorf("Calls = %d, want 2 (judge not counted)", results[0].Calls)
+	}
+}
+
+func TestBenchFailedCase(t *testing.T) {
+	roster := []Agent{&mockAgent{name: "Claude", available: true, err: errors.New("boom")}}
+	suite := &BenchSuite{
+		Configs: []BenchConfig{{Name: "default"}},
+		Cases:   []BenchCase{{Name: "c", Prompt: "q", Keywords: []string{"x"}}},
+	}
+	results, err := (&Bench{Roster: roster}).Run(context.Background(), suite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Err == nil || results[0].Score != 0 {
+		t.Errorf("result = %+v, want an error and score 0", results[0])
+	}
+	if s := SummarizeBench(results)[0]; s.Failed != 1 {
+		t.Errorf("Failed = %d, want 1", s.Failed)
+	}
+}
+
+func TestBenchUnknownAgent(t *testing.T) {
+	suite := &BenchSuite{
+		Configs: []BenchConfig{{Name: "bad", Agents: []string{"Nope"}}},
+		Cases:   []BenchCase{{Prompt: "q", Keywords: []string{"x"}}},
+	}
+	_, err := (&Bench{Roster: []Agent{&mockAgent{name: "Claude", available: true}}}).Run(context.Background(), suite)
+	if err == nil || !strings.Contains(err.Error(), `agent "Nope" not in roster`) {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestLoadBenchSuite(t *testing.T) {
+	dir := t.TempDir()
+	write := func(content string) string {
+		path := filepath.Join(dir, "suite.yaml")
+		os.WriteFile(path, []byte(content), 0644)
+		return path
+	}
+
+	s, err := LoadBenchSuite(write(`
+cases:
+  - prompt: How do I prevent SQL injection?
+    keywords: [parameterized]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Configs) != 1 || s.Configs[0].Name != "default" || s.Cases[0].Name != "case-1" {
+		t.Errorf("defaults not applied: %+v", s)
+	}
+
+	for content, want := range map[string]string{
+		"cases: []":                            "no cases",
+		"cases:\n  - keywords: [x]":            "has no prompt",
+		"cases:\n  - prompt: q":                "needs keywords or a rubric",
+		"cases:\n  - prompt: q\n    rubric: r": "names no judge",
+		"configs:\n  - agents: [Claude]\ncases:\n  - prompt: q\n    keywords: [x]": "has no name",
+	} {
+		if _, err := LoadBenchSuite(write(content)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: err = %v, want %q", content, err, want)
+		}
+	}
+}
+
+func TestParseJudgeScore(t *testing.T) {
+	for out, want := range map[string]float64{
+		"SCORE: 7/10":                       0.7,
+		"score: 10 / 10":                    1,
+		"SCORE: 2/10\nrevised\nSCORE: 6/10": 0.6,
+	} {
+		got, err := ParseJudgeScore(out)
+		if err != nil || got != want {
+			t.Errorf("ParseJudgeScore(%q) = %v, %v; want %v", out, got, err, want)
+		}
+	}
+	for _, out := range []string{"looks fine", "SCORE: 11/10"} {
+		if _, err := ParseJudgeScore(out); err == nil {
+			t.Errorf("ParseJudgeScore(%q) should fail", out)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package consensus
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// PromptInput carries everything a mode needs to assemble its prompts. Modes
+// read the fields they understand and ignore the rest.
+type PromptInput struct {
+	Question string // general-prompt: the question; code-review: the change description
+	Context  string // additional context (general-prompt)
+
+	Diff          string      // code-review
+	ModifiedFiles string      // code-review, one path per line
+	Plan          string      // code-review implementation plan, may be empty
+	Focus         ReviewFocus // code-review
+
+	// ChunkThreshold splits large files of a code review into separately
+	// reviewed chunks (see ChunkDiff); 0 keeps the diff whole.
+	ChunkThreshold int
+
+	Sources []Source // fact-check: the documents the claim (Question) is checked against
+}
+
+// PromptBuilder assembles the stage 1 and chairman prompts for one consensus
+// mode. BuildStage1 returns one prompt for an unchunked run, or one labeled
+// prompt per chunk.
+type PromptBuilder interface {
+	BuildStage1(in PromptInput) []ChunkPrompt
+	BuildChairman(in PromptInput, results []AgentResult) string
+}
+
+// ResultParser is implemented by prompt builders whose syntheses carry
+// structure worth keeping, such as a verdict. ParseResult runs after a
+// successful run and records it in the result.
+type ResultParser interface {
+	ParseResult(in PromptInput, r *ConsensusResult)
+}
+
+var (
+	buildersMu sync.RWMutex
+	builders   = map[string]PromptBuilder{}
+)
+
+func init() {
+	RegisterMode("code-review", CodeReviewBuilder{})
+	RegisterMode("general-prompt", GeneralBuilder{})
+	RegisterMode("fact-check", FactCheckBuilder{})
+}
+
+// RegisterMode makes a prompt builder available under a mode name, replacing
+// any builder registered under that name.
+func RegisterMode(name string, b PromptBuilder) {
+	buildersMu.Lock()
+	defer buildersMu.Unlock()
+	builders[name] = b
+}
+
+// LookupMode returns the prompt builder registered for a mode.
+func LookupMode(name string) (PromptBuilder, bool) {
+	buildersMu.RLock()
+	defer buildersMu.RUnlock()
+	b, ok := builders[name]
+	return b, ok
+}
+
+// Modes lists the registered mode names, sorted.
+func Modes() []string {
+	buildersMu.RLock()
+	defer buildersMu.RUnlock()
+	names := make([]string, 0, len(builders))
+	for name := range builders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RunConsensusWithBuilder is like Run but builds the stage 1 and chairman
+// prompts for in with a mode's PromptBuilder.
+func RunConsensusWithBuilder(ctx context.Context, agents, chairmen []Agent, b PromptBuilder, in PromptInput, opts Options) (*ConsensusResult, error) {
+	prompts := b.BuildStage1(in)
+	if len(prompts) == 0 {
+		return nil, fmt.Errorf("prompt builder produced no stage 1 prompts")
+	}
+	build := func(results []AgentResult) string {
+		return b.BuildChairman(in, results)
+	}
+	result, err := Run(ctx, agents, chairmen, prompts, build, opts)
+	if err != nil {
+		return result, err
+	}
+	if p, ok := b.(ResultParser); ok {
+		p.ParseResult(in, result)
+	}
+	return result, nil
+}
+
+// CodeReviewBuilder is the code-review mode: a review of Diff, chunked per
+// ChunkThreshold and narrowed by Focus.
+type CodeReviewBuilder struct{}
+
+func (CodeReviewBuilder) BuildStage1(in PromptInput) []ChunkPrompt {
+	if chunks := ChunkDiff(in.Diff, in.ChunkThreshold); len(chunks) > 1 {
+		prompts := make([]ChunkPrompt, len(chunks))
+		for i, c := range chunks {
+			prompts[i] = ChunkPrompt{
+				Label:  c.Label,
+				Prompt: FocusCodeReviewPrompt(BuildCodeReviewChunkPrompt(in.Question, in.ModifiedFiles, in.Plan, c), in.Focus),
+			}
+		}
+		return prompts
+	}
+	return []ChunkPrompt{{Prompt: FocusCodeReviewPrompt(BuildCodeReviewPrompt(in.Question, in.Diff, in.ModifiedFiles, in.Plan), in.Focus)}}
+}
+
+// BuildChairman uses the chunked synthesis when the reviews cover separate
+// chunks, except for a targeted review, whose findings are grouped by category.
+func (CodeReviewBuilder) BuildChairman(in PromptInput, results []AgentResult) string {
+	if in.Focus == FocusGeneral {
+		for _, r := range results {
+			if r.Chunk != "" {
+				return BuildChunkedCodeReviewChairmanPrompt(in.Question, in.ModifiedFiles, results)
+			}
+		}
+	}
+	return BuildFocusedCodeReviewChairmanPrompt(in.Focus, in.Question, in.ModifiedFiles, results)
+}
+
+// GeneralBuilder is the general-prompt mode: a question with optional context.
+type GeneralBuilder struct{}
+
+func (GeneralBuilder) BuildStage1(in PromptInput) []ChunkPrompt {
+	return []ChunkPrompt{{Prompt: BuildGeneralPrompt(in.Question, in.Context)}}
+}
+
+func (GeneralBuilder) BuildChairman(in PromptInput, results []AgentResult) string {
+	return BuildGeneralChairmanPrompt(in.Question, results)
+}
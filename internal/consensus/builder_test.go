@@ -0,0 +1,82 @@
+package consensus
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// haikuBuilder is a toy mode: agents answer in haiku, the chairman picks one.
+type haikuBuilder struct{}
+
+func (haikuBuilder) BuildStage1(in PromptInput) []ChunkPrompt {
+	return []ChunkPrompt{{Prompt: "Answer in haiku: " + in.Question}}
+}
+
+func (haikuBuilder) BuildChairman(in PromptInput, results []AgentResult) string {
+	var b strings.Builder
+	b.WriteString("Pick the best haiku about " + in.Question + ":\n")
+	for _, r := range results {
+		b.WriteString(r.Agent + ": " + r.Output + "\n")
+	}
+	return b.String()
+}
+
+func TestRegisterModeRunsThroughBuilder(t *testing.T) {
+	RegisterMode("haiku", haikuBuilder{})
+	t.Cleanup(func() {
+		buildersMu.Lock()
+		delete(builders, "haiku")
+		buildersMu.Unlock()
+	})
+	if !slices.Contains(Modes(), "haiku") {
+		t.Fatalf("Modes() = %v, want haiku registered", Modes())
+	}
+	b, ok := LookupMode("haiku")
+	if !ok {
+		t.Fatal("LookupMode(haiku) not found")
+	}
+
+	agent := &recordingAgent{mockAgent: mockAgent{name: "Claude", available: true, response: "old pond, frog jumps in"}}
+	chairman := &recordingAgent{mockAgent: mockAgent{name: "Chair", available: true, response: "the frog one"}}
+	result, err := RunConsensusWithBuilder(context.Background(), []Agent{agent}, []Agent{chairman}, b, PromptInput{Question: "ponds"}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if agent.prompt != "Answer in haiku: ponds" {
+		t.Errorf("stage 1 prompt = %q", agent.prompt)
+	}
+	if !strings.Contains(chairman.prompt, "Claude: old pond, frog jumps in") {
+		t.Errorf("chairman prompt missing stage 1 output:\n%s", chairman.prompt)
+	}
+	if result.ChairmanOutput != "the frog one" {
+		t.Errorf("ChairmanOutput = %q", result.ChairmanOutput)
+	}
+}
+
+func TestBuiltinModesRegistered(t *testing.T) {
+	for _, mode := range []string{"code-review", "general-prompt", "fact-check"} {
+		if _, ok := LookupMode(mode); !ok {
+			t.Errorf("%s mode not registered", mode)
+		}
+	}
+}
+
+func TestCodeReviewBuilderChunks(t *testing.T) {
+	diff := "diff --git a/big.go b/big.go\n--- a/big.go\n+++ b/big.go\n@@ -1,2 +1,2 @@\n-a\n+b\n@@ -10,2 +10,2 @@\n-c\n+d\n"
+	in := PromptInput{Question: "refactor", Diff: diff, ModifiedFiles: "big.go\n", ChunkThreshold: 10}
+	prompts := CodeReviewBuilder{}.BuildStage1(in)
+	if len(prompts) < 2 {
+		t.Fatalf("got %d prompts, want one per chunk", len(prompts))
+	}
+	chairman := CodeReviewBuilder{}.BuildChairman(in, []AgentResult{{Agent: "Claude", Chunk: prompts[0].Label, Output: "ok"}})
+	if chairman != BuildChunkedCodeReviewChairmanPrompt("refactor", "big.go\n", []AgentResult{{Agent: "Claude", Chunk: prompts[0].Label, Output: "ok"}}) {
+		t.Error("chunked review should use the chunked chairman prompt")
+	}
+
+	in.ChunkThreshold = 0
+	if prompts := (CodeReviewBuilder{}).BuildStage1(in); len(prompts) != 1 || prompts[0].Label != "" {
+		t.Errorf("threshold 0 gave %d prompts, want the whole diff", len(prompts))
+	}
+}
@@ -0,0 +1,254 @@
+package consensus
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ResponseCache stores agent responses on disk, keyed by agent, model and the
+// exact prompt, so repeated stage 1 prompts skip the API call.
+type ResponseCache struct {
+	dir string
+	ttl time.Duration // zero keeps entries forever
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+type cacheEntry struct {
+	Agent   string    `json:"agent"`
+	Model   string    `json:"model,omitempty"`
+	Created time.Time `json:"created"`
+	Output  string    `json:"output"`
+}
+
+// DefaultCacheDir returns the per-user response cache directory.
+func DefaultCacheDir() string {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		base = os.TempDir()
+	}
+	return filepath.Join(base, "conclave", "responses")
+}
+
+// NewResponseCache opens (creating if needed) a cache in dir. Entries older
+// than ttl are ignored; ttl <= 0 never expires entries.
+func NewResponseCache(dir string, ttl time.Duration) (*ResponseCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("create cache dir: %w", err)
+	}
+	return &ResponseCache{dir: dir, ttl: ttl}, nil
+}
+
+// agentModel reports the model behind an agent, when it exposes one.
+func agentModel(a Agent) string {
+	if m, ok := a.(interface{ Model() string }); ok {
+		return m.Model()
+	}
+	return ""
+}
+
+func (c *ResponseCache) path(a Agent, prompt string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s", a.Name(), agentModel(a), prompt)
+	return filepath.Join(c.dir, hex.EncodeToString(h.Sum(nil))+".json")
+}
+
+// Get returns the cached response of agent a to prompt.
+func (c *ResponseCache) Get(a Agent, prompt string) (string, bool) {
+	data, err := os.ReadFile(c.path(a, prompt))
+	if err != nil {
+		c.misses.Add(1)
+		return "", false
+	}
+	var e cacheEntry
+	if err := json.Unmarshal(data, &e); err != nil || e.Output == "" ||
+		(c.ttl > 0 && time.Since(e.Created) > c.ttl) {
+		c.misses.Add(1)
+		return "", false
+	}
+	c.hits.Add(1)
+	return e.Output, true
+}
+
+// Put stores agent a's response to prompt.
+func (c *ResponseCache) Put(a Agent, prompt, output string) error {
+	data, err := json.Marshal(cacheEntry{Agent: a.Name(), Model: agentModel(a), Created: time.Now(), Output: output})
+	if err != nil {
+		return err
+	}
+	path := c.path(a, prompt)
+	tmp, err := os.CreateTemp(c.dir, ".entry-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	tmp.Close()
+	return os.Rename(tmp.Name(), path)
+}
+
+// Hits and Misses count lookups since the cache was opened.
+func (c *ResponseCache) Hits() int64   { return c.hits.Load() }
+func (c *ResponseCache) Misses() int64 { return c.misses.Load() }
+
+// CachedAgent answers from a ResponseCache when it can and records fresh
+// successful responses.
+type CachedAgent struct {
+	Agent
+	cache *ResponseCache
+}
+
+// WithCache wraps each agent so its responses go through cache.
+func WithCache(agents []Agent, cache *ResponseCache) []Agent {
+	wrapped := make([]Agent, len(agents))
+	for i, a := range agents {
+		wrapped[i] = &CachedAgent{Agent: a, cache: cache}
+	}
+	return wrapped
+}
+
+func (a *CachedAgent) Run(ctx context.Context, prompt string) (string, error) {
+	out, _, err := a.RunCached(ctx, prompt)
+	return out, err
+}
+
+// RunCached is Run also reporting whether the response came from the cache.
+func (a *CachedAgent) RunCached(ctx context.Context, prompt string) (string, bool, error) {
+	if out, ok := a.cache.Get(a.Agent, prompt); ok {
+		return out, true, nil
+	}
+	out, err := a.Agent.Run(ctx, prompt)
+	if err == nil && out != "" {
+		if perr := a.cache.Put(a.Agent, prompt, out); perr != nil {
+			fmt.Fprintf(os.Stderr, "  %s: cache write failed: %v\n", a.Name(), perr)
+		}
+	}
+	return out, false, err
+}
+
+// stage1Cached reports whether every stage 1 result was served from the
+// cache, as when a run that died in stage 2 is repeated.
+func stage1Cached(results []AgentResult) bool {
+	for _, r := range results {
+		if !r.Cached {
+			return false
+		}
+	}
+	return len(results) > 0
+}
+
+// cachedRunner is an agent that can tell a cached response from a fresh one.
+type cachedRunner interface {
+	RunCached(ctx context.Context, prompt string) (string, bool, error)
+}
+
+// WarmStats summarizes a WarmCache run. Token counts are estimates (about
+// four characters per token) covering only calls that missed the cache.
+type WarmStats struct {
+	Prompts   int
+	Hits      int
+	Misses    int
+	Failures  int
+	TokensIn  int
+	TokensOut int
+
+	// Agents breaks the token estimates down by available agent, in roster
+	// order, for pricing with EstimateCost.
+	Agents []WarmAgentStats
+}
+
+// WarmAgentStats is one agent's share of a WarmCache run's token estimates.
+type WarmAgentStats struct {
+	Agent  string
+	Model  string
+	Tokens TokenUsage
+}
+
+// WarmCache runs every available agent against every prompt, stage 1 only,
+// storing responses in cache. Up to concurrency prompts (minimum 1) are warmed
+// at once, each under its own timeout.
+func WarmCache(ctx context.Context, agents []Agent, cache *ResponseCache, prompts []string, concurrency int, timeout time.Duration) WarmStats {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	var available []Agent
+	for _, a := range agents {
+		if a.Available() {
+			available = append(available, a)
+		}
+	}
+
+	var mu sync.Mutex
+	stats := WarmStats{Prompts: len(prompts), Agents: make([]WarmAgentStats, len(available))}
+	for j, a := range available {
+		stats.Agents[j] = WarmAgentStats{Agent: a.Name(), Model: agentModel(a)}
+	}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, prompt := range prompts {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, prompt string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			pctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			var pwg sync.WaitGroup
+			for j, a := range available {
+				pwg.Add(1)
+				go func(j int, a Agent) {
+					defer pwg.Done()
+					if _, ok := cache.Get(a, prompt); ok {
+						mu.Lock()
+						stats.Hits++
+						mu.Unlock()
+						return
+					}
+					out, err := a.Run(pctx, prompt)
+					if err == nil && out != "" {
+						err = cache.Put(a, prompt, out)
+					} else if err == nil {
+						err = fmt.Errorf("empty response")
+					}
+
+					mu.Lock()
+					defer mu.Unlock()
+					stats.Misses++
+					in := estimateTokens(prompt)
+					stats.TokensIn += in
+					stats.Agents[j].Tokens.InputTokens += int64(in)
+					if err != nil {
+						stats.Failures++
+						fmt.Fprintf(os.Stderr, "  prompt %d: %s: %s\n", i+1, a.Name(), describeFailure(asAgentError(a.Name(), err)))
+						return
+					}
+					n := estimateTokens(out)
+					stats.TokensOut += n
+					stats.Agents[j].Tokens.OutputTokens += int64(n)
+					fmt.Fprintf(os.Stderr, "  prompt %d: %s: cached\n", i+1, a.Name())
+				}(j, a)
+			}
+			pwg.Wait()
+		}(i, prompt)
+	}
+	wg.Wait()
+	return stats
+}
+
+// estimateTokens approximates a token count from text length.
+func estimateTokens(s string) int {
+	return (len(s) + 3) / 4
+}
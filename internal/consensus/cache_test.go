@@ -0,0 +1,166 @@
+package consensus
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type countingAgent struct {
+	mockAgent
+	model string
+	calls atomic.Int32
+}
+
+func (c *countingAgent) Model() string { return c.model }
+func (c *countingAgent) Run(ctx context.Context, prompt string) (string, error) {
+	c.calls.Add(1)
+	return c.mockAgent.Run(ctx, prompt)
+}
+
+func TestResponseCacheGetPut(t *testing.T) {
+	cache, err := NewResponseCache(t.TempDir(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &countingAgent{mockAgent: mockAgent{name: "A", available: true}, model: "m1"}
+	if _, ok := cache.Get(a, "p"); ok {
+		t.Fatal("empty cache should miss")
+	}
+	if err := cache.Put(a, "p", "answer"); err != nil {
+		t.Fatal(err)
+	}
+	if out, ok := cache.Get(a, "p"); !ok || out != "answer" {
+		t.Errorf("Get = %q, %v", out, ok)
+	}
+	if _, ok := cache.Get(a, "other prompt"); ok {
+		t.Error("different prompt should miss")
+	}
+	a.model = "m2"
+	if _, ok := cache.Get(a, "p"); ok {
+		t.Error("different model should miss")
+	}
+	if cache.Hits() != 1 || cache.Misses() != 3 {
+		t.Errorf("hits=%d misses=%d", cache.Hits(), cache.Misses())
+	}
+}
+
+func TestResponseCacheTTL(t *testing.T) {
+	dir := t.TempDir()
+	a := &mockAgent{name: "A", available: true}
+	writer, _ := NewResponseCache(dir, 0)
+	writer.Put(a, "p", "answer")
+
+	time.Sleep(20 * time.Millisecond)
+	reader, _ := NewResponseCache(dir, 10*time.Millisecond)
+	if _, ok := reader.Get(a, "p"); ok {
+		t.Error("expired entry should miss")
+	}
+}
+
+func TestCachedAgentSkipsRepeatCalls(t *testing.T) {
+	cache, _ := NewResponseCache(t.TempDir(), 0)
+	a := &countingAgent{mockAgent: mockAgent{name: "A", available: true, response: "r"}}
+	wrapped := WithCache([]Agent{a}, cache)[0]
+
+	for i := 0; i < 3; i++ {
+		out, err := wrapped.Run(context.Background(), "p")
+		if err != nil || out != "r" {
+			t.Fatalf("Run = %q, %v", out, err)
+		}
+	}
+	if a.calls.Load() != 1 {
+		t.Errorf("underlying agent called %d times, want 1", a.calls.Load())
+	}
+}
+
+func TestCachedAgentDoesNotCacheFailures(t *testing.T) {
+	cache, _ := NewResponseCache(t.TempDir(), 0)
+	a := &countingAgent{mockAgent: mockAgent{name: "A", available: true, err: fmt.Errorf("boom")}}
+	wrapped := WithCache([]Agent{a}, cache)[0]
+	wrapped.Run(context.Background(), "p")
+	wrapped.Run(context.Background(), "p")
+	if a.calls.Load() != 2 {
+		t.Errorf("failures should not be cached, calls = %d", a.calls.Load())
+	}
+}
+
+func TestWarmCache(t *testing.T) {
+	cache, _ := NewResponseCache(t.TempDir(), 0)
+	a := &countingAgent{mockAgent: mockAgent{name: "A", available: true, response: "ra"}, model: "big"}
+	b := &countingAgent{mockAgent: mockAgent{name: "B", available: true, err: fmt.Errorf("down")}}
+	off := &countingAgent{mockAgent: mockAgent{name: "Off", available: false}}
+	agents := []Agent{a, b, off}
+	prompts := []string{"p1", "p2", "p3"}
+
+	stats := WarmCache(context.Background(), agents, cache, prompts, 2, time.Second)
+	if stats.Prompts != 3 || stats.Hits != 0 || stats.Misses != 6 || stats.Failures != 3 {
+		t.Errorf("first warm stats = %+v", stats)
+	}
+	if stats.TokensOut == 0 {
+		t.Error("expected output token estimate")
+	}
+	if len(stats.Agents) != 2 || stats.Agents[0].Agent != "A" || stats.Agents[0].Model != "big" || stats.Agents[0].Tokens.OutputTokens == 0 {
+		t.Errorf("per-agent stats = %+v, want A's estimates first", stats.Agents)
+	} else if b := stats.Agents[1].Tokens; b.InputTokens == 0 || b.OutputTokens != 0 {
+		t.Errorf("B tokens = %+v, want prompts counted but no output from failed calls", b)
+	}
+	if off.calls.Load() != 0 {
+		t.Error("unavailable agents should be skipped")
+	}
+
+	stats = WarmCache(context.Background(), agents, cache, prompts, 2, time.Second)
+	if stats.Hits != 3 || stats.Misses != 3 {
+		t.Errorf("second warm stats = %+v", stats)
+	}
+	if a.calls.Load() != 3 {
+		t.Errorf("cached prompts should not be re-run, calls = %d", a.calls.Load())
+	}
+}
+
+func TestRunResumesFromCachedStage1(t *testing.T) {
+	cache, _ := NewResponseCache(t.TempDir(), 0)
+	a := &countingAgent{mockAgent: mockAgent{name: "A", available: true, response: "analysis A"}}
+	b := &countingAgent{mockAgent: mockAgent{name: "B", available: true, response: "analysis B"}}
+	agents := WithCache([]Agent{a, b}, cache)
+	prompts := []ChunkPrompt{{Prompt: "same prompt"}}
+	build := func([]AgentResult) string { return "chair" }
+
+	// The first run dies in stage 2 after stage 1 was cached.
+	failing := &mockAgent{name: "Chair", available: true, err: fmt.Errorf("connection reset")}
+	if _, err := Run(context.Background(), agents, []Agent{failing}, prompts, build, Options{}); err == nil {
+		t.Fatal("expected stage 2 failure")
+	}
+
+	chairman := &countingAgent{mockAgent: mockAgent{name: "Chair", available: true, response: "synthesis"}}
+	result, err := Run(context.Background(), agents, []Agent{chairman}, prompts, build, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.calls.Load() != 1 || b.calls.Load() != 1 {
+		t.Errorf("stage 1 agents called %d and %d times, want once each", a.calls.Load(), b.calls.Load())
+	}
+	if chairman.calls.Load() != 1 || result.ChairmanOutput != "synthesis" {
+		t.Errorf("stage 2 calls = %d, output %q", chairman.calls.Load(), result.ChairmanOutput)
+	}
+	for _, r := range result.Stage1Results {
+		if !r.Cached {
+			t.Errorf("%s result not marked cached", r.Agent)
+		}
+	}
+	if !stage1Cached(result.Stage1Results) {
+		t.Error("stage1Cached = false for a fully cached stage 1")
+	}
+
+	// A fresh agent mixes in: stage 1 is no longer fully cached.
+	c := &countingAgent{mockAgent: mockAgent{name: "C", available: true, response: "analysis C"}}
+	result, err = Run(context.Background(), append(agents, WithCache([]Agent{c}, cache)...), []Agent{chairman}, prompts, build, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stage1Cached(result.Stage1Results) || result.Stage1Results[2].Cached {
+		t.Error("fresh result reported as cached")
+	}
+}
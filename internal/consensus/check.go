@@ -0,0 +1,68 @@
+package consensus
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ProbePrompt is the tiny prompt a liveness check sends each agent.
+const ProbePrompt = "Reply with the single word OK."
+
+// Liveness check outcomes.
+const (
+	CheckOK          = "ok"
+	CheckSlow        = "slow"        // answered, but slower than the slow threshold
+	CheckUnavailable = "unavailable" // not configured, or the probe failed
+)
+
+// AgentCheck is the result of probing one agent.
+type AgentCheck struct {
+	Agent   string
+	Model   string
+	Status  string
+	Latency time.Duration // 0 when the agent was not probed
+	Detail  string        // why the agent is unavailable or slow
+}
+
+// Usable reports whether the agent answered the probe.
+func (c AgentCheck) Usable() bool { return c.Status == CheckOK || c.Status == CheckSlow }
+
+// CheckAgents probes every agent in parallel: an unavailable agent is
+// reported with its reason, an available one is sent ProbePrompt with
+// timeout and reported slow when it takes longer than slow. Unlike
+// Available, this catches expired keys and unreachable endpoints before a
+// long run waits out its stage timeout on them. Results are in agent order.
+func CheckAgents(ctx context.Context, agents []Agent, timeout, slow time.Duration) []AgentCheck {
+	checks := make([]AgentCheck, len(agents))
+	var wg sync.WaitGroup
+	for i, a := range agents {
+		checks[i] = AgentCheck{Agent: a.Name(), Model: agentModel(a)}
+		if reason := UnavailableReason(a); reason != "" {
+			checks[i].Status, checks[i].Detail = CheckUnavailable, reason
+			continue
+		}
+		wg.Add(1)
+		go func(c *AgentCheck, a Agent) {
+			defer wg.Done()
+			pctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			start := time.Now()
+			_, err := a.Run(pctx, ProbePrompt)
+			c.Latency = time.Since(start)
+			switch {
+			case err != nil && pctx.Err() == context.DeadlineExceeded:
+				c.Status, c.Detail = CheckUnavailable, fmt.Sprintf("no response within %s", timeout)
+			case err != nil:
+				c.Status, c.Detail = CheckUnavailable, err.Error()
+			case slow > 0 && c.Latency > slow:
+				c.Status, c.Detail = CheckSlow, fmt.Sprintf("slower than %s", slow)
+			default:
+				c.Status = CheckOK
+			}
+		}(&checks[i], a)
+	}
+	wg.Wait()
+	return checks
+}
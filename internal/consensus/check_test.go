@@ -0,0 +1,46 @@
+package consensus
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckAgents(t *testing.T) {
+	agents := []Agent{
+		&mockAgent{name: "Fast", available: true, response: "OK"},
+		&mockAgent{name: "Slow", available: true, response: "OK", delay: 60 * time.Millisecond},
+		&mockAgent{name: "Hung", available: true, response: "OK", delay: 5 * time.Second},
+		&mockAgent{name: "Expired", available: true, err: fmt.Errorf("HTTP 401: invalid key")},
+		&mockAgent{name: "Off", available: false},
+	}
+	start := time.Now()
+	checks := CheckAgents(context.Background(), agents, 200*time.Millisecond, 30*time.Millisecond)
+	if time.Since(start) > time.Second {
+		t.Errorf("CheckAgents took %v, probes should run in parallel and stop at the timeout", time.Since(start))
+	}
+
+	want := map[string]string{"Fast": CheckOK, "Slow": CheckSlow, "Hung": CheckUnavailable, "Expired": CheckUnavailable, "Off": CheckUnavailable}
+	for i, c := range checks {
+		if c.Agent != agents[i].Name() {
+			t.Errorf("checks[%d] = %s, want agent order", i, c.Agent)
+		}
+		if c.Status != want[c.Agent] {
+			t.Errorf("%s status = %q, want %q (%s)", c.Agent, c.Status, want[c.Agent], c.Detail)
+		}
+	}
+	if !checks[0].Usable() || !checks[1].Usable() || checks[2].Usable() {
+		t.Errorf("usable = %v %v %v, want ok and slow usable", checks[0].Usable(), checks[1].Usable(), checks[2].Usable())
+	}
+	if !strings.Contains(checks[2].Detail, "no response within") {
+		t.Errorf("hung detail = %q", checks[2].Detail)
+	}
+	if !strings.Contains(checks[3].Detail, "401") {
+		t.Errorf("expired detail = %q, want the probe error", checks[3].Detail)
+	}
+	if checks[4].Detail != "not available" || checks[4].Latency != 0 {
+		t.Errorf("unavailable agent = %+v, should not be probed", checks[4])
+	}
+}
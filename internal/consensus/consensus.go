@@ -3,28 +3,108 @@ package consensus
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
+	"slices"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/signalnine/conclave/internal/bus"
+	"github.com/signalnine/conclave/internal/config"
 )
 
 type AgentResult struct {
 	Agent  string
+	Chunk  string // stage 1 chunk label; empty when the input was not split
 	Output string
 	Err    error
+	Cached bool // stage 1: served from the response cache, see WithCache
+
+	// Retries counts the stage 1 retries spent on this result, see
+	// Options.Retries and Options.RetryPolicy.
+	Retries int
+
+	// Tokens the API reported for the calls behind the result, retries and
+	// chairman fallbacks included, and their cost estimated from
+	// Options.Prices. Zero for agents that do not report usage.
+	PromptTokens     int64
+	CompletionTokens int64
+	CostUSD          float64
+}
+
+// Attempts is the number of calls made for the result: the first plus its
+// retries.
+func (r AgentResult) Attempts() int { return r.Retries + 1 }
+
+// Tokens returns the result's token counts as a TokenUsage.
+func (r AgentResult) Tokens() TokenUsage {
+	return TokenUsage{InputTokens: r.PromptTokens, OutputTokens: r.CompletionTokens}
 }
 
 type ConsensusResult struct {
+	RunID           string // correlates the output file and progress events
 	Stage1Results   []AgentResult
 	Rebuttals       []AgentResult
+	Critiques       []AgentResult // cross-critique pass, see RunConsensusCritique
 	ChairmanName    string
 	ChairmanOutput  string
 	OutputFile      string
 	AgentsSucceeded int
+	Escalated       bool              // stage 2 timed out and the fast chairman synthesized instead
+	ShortCircuited  bool              // Options.Fast: ChairmanName's confident stage 1 answer, stage 2 skipped
+	Consistency     *ConsistencyCheck // set when Options.Verify ran a self-consistency check
+
+	// ChairmanOrder lists the stage 1 results in the order the chairman saw
+	// them; Seed is the shuffle seed when Options.Order was OrderShuffle.
+	ChairmanOrder []string
+	Seed          int64
+
+	Agreement Agreement // how closely the stage 1 agents agreed
+
+	// Syntheses holds each chairman's synthesis when Options.Chairmen ran
+	// several chairmen; ChairmanName is then the merger.
+	Syntheses []AgentResult
+
+	FactCheck *FactCheck // fact-check mode: the parsed verdict and citations
+
+	Gate *UnanimousGate // set when Options.RequireUnanimous gated the run
+
+	// Disagreement is set when Options.DisagreementThreshold found the stage
+	// 1 agents at odds; DisagreementReason says how, e.g. "Claude and Codex
+	// diverge by 91%".
+	Disagreement       bool
+	DisagreementReason string
+
+	// Tokens and CostUSD total the stage 1 and stage 2 calls of a Run.
+	Tokens  TokenUsage
+	CostUSD float64
+
+	// Rounds holds every stage 1 round's results when Options.Rounds was
+	// set, oldest first; Rounds[0] is Stage1Results.
+	Rounds [][]AgentResult
+}
+
+// TimedOut names the agents whose stage 1 call hit its timeout, once each.
+func (r *ConsensusResult) TimedOut() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, res := range r.Stage1Results {
+		if res.Err != nil && ErrorKindOf(res.Err) == KindTimeout && !seen[res.Agent] {
+			seen[res.Agent] = true
+			names = append(names, res.Agent)
+		}
+	}
+	return names
 }
 
 func RunStage1(ctx context.Context, agents []Agent) []AgentResult {
+	return RunStage1WithTimeouts(ctx, agents, nil)
+}
+
+// RunStage1WithTimeouts is RunStage1 with a timeout per agent name: a listed
+// agent runs under its own deadline (within ctx), the rest under ctx alone.
+func RunStage1WithTimeouts(ctx context.Context, agents []Agent, timeouts map[string]time.Duration) []AgentResult {
 	results := make([]AgentResult, len(agents))
 	var wg sync.WaitGroup
 
@@ -32,8 +112,10 @@ func RunStage1(ctx context.Context, agents []Agent) []AgentResult {
 		wg.Add(1)
 		go func(i int, a Agent) {
 			defer wg.Done()
+			ctx, cancel := agentContext(ctx, a.Name(), timeouts)
+			defer cancel()
 			output, err := a.Run(ctx, "")
-			results[i] = AgentResult{Agent: a.Name(), Output: output, Err: err}
+			results[i] = AgentResult{Agent: a.Name(), Output: output, Err: asAgentError(a.Name(), err)}
 		}(i, agent)
 	}
 
@@ -41,6 +123,19 @@ func RunStage1(ctx context.Context, agents []Agent) []AgentResult {
 	return results
 }
 
+// RunStage1WithRetry is RunStage1 retrying each agent's transient failures
+// as policy allows, within ctx's deadline.
+func RunStage1WithRetry(ctx context.Context, agents []Agent, policy RetryPolicy) []AgentResult {
+	return runStage1Chunks(ctx, agents, []ChunkPrompt{{}}, retrier{policy: policy}, nil, nil, nil)
+}
+
+// RunStage1Stream is RunStage1 publishing each agent's response to b as it
+// arrives, on StreamTopic(agent). Agents that do not stream publish their
+// response in one piece when it completes.
+func RunStage1Stream(ctx context.Context, agents []Agent, b bus.MessageBus) []AgentResult {
+	return runStage1Chunks(ctx, agents, []ChunkPrompt{{}}, retrier{}, nil, &stage1Stream{bus: b}, nil)
+}
+
 func runStage1WithPrompt(ctx context.Context, agents []Agent, prompt string) []AgentResult {
 	results := make([]AgentResult, len(agents))
 	var wg sync.WaitGroup
@@ -49,8 +144,10 @@ func runStage1WithPrompt(ctx context.Context, agents []Agent, prompt string) []A
 		wg.Add(1)
 		go func(i int, a Agent) {
 			defer wg.Done()
+			ctx, sink := withUsageSink(ctx)
 			output, err := a.Run(ctx, prompt)
-			results[i] = AgentResult{Agent: a.Name(), Output: output, Err: err}
+			u := sink.usage()
+			results[i] = AgentResult{Agent: a.Name(), Output: output, Err: asAgentError(a.Name(), err), PromptTokens: u.InputTokens, CompletionTokens: u.OutputTokens}
 		}(i, agent)
 	}
 
@@ -59,20 +156,222 @@ func runStage1WithPrompt(ctx context.Context, agents []Agent, prompt string) []A
 }
 
 func RunStage2(ctx context.Context, chairmen []Agent, prompt string) (AgentResult, error) {
+	return runStage2(ctx, chairmen, prompt, nil, nil)
+}
+
+// runStage2 tries each available chairman in order. With a retry budget, every
+// fallback after a failed chairman consumes one retry; without one, fallback
+// is unlimited. When w is set, the synthesis is written to it as it arrives,
+// so a partial synthesis survives a crash.
+func runStage2(ctx context.Context, chairmen []Agent, prompt string, budget *RetryBudget, w io.Writer) (AgentResult, error) {
+	ctx, sink := withUsageSink(ctx)
+	attempted := false
 	for _, chairman := range chairmen {
 		if !chairman.Available() {
 			continue
 		}
-		output, err := chairman.Run(ctx, prompt)
+		if attempted && budget != nil {
+			if !budget.Take() {
+				return AgentResult{}, fmt.Errorf("chairman failed and retry budget exhausted (0/%d remaining)", budget.Total())
+			}
+			fmt.Fprintf(os.Stderr, "  Falling back to %s (retry budget: %d/%d remaining)\n", chairman.Name(), budget.Remaining(), budget.Total())
+		}
+		if attempted && w != nil {
+			fmt.Fprintf(w, "\n\n[synthesis interrupted; falling back to %s]\n\n", chairman.Name())
+		}
+		attempted = true
+		output, err := runTo(ctx, chairman, prompt, w)
 		if err == nil && output != "" {
-			return AgentResult{Agent: chairman.Name(), Output: output}, nil
+			u := sink.usage()
+			return AgentResult{Agent: chairman.Name(), Output: output, PromptTokens: u.InputTokens, CompletionTokens: u.OutputTokens}, nil
+		}
+		if err == nil {
+			err = fmt.Errorf("empty response")
 		}
-		fmt.Fprintf(os.Stderr, "  %s: FAILED (%v)\n", chairman.Name(), err)
+		fmt.Fprintf(os.Stderr, "  %s: %s\n", chairman.Name(), describeFailure(asAgentError(chairman.Name(), err)))
 	}
 	return AgentResult{}, fmt.Errorf("all chairman agents failed")
 }
 
+// PinChairman returns the chairman order with the named agent first and the
+// rest of the roster as fallback. Names match case-insensitively; an empty
+// name keeps the roster order.
+func PinChairman(agents []Agent, name string) ([]Agent, error) {
+	if name == "" {
+		return agents, nil
+	}
+	var pinned Agent
+	var rest []Agent
+	for _, a := range agents {
+		if pinned == nil && strings.EqualFold(a.Name(), name) {
+			pinned = a
+		} else {
+			rest = append(rest, a)
+		}
+	}
+	if pinned == nil {
+		names := make([]string, len(agents))
+		for i, a := range agents {
+			names[i] = a.Name()
+		}
+		return nil, fmt.Errorf("chairman %q not in agent roster (%s)", name, strings.Join(names, ", "))
+	}
+	return append([]Agent{pinned}, rest...), nil
+}
+
 func RunConsensus(ctx context.Context, agents, chairmen []Agent, prompt string, stage1Timeout, stage2Timeout int) (*ConsensusResult, error) {
+	buildChairman := func(results []AgentResult) string {
+		return buildChairmanPrompt(prompt, results)
+	}
+	prompts := []ChunkPrompt{{Prompt: prompt}}
+	return RunConsensusChunked(ctx, agents, chairmen, prompts, buildChairman, stage1Timeout, stage2Timeout)
+}
+
+// ChunkPrompt is a stage 1 prompt covering one part of a larger input.
+type ChunkPrompt struct {
+	Label  string
+	Prompt string
+}
+
+// RunConsensusChunked runs stage 1 once per prompt for every agent, all in
+// parallel under one stage 1 deadline, then synthesizes every chunk's results
+// in a single chairman pass. Results carry their chunk label in AgentResult.Chunk.
+func RunConsensusChunked(ctx context.Context, agents, chairmen []Agent, prompts []ChunkPrompt, buildChairman func([]AgentResult) string, stage1Timeout, stage2Timeout int) (*ConsensusResult, error) {
+	return Run(ctx, agents, chairmen, prompts, buildChairman, Options{Stage1Timeout: stage1Timeout, Stage2Timeout: stage2Timeout})
+}
+
+// DefaultStageTimeout is the per-stage timeout in seconds used when Options
+// leaves a stage timeout unset.
+const DefaultStageTimeout = 60
+
+// Options tunes a consensus run. Zero values keep the default behavior.
+type Options struct {
+	Stage1Timeout int // seconds; <= 0 uses DefaultStageTimeout
+	Stage2Timeout int // seconds; <= 0 uses DefaultStageTimeout
+
+	// Retries is a total retry budget shared by stage 1 agent retries and
+	// stage 2 chairman fallbacks. When it runs out, further failures are
+	// final. Zero disables stage 1 retries and leaves chairman fallback
+	// unlimited.
+	Retries int
+
+	// Prices estimates each result's CostUSD by model; see config.PriceOf.
+	// Models without a price cost nothing.
+	Prices map[string]config.ModelPrice
+
+	// RetryPolicy caps and paces each stage 1 agent's retries. With Retries
+	// also set, a retry needs both to allow it.
+	RetryPolicy RetryPolicy
+
+	// FastChairman, when set, takes over if stage 2 hits its deadline. It
+	// synthesizes from summarized stage 1 outputs under FastTimeout.
+	FastChairman Agent
+	FastTimeout  int // seconds; <= 0 uses DefaultFastTimeout
+
+	// RunID identifies the run in its result and progress events; empty
+	// generates one. Bus, when set, receives progress events on ProgressTopic.
+	RunID string
+	Bus   bus.MessageBus
+
+	// DisagreementThreshold, when above 0, flags the run when two stage 1
+	// agents' outputs diverge by more than it (0..1) under Divergence, or
+	// when reviewers split on whether there is a critical issue. A flagged
+	// run tells the chairman the agents disagree. Divergence scores two
+	// outputs from 0 (same) to 1; nil uses KeywordDivergence.
+	DisagreementThreshold float64
+	Divergence            func(a, b string) float64
+
+	// StreamStage1 publishes each stage 1 response to Bus as it arrives, on
+	// StreamTopic(agent), so a UI can show agents' progress. Agents that do
+	// not stream publish their response in one piece.
+	StreamStage1 bool
+
+	// StreamTo, when set, receives the chairman synthesis as it arrives
+	// (incrementally for StreamingAgent chairmen, in one piece otherwise).
+	StreamTo io.Writer
+
+	// Verify runs a self-consistency pass after stage 2: the chairman that
+	// wrote the synthesis checks it for internal contradictions, within
+	// VerifyTimeout seconds (<= 0 uses DefaultStageTimeout).
+	Verify        bool
+	VerifyTimeout int
+
+	// Order sets how stage 1 results are ordered in the chairman prompt.
+	// Seed makes OrderShuffle reproducible; 0 picks one, recorded in the
+	// result.
+	Order ResultOrder
+	Seed  int64
+
+	// Chairmen, when 2 or more, has that many available chairmen synthesize
+	// in parallel and a merger reconcile their syntheses, reducing single
+	// chairman bias at the cost of extra calls. The merge pass gets its own
+	// stage 2 timeout. Merger picks the merging agent; nil uses the first
+	// chairman that produced a synthesis.
+	Chairmen int
+	Merger   Agent
+
+	// AgentTimeouts gives the named agents their own stage 1 timeout in
+	// seconds, longer or shorter than Stage1Timeout, which still applies to
+	// the others. Stage 1 lasts until the longest of them.
+	AgentTimeouts map[string]int
+
+	// Progress, when set, receives a stage 1 progress line every
+	// ProgressInterval (<= 0 uses DefaultProgressInterval) until stage 1
+	// completes, e.g. "2/3 agents done, 45s elapsed of 120s".
+	Progress         io.Writer
+	ProgressInterval time.Duration
+
+	// Fast asks agents to mark answers they are certain of (see
+	// ConfidenceMarker). If the first successful stage 1 response is marked,
+	// it is returned as the result at once: the other agents are canceled and
+	// stage 2 is skipped. Fast only applies to a single, unchunked prompt.
+	Fast bool
+
+	// RequireUnanimous asks every stage 1 agent for an approve/block signal
+	// and computes the result's Gate from those signals before stage 2. The
+	// synthesis still runs but has no say in the gate. Fast is ignored.
+	RequireUnanimous bool
+
+	// Rounds, when 2 or more, runs stage 1 that many times: from the second
+	// round on, every agent sees all answers from the round before and
+	// rebuts or revises its own, each round under RoundTimeout seconds (<= 0
+	// uses Stage1Timeout). Rounds stop early once fewer than two agents
+	// answered. The chairman synthesizes the final round, told how it came
+	// about, unless RoundsChairman writes its prompt from every round.
+	// Rounds only apply to a single, unchunked prompt without Fast.
+	Rounds         int
+	RoundTimeout   int
+	RoundsChairman func(rounds [][]AgentResult) string
+}
+
+// DefaultFastTimeout is the fast chairman's timeout in seconds when Options
+// leaves FastTimeout unset.
+const DefaultFastTimeout = 30
+
+// escalationSentences is how much of each stage 1 output the fast chairman sees.
+const escalationSentences = 5
+
+// Run is the general consensus flow behind the RunConsensus* helpers: stage 1
+// runs every available agent against every prompt, then stage 2 synthesizes
+// the results with the first chairman that succeeds.
+func Run(ctx context.Context, agents, chairmen []Agent, prompts []ChunkPrompt, buildChairman func([]AgentResult) string, opts Options) (*ConsensusResult, error) {
+	stage1Timeout, stage2Timeout := opts.Stage1Timeout, opts.Stage2Timeout
+	if stage1Timeout <= 0 {
+		stage1Timeout = DefaultStageTimeout
+	}
+	if stage2Timeout <= 0 {
+		stage2Timeout = DefaultStageTimeout
+	}
+	runID := opts.RunID
+	if runID == "" {
+		runID = bus.NewID()
+	}
+	prog := progress{bus: opts.Bus, runID: runID}
+	var budget *RetryBudget
+	if opts.Retries > 0 {
+		budget = NewRetryBudget(opts.Retries)
+	}
+
 	// Filter available agents
 	var available []Agent
 	for _, a := range agents {
@@ -85,111 +384,422 @@ func RunConsensus(ctx context.Context, agents, chairmen []Agent, prompt string,
 	}
 
 	// Stage 1
+	fmt.Fprintf(os.Stderr, "Run ID: %s\n", runID)
+	prog.emit(EventStart, ProgressEvent{Agents: len(available), Chunks: len(prompts)})
 	fmt.Fprintln(os.Stderr, "Stage 1: Launching parallel agent analysis...")
-	ctx1, cancel1 := context.WithTimeout(ctx, time.Duration(stage1Timeout)*time.Second)
+	if len(prompts) > 1 {
+		fmt.Fprintf(os.Stderr, "  Input split into %d chunks\n", len(prompts))
+	}
+	timeouts, stageTimeout := agentTimeouts(available, stage1Timeout, opts.AgentTimeouts)
+	ctx1, cancel1 := context.WithTimeout(ctx, stageTimeout)
 	defer cancel1()
 
 	fmt.Fprintf(os.Stderr, "  Waiting for agents (%ds timeout)...\n", stage1Timeout)
+	for _, a := range available {
+		if secs, ok := opts.AgentTimeouts[a.Name()]; ok {
+			fmt.Fprintf(os.Stderr, "  %s: %ds timeout\n", a.Name(), secs)
+		}
+	}
 	start1 := time.Now()
-	results := runStage1WithPrompt(ctx1, available, prompt)
+	unit := "agents"
+	if len(prompts) > 1 {
+		unit = "agent chunks"
+	}
+	ticker := startStage1Ticker(opts.Progress, opts.ProgressInterval, stageTimeout, len(prompts)*len(available), unit)
+	onDone := ticker.completed
+	question := prompts[0].Prompt
+	if opts.RequireUnanimous {
+		gated := make([]ChunkPrompt, len(prompts))
+		for i, cp := range prompts {
+			gated[i] = ChunkPrompt{Label: cp.Label, Prompt: cp.Prompt + "\n\n" + gateInstruction}
+		}
+		prompts = gated
+	}
+	var fast *fastPath
+	if opts.Fast && !opts.RequireUnanimous && len(prompts) == 1 {
+		fast = &fastPath{cancel: cancel1}
+		prompts = []ChunkPrompt{{Label: prompts[0].Label, Prompt: prompts[0].Prompt + "\n\n" + confidenceInstruction}}
+		onDone = func(r AgentResult) {
+			ticker.completed(r)
+			fast.done(r)
+		}
+	}
+	var stream *stage1Stream
+	if opts.StreamStage1 && opts.Bus != nil {
+		stream = &stage1Stream{bus: opts.Bus, runID: runID}
+	}
+	retry := retrier{budget: budget, policy: opts.RetryPolicy}
+	results := runStage1Chunks(ctx1, available, prompts, retry, timeouts, stream, onDone)
+	ticker.Stop()
+	priced(results, opts.Prices, available...)
 	fmt.Fprintf(os.Stderr, "  Stage 1 duration: %.1fs\n", time.Since(start1).Seconds())
 
-	// Tally results
-	succeeded := 0
+	if answer := fast.result(); answer != nil {
+		fmt.Fprintf(os.Stderr, "  %s: SUCCESS (confident; remaining agents canceled, stage 2 skipped)\n", answer.Agent)
+		for _, r := range results {
+			prog.result(1, r)
+		}
+		succeeded := countSucceeded(results)
+		if opts.StreamTo != nil {
+			io.WriteString(opts.StreamTo, answer.Output)
+		}
+		prog.emit(EventDone, ProgressEvent{Agent: answer.Agent, Status: "success"})
+		tokens, cost := usageOf(results...)
+		return &ConsensusResult{
+			Tokens:          tokens,
+			CostUSD:         cost,
+			RunID:           runID,
+			Stage1Results:   results,
+			ChairmanName:    answer.Agent,
+			ChairmanOutput:  answer.Output,
+			AgentsSucceeded: succeeded,
+			ShortCircuited:  true,
+			Agreement:       MeasureAgreement(results, len(available)),
+		}, nil
+	}
+
+	// Tally results: an agent succeeds if any of its chunks succeeded
+	agentOK := make(map[string]bool)
 	for _, r := range results {
-		if r.Err == nil {
-			fmt.Fprintf(os.Stderr, "  %s: SUCCESS\n", r.Agent)
-			succeeded++
+		prog.result(1, r)
+		name := r.Agent
+		if r.Chunk != "" {
+			name = fmt.Sprintf("%s [%s]", r.Agent, r.Chunk)
+		}
+		if r.Err == nil && r.Cached {
+			fmt.Fprintf(os.Stderr, "  %s: SUCCESS (cached)\n", name)
+			agentOK[r.Agent] = true
+		} else if r.Err == nil {
+			fmt.Fprintf(os.Stderr, "  %s: SUCCESS\n", name)
+			agentOK[r.Agent] = true
 		} else {
-			fmt.Fprintf(os.Stderr, "  %s: FAILED (%v)\n", r.Agent, r.Err)
+			fmt.Fprintf(os.Stderr, "  %s: %s\n", name, describeFailure(r.Err))
 		}
 	}
+	succeeded := len(agentOK)
 	fmt.Fprintf(os.Stderr, "  Agents completed: %d/%d succeeded\n", succeeded, len(available))
 	if succeeded == 0 {
-		return nil, fmt.Errorf("all agents failed (0/%d succeeded)", len(available))
+		err := fmt.Errorf("all agents failed (0/%d succeeded)", len(available))
+		prog.emit(EventDone, ProgressEvent{Status: "failed", Error: err.Error()})
+		return nil, err
 	}
 
+	if stage1Cached(results) {
+		fmt.Fprintln(os.Stderr, "  Stage 1 served from cache.")
+	}
+
+	var gate *UnanimousGate
+	if opts.RequireUnanimous {
+		gate = EvaluateUnanimous(results)
+		if gate.Verdict == GateApprove {
+			fmt.Fprintln(os.Stderr, "  Unanimous gate: APPROVE")
+		} else {
+			fmt.Fprintf(os.Stderr, "  Unanimous gate: BLOCK (%s)\n", strings.Join(gate.Blockers(), ", "))
+		}
+	}
+
+	rounds := [][]AgentResult{results}
+	if opts.Rounds > 1 {
+		if len(prompts) > 1 {
+			fmt.Fprintln(os.Stderr, "  Skipping rounds: the input is chunked")
+		} else {
+			roundTimeout := opts.RoundTimeout
+			if roundTimeout <= 0 {
+				roundTimeout = stage1Timeout
+			}
+			rounds = runRounds(ctx, available, question, rounds, opts, roundTimeout, retry, prog)
+		}
+	}
+	final := rounds[len(rounds)-1]
+	counted := slices.Concat(rounds...)
+
 	// Stage 2
 	fmt.Fprintln(os.Stderr, "\nStage 2: Chairman synthesis...")
 	ctx2, cancel2 := context.WithTimeout(ctx, time.Duration(stage2Timeout)*time.Second)
 	defer cancel2()
 
-	chairmanPrompt := buildChairmanPrompt(prompt, results)
+	ordered, seed := orderResults(final, opts.Order, opts.Seed)
+	switch opts.Order {
+	case OrderShuffle:
+		fmt.Fprintf(os.Stderr, "  Chairman input order: shuffled (seed %d)\n", seed)
+	case OrderSorted:
+		fmt.Fprintln(os.Stderr, "  Chairman input order: sorted by agent")
+	}
+	var disagreement, directive string
+	if opts.DisagreementThreshold > 0 {
+		if disagreement = detectDisagreement(final, opts.DisagreementThreshold, opts.Divergence); disagreement != "" {
+			fmt.Fprintf(os.Stderr, "  AGENTS DISAGREE: %s\n", disagreement)
+			directive = fmt.Sprintf(disagreementDirective, disagreement) + "\n\n"
+		}
+	}
+	if len(rounds) > 1 {
+		directive += fmt.Sprintf(roundsDirective, len(rounds)) + "\n\n"
+	}
+	chairmanPrompt := directive + buildChairman(ordered)
+	if len(rounds) > 1 && opts.RoundsChairman != nil {
+		chairmanPrompt = opts.RoundsChairman(rounds)
+	}
 	start2 := time.Now()
-	chairResult, err := RunStage2(ctx2, chairmen, chairmanPrompt)
+	var chairResult AgentResult
+	var syntheses []AgentResult
+	var err error
+	if opts.Chairmen > 1 {
+		chairResult, syntheses, err = runMultiStage2(ctx2, ctx, chairmen, chairmanPrompt, opts, stage2Timeout, budget)
+	} else {
+		chairResult, err = runStage2(ctx2, chairmen, chairmanPrompt, budget, opts.StreamTo)
+	}
+	escalated := false
+	if err != nil && opts.FastChairman != nil && ctx2.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+		chairResult, err = escalateStage2(ctx, opts, directive+buildChairman(summarizeResults(ordered)))
+		escalated = err == nil
+	}
 	if err != nil {
-		return nil, fmt.Errorf("stage 2 failed: %w", err)
+		prog.emit(EventDone, ProgressEvent{Stage: 2, Status: "failed", Error: err.Error()})
+		// Return the stage 1 work alongside the error, so it can be salvaged.
+		tokens, cost := usageOf(counted...)
+		result := &ConsensusResult{
+			RunID:           runID,
+			Stage1Results:   results,
+			AgentsSucceeded: succeeded,
+			ChairmanOrder:   resultLabels(ordered),
+			Seed:            seed,
+			Agreement:       MeasureAgreement(results, len(available)),
+			Syntheses:       syntheses,
+			Gate:            gate,
+			Tokens:          tokens,
+			CostUSD:         cost,
+
+			Disagreement:       disagreement != "",
+			DisagreementReason: disagreement,
+		}
+		if opts.Rounds > 0 {
+			result.Rounds = rounds
+		}
+		return result, fmt.Errorf("stage 2 failed: %w", err)
+	}
+	priced(syntheses, opts.Prices, chairmen...)
+	chair := []AgentResult{chairResult}
+	priced(chair, opts.Prices, append(chairmen, opts.FastChairman, opts.Merger)...)
+	chairResult = chair[0]
+	counted = append(counted, syntheses...)
+	if len(syntheses) == 0 || countSucceeded(syntheses) != 1 || escalated {
+		// Otherwise the chairman result is the lone synthesis, counted above.
+		counted = append(counted, chairResult)
 	}
+	tokens, cost := usageOf(counted...)
+
+	prog.result(2, chairResult)
 	fmt.Fprintf(os.Stderr, "  %s: SUCCESS\n", chairResult.Agent)
 	fmt.Fprintf(os.Stderr, "  Stage 2 duration: %.1fs\n", time.Since(start2).Seconds())
+	if budget != nil {
+		fmt.Fprintf(os.Stderr, "  Retry budget: %d/%d remaining\n", budget.Remaining(), budget.Total())
+	}
 
-	return &ConsensusResult{
+	var consistency *ConsistencyCheck
+	if opts.Verify {
+		if chairman := findAgent(chairResult.Agent, append(chairmen, opts.FastChairman, opts.Merger)...); chairman != nil {
+			timeout := opts.VerifyTimeout
+			if timeout <= 0 {
+				timeout = DefaultStageTimeout
+			}
+			consistency = verifySynthesis(ctx, chairman, chairResult.Output, timeout)
+		}
+	}
+
+	prog.emit(EventDone, ProgressEvent{Agent: chairResult.Agent, Status: "success"})
+
+	result := &ConsensusResult{
+		RunID:           runID,
 		Stage1Results:   results,
 		ChairmanName:    chairResult.Agent,
 		ChairmanOutput:  chairResult.Output,
 		AgentsSucceeded: succeeded,
-	}, nil
+		Escalated:       escalated,
+		Consistency:     consistency,
+		ChairmanOrder:   resultLabels(ordered),
+		Seed:            seed,
+		Agreement:       MeasureAgreement(results, len(available)),
+		Syntheses:       syntheses,
+		Gate:            gate,
+		Tokens:          tokens,
+		CostUSD:         cost,
+
+		Disagreement:       disagreement != "",
+		DisagreementReason: disagreement,
+	}
+	if opts.Rounds > 0 {
+		result.Rounds = rounds
+	}
+	return result, nil
 }
 
-// RunConsensusWithBuilder is like RunConsensus but accepts a function to build
-// the chairman prompt from stage 1 results (allowing mode-specific prompt building).
-func RunConsensusWithBuilder(ctx context.Context, agents, chairmen []Agent, stage1Prompt string, buildChairman func([]AgentResult) string, stage1Timeout, stage2Timeout int) (*ConsensusResult, error) {
-	// Filter available agents
-	var available []Agent
-	for _, a := range agents {
-		if a.Available() {
-			available = append(available, a)
+// roundsDirective tells the chairman the analyses are the final round of
+// several.
+const roundsDirective = `The analyses below are the agents' answers after %d rounds: from round 2 on, each agent saw the others' previous answers, rebutted them and revised its own. Treat positions they converged on as strong signals, and note points still contested.`
+
+// runRounds runs rounds 2 to opts.Rounds after stage 1's first round, every
+// agent in parallel, rebutting and revising against the round before. Each
+// agent runs as in stage 1, under its timeout (timeout seconds by default)
+// and retried per retry. Rounds stop once fewer than two agents answered
+// the round before.
+func runRounds(ctx context.Context, agents []Agent, question string, rounds [][]AgentResult, opts Options, timeout int, retry retrier, prog progress) [][]AgentResult {
+	for round := len(rounds) + 1; round <= opts.Rounds; round++ {
+		previous := rounds[len(rounds)-1]
+		if n := countSucceeded(previous); n < 2 {
+			fmt.Fprintf(os.Stderr, "  Stopping after round %d: need at least 2 answers to rebut, got %d\n", round-1, n)
+			break
 		}
+		fmt.Fprintf(os.Stderr, "\n  Round %d of %d (%d agents, %ds timeout)...\n", round, opts.Rounds, len(agents), timeout)
+		timeouts, stageTimeout := agentTimeouts(agents, timeout, opts.AgentTimeouts)
+		ctxR, cancel := context.WithTimeout(ctx, stageTimeout)
+		results := make([]AgentResult, len(agents))
+		var wg sync.WaitGroup
+		for i, a := range agents {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				prompt := []ChunkPrompt{{Prompt: BuildRoundPrompt(question, previous, a.Name(), round)}}
+				results[i] = runStage1Chunks(ctxR, []Agent{a}, prompt, retry, timeouts, nil, nil)[0]
+			}()
+		}
+		wg.Wait()
+		cancel()
+		priced(results, opts.Prices, agents...)
+		for _, r := range results {
+			prog.roundResult(round, r)
+			if r.Err != nil {
+				fmt.Fprintf(os.Stderr, "  %s: ROUND %d %s\n", r.Agent, round, describeFailure(r.Err))
+			} else {
+				fmt.Fprintf(os.Stderr, "  %s: ROUND %d SUCCESS\n", r.Agent, round)
+			}
+		}
+		rounds = append(rounds, results)
 	}
-	if len(available) == 0 {
-		return nil, fmt.Errorf("no agents available (need at least 1 API key)")
-	}
+	return rounds
+}
 
-	// Stage 1
-	fmt.Fprintln(os.Stderr, "Stage 1: Launching parallel agent analysis...")
-	ctx1, cancel1 := context.WithTimeout(ctx, time.Duration(stage1Timeout)*time.Second)
-	defer cancel1()
+// escalateStage2 retries synthesis with the fast chairman after stage 2 ran
+// out of time, trading synthesis quality for a usable result.
+func escalateStage2(ctx context.Context, opts Options, prompt string) (AgentResult, error) {
+	timeout := opts.FastTimeout
+	if timeout <= 0 {
+		timeout = DefaultFastTimeout
+	}
+	fmt.Fprintf(os.Stderr, "  Stage 2 timed out; escalating to fast chairman %s (%ds timeout)...\n", opts.FastChairman.Name(), timeout)
+	if opts.StreamTo != nil {
+		fmt.Fprintf(opts.StreamTo, "\n\n[stage 2 timed out; fast chairman %s synthesizes from summarized results]\n\n", opts.FastChairman.Name())
+	}
+	ctx3, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	defer cancel()
 
-	fmt.Fprintf(os.Stderr, "  Waiting for agents (%ds timeout)...\n", stage1Timeout)
-	start1 := time.Now()
-	results := runStage1WithPrompt(ctx1, available, stage1Prompt)
-	fmt.Fprintf(os.Stderr, "  Stage 1 duration: %.1fs\n", time.Since(start1).Seconds())
+	result, err := runStage2(ctx3, []Agent{opts.FastChairman}, prompt, nil, opts.StreamTo)
+	if err != nil {
+		return AgentResult{}, fmt.Errorf("fast chairman escalation: %w", err)
+	}
+	return result, nil
+}
 
-	// Tally results
-	succeeded := 0
-	for _, r := range results {
+// summarizeResults shortens each successful stage 1 output to its first few
+// sentences for the fast chairman's prompt.
+func summarizeResults(results []AgentResult) []AgentResult {
+	summarized := make([]AgentResult, len(results))
+	for i, r := range results {
+		summarized[i] = r
 		if r.Err == nil {
-			fmt.Fprintf(os.Stderr, "  %s: SUCCESS\n", r.Agent)
-			succeeded++
-		} else {
-			fmt.Fprintf(os.Stderr, "  %s: FAILED (%v)\n", r.Agent, r.Err)
+			summarized[i].Output = truncateToSentences(r.Output, escalationSentences)
 		}
 	}
-	fmt.Fprintf(os.Stderr, "  Agents completed: %d/%d succeeded\n", succeeded, len(available))
-	if succeeded == 0 {
-		return nil, fmt.Errorf("all agents failed (0/%d succeeded)", len(available))
+	return summarized
+}
+
+// agentTimeouts resolves Options.AgentTimeouts for the agents that will run:
+// with any override, every agent gets its timeout (stage1Timeout seconds by
+// default), and stage 1 as a whole lasts as long as the longest. With none it
+// returns nil and stage 1 lasts stage1Timeout, as it always has.
+func agentTimeouts(agents []Agent, stage1Timeout int, overrides map[string]int) (map[string]time.Duration, time.Duration) {
+	stage := time.Duration(stage1Timeout) * time.Second
+	if len(overrides) == 0 {
+		return nil, stage
+	}
+	timeouts := make(map[string]time.Duration, len(agents))
+	for _, a := range agents {
+		d := stage
+		if secs, ok := overrides[a.Name()]; ok && secs > 0 {
+			d = time.Duration(secs) * time.Second
+		}
+		timeouts[a.Name()] = d
+	}
+	longest := time.Duration(0)
+	for _, d := range timeouts {
+		longest = max(longest, d)
 	}
+	return timeouts, longest
+}
 
-	// Stage 2
-	fmt.Fprintln(os.Stderr, "\nStage 2: Chairman synthesis...")
-	ctx2, cancel2 := context.WithTimeout(ctx, time.Duration(stage2Timeout)*time.Second)
-	defer cancel2()
+// agentContext derives the context agent name runs under: ctx bounded by its
+// entry in timeouts, if any.
+func agentContext(ctx context.Context, name string, timeouts map[string]time.Duration) (context.Context, context.CancelFunc) {
+	if d, ok := timeouts[name]; ok {
+		return context.WithTimeout(ctx, d)
+	}
+	return ctx, func() {}
+}
 
-	chairmanPrompt := buildChairman(results)
-	start2 := time.Now()
-	chairResult, err := RunStage2(ctx2, chairmen, chairmanPrompt)
-	if err != nil {
-		return nil, fmt.Errorf("stage 2 failed: %w", err)
+// runStage1Chunks runs every agent against every prompt concurrently. Results
+// are ordered by prompt, then by agent. Transient failures are retried while
+// the shared budget allows.
+func runStage1Chunks(ctx context.Context, agents []Agent, prompts []ChunkPrompt, retry retrier, timeouts map[string]time.Duration, stream *stage1Stream, onDone func(AgentResult)) []AgentResult {
+	results := make([]AgentResult, len(prompts)*len(agents))
+	var wg sync.WaitGroup
+
+	for p, cp := range prompts {
+		for i, agent := range agents {
+			wg.Add(1)
+			go func(idx int, a Agent, cp ChunkPrompt) {
+				defer wg.Done()
+				ctx, cancel := agentContext(ctx, a.Name(), timeouts)
+				defer cancel()
+				ctx, sink := withUsageSink(ctx)
+				var output string
+				var cached bool
+				var err error
+				if c, ok := a.(cachedRunner); ok {
+					output, cached, err = c.RunCached(ctx, cp.Prompt)
+					if w := stream.writer(a.Name(), cp.Label, 1); w != nil && err == nil && output != "" {
+						io.WriteString(w, output)
+					}
+				} else {
+					output, err = runTo(ctx, a, cp.Prompt, stream.writer(a.Name(), cp.Label, 1))
+				}
+				retries := 0
+				for retryable(ctx, err) {
+					// A wait that would reach the deadline leaves no time to retry.
+					d, ok := retry.delay(ctx, err, retries+1)
+					if !ok || !retry.allow(retries) {
+						break
+					}
+					retries++
+					fmt.Fprintf(os.Stderr, "  %s: retrying after error (%v), %s\n", a.Name(), err, retry.describe(retries))
+					if d > 0 {
+						fmt.Fprintf(os.Stderr, "  %s: waiting %s\n", a.Name(), d.Round(time.Millisecond))
+						if !sleepCtx(ctx, d) {
+							break
+						}
+					}
+					output, err = runTo(ctx, a, cp.Prompt, stream.writer(a.Name(), cp.Label, retries+1))
+				}
+				u := sink.usage()
+				results[idx] = AgentResult{Agent: a.Name(), Chunk: cp.Label, Output: output, Err: asAgentError(a.Name(), err), Cached: cached, Retries: retries,
+					PromptTokens: u.InputTokens, CompletionTokens: u.OutputTokens}
+				if onDone != nil {
+					onDone(results[idx])
+				}
+			}(p*len(agents)+i, agent, cp)
+		}
 	}
-	fmt.Fprintf(os.Stderr, "  %s: SUCCESS\n", chairResult.Agent)
-	fmt.Fprintf(os.Stderr, "  Stage 2 duration: %.1fs\n", time.Since(start2).Seconds())
 
-	return &ConsensusResult{
-		Stage1Results:   results,
-		ChairmanName:    chairResult.Agent,
-		ChairmanOutput:  chairResult.Output,
-		AgentsSucceeded: succeeded,
-	}, nil
+	wg.Wait()
+	return results
 }
 
 func buildChairmanPrompt(originalPrompt string, results []AgentResult) string {
@@ -283,19 +893,16 @@ func RunConsensusWithDebate(ctx context.Context, agents, chairmen []Agent,
 	if len(available) == 0 {
 		return nil, fmt.Errorf("no agents available")
 	}
+	runID := bus.NewID()
 
 	// Stage 1
+	fmt.Fprintf(os.Stderr, "Run ID: %s\n", runID)
 	fmt.Fprintf(os.Stderr, "Stage 1: Launching parallel agent analysis...\n")
 	ctx1, cancel1 := context.WithTimeout(ctx, time.Duration(stage1Timeout)*time.Second)
 	defer cancel1()
 	stage1Results := runStage1WithPrompt(ctx1, available, stage1Prompt)
 
-	succeeded := 0
-	for _, r := range stage1Results {
-		if r.Err == nil {
-			succeeded++
-		}
-	}
+	succeeded := countSucceeded(stage1Results)
 	if succeeded == 0 {
 		return nil, fmt.Errorf("all agents failed in Stage 1")
 	}
@@ -325,10 +932,119 @@ func RunConsensusWithDebate(ctx context.Context, agents, chairmen []Agent,
 	}
 
 	return &ConsensusResult{
+		RunID:           runID,
 		Stage1Results:   stage1Results,
 		Rebuttals:       rebuttals,
 		ChairmanName:    chairmanResult.Agent,
 		ChairmanOutput:  chairmanResult.Output,
 		AgentsSucceeded: succeeded,
+		Agreement:       MeasureAgreement(stage1Results, len(available)),
+	}, nil
+}
+
+// RoundTimeouts are the per-stage timeouts of RunConsensusRounds, in seconds.
+type RoundTimeouts struct {
+	Stage1 int // the first round
+	Round  int // each later round
+	Stage2 int
+}
+
+// RunConsensusRounds runs RunConsensus over rounds stage 1 rounds (see
+// Options.Rounds), the chairman synthesizing every round. With rounds <= 1
+// it is RunConsensus.
+func RunConsensusRounds(ctx context.Context, agents, chairmen []Agent, prompt string, rounds int, timeouts RoundTimeouts) (*ConsensusResult, error) {
+	buildChairman := func(results []AgentResult) string {
+		return buildChairmanPrompt(prompt, results)
+	}
+	return Run(ctx, agents, chairmen, []ChunkPrompt{{Prompt: prompt}}, buildChairman, Options{
+		Stage1Timeout: timeouts.Stage1,
+		Stage2Timeout: timeouts.Stage2,
+		Rounds:        max(rounds, 1),
+		RoundTimeout:  timeouts.Round,
+		RoundsChairman: func(all [][]AgentResult) string {
+			return BuildRoundsChairmanPrompt(prompt, all)
+		},
+	})
+}
+
+// countSucceeded counts the results without an error.
+func countSucceeded(results []AgentResult) int {
+	n := 0
+	for _, r := range results {
+		if r.Err == nil {
+			n++
+		}
+	}
+	return n
+}
+
+// RunConsensusCritique runs stage 1, then a single cross-critique pass in
+// which every agent reviews all stage 1 outputs, then chairman synthesis over
+// both. Unlike debate, agents see the full analyses rather than summaries.
+// The critique pass is skipped when fewer than two analyses succeeded.
+func RunConsensusCritique(ctx context.Context, agents, chairmen []Agent,
+	stage1Prompt, question string,
+	buildChairman func(analyses, critiques []AgentResult) string,
+	stage1Timeout, critiqueTimeout, stage2Timeout int) (*ConsensusResult, error) {
+
+	var available []Agent
+	for _, a := range agents {
+		if a.Available() {
+			available = append(available, a)
+		}
+	}
+	if len(available) == 0 {
+		return nil, fmt.Errorf("no agents available")
+	}
+	runID := bus.NewID()
+
+	// Stage 1
+	fmt.Fprintf(os.Stderr, "Run ID: %s\n", runID)
+	fmt.Fprintf(os.Stderr, "Stage 1: Launching parallel agent analysis...\n")
+	ctx1, cancel1 := context.WithTimeout(ctx, time.Duration(stage1Timeout)*time.Second)
+	defer cancel1()
+	stage1Results := runStage1WithPrompt(ctx1, available, stage1Prompt)
+
+	succeeded := countSucceeded(stage1Results)
+	if succeeded == 0 {
+		return nil, fmt.Errorf("all agents failed in Stage 1")
+	}
+
+	// Stage 1.5: Cross-critique
+	var critiques []AgentResult
+	if succeeded < 2 {
+		fmt.Fprintf(os.Stderr, "  Skipping critique: need at least 2 successful Stage 1 results, got %d\n", succeeded)
+	} else {
+		fmt.Fprintf(os.Stderr, "\n  Stage 1.5: Cross-critique (%d agents)...\n", len(available))
+		ctxC, cancelC := context.WithTimeout(ctx, time.Duration(critiqueTimeout)*time.Second)
+		defer cancelC()
+		critiques = runStage1WithPrompt(ctxC, available, BuildCritiquePrompt(question, stage1Results))
+		for _, r := range critiques {
+			if r.Err != nil {
+				fmt.Fprintf(os.Stderr, "  %s: CRITIQUE FAILED (%v)\n", r.Agent, r.Err)
+			} else {
+				fmt.Fprintf(os.Stderr, "  %s: CRITIQUE SUCCESS\n", r.Agent)
+			}
+		}
+	}
+
+	// Stage 2
+	fmt.Fprintf(os.Stderr, "\nStage 2: Chairman synthesis...\n")
+	ctx2, cancel2 := context.WithTimeout(ctx, time.Duration(stage2Timeout)*time.Second)
+	defer cancel2()
+
+	chairmanResult, err := RunStage2(ctx2, chairmen, buildChairman(stage1Results, critiques))
+	if err != nil {
+		return nil, fmt.Errorf("stage 2: %w", err)
+	}
+
+	return &ConsensusResult{
+		RunID:           runID,
+		Stage1Results:   stage1Results,
+		Critiques:       critiques,
+		ChairmanName:    chairmanResult.Agent,
+		ChairmanOutput:  chairmanResult.Output,
+		AgentsSucceeded: succeeded,
+		Agreement:       MeasureAgreement(stage1Results, len(available)),
 	}, nil
 }
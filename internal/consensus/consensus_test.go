@@ -2,9 +2,15 @@ package consensus
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/signalnine/conclave/internal/bus"
 )
 
 type mockAgent struct {
@@ -80,6 +86,51 @@ func TestRunStage1_Timeout(t *testing.T) {
 	}
 }
 
+func TestRunStage1WithTimeouts(t *testing.T) {
+	agents := []Agent{
+		&mockAgent{name: "Cloud", available: true, response: "fast", delay: 200 * time.Millisecond},
+		&mockAgent{name: "Local", available: true, response: "slow", delay: 200 * time.Millisecond},
+	}
+	results := RunStage1WithTimeouts(context.Background(), agents, map[string]time.Duration{"Cloud": 20 * time.Millisecond})
+	if ErrorKindOf(results[0].Err) != KindTimeout {
+		t.Errorf("Cloud err = %v, want its own timeout", results[0].Err)
+	}
+	if results[1].Err != nil {
+		t.Errorf("Local should succeed without a timeout of its own: %v", results[1].Err)
+	}
+}
+
+func TestRunAgentTimeouts(t *testing.T) {
+	agents := []Agent{
+		&mockAgent{name: "Cloud", available: true, response: "cloud", delay: 1500 * time.Millisecond},
+		&mockAgent{name: "Local", available: true, response: "local", delay: 1500 * time.Millisecond},
+	}
+	chairman := &mockAgent{name: "Chair", available: true, response: "synthesis"}
+	opts := Options{Stage1Timeout: 1, AgentTimeouts: map[string]int{"Local": 3}}
+	result, err := Run(context.Background(), agents, []Agent{chairman}, []ChunkPrompt{{Prompt: "q"}}, func([]AgentResult) string { return "chair" }, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Stage1Results[1].Err != nil {
+		t.Errorf("Local should outlast the stage 1 timeout: %v", result.Stage1Results[1].Err)
+	}
+	if got := result.TimedOut(); len(got) != 1 || got[0] != "Cloud" {
+		t.Errorf("TimedOut() = %v, want [Cloud]", got)
+	}
+}
+
+func TestAgentTimeoutsDefault(t *testing.T) {
+	agents := []Agent{&mockAgent{name: "A"}, &mockAgent{name: "B"}}
+	timeouts, stage := agentTimeouts(agents, 60, nil)
+	if timeouts != nil || stage != time.Minute {
+		t.Errorf("without overrides got %v, %s; want nil, 1m0s", timeouts, stage)
+	}
+	timeouts, stage = agentTimeouts(agents, 60, map[string]int{"B": 30, "Absent": 600})
+	if timeouts["A"] != time.Minute || timeouts["B"] != 30*time.Second || stage != time.Minute {
+		t.Errorf("with overrides got %v, %s", timeouts, stage)
+	}
+}
+
 func TestRunStage2_FirstChairmanSucceeds(t *testing.T) {
 	chairmen := []Agent{
 		&mockAgent{name: "Chair", available: true, response: "synthesis"},
@@ -303,3 +354,694 @@ func TestRunConsensusWithDebateNoAvailableAgents(t *testing.T) {
 		t.Error("expected error with no available agents")
 	}
 }
+
+func TestRunConsensusChunked(t *testing.T) {
+	agents := []Agent{
+		&mockAgent{name: "A", available: true, response: "review A"},
+		&mockAgent{name: "B", available: true, err: fmt.Errorf("fail")},
+	}
+	chairmen := []Agent{
+		&mockAgent{name: "Chair", available: true, response: "merged"},
+	}
+	prompts := []ChunkPrompt{
+		{Label: "big.go (hunk 1 of 2)", Prompt: "p1"},
+		{Label: "big.go (hunk 2 of 2)", Prompt: "p2"},
+	}
+
+	var chairmanInput []AgentResult
+	build := func(results []AgentResult) string {
+		chairmanInput = results
+		return "synthesize"
+	}
+
+	result, err := RunConsensusChunked(context.Background(), agents, chairmen, prompts, build, 60, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chairmanInput) != 4 {
+		t.Fatalf("chairman got %d results, want 4 (2 agents x 2 chunks)", len(chairmanInput))
+	}
+	if chairmanInput[0].Chunk != "big.go (hunk 1 of 2)" || chairmanInput[2].Chunk != "big.go (hunk 2 of 2)" {
+		t.Errorf("results not ordered by chunk: %+v", chairmanInput)
+	}
+	if result.AgentsSucceeded != 1 {
+		t.Errorf("AgentsSucceeded = %d, want 1", result.AgentsSucceeded)
+	}
+	if result.ChairmanOutput != "merged" {
+		t.Errorf("output = %q", result.ChairmanOutput)
+	}
+}
+
+func TestBuildChunkedCodeReviewChairmanPrompt(t *testing.T) {
+	results := []AgentResult{
+		{Agent: "A", Chunk: "big.go (hunk 1 of 2)", Output: "issue in first hunk"},
+		{Agent: "B", Chunk: "big.go (hunk 1 of 2)", Err: fmt.Errorf("fail")},
+		{Agent: "A", Chunk: "big.go (hunk 2 of 2)", Output: "issue in second hunk"},
+	}
+	prompt := BuildChunkedCodeReviewChairmanPrompt("desc", "big.go\n", results)
+	if !strings.Contains(prompt, "## Part: big.go (hunk 1 of 2)") || !strings.Contains(prompt, "## Part: big.go (hunk 2 of 2)") {
+		t.Error("should group reviews by chunk")
+	}
+	if !strings.Contains(prompt, "issue in second hunk") {
+		t.Error("should include chunk reviews")
+	}
+	if strings.Contains(prompt, "--- B Review ---") {
+		t.Error("should omit failed reviews")
+	}
+}
+
+// flakyAgent fails its first `failures` calls, then succeeds.
+type flakyAgent struct {
+	name     string
+	failures int
+	calls    atomic.Int32
+}
+
+func (f *flakyAgent) Name() string    { return f.name }
+func (f *flakyAgent) Available() bool { return true }
+func (f *flakyAgent) Run(ctx context.Context, prompt string) (string, error) {
+	if int(f.calls.Add(1)) <= f.failures {
+		return "", fmt.Errorf("transient error")
+	}
+	return "resp-" + f.name, nil
+}
+
+func TestRetryBudget(t *testing.T) {
+	b := NewRetryBudget(2)
+	if !b.Take() || !b.Take() {
+		t.Fatal("budget of 2 should allow two retries")
+	}
+	if b.Take() {
+		t.Error("exhausted budget should refuse")
+	}
+	if b.Remaining() != 0 || b.Total() != 2 {
+		t.Errorf("remaining = %d, total = %d", b.Remaining(), b.Total())
+	}
+
+	var nilBudget *RetryBudget
+	if nilBudget.Take() {
+		t.Error("nil budget should allow no retries")
+	}
+}
+
+func TestRunStage1RetriesWithinBudget(t *testing.T) {
+	flaky := &flakyAgent{name: "A", failures: 1}
+	prompts := []ChunkPrompt{{Prompt: "p"}}
+	results := runStage1Chunks(context.Background(), []Agent{flaky}, prompts, retrier{budget: NewRetryBudget(1)}, nil, nil, nil)
+	if results[0].Err != nil {
+		t.Errorf("flaky agent should succeed on retry: %v", results[0].Err)
+	}
+	if flaky.calls.Load() != 2 {
+		t.Errorf("calls = %d, want 2", flaky.calls.Load())
+	}
+}
+
+func TestRunStage1NoBudgetNoRetry(t *testing.T) {
+	flaky := &flakyAgent{name: "A", failures: 1}
+	results := runStage1Chunks(context.Background(), []Agent{flaky}, []ChunkPrompt{{Prompt: "p"}}, retrier{}, nil, nil, nil)
+	if results[0].Err == nil {
+		t.Error("without a budget the failure should stand")
+	}
+	if flaky.calls.Load() != 1 {
+		t.Errorf("calls = %d, want 1", flaky.calls.Load())
+	}
+}
+
+func TestWriteStage1Failures(t *testing.T) {
+	ok := &mockAgent{name: "A", available: true, response: "fine"}
+	unauthorized := &mockAgent{name: "B", available: true, err: newAgentError("B", 401, fmt.Errorf("HTTP 401:\nbad key"))}
+	flaky := &flakyAgent{name: "C", failures: 3}
+	results := runStage1Chunks(context.Background(), []Agent{ok, unauthorized, flaky}, []ChunkPrompt{{Prompt: "p"}}, retrier{budget: NewRetryBudget(2)}, nil, nil, nil)
+	if results[2].Retries != 2 {
+		t.Fatalf("C retries = %d, want 2", results[2].Retries)
+	}
+
+	var buf strings.Builder
+	WriteStage1Failures(&buf, results)
+	out := buf.String()
+	for _, want := range []string{
+		"## Stage 1 Failures",
+		"- **B**: `auth`, no retries: HTTP 401: bad key",
+		"- **C**: `other`, after 2 retries: transient error",
+		"**Reduced coverage:** the synthesis draws on 1 of 3 agents.",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "**A**") {
+		t.Errorf("succeeded agent listed:\n%s", out)
+	}
+
+	buf.Reset()
+	WriteStage1Failures(&buf, results[:1])
+	if buf.Len() != 0 {
+		t.Errorf("no failures should write nothing, got %q", buf.String())
+	}
+}
+
+func TestWriteStage1FailuresChunkOnly(t *testing.T) {
+	results := []AgentResult{
+		{Agent: "A", Chunk: "a.go", Output: "ok"},
+		{Agent: "A", Chunk: "b.go", Err: asAgentError("A", fmt.Errorf("boom")), Retries: 1},
+	}
+	var buf strings.Builder
+	WriteStage1Failures(&buf, results)
+	out := buf.String()
+	if !strings.Contains(out, "- **A [b.go]**: `other`, after 1 retry: boom") {
+		t.Errorf("chunk failure not listed:\n%s", out)
+	}
+	if !strings.Contains(out, "1 of 2 stage 1 results are missing") {
+		t.Errorf("chunk coverage note missing:\n%s", out)
+	}
+}
+
+func TestRunRetryBudgetExhaustedAcrossStages(t *testing.T) {
+	// Stage 1: A needs one retry, B needs one retry -> budget of 2 is spent
+	agents := []Agent{
+		&flakyAgent{name: "A", failures: 1},
+		&flakyAgent{name: "B", failures: 1},
+	}
+	// Stage 2: primary fails; fallback would need a retry that is no longer available
+	fallback := &mockAgent{name: "Fallback", available: true, response: "synthesis"}
+	chairmen := []Agent{
+		&mockAgent{name: "Primary", available: true, err: fmt.Errorf("fail")},
+		fallback,
+	}
+	build := func([]AgentResult) string { return "synthesize" }
+
+	_, err := Run(context.Background(), agents, chairmen, []ChunkPrompt{{Prompt: "p"}}, build,
+		Options{Stage1Timeout: 60, Stage2Timeout: 60, Retries: 2})
+	if err == nil {
+		t.Fatal("expected failure once the shared budget is exhausted")
+	}
+	if !strings.Contains(err.Error(), "budget exhausted") {
+		t.Errorf("error = %v, want budget exhausted", err)
+	}
+
+	// With one more retry the fallback chairman gets its chance
+	agents = []Agent{
+		&flakyAgent{name: "A", failures: 1},
+		&flakyAgent{name: "B", failures: 1},
+	}
+	result, err := Run(context.Background(), agents, chairmen, []ChunkPrompt{{Prompt: "p"}}, build,
+		Options{Stage1Timeout: 60, Stage2Timeout: 60, Retries: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ChairmanName != "Fallback" {
+		t.Errorf("chairman = %q, want Fallback", result.ChairmanName)
+	}
+}
+
+func TestPinChairman(t *testing.T) {
+	agents := []Agent{
+		&mockAgent{name: "Claude", available: true, response: "from claude"},
+		&mockAgent{name: "Gemini", available: true, response: "from gemini"},
+		&mockAgent{name: "Codex", available: true, response: "from codex"},
+	}
+	chairmen, err := PinChairman(agents, "gemini")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chairmen[0].Name() != "Gemini" || len(chairmen) != 3 {
+		t.Fatalf("order = %v", chairmen)
+	}
+
+	result, err := RunStage2(context.Background(), chairmen, "prompt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Agent != "Gemini" {
+		t.Errorf("chairman = %q, want Gemini", result.Agent)
+	}
+}
+
+func TestPinChairmanUnknown(t *testing.T) {
+	agents := []Agent{&mockAgent{name: "Claude", available: true}}
+	if _, err := PinChairman(agents, "Llama"); err == nil {
+		t.Error("expected error for chairman not in roster")
+	}
+	if got, _ := PinChairman(agents, ""); len(got) != 1 {
+		t.Error("empty name should keep roster")
+	}
+}
+
+type recordingAgent struct {
+	mockAgent
+	prompt string
+}
+
+func (r *recordingAgent) Run(ctx context.Context, prompt string) (string, error) {
+	r.prompt = prompt
+	return r.mockAgent.Run(ctx, prompt)
+}
+
+func TestRunEscalatesToFastChairmanOnTimeout(t *testing.T) {
+	long := strings.Repeat("A sentence of analysis. ", 20)
+	agents := []Agent{
+		&mockAgent{name: "A", available: true, response: long},
+		&mockAgent{name: "B", available: true, response: long},
+	}
+	slow := &mockAgent{name: "Slow", available: true, response: "too late", delay: 5 * time.Second}
+	fast := &recordingAgent{mockAgent: mockAgent{name: "Fast", available: true, response: "quick synthesis"}}
+
+	var fullPrompt string
+	build := func(results []AgentResult) string {
+		p := buildChairmanPrompt("q", results)
+		if fullPrompt == "" {
+			fullPrompt = p
+		}
+		return p
+	}
+	opts := Options{Stage1Timeout: 5, Stage2Timeout: 1, FastChairman: fast, FastTimeout: 5}
+	result, err := Run(context.Background(), agents, []Agent{slow}, []ChunkPrompt{{Prompt: "q"}}, build, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Escalated || result.ChairmanName != "Fast" || result.ChairmanOutput != "quick synthesis" {
+		t.Errorf("result = %+v", result)
+	}
+	if len(fast.prompt) >= len(fullPrompt) {
+		t.Errorf("fast chairman prompt should be shorter (%d vs %d)", len(fast.prompt), len(fullPrompt))
+	}
+}
+
+func TestRunNoEscalationWithoutFastChairman(t *testing.T) {
+	agents := []Agent{&mockAgent{name: "A", available: true, response: "ok"}}
+	slow := &mockAgent{name: "Slow", available: true, response: "late", delay: 5 * time.Second}
+	_, err := Run(context.Background(), agents, []Agent{slow}, []ChunkPrompt{{Prompt: "q"}},
+		func(r []AgentResult) string { return "p" }, Options{Stage1Timeout: 5, Stage2Timeout: 1})
+	if err == nil {
+		t.Error("expected stage 2 timeout error")
+	}
+}
+
+func TestRunNoEscalationOnNonTimeoutFailure(t *testing.T) {
+	agents := []Agent{&mockAgent{name: "A", available: true, response: "ok"}}
+	broken := &mockAgent{name: "Broken", available: true, err: fmt.Errorf("500")}
+	fast := &mockAgent{name: "Fast", available: true, response: "quick"}
+	_, err := Run(context.Background(), agents, []Agent{broken}, []ChunkPrompt{{Prompt: "q"}},
+		func(r []AgentResult) string { return "p" }, Options{FastChairman: fast})
+	if err == nil {
+		t.Error("fast chairman should only step in on a stage 2 timeout")
+	}
+}
+
+func TestRunReturnsStage1WhenChairmanFails(t *testing.T) {
+	agents := []Agent{
+		&mockAgent{name: "A", available: true, response: "analysis A"},
+		&mockAgent{name: "B", available: true, err: fmt.Errorf("down")},
+	}
+	chair := []Agent{&mockAgent{name: "Chair", available: true, err: fmt.Errorf("overloaded")}}
+	result, err := Run(context.Background(), agents, chair, []ChunkPrompt{{Prompt: "q"}},
+		func(r []AgentResult) string { return "p" }, Options{})
+	if err == nil || !strings.Contains(err.Error(), "stage 2 failed") {
+		t.Fatalf("err = %v, want a stage 2 failure", err)
+	}
+	if result == nil {
+		t.Fatal("result should carry the stage 1 work")
+	}
+	if len(result.Stage1Results) != 2 || result.Stage1Results[0].Output != "analysis A" || result.AgentsSucceeded != 1 {
+		t.Errorf("stage 1 = %+v, succeeded %d", result.Stage1Results, result.AgentsSucceeded)
+	}
+	if result.ChairmanOutput != "" || result.ChairmanName != "" || result.RunID == "" {
+		t.Errorf("result = %+v, want a run ID and no synthesis", result)
+	}
+}
+
+func TestRunPublishesProgressWithRunID(t *testing.T) {
+	b := bus.NewChannelBus()
+	defer b.Close()
+	events, _ := b.Subscribe(ProgressTopic)
+
+	agents := []Agent{
+		&mockAgent{name: "A", available: true, response: "ok"},
+		&mockAgent{name: "B", available: true, err: fmt.Errorf("down")},
+	}
+	chair := []Agent{&mockAgent{name: "Chair", available: true, response: "synthesis"}}
+	result, err := Run(context.Background(), agents, chair, []ChunkPrompt{{Prompt: "q"}},
+		func(r []AgentResult) string { return "p" }, Options{Bus: b})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.RunID == "" {
+		t.Fatal("result should carry a run ID")
+	}
+
+	var types []string
+	for len(events) > 0 {
+		env := <-events
+		var ev ProgressEvent
+		if err := json.Unmarshal(env.Payload, &ev); err != nil {
+			t.Fatal(err)
+		}
+		if ev.RunID != result.RunID {
+			t.Errorf("%s event run ID = %q, want %q", env.Type, ev.RunID, result.RunID)
+		}
+		types = append(types, env.Type)
+	}
+	want := []string{EventStart, EventAgent, EventAgent, EventChairman, EventDone}
+	if strings.Join(types, ",") != strings.Join(want, ",") {
+		t.Errorf("events = %v, want %v", types, want)
+	}
+}
+
+func TestRunKeepsCallerRunID(t *testing.T) {
+	agents := []Agent{&mockAgent{name: "A", available: true, response: "ok"}}
+	result, err := Run(context.Background(), agents, agents, []ChunkPrompt{{Prompt: "q"}},
+		func(r []AgentResult) string { return "p" }, Options{RunID: "run-42"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.RunID != "run-42" {
+		t.Errorf("RunID = %q", result.RunID)
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent writers.
+type syncBuffer struct {
+	mu sync.Mutex
+	b  strings.Builder
+}
+
+func (s *syncBuffer) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.b.Write(p)
+}
+
+func (s *syncBuffer) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.b.String()
+}
+
+func TestRunPrintsStage1Progress(t *testing.T) {
+	agents := []Agent{
+		&mockAgent{name: "A", available: true, response: "a"},
+		&mockAgent{name: "B", available: true, response: "b", delay: 100 * time.Millisecond},
+		&mockAgent{name: "C", available: true, response: "c", delay: 250 * time.Millisecond},
+	}
+	var progress syncBuffer
+	opts := Options{Stage1Timeout: 5, Progress: &progress, ProgressInterval: 20 * time.Millisecond}
+	if _, err := Run(context.Background(), agents, agents, []ChunkPrompt{{Prompt: "q"}},
+		func(r []AgentResult) string { return "p" }, opts); err != nil {
+		t.Fatal(err)
+	}
+	got := progress.String()
+	for _, want := range []string{"1/3 agents done", "2/3 agents done", "elapsed of 5s"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("progress missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "3/3") {
+		t.Errorf("progress printed after stage 1 completed:\n%s", got)
+	}
+
+	// Counts never go backwards as agents complete.
+	last := 0
+	for _, line := range strings.Split(strings.TrimSpace(got), "\n") {
+		var done, total int
+		fmt.Sscanf(strings.TrimSpace(line), "%d/%d", &done, &total)
+		if done < last {
+			t.Errorf("progress went backwards: %q", line)
+		}
+		last = done
+	}
+}
+
+func TestRunProgressDisabledByDefault(t *testing.T) {
+	agents := []Agent{&mockAgent{name: "A", available: true, response: "a", delay: 30 * time.Millisecond}}
+	ticker := startStage1Ticker(nil, time.Millisecond, time.Second, 1, "agents")
+	results := runStage1Chunks(context.Background(), agents, []ChunkPrompt{{Prompt: "q"}}, retrier{}, nil, nil, ticker.completed)
+	ticker.Stop()
+	if ticker.done.Load() != 1 || results[0].Err != nil {
+		t.Errorf("done = %d, results = %+v", ticker.done.Load(), results)
+	}
+}
+
+// promptLogAgent records every prompt it receives, in order.
+type promptLogAgent struct {
+	mockAgent
+	mu      sync.Mutex
+	prompts []string
+}
+
+func (p *promptLogAgent) Run(ctx context.Context, prompt string) (string, error) {
+	p.mu.Lock()
+	p.prompts = append(p.prompts, prompt)
+	p.mu.Unlock()
+	return p.mockAgent.Run(ctx, prompt)
+}
+
+func TestRunConsensusCritique(t *testing.T) {
+	a := &promptLogAgent{mockAgent: mockAgent{name: "A", available: true, response: "A says cache in Redis"}}
+	b := &promptLogAgent{mockAgent: mockAgent{name: "B", available: true, response: "B says cache in memory"}}
+	chair := &recordingAgent{mockAgent: mockAgent{name: "Chair", available: true, response: "Synthesis"}}
+
+	var gotCritiques []AgentResult
+	build := func(analyses, critiques []AgentResult) string {
+		gotCritiques = critiques
+		return BuildCritiqueChairmanPrompt("Where to cache?", analyses, critiques)
+	}
+
+	result, err := RunConsensusCritique(context.Background(), []Agent{a, b}, []Agent{chair},
+		"stage 1 prompt", "Where to cache?", build, 60, 60, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ag := range []*promptLogAgent{a, b} {
+		if len(ag.prompts) != 2 {
+			t.Fatalf("%s ran %d times, want analysis + critique", ag.name, len(ag.prompts))
+		}
+		if ag.prompts[0] != "stage 1 prompt" {
+			t.Errorf("%s first prompt = %q", ag.name, ag.prompts[0])
+		}
+		critique := ag.prompts[1]
+		if !strings.Contains(critique, "A says cache in Redis") || !strings.Contains(critique, "B says cache in memory") {
+			t.Errorf("%s critique prompt should include every stage 1 output:\n%s", ag.name, critique)
+		}
+	}
+	if len(result.Critiques) != 2 || len(gotCritiques) != 2 {
+		t.Fatalf("critiques = %d (builder saw %d), want 2", len(result.Critiques), len(gotCritiques))
+	}
+	if !strings.Contains(chair.prompt, "--- A Critique ---") {
+		t.Errorf("chairman prompt missing critiques:\n%s", chair.prompt)
+	}
+	if result.ChairmanOutput != "Synthesis" || len(result.Stage1Results) != 2 {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestRunConsensusCritiqueSkipsWithOneAnalysis(t *testing.T) {
+	agents := []Agent{
+		&mockAgent{name: "A", available: true, response: "only me"},
+		&mockAgent{name: "B", available: true, err: fmt.Errorf("down")},
+	}
+	chairmen := []Agent{&mockAgent{name: "Chair", available: true, response: "Synthesis"}}
+
+	result, err := RunConsensusCritique(context.Background(), agents, chairmen, "p", "q",
+		func(analyses, critiques []AgentResult) string { return "synthesize" }, 60, 60, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Critiques != nil {
+		t.Errorf("critique should be skipped, got %d critiques", len(result.Critiques))
+	}
+}
+
+func TestRunConsensusRounds(t *testing.T) {
+	a := &promptLogAgent{mockAgent: mockAgent{name: "A", available: true, response: "A says cache in Redis"}}
+	b := &promptLogAgent{mockAgent: mockAgent{name: "B", available: true, response: "B says cache in memory"}}
+	chair := &recordingAgent{mockAgent: mockAgent{name: "Chair", available: true, response: "Synthesis"}}
+
+	result, err := RunConsensusRounds(context.Background(), []Agent{a, b}, []Agent{chair}, "Where to cache?", 3, RoundTimeouts{60, 60, 60})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Rounds) != 3 || len(result.Rounds[2]) != 2 {
+		t.Fatalf("rounds = %+v, want 3 rounds of 2 results", result.Rounds)
+	}
+	for _, ag := range []*promptLogAgent{a, b} {
+		if len(ag.prompts) != 3 || ag.prompts[0] != "Where to cache?" {
+			t.Fatalf("%s prompts = %q, want the question then 2 rebuttal rounds", ag.name, ag.prompts)
+		}
+		if p := ag.prompts[2]; !strings.Contains(p, "Round 3") || !strings.Contains(p, "B says cache in memory") || !strings.Contains(p, ag.name+" (you)") {
+			t.Errorf("%s round 3 prompt should show round 2's answers:\n%s", ag.name, p)
+		}
+	}
+	if !strings.Contains(chair.prompt, "## Round 3") || result.ChairmanOutput != "Synthesis" {
+		t.Errorf("chairman prompt should cover every round:\n%s", chair.prompt)
+	}
+}
+
+func TestRunConsensusRoundsOneRoundIsRunConsensus(t *testing.T) {
+	a := &promptLogAgent{mockAgent: mockAgent{name: "A", available: true, response: "answer"}}
+	chair := &recordingAgent{mockAgent: mockAgent{name: "Chair", available: true, response: "Synthesis"}}
+
+	result, err := RunConsensusRounds(context.Background(), []Agent{a}, []Agent{chair}, "q", 1, RoundTimeouts{Stage1: 60, Stage2: 60})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a.prompts) != 1 || len(result.Rounds) != 1 || len(result.Rounds[0]) != 1 {
+		t.Fatalf("prompts = %q, rounds = %+v; want a single stage 1 round", a.prompts, result.Rounds)
+	}
+	if want := buildChairmanPrompt("q", result.Stage1Results); chair.prompt != want {
+		t.Errorf("chairman prompt = %q, want RunConsensus's %q", chair.prompt, want)
+	}
+}
+
+func TestRunConsensusRoundsStopsWithOneAnswer(t *testing.T) {
+	agents := []Agent{
+		&mockAgent{name: "A", available: true, response: "only me"},
+		&mockAgent{name: "B", available: true, err: fmt.Errorf("down")},
+	}
+	chairmen := []Agent{&mockAgent{name: "Chair", available: true, response: "Synthesis"}}
+
+	result, err := RunConsensusRounds(context.Background(), agents, chairmen, "q", 3, RoundTimeouts{60, 60, 60})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Rounds) != 1 {
+		t.Errorf("rounds = %d, want only stage 1 when nothing can be rebutted", len(result.Rounds))
+	}
+}
+
+func TestRunRoundsUseOptions(t *testing.T) {
+	b := bus.NewChannelBus()
+	defer b.Close()
+	events, _ := b.Subscribe(ProgressTopic)
+	a := &usageAgent{mockAgent: mockAgent{name: "A", available: true, response: "a"}, prompt: 10, completion: 1}
+	c := &usageAgent{mockAgent: mockAgent{name: "C", available: true, response: "c"}, prompt: 20, completion: 2}
+	chair := &recordingAgent{mockAgent: mockAgent{name: "Chair", available: true, response: "synthesis"}}
+
+	result, err := Run(context.Background(), []Agent{a, c}, []Agent{chair}, []ChunkPrompt{{Prompt: "q"}},
+		func([]AgentResult) string { return "mode chairman prompt" }, Options{Rounds: 2, Bus: b})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Rounds) != 2 || a.calls.Load() != 2 || c.calls.Load() != 2 {
+		t.Fatalf("rounds = %d, calls = %d and %d; want 2 rounds of one call each", len(result.Rounds), a.calls.Load(), c.calls.Load())
+	}
+	if want := (TokenUsage{InputTokens: 60, OutputTokens: 6}); result.Tokens != want {
+		t.Errorf("tokens = %+v, want %+v from both rounds", result.Tokens, want)
+	}
+	if !strings.Contains(chair.prompt, "after 2 rounds") || !strings.HasSuffix(chair.prompt, "mode chairman prompt") {
+		t.Errorf("chairman prompt should be the mode's, told about the rounds:\n%s", chair.prompt)
+	}
+	roundEvents := 0
+	for len(events) > 0 {
+		var ev ProgressEvent
+		if err := json.Unmarshal((<-events).Payload, &ev); err != nil {
+			t.Fatal(err)
+		}
+		if ev.Round == 2 {
+			roundEvents++
+		}
+	}
+	if roundEvents != 2 {
+		t.Errorf("round 2 progress events = %d, want one per agent", roundEvents)
+	}
+}
+
+// rateLimitedAgent fails its first call with a rate-limit error carrying
+// retryAfter, then succeeds, recording when each call happened.
+type rateLimitedAgent struct {
+	mockAgent
+	retryAfter time.Duration
+	calls      []time.Time
+}
+
+func (a *rateLimitedAgent) Run(ctx context.Context, prompt string) (string, error) {
+	a.calls = append(a.calls, time.Now())
+	if len(a.calls) == 1 {
+		return "", &AgentError{Agent: a.name, Kind: KindRateLimit, Err: fmt.Errorf("429"), RetryAfter: a.retryAfter}
+	}
+	return "ok", nil
+}
+
+func TestRunStage1HonorsRetryAfter(t *testing.T) {
+	a := &rateLimitedAgent{mockAgent: mockAgent{name: "A", available: true}, retryAfter: 150 * time.Millisecond}
+	results := runStage1Chunks(context.Background(), []Agent{a}, []ChunkPrompt{{Prompt: "p"}}, retrier{budget: NewRetryBudget(1)}, nil, nil, nil)
+	if results[0].Err != nil {
+		t.Fatalf("should succeed after waiting: %v", results[0].Err)
+	}
+	if len(a.calls) != 2 {
+		t.Fatalf("calls = %d, want 2", len(a.calls))
+	}
+	if gap := a.calls[1].Sub(a.calls[0]); gap < a.retryAfter {
+		t.Errorf("retried after %s, want at least Retry-After %s", gap, a.retryAfter)
+	}
+}
+
+func TestRunStage1WithRetry(t *testing.T) {
+	flaky := &flakyAgent{name: "Flaky", failures: 2}
+	steady := &mockAgent{name: "Steady", available: true, response: "ok"}
+	start := time.Now()
+	results := RunStage1WithRetry(context.Background(), []Agent{flaky, steady}, RetryPolicy{Max: 2, Backoff: 20 * time.Millisecond})
+	if results[0].Err != nil || results[0].Attempts() != 3 {
+		t.Errorf("Flaky: err = %v, attempts = %d; want success on attempt 3", results[0].Err, results[0].Attempts())
+	}
+	// Backoff doubles: 20ms, then 40ms.
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Errorf("took %s, want at least the 60ms of backoff", elapsed)
+	}
+	if results[1].Attempts() != 1 {
+		t.Errorf("Steady attempts = %d, want 1", results[1].Attempts())
+	}
+}
+
+func TestRunStage1WithRetryStopsAtMax(t *testing.T) {
+	flaky := &flakyAgent{name: "Flaky", failures: 5}
+	results := RunStage1WithRetry(context.Background(), []Agent{flaky}, RetryPolicy{Max: 2})
+	if results[0].Err == nil || results[0].Attempts() != 3 || flaky.calls.Load() != 3 {
+		t.Errorf("err = %v, attempts = %d, calls = %d; want failure after 3 calls", results[0].Err, results[0].Attempts(), flaky.calls.Load())
+	}
+}
+
+func TestRunStage1WithRetryRespectsDeadline(t *testing.T) {
+	flaky := &flakyAgent{name: "Flaky", failures: 5}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	results := RunStage1WithRetry(ctx, []Agent{flaky}, RetryPolicy{Max: 10, Backoff: 30 * time.Millisecond})
+	if elapsed := time.Since(start); elapsed > 200*time.Millisecond {
+		t.Errorf("took %s, want retries to stop at the 50ms deadline", elapsed)
+	}
+	if results[0].Err == nil {
+		t.Error("should fail once the deadline passes")
+	}
+}
+
+func TestRetrierPolicyAndBudget(t *testing.T) {
+	budget := NewRetryBudget(1)
+	r := retrier{budget: budget, policy: RetryPolicy{Max: 3}}
+	if !r.allow(0) {
+		t.Fatal("first retry should be allowed")
+	}
+	if r.allow(1) {
+		t.Error("exhausted budget should veto the policy")
+	}
+	capped := retrier{budget: NewRetryBudget(5), policy: RetryPolicy{Max: 1}}
+	capped.allow(0)
+	if capped.allow(1) || capped.budget.Remaining() != 4 {
+		t.Errorf("policy cap should stop retries without spending budget, remaining = %d", capped.budget.Remaining())
+	}
+	if (retrier{}).allow(0) {
+		t.Error("no budget and no policy should retry nothing")
+	}
+}
+
+func TestRetryDelayCappedByDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := &AgentError{Kind: KindRateLimit, Err: fmt.Errorf("429"), RetryAfter: time.Hour}
+	if d := retryDelay(ctx, err); d > 50*time.Millisecond {
+		t.Errorf("retryDelay = %s, want capped at the 50ms deadline", d)
+	}
+	if d := retryDelay(context.Background(), fmt.Errorf("plain")); d != 0 {
+		t.Errorf("retryDelay without Retry-After = %s, want 0", d)
+	}
+}
@@ -0,0 +1,180 @@
+package consensus
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultChunkThreshold is the per-file diff size (bytes) above which a file
+// is reviewed hunk-by-hunk in separate stage 1 prompts.
+const DefaultChunkThreshold = 20000
+
+// DiffHunk is a single "@@" hunk from a unified diff.
+type DiffHunk struct {
+	Header string // the "@@ -a,b +c,d @@" line
+	Body   string // header line plus all hunk lines, newline-terminated
+}
+
+// FileDiff is the portion of a unified diff that belongs to one file.
+type FileDiff struct {
+	Path   string
+	Header string // "diff --git" through "+++" lines, newline-terminated
+	Hunks  []DiffHunk
+}
+
+// Size returns the byte length of the file's diff.
+func (f FileDiff) Size() int {
+	n := len(f.Header)
+	for _, h := range f.Hunks {
+		n += len(h.Body)
+	}
+	return n
+}
+
+// String reassembles the file's diff.
+func (f FileDiff) String() string {
+	var b strings.Builder
+	b.WriteString(f.Header)
+	for _, h := range f.Hunks {
+		b.WriteString(h.Body)
+	}
+	return b.String()
+}
+
+// DiffChunk is one unit of stage 1 review work.
+type DiffChunk struct {
+	Label string // human-readable scope, e.g. "main.go (hunks 1-3 of 7)"
+	Path  string // file path, empty when the chunk groups several small files
+	Diff  string
+}
+
+// ParseUnifiedDiff splits a unified diff (as produced by git diff) into
+// per-file sections and their hunks. Lines before the first file header are
+// ignored.
+func ParseUnifiedDiff(diff string) []FileDiff {
+	var files []FileDiff
+	var cur *FileDiff
+	var hunk *strings.Builder
+	var hunkHeader string
+
+	flushHunk := func() {
+		if cur != nil && hunk != nil {
+			cur.Hunks = append(cur.Hunks, DiffHunk{Header: hunkHeader, Body: hunk.String()})
+		}
+		hunk = nil
+	}
+	flushFile := func() {
+		flushHunk()
+		if cur != nil {
+			files = append(files, *cur)
+		}
+		cur = nil
+	}
+
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		if line == "" {
+			continue
+		}
+		if !strings.HasSuffix(line, "\n") {
+			line += "\n"
+		}
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flushFile()
+			cur = &FileDiff{Path: diffGitPath(line), Header: line}
+		case cur == nil:
+			continue
+		case strings.HasPrefix(line, "@@"):
+			flushHunk()
+			hunk = &strings.Builder{}
+			hunkHeader = strings.TrimRight(line, "\n")
+			hunk.WriteString(line)
+		case hunk != nil:
+			hunk.WriteString(line)
+		default:
+			if strings.HasPrefix(line, "+++ ") {
+				if p := strings.TrimSpace(strings.TrimPrefix(line, "+++ ")); p != "/dev/null" {
+					cur.Path = strings.TrimPrefix(p, "b/")
+				}
+			}
+			cur.Header += line
+		}
+	}
+	flushFile()
+	return files
+}
+
+// diffGitPath extracts the destination path from a "diff --git a/x b/x" line.
+func diffGitPath(line string) string {
+	line = strings.TrimSpace(strings.TrimPrefix(line, "diff --git "))
+	if i := strings.LastIndex(line, " b/"); i >= 0 {
+		return line[i+3:]
+	}
+	return line
+}
+
+// ChunkDiff splits a diff into review chunks. Files whose diff is at most
+// threshold bytes are grouped into a single chunk; larger files are split at
+// hunk boundaries, packing consecutive hunks while they fit under threshold.
+// A threshold <= 0, or a diff with no oversized files, yields a single chunk
+// holding the whole diff.
+func ChunkDiff(diff string, threshold int) []DiffChunk {
+	files := ParseUnifiedDiff(diff)
+	if threshold <= 0 || !hasOversizedFile(files, threshold) {
+		return []DiffChunk{{Diff: diff}}
+	}
+
+	var chunks []DiffChunk
+	var small strings.Builder
+	for _, f := range files {
+		if f.Size() <= threshold || len(f.Hunks) < 2 {
+			small.WriteString(f.String())
+			continue
+		}
+		chunks = append(chunks, splitFileHunks(f, threshold)...)
+	}
+	if small.Len() > 0 {
+		chunks = append(chunks, DiffChunk{Label: "remaining files", Diff: small.String()})
+	}
+	return chunks
+}
+
+func hasOversizedFile(files []FileDiff, threshold int) bool {
+	for _, f := range files {
+		if f.Size() > threshold && len(f.Hunks) > 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// splitFileHunks packs a file's hunks into chunks of at most threshold bytes.
+// Each chunk repeats the file header so it reads as a standalone diff.
+func splitFileHunks(f FileDiff, threshold int) []DiffChunk {
+	var chunks []DiffChunk
+	total := len(f.Hunks)
+	start := 0
+	for start < total {
+		end := start + 1
+		size := len(f.Header) + len(f.Hunks[start].Body)
+		for end < total && size+len(f.Hunks[end].Body) <= threshold {
+			size += len(f.Hunks[end].Body)
+			end++
+		}
+		part := FileDiff{Path: f.Path, Header: f.Header, Hunks: f.Hunks[start:end]}
+		chunks = append(chunks, DiffChunk{
+			Label: hunkLabel(f.Path, start+1, end, total),
+			Path:  f.Path,
+			Diff:  part.String(),
+		})
+		start = end
+	}
+	return chunks
+}
+
+func hunkLabel(path string, first, last, total int) string {
+	if first == last {
+		return fmt.Sprintf("%s (hunk %d of %d)", path, first, total)
+	}
+	return fmt.Sprintf("%s (hunks %d-%d of %d)", path, first, last, total)
+}
@@ -0,0 +1,142 @@
+package consensus
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const sampleDiff = `diff --git a/big.go b/big.go
+index 1111111..2222222 100644
+--- a/big.go
++++ b/big.go
+@@ -1,3 +1,3 @@ package big
+-old one
++new one
+ context
+@@ -20,2 +20,3 @@ func f() {
+ keep
++added line
+@@ -40,2 +41,2 @@ func g() {
+-removed
++replaced
+diff --git a/small.go b/small.go
+index 3333333..4444444 100644
+--- a/small.go
++++ b/small.go
+@@ -1 +1 @@
+-a
++b
+`
+
+func TestParseUnifiedDiff(t *testing.T) {
+	files := ParseUnifiedDiff(sampleDiff)
+	if len(files) != 2 {
+		t.Fatalf("got %d files, want 2", len(files))
+	}
+	if files[0].Path != "big.go" || files[1].Path != "small.go" {
+		t.Errorf("paths = %q, %q", files[0].Path, files[1].Path)
+	}
+	if len(files[0].Hunks) != 3 {
+		t.Fatalf("big.go hunks = %d, want 3", len(files[0].Hunks))
+	}
+	if files[0].Hunks[1].Header != "@@ -20,2 +20,3 @@ func f() {" {
+		t.Errorf("hunk header = %q", files[0].Hunks[1].Header)
+	}
+	if !strings.Contains(files[0].Hunks[1].Body, "+added line") {
+		t.Errorf("hunk body missing content: %q", files[0].Hunks[1].Body)
+	}
+	if !strings.HasPrefix(files[0].Header, "diff --git a/big.go b/big.go") || !strings.Contains(files[0].Header, "+++ b/big.go") {
+		t.Errorf("file header = %q", files[0].Header)
+	}
+}
+
+func TestParseUnifiedDiffRoundTrip(t *testing.T) {
+	var b strings.Builder
+	for _, f := range ParseUnifiedDiff(sampleDiff) {
+		b.WriteString(f.String())
+	}
+	if b.String() != sampleDiff {
+		t.Errorf("reassembled diff differs:\n%s", b.String())
+	}
+}
+
+func TestParseUnifiedDiffNewFile(t *testing.T) {
+	diff := "diff --git a/new.go b/new.go\nnew file mode 100644\n--- /dev/null\n+++ b/new.go\n@@ -0,0 +1 @@\n+hello\n"
+	files := ParseUnifiedDiff(diff)
+	if len(files) != 1 || files[0].Path != "new.go" {
+		t.Fatalf("files = %+v", files)
+	}
+}
+
+func TestChunkDiffSmallKeepsSinglePrompt(t *testing.T) {
+	chunks := ChunkDiff(sampleDiff, 10000)
+	if len(chunks) != 1 {
+		t.Fatalf("got %d chunks, want 1", len(chunks))
+	}
+	if chunks[0].Label != "" || chunks[0].Diff != sampleDiff {
+		t.Errorf("single chunk should carry the whole diff unlabeled: %+v", chunks[0])
+	}
+}
+
+func TestChunkDiffDisabled(t *testing.T) {
+	if chunks := ChunkDiff(sampleDiff, 0); len(chunks) != 1 {
+		t.Errorf("threshold 0 should disable chunking, got %d chunks", len(chunks))
+	}
+}
+
+func TestChunkDiffSplitsLargeFile(t *testing.T) {
+	// Threshold below big.go's size but large enough for header + one hunk
+	big := ParseUnifiedDiff(sampleDiff)[0]
+	threshold := len(big.Header) + len(big.Hunks[0].Body)
+	chunks := ChunkDiff(sampleDiff, threshold)
+
+	// 3 single-hunk chunks for big.go plus one for the small file
+	if len(chunks) != 4 {
+		t.Fatalf("got %d chunks, want 4: %+v", len(chunks), chunks)
+	}
+	for i, c := range chunks[:3] {
+		if c.Path != "big.go" {
+			t.Errorf("chunk %d path = %q", i, c.Path)
+		}
+		want := fmt.Sprintf("big.go (hunk %d of 3)", i+1)
+		if c.Label != want {
+			t.Errorf("chunk %d label = %q, want %q", i, c.Label, want)
+		}
+		if !strings.HasPrefix(c.Diff, "diff --git a/big.go b/big.go") {
+			t.Errorf("chunk %d should repeat the file header", i)
+		}
+		if strings.Count(c.Diff, "@@ -") != 1 {
+			t.Errorf("chunk %d should hold exactly one hunk:\n%s", i, c.Diff)
+		}
+	}
+	if chunks[3].Path != "" || !strings.Contains(chunks[3].Diff, "small.go") {
+		t.Errorf("small files chunk = %+v", chunks[3])
+	}
+}
+
+func TestChunkDiffPacksHunks(t *testing.T) {
+	big := ParseUnifiedDiff(sampleDiff)[0]
+	threshold := len(big.Header) + len(big.Hunks[0].Body) + len(big.Hunks[1].Body)
+	chunks := ChunkDiff(sampleDiff, threshold)
+	if len(chunks) != 3 {
+		t.Fatalf("got %d chunks, want 3: %+v", len(chunks), chunks)
+	}
+	if chunks[0].Label != "big.go (hunks 1-2 of 3)" {
+		t.Errorf("label = %q", chunks[0].Label)
+	}
+	if chunks[1].Label != "big.go (hunk 3 of 3)" {
+		t.Errorf("label = %q", chunks[1].Label)
+	}
+}
+
+func TestBuildCodeReviewChunkPrompt(t *testing.T) {
+	chunk := DiffChunk{Label: "big.go (hunk 2 of 3)", Path: "big.go", Diff: "+added line"}
+	prompt := BuildCodeReviewChunkPrompt("desc", "big.go\n", "", chunk)
+	if !strings.Contains(prompt, "**Review Scope:** big.go (hunk 2 of 3)") {
+		t.Error("should include the chunk scope")
+	}
+	if strings.Index(prompt, "Review Scope") > strings.Index(prompt, "**Diff:**") {
+		t.Error("scope should come before the diff")
+	}
+}
@@ -0,0 +1,189 @@
+package consensus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrorKind categorizes why an agent call failed.
+type ErrorKind string
+
+const (
+	KindTimeout   ErrorKind = "timeout"
+	KindAuth      ErrorKind = "auth"
+	KindRateLimit ErrorKind = "ratelimit"
+	KindOther     ErrorKind = "other"
+)
+
+// AgentError is the error type returned by agents and stored in
+// AgentResult.Err, so callers can tell a timeout from an auth failure
+// without string matching.
+type AgentError struct {
+	Agent string
+	Kind  ErrorKind
+	Err   error
+	// RetryAfter is the provider's Retry-After guidance, zero when absent.
+	RetryAfter time.Duration
+}
+
+func (e *AgentError) Error() string { return e.Err.Error() }
+func (e *AgentError) Unwrap() error { return e.Err }
+
+// newAgentError wraps err for agent, classifying it by the HTTP status (0 when
+// no response was received) and the error itself.
+func newAgentError(agent string, status int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &AgentError{Agent: agent, Kind: classifyError(status, err), Err: err}
+}
+
+// responseError is newAgentError for a failed HTTP response, also recording
+// the response's Retry-After header.
+func responseError(agent string, resp *http.Response, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &AgentError{
+		Agent:      agent,
+		Kind:       classifyError(resp.StatusCode, err),
+		Err:        err,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}
+}
+
+// parseRetryAfter parses a Retry-After value in either delay-seconds or
+// HTTP-date form. Missing, malformed or past values yield zero.
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// RetryAfterOf returns the Retry-After delay carried by an agent error, or
+// zero.
+func RetryAfterOf(err error) time.Duration {
+	var ae *AgentError
+	if errors.As(err, &ae) {
+		return ae.RetryAfter
+	}
+	return 0
+}
+
+// asAgentError returns err as an *AgentError, wrapping it if needed.
+func asAgentError(agent string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var ae *AgentError
+	if errors.As(err, &ae) {
+		return err
+	}
+	return newAgentError(agent, 0, err)
+}
+
+func classifyError(status int, err error) ErrorKind {
+	switch status {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return KindAuth
+	case http.StatusTooManyRequests:
+		return KindRateLimit
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return KindTimeout
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return KindTimeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return KindTimeout
+	}
+	return KindOther
+}
+
+// ErrorKindOf returns the kind of an agent error, or KindOther for errors
+// that are not AgentErrors.
+func ErrorKindOf(err error) ErrorKind {
+	var ae *AgentError
+	if errors.As(err, &ae) {
+		return ae.Kind
+	}
+	return KindOther
+}
+
+// describeFailure formats an error for the per-agent FAILED log line.
+func describeFailure(err error) string {
+	return fmt.Sprintf("FAILED [%s] (%v)", ErrorKindOf(err), err)
+}
+
+// WriteStage1Failures writes a "## Stage 1 Failures" report section listing
+// every failed stage 1 result with its error category and retries, and a
+// note on how much coverage the synthesis lost. It writes nothing when every
+// result succeeded.
+func WriteStage1Failures(w io.Writer, results []AgentResult) {
+	var failed []AgentResult
+	var agents []string
+	ok := make(map[string]bool)
+	for _, r := range results {
+		if _, seen := ok[r.Agent]; !seen {
+			agents = append(agents, r.Agent)
+			ok[r.Agent] = false
+		}
+		if r.Err != nil {
+			failed = append(failed, r)
+		} else {
+			ok[r.Agent] = true
+		}
+	}
+	if len(failed) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "\n## Stage 1 Failures\n\n")
+	for _, r := range failed {
+		name := r.Agent
+		if r.Chunk != "" {
+			name = fmt.Sprintf("%s [%s]", r.Agent, r.Chunk)
+		}
+		retries := "no retries"
+		switch r.Retries {
+		case 0:
+		case 1:
+			retries = "after 1 retry"
+		default:
+			retries = fmt.Sprintf("after %d retries", r.Retries)
+		}
+		detail := strings.Join(strings.Fields(r.Err.Error()), " ")
+		fmt.Fprintf(w, "- **%s**: `%s`, %s: %s\n", name, ErrorKindOf(r.Err), retries, detail)
+	}
+
+	succeeded := 0
+	for _, a := range agents {
+		if ok[a] {
+			succeeded++
+		}
+	}
+	if succeeded < len(agents) {
+		fmt.Fprintf(w, "\n**Reduced coverage:** the synthesis draws on %d of %d agents.\n", succeeded, len(agents))
+	} else {
+		fmt.Fprintf(w, "\n**Reduced coverage:** every agent answered, but %d of %d stage 1 results are missing from the synthesis.\n", len(failed), len(results))
+	}
+}
@@ -0,0 +1,198 @@
+package consensus
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Source is a document a fact check is grounded in.
+type Source struct {
+	Name    string // how prompts and citations refer to it, e.g. "report.md"
+	Content string
+}
+
+// ReadSources reads source files, naming each after its base name (or its
+// path when base names collide).
+func ReadSources(paths []string) ([]Source, error) {
+	counts := make(map[string]int)
+	for _, p := range paths {
+		counts[filepath.Base(p)]++
+	}
+	sources := make([]Source, 0, len(paths))
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("read source: %w", err)
+		}
+		name := filepath.Base(p)
+		if counts[name] > 1 {
+			name = p
+		}
+		sources = append(sources, Source{Name: name, Content: string(data)})
+	}
+	return sources, nil
+}
+
+// Verdict is a fact check's conclusion about a claim.
+type Verdict string
+
+const (
+	VerdictSupported   Verdict = "supported"
+	VerdictUnsupported Verdict = "unsupported"
+	VerdictUncertain   Verdict = "uncertain"
+)
+
+// Citation is a passage quoted from a source in the chairman's verdict.
+// Verified reports whether the passage really appears in the named source.
+type Citation struct {
+	Source   string
+	Passage  string
+	Supports bool // false for a passage that contradicts the claim
+	Verified bool
+}
+
+// FactCheck is the structured outcome of a fact-check run.
+type FactCheck struct {
+	Verdict   Verdict
+	Citations []Citation
+}
+
+// FactCheckBuilder is the fact-check mode: agents judge Question (the claim)
+// strictly against Sources, and the chairman reconciles their findings into
+// a verdict with citations.
+type FactCheckBuilder struct{}
+
+func (FactCheckBuilder) BuildStage1(in PromptInput) []ChunkPrompt {
+	return []ChunkPrompt{{Prompt: BuildFactCheckPrompt(in.Question, in.Context, in.Sources)}}
+}
+
+func (FactCheckBuilder) BuildChairman(in PromptInput, results []AgentResult) string {
+	return BuildFactCheckChairmanPrompt(in.Question, results)
+}
+
+// ParseResult records the verdict and citations of the synthesis.
+func (FactCheckBuilder) ParseResult(in PromptInput, r *ConsensusResult) {
+	r.FactCheck = ParseFactCheck(r.ChairmanOutput, in.Sources)
+}
+
+func writeSources(b *strings.Builder, sources []Source) {
+	b.WriteString("**Sources:**\n\n")
+	for _, s := range sources {
+		fmt.Fprintf(b, "--- Source: %s ---\n%s\n--- End of %s ---\n\n", s.Name, strings.TrimSpace(s.Content), s.Name)
+	}
+}
+
+// BuildFactCheckPrompt creates the stage 1 prompt for checking a claim
+// against the supplied sources only.
+func BuildFactCheckPrompt(claim, context string, sources []Source) string {
+	var b strings.Builder
+	b.WriteString("# Fact Check - Verify a Claim Against Sources\n\n")
+	b.WriteString("**Your Task:** Decide whether the claim below is supported by the sources provided. Use ONLY these sources; do not rely on your training data or outside knowledge. If the sources do not settle the claim, say so.\n\n")
+	fmt.Fprintf(&b, "**Claim:**\n%s\n\n", claim)
+	if context != "" {
+		fmt.Fprintf(&b, "**Context:**\n%s\n\n", context)
+	}
+	writeSources(&b, sources)
+	b.WriteString(`**Instructions:**
+Cite every passage you rely on verbatim, one per line, as: [source name] "exact quoted passage"
+Break the claim into its parts and mark each part SUPPORTED, CONTRADICTED or UNSUPPORTED (no passage addresses it).
+
+**Output Format:**
+
+## Claim Parts
+- <part>: SUPPORTED / CONTRADICTED / UNSUPPORTED, with citations
+
+## Supporting Citations
+- [source] "passage"
+
+## Contradicting Citations
+- [source] "passage"
+
+## Verdict
+SUPPORTED, UNSUPPORTED or UNCERTAIN
+`)
+	return b.String()
+}
+
+// BuildFactCheckChairmanPrompt creates the chairman prompt reconciling the
+// agents' fact checks into one verdict.
+func BuildFactCheckChairmanPrompt(claim string, results []AgentResult) string {
+	var b strings.Builder
+	b.WriteString("You are reconciling independent fact checks of a claim against supplied sources.\n\n")
+	fmt.Fprintf(&b, "**Claim:**\n%s\n\n", claim)
+	b.WriteString("**Fact Checks:**\n\n")
+	for _, r := range results {
+		if r.Err == nil {
+			fmt.Fprintf(&b, "--- %s Fact Check ---\n%s\n\n", r.Agent, r.Output)
+		}
+	}
+	b.WriteString(`**Instructions:**
+Keep only citations quoted verbatim from a source; drop any point an agent supported with outside knowledge. Where agents disagree, prefer the reading the quoted passages support. Answer SUPPORTED only if the sources support every part of the claim, UNSUPPORTED if a source contradicts it or no passage supports it, and UNCERTAIN if the sources are ambiguous or incomplete.
+
+Output format:
+## Verdict
+SUPPORTED, UNSUPPORTED or UNCERTAIN
+## Supporting Citations
+- [source] "passage"
+## Contradicting Citations
+- [source] "passage"
+## Unsupported Parts
+## Reasoning`)
+	return b.String()
+}
+
+var (
+	factVerdictRe = regexp.MustCompile(`(?i)\b(SUPPORTED|UNSUPPORTED|UNCERTAIN)\b`)
+	citationRe    = regexp.MustCompile(`\[([^\]]+)\]\s*["“]([^"”]+)["”]`)
+)
+
+// ParseFactCheck reads the verdict and the citations under "Supporting" and
+// "Contradicting" headings of a fact-check synthesis, checking each quoted
+// passage against its source. A missing or unreadable verdict is uncertain.
+func ParseFactCheck(output string, sources []Source) *FactCheck {
+	fc := &FactCheck{Verdict: VerdictUncertain}
+	section := ""
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "#") {
+			section = strings.ToLower(strings.TrimLeft(trimmed, "# "))
+			continue
+		}
+		switch {
+		case strings.HasPrefix(section, "verdict"):
+			if m := factVerdictRe.FindString(trimmed); m != "" && fc.Verdict == VerdictUncertain {
+				fc.Verdict = Verdict(strings.ToLower(m))
+				section = "" // only the first verdict line counts
+			}
+		case strings.HasPrefix(section, "supporting"), strings.HasPrefix(section, "contradicting"):
+			for _, m := range citationRe.FindAllStringSubmatch(trimmed, -1) {
+				fc.Citations = append(fc.Citations, Citation{
+					Source:   m[1],
+					Passage:  m[2],
+					Supports: strings.HasPrefix(section, "supporting"),
+					Verified: passageInSources(m[1], m[2], sources),
+				})
+			}
+		}
+	}
+	return fc
+}
+
+// passageInSources reports whether passage appears in the named source,
+// ignoring case and whitespace differences.
+func passageInSources(name, passage string, sources []Source) bool {
+	want := normalizeSpace(passage)
+	for _, s := range sources {
+		if strings.EqualFold(s.Name, name) && strings.Contains(normalizeSpace(s.Content), want) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeSpace(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
@@ -0,0 +1,133 @@
+package consensus
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const factCheckSource = `Q3 Operations Report
+
+The migration to the new billing system finished on 14 August.
+Customer-facing downtime during the cutover was 42 minutes.
+`
+
+func TestFactCheckModeVerdict(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ops-report.md")
+	if err := os.WriteFile(path, []byte(factCheckSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sources, err := ReadSources([]string{path})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	agent := &recordingAgent{mockAgent: mockAgent{name: "A", available: true, response: `## Verdict
+UNSUPPORTED
+[ops-report.md] "Customer-facing downtime during the cutover was 42 minutes."`}}
+	chairman := &recordingAgent{mockAgent: mockAgent{name: "Chair", available: true, response: `## Verdict
+UNSUPPORTED
+
+## Supporting Citations
+- [ops-report.md] "The migration to the new billing system finished on 14 August."
+
+## Contradicting Citations
+- [ops-report.md] "Customer-facing downtime during the cutover was 42 minutes."
+- [ops-report.md] "There was no downtime at all."
+
+## Unsupported Parts
+None
+
+## Reasoning
+The report records 42 minutes of downtime.`}}
+
+	b, ok := LookupMode("fact-check")
+	if !ok {
+		t.Fatal("fact-check mode not registered")
+	}
+	in := PromptInput{Question: "The billing migration finished in August with zero downtime.", Sources: sources}
+	result, err := RunConsensusWithBuilder(context.Background(), []Agent{agent}, []Agent{chairman}, b, in, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"Use ONLY these sources", "--- Source: ops-report.md ---", "42 minutes", in.Question} {
+		if !strings.Contains(agent.prompt, want) {
+			t.Errorf("stage 1 prompt missing %q", want)
+		}
+	}
+	if !strings.Contains(chairman.prompt, "--- A Fact Check ---") {
+		t.Errorf("chairman prompt missing the agent's fact check:\n%s", chairman.prompt)
+	}
+
+	fc := result.FactCheck
+	if fc == nil {
+		t.Fatal("FactCheck not captured in the result")
+	}
+	if fc.Verdict != VerdictUnsupported {
+		t.Errorf("Verdict = %q, want unsupported", fc.Verdict)
+	}
+	if len(fc.Citations) != 3 {
+		t.Fatalf("got %d citations, want 3: %+v", len(fc.Citations), fc.Citations)
+	}
+	var supporting, verified, fabricated int
+	for _, c := range fc.Citations {
+		if c.Supports {
+			supporting++
+		}
+		if c.Verified {
+			verified++
+		}
+		if c.Passage == "There was no downtime at all." && !c.Verified {
+			fabricated++
+		}
+	}
+	if supporting != 1 || verified != 2 || fabricated != 1 {
+		t.Errorf("citations = %+v", fc.Citations)
+	}
+}
+
+func TestParseFactCheck(t *testing.T) {
+	sources := []Source{{Name: "a.md", Content: "The sky is   blue\nat noon."}}
+	tests := []struct {
+		output string
+		want   Verdict
+	}{
+		{"## Verdict\nSUPPORTED\n## Reasoning\nunsupported elsewhere", VerdictSupported},
+		{"## Verdict\n**Verdict:** Uncertain", VerdictUncertain},
+		{"no structure at all", VerdictUncertain},
+		{"## Verdict\nUNSUPPORTED", VerdictUnsupported},
+	}
+	for _, tt := range tests {
+		if got := ParseFactCheck(tt.output, sources).Verdict; got != tt.want {
+			t.Errorf("ParseFactCheck(%q) = %q, want %q", tt.output, got, tt.want)
+		}
+	}
+
+	fc := ParseFactCheck("## Supporting Citations\n- [A.md] \"the sky is blue at noon\"\n- [b.md] \"the sky is blue\"", sources)
+	if len(fc.Citations) != 2 || !fc.Citations[0].Verified || fc.Citations[1].Verified {
+		t.Errorf("citations = %+v, want first verified (case/space-insensitive), second not (wrong source)", fc.Citations)
+	}
+}
+
+func TestReadSourcesNamesCollisionsByPath(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "x", "notes.md")
+	b := filepath.Join(dir, "y", "notes.md")
+	for _, p := range []string{a, b} {
+		os.MkdirAll(filepath.Dir(p), 0755)
+		os.WriteFile(p, []byte("content"), 0644)
+	}
+	sources, err := ReadSources([]string{a, b})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sources[0].Name != a || sources[1].Name != b {
+		t.Errorf("names = %q, %q", sources[0].Name, sources[1].Name)
+	}
+	if _, err := ReadSources([]string{filepath.Join(dir, "missing.md")}); err == nil {
+		t.Error("expected an error for a missing source")
+	}
+}
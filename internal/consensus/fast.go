@@ -0,0 +1,80 @@
+package consensus
+
+import (
+	"strings"
+	"sync"
+)
+
+// ConfidenceMarker is the line a stage 1 agent ends its answer with when it
+// is certain, letting a fast run (Options.Fast) skip the other agents and
+// stage 2.
+const ConfidenceMarker = "CONFIDENT"
+
+const confidenceInstruction = "If the question is simple and you are certain your answer is complete and correct, end your response with a line containing only " + ConfidenceMarker + ". Otherwise do not write that word."
+
+// IsConfident reports whether output carries the confidence marker: a line
+// reading CONFIDENT, or a "Confidence: high" line, ignoring case and
+// markdown emphasis.
+func IsConfident(output string) bool {
+	for _, line := range strings.Split(output, "\n") {
+		if isConfidenceLine(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// StripConfidence removes the confidence marker lines from output.
+func StripConfidence(output string) string {
+	lines := strings.Split(output, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if !isConfidenceLine(line) {
+			kept = append(kept, line)
+		}
+	}
+	return strings.TrimSpace(strings.Join(kept, "\n"))
+}
+
+func isConfidenceLine(line string) bool {
+	l := strings.ToLower(strings.Trim(strings.TrimSpace(line), "*_`#. "))
+	if l == strings.ToLower(ConfidenceMarker) {
+		return true
+	}
+	if rest, ok := strings.CutPrefix(l, "confidence:"); ok {
+		return strings.Trim(strings.TrimSpace(rest), "*_`. ") == "high"
+	}
+	return false
+}
+
+// fastPath watches stage 1 for the first successful response and, when it
+// is confident, cancels the rest of stage 1.
+type fastPath struct {
+	mu     sync.Mutex
+	seen   bool
+	answer *AgentResult
+	cancel func()
+}
+
+func (f *fastPath) done(r AgentResult) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.seen || r.Err != nil {
+		return
+	}
+	f.seen = true
+	if IsConfident(r.Output) {
+		f.answer = &AgentResult{Agent: r.Agent, Output: StripConfidence(r.Output)}
+		f.cancel()
+	}
+}
+
+// result returns the confident answer, or nil; a nil fastPath has none.
+func (f *fastPath) result() *AgentResult {
+	if f == nil {
+		return nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.answer
+}
@@ -0,0 +1,89 @@
+package consensus
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunFastShortCircuitsOnConfidentAnswer(t *testing.T) {
+	quick := &recordingAgent{mockAgent: mockAgent{name: "A", available: true, response: "Paris is the capital of France.\n\n**CONFIDENT**"}}
+	agents := []Agent{
+		quick,
+		&mockAgent{name: "B", available: true, response: "slow", delay: 5 * time.Second},
+		&mockAgent{name: "C", available: true, response: "slow", delay: 5 * time.Second},
+	}
+	chairman := &recordingAgent{mockAgent: mockAgent{name: "Chair", available: true, response: "synthesis"}}
+
+	start := time.Now()
+	result, err := Run(context.Background(), agents, []Agent{chairman}, []ChunkPrompt{{Prompt: "capital of France?"}}, func([]AgentResult) string { return "chair" }, Options{Fast: true, Stage1Timeout: 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("took %s, want the slow agents canceled", elapsed)
+	}
+	if !result.ShortCircuited || result.ChairmanName != "A" {
+		t.Errorf("ShortCircuited = %v, ChairmanName = %q; want true, A", result.ShortCircuited, result.ChairmanName)
+	}
+	if result.ChairmanOutput != "Paris is the capital of France." {
+		t.Errorf("ChairmanOutput = %q, want the answer without the marker", result.ChairmanOutput)
+	}
+	if chairman.prompt != "" {
+		t.Error("stage 2 ran despite a confident answer")
+	}
+	if result.AgentsSucceeded != 1 || len(result.Stage1Results) != 3 {
+		t.Errorf("AgentsSucceeded = %d, Stage1Results = %d; want 1, 3", result.AgentsSucceeded, len(result.Stage1Results))
+	}
+	if !strings.Contains(quick.prompt, "end your response with a line containing only CONFIDENT") {
+		t.Errorf("stage 1 prompt lacks the confidence instruction:\n%s", quick.prompt)
+	}
+}
+
+func TestRunFastFallsBackWithoutConfidence(t *testing.T) {
+	agents := []Agent{
+		&mockAgent{name: "A", available: true, response: "maybe Paris"},
+		// Only the first response counts, so a later confident one is synthesized normally.
+		&mockAgent{name: "B", available: true, response: "Paris\nCONFIDENT", delay: 50 * time.Millisecond},
+	}
+	chairman := &mockAgent{name: "Chair", available: true, response: "synthesis"}
+	result, err := Run(context.Background(), agents, []Agent{chairman}, []ChunkPrompt{{Prompt: "q"}}, func([]AgentResult) string { return "chair" }, Options{Fast: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ShortCircuited || result.ChairmanOutput != "synthesis" || result.AgentsSucceeded != 2 {
+		t.Errorf("got ShortCircuited=%v output=%q succeeded=%d, want a full run", result.ShortCircuited, result.ChairmanOutput, result.AgentsSucceeded)
+	}
+}
+
+func TestRunIgnoresConfidenceWithoutFast(t *testing.T) {
+	agents := []Agent{&mockAgent{name: "A", available: true, response: "Paris\nCONFIDENT"}}
+	chairman := &mockAgent{name: "Chair", available: true, response: "synthesis"}
+	result, err := Run(context.Background(), agents, []Agent{chairman}, []ChunkPrompt{{Prompt: "q"}}, func([]AgentResult) string { return "chair" }, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ShortCircuited || result.ChairmanOutput != "synthesis" {
+		t.Errorf("short-circuited without Options.Fast")
+	}
+}
+
+func TestIsConfident(t *testing.T) {
+	tests := []struct {
+		output string
+		want   bool
+	}{
+		{"answer\nCONFIDENT", true},
+		{"answer\n**Confident.**", true},
+		{"answer\nConfidence: High", true},
+		{"answer\nConfidence: medium", false},
+		{"I am not confident about this", false},
+		{"answer", false},
+	}
+	for _, tt := range tests {
+		if got := IsConfident(tt.output); got != tt.want {
+			t.Errorf("IsConfident(%q) = %v, want %v", tt.output, got, tt.want)
+		}
+	}
+}
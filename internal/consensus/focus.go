@@ -0,0 +1,147 @@
+package consensus
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ReviewFocus narrows a code review to one dimension. The zero value is the
+// general review.
+type ReviewFocus string
+
+const (
+	FocusGeneral     ReviewFocus = ""
+	FocusSecurity    ReviewFocus = "security"
+	FocusPerformance ReviewFocus = "performance"
+	FocusCorrectness ReviewFocus = "correctness"
+	FocusStyle       ReviewFocus = "style"
+)
+
+type focusSpec struct {
+	emphasis   string   // what reviewers and the chairman should prioritize
+	categories []string // headings findings are grouped under
+}
+
+var reviewFoci = map[ReviewFocus]focusSpec{
+	FocusSecurity: {
+		emphasis: "security: injection, authentication and authorization flaws, secrets or sensitive data exposure, unsafe deserialization, path traversal, SSRF, and risky dependencies or configuration. Consider how an attacker could reach and abuse each change",
+		categories: []string{
+			"Injection & Input Validation",
+			"Authentication & Authorization",
+			"Secrets & Data Exposure",
+			"Dependencies & Configuration",
+		},
+	},
+	FocusPerformance: {
+		emphasis: "performance: algorithmic complexity, allocations and copies in hot paths, N+1 queries and redundant I/O, lock contention, and unbounded memory or goroutine growth",
+		categories: []string{
+			"Algorithmic Complexity",
+			"Memory & Allocation",
+			"I/O & Queries",
+			"Concurrency & Contention",
+		},
+	},
+	FocusCorrectness: {
+		emphasis: "correctness: logic errors, unhandled edge cases and error paths, race conditions, broken invariants, and behavior that contradicts the description or plan",
+		categories: []string{
+			"Logic Errors",
+			"Edge Cases & Error Handling",
+			"Concurrency & State",
+			"Spec Mismatches",
+		},
+	},
+	FocusStyle: {
+		emphasis: "style and maintainability: naming, readability, consistency with surrounding code, duplication, dead code, and missing or misleading documentation",
+		categories: []string{
+			"Naming & Readability",
+			"Consistency with Codebase",
+			"Duplication & Dead Code",
+			"Documentation",
+		},
+	},
+}
+
+// ParseReviewFocus validates a --focus value. Empty or "general" selects the
+// general review.
+func ParseReviewFocus(s string) (ReviewFocus, error) {
+	f := ReviewFocus(strings.ToLower(strings.TrimSpace(s)))
+	if f == FocusGeneral || f == "general" {
+		return FocusGeneral, nil
+	}
+	if _, ok := reviewFoci[f]; !ok {
+		return "", fmt.Errorf("invalid focus %q: must be one of %s", s, strings.Join(ReviewFoci(), ", "))
+	}
+	return f, nil
+}
+
+// ReviewFoci lists the supported focus names.
+func ReviewFoci() []string {
+	names := make([]string, 0, len(reviewFoci))
+	for f := range reviewFoci {
+		names = append(names, string(f))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FocusCodeReviewPrompt adds the focus framing to a stage 1 code review
+// prompt. The general focus returns the prompt unchanged.
+func FocusCodeReviewPrompt(prompt string, focus ReviewFocus) string {
+	spec, ok := reviewFoci[focus]
+	if !ok {
+		return prompt
+	}
+	framing := fmt.Sprintf("**Review Focus:** This is a targeted %s review. Prioritize %s. Mention other issues only if they are critical.\n\n", focus, spec.emphasis)
+	return strings.Replace(prompt, "**Diff:**", framing+"**Diff:**", 1)
+}
+
+// BuildFocusedCodeReviewChairmanPrompt builds the chairman prompt for a
+// targeted review, asking for findings grouped by the focus's categories.
+// The general focus falls back to BuildCodeReviewChairmanPrompt.
+func BuildFocusedCodeReviewChairmanPrompt(focus ReviewFocus, description, modifiedFiles string, results []AgentResult) string {
+	spec, ok := reviewFoci[focus]
+	if !ok {
+		return BuildCodeReviewChairmanPrompt(description, modifiedFiles, results)
+	}
+	succeeded := 0
+	for _, r := range results {
+		if r.Err == nil {
+			succeeded++
+		}
+	}
+	title := strings.ToUpper(string(focus[:1])) + string(focus[1:])
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s Review Consensus - Stage 2 Chairman Synthesis\n\n", title)
+	fmt.Fprintf(&b, "**Your Task:** Compile a consensus %s review from multiple independent reviewers. Prioritize %s.\n\n", focus, spec.emphasis)
+	b.WriteString("**CRITICAL:** Report every " + string(focus) + " finding mentioned by any reviewer. If reviewers disagree about a finding or its severity, report the disagreement explicitly.\n\n")
+	fmt.Fprintf(&b, "**Change Description:** %s\n\n", description)
+	fmt.Fprintf(&b, "**Modified Files:**\n%s\n\n", modifiedFiles)
+	fmt.Fprintf(&b, "**Reviews Received (%d of %d):**\n\n", succeeded, len(results))
+
+	for _, r := range results {
+		if r.Err != nil {
+			continue
+		}
+		if r.Chunk != "" {
+			fmt.Fprintf(&b, "--- %s Review (%s) ---\n%s\n\n", r.Agent, r.Chunk, r.Output)
+		} else {
+			fmt.Fprintf(&b, "--- %s Review ---\n%s\n\n", r.Agent, r.Output)
+		}
+	}
+
+	b.WriteString("**Instructions:**\nGroup findings under these categories, writing 'None' for a category without findings. Tag each finding with its severity (Critical, High, Medium, Low) and how many reviewers raised it:\n\n")
+	for _, c := range spec.categories {
+		fmt.Fprintf(&b, "## %s\n- [Findings]\n\n", c)
+	}
+	b.WriteString("## Other Critical Issues\n[Critical issues outside the " + string(focus) + " focus, or 'None']\n\n")
+	fmt.Fprintf(&b, `## Final Recommendation
+- If Critical or High findings exist: "Address %[1]s findings before merging"
+- If only Medium or Low findings: "Review %[1]s concerns"
+- If no findings: "No %[1]s issues found"
+
+Be direct and specific: name the file, the problem and the fix.
+`, focus)
+	return b.String()
+}
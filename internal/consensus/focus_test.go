@@ -0,0 +1,61 @@
+package consensus
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseReviewFocus(t *testing.T) {
+	for in, want := range map[string]ReviewFocus{"": FocusGeneral, "general": FocusGeneral, "Security": FocusSecurity, " style ": FocusStyle} {
+		if got, err := ParseReviewFocus(in); err != nil || got != want {
+			t.Errorf("ParseReviewFocus(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseReviewFocus("vibes"); err == nil || !strings.Contains(err.Error(), "security") {
+		t.Errorf("invalid focus error = %v, want it to list the choices", err)
+	}
+}
+
+func TestSecurityFocusPrompts(t *testing.T) {
+	stage1 := FocusCodeReviewPrompt(BuildCodeReviewPrompt("Add login", "+code", "auth.go\n", ""), FocusSecurity)
+	if !strings.Contains(stage1, "**Review Focus:** This is a targeted security review") {
+		t.Errorf("stage 1 prompt missing security framing:\n%s", stage1)
+	}
+	if strings.Index(stage1, "**Review Focus:**") > strings.Index(stage1, "**Diff:**") {
+		t.Error("focus framing should come before the diff")
+	}
+
+	results := []AgentResult{
+		{Agent: "Claude", Output: "SQL built with fmt.Sprintf"},
+		{Agent: "Gemini", Err: errors.New("timeout")},
+	}
+	chairman := BuildFocusedCodeReviewChairmanPrompt(FocusSecurity, "Add login", "auth.go\n", results)
+	for _, want := range []string{
+		"# Security Review Consensus",
+		"## Injection & Input Validation",
+		"## Authentication & Authorization",
+		"## Secrets & Data Exposure",
+		"**Reviews Received (1 of 2):**",
+		"SQL built with fmt.Sprintf",
+		`"Address security findings before merging"`,
+	} {
+		if !strings.Contains(chairman, want) {
+			t.Errorf("security chairman prompt missing %q", want)
+		}
+	}
+	if strings.Contains(chairman, "## High Priority - Multiple Reviewers Agree") {
+		t.Error("security chairman prompt should replace the general tiers")
+	}
+}
+
+func TestGeneralFocusIsUnchanged(t *testing.T) {
+	prompt := BuildCodeReviewPrompt("d", "+x", "f.go\n", "")
+	if FocusCodeReviewPrompt(prompt, FocusGeneral) != prompt {
+		t.Error("general focus should not change the stage 1 prompt")
+	}
+	results := []AgentResult{{Agent: "Claude", Output: "ok"}}
+	if BuildFocusedCodeReviewChairmanPrompt(FocusGeneral, "d", "f.go\n", results) != BuildCodeReviewChairmanPrompt("d", "f.go\n", results) {
+		t.Error("general focus should use the standard chairman prompt")
+	}
+}
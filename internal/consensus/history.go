@@ -0,0 +1,144 @@
+package consensus
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// historyIndexFile is the append-only JSONL index of labeled runs.
+const historyIndexFile = "index.jsonl"
+
+// ErrNoLabeledRun is returned when no stored run carries a label.
+var ErrNoLabeledRun = errors.New("no run with that label")
+
+var labelPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// RunRecord describes a labeled consensus run kept in the history.
+type RunRecord struct {
+	Label  string    `json:"label"`
+	RunID  string    `json:"run_id"`
+	Mode   string    `json:"mode"`
+	Date   time.Time `json:"date"`
+	Report string    `json:"report"` // path relative to the history directory
+}
+
+// History stores labeled consensus reports under <dir>/<label>/ with an
+// index of their metadata, a lightweight run history without a database.
+type History struct {
+	dir string
+}
+
+// DefaultHistoryDir is where labeled runs are kept when CONCLAVE_HISTORY_DIR
+// is unset.
+func DefaultHistoryDir() string {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		base = os.TempDir()
+	}
+	return filepath.Join(base, "conclave", "runs")
+}
+
+// OpenHistory opens (creating if needed) a run history in dir.
+func OpenHistory(dir string) (*History, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("create history dir: %w", err)
+	}
+	return &History{dir: dir}, nil
+}
+
+// ValidateLabel rejects labels that are unsafe as a directory name.
+func ValidateLabel(label string) error {
+	if !labelPattern.MatchString(label) {
+		return fmt.Errorf("invalid label %q: use letters, digits, '.', '_' and '-', starting with a letter or digit", label)
+	}
+	return nil
+}
+
+// Save copies the report at reportPath into the history under rec.Label and
+// indexes it. Reusing a label keeps earlier runs; Latest returns the newest.
+func (h *History) Save(rec RunRecord, reportPath string) (RunRecord, error) {
+	if err := ValidateLabel(rec.Label); err != nil {
+		return rec, err
+	}
+	data, err := os.ReadFile(reportPath)
+	if err != nil {
+		return rec, fmt.Errorf("read report: %w", err)
+	}
+	if rec.Date.IsZero() {
+		rec.Date = time.Now()
+	}
+	name := rec.RunID
+	if name == "" {
+		name = rec.Date.Format("20060102-150405")
+	}
+	rec.Report = filepath.Join(rec.Label, name+".md")
+	if err := os.MkdirAll(filepath.Join(h.dir, rec.Label), 0755); err != nil {
+		return rec, fmt.Errorf("create label dir: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(h.dir, rec.Report), data, 0644); err != nil {
+		return rec, fmt.Errorf("store report: %w", err)
+	}
+
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return rec, err
+	}
+	f, err := os.OpenFile(filepath.Join(h.dir, historyIndexFile), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return rec, fmt.Errorf("open history index: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return rec, fmt.Errorf("write history index: %w", err)
+	}
+	return rec, nil
+}
+
+// List returns the indexed runs, oldest first. Malformed index lines are
+// skipped.
+func (h *History) List() ([]RunRecord, error) {
+	f, err := os.Open(filepath.Join(h.dir, historyIndexFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []RunRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var rec RunRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil || rec.Label == "" {
+			continue
+		}
+		records = append(records, rec)
+	}
+	return records, scanner.Err()
+}
+
+// Latest returns the newest run stored under label.
+func (h *History) Latest(label string) (RunRecord, error) {
+	records, err := h.List()
+	if err != nil {
+		return RunRecord{}, err
+	}
+	for i := len(records) - 1; i >= 0; i-- {
+		if records[i].Label == label {
+			return records[i], nil
+		}
+	}
+	return RunRecord{}, fmt.Errorf("%w %q", ErrNoLabeledRun, label)
+}
+
+// ReadReport returns the stored report of rec.
+func (h *History) ReadReport(rec RunRecord) ([]byte, error) {
+	return os.ReadFile(filepath.Join(h.dir, rec.Report))
+}
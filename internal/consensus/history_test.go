@@ -0,0 +1,73 @@
+package consensus
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeReport(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "consensus-1.md")
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestHistorySaveAndRetrieve(t *testing.T) {
+	h, err := OpenHistory(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.Save(RunRecord{Label: "auth-refactor", RunID: "run-1", Mode: "code-review"}, writeReport(t, "# round 1")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.Save(RunRecord{Label: "other", RunID: "run-2", Mode: "general-prompt"}, writeReport(t, "# other")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.Save(RunRecord{Label: "auth-refactor", RunID: "run-3", Mode: "code-review"}, writeReport(t, "# round 2")); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := h.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 || records[0].RunID != "run-1" || records[2].RunID != "run-3" {
+		t.Fatalf("List() = %+v, want 3 runs oldest first", records)
+	}
+	if records[0].Mode != "code-review" || records[0].Date.IsZero() {
+		t.Errorf("metadata not recorded: %+v", records[0])
+	}
+
+	rec, err := h.Latest("auth-refactor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := h.ReadReport(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(report) != "# round 2" {
+		t.Errorf("Latest report = %q, want the newest run under the label", report)
+	}
+
+	if _, err := h.Latest("missing"); !errors.Is(err, ErrNoLabeledRun) {
+		t.Errorf("Latest(missing) err = %v, want ErrNoLabeledRun", err)
+	}
+}
+
+func TestValidateLabel(t *testing.T) {
+	for _, label := range []string{"auth-refactor-round2", "v1.2_review"} {
+		if err := ValidateLabel(label); err != nil {
+			t.Errorf("ValidateLabel(%q) = %v", label, err)
+		}
+	}
+	for _, label := range []string{"", "../escape", ".hidden", "has space", "a/b"} {
+		if err := ValidateLabel(label); err == nil {
+			t.Errorf("ValidateLabel(%q) accepted an unsafe label", label)
+		}
+	}
+}
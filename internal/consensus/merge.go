@@ -0,0 +1,85 @@
+package consensus
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// selectChairmen returns the first n available chairmen.
+func selectChairmen(chairmen []Agent, n int) []Agent {
+	var selected []Agent
+	for _, c := range chairmen {
+		if !c.Available() {
+			continue
+		}
+		selected = append(selected, c)
+		if len(selected) == n {
+			break
+		}
+	}
+	return selected
+}
+
+// runMultiStage2 has the first n available chairmen synthesize in parallel,
+// then a merger reconciles their syntheses into the final one. The merger is
+// opts.Merger, or the first chairman that produced a synthesis; the other
+// chairmen are its fallback. A single synthesis is used as is. All
+// intermediate syntheses are returned alongside the final result.
+func runMultiStage2(ctx, parent context.Context, chairmen []Agent, prompt string, opts Options, timeout int, budget *RetryBudget) (AgentResult, []AgentResult, error) {
+	selected := selectChairmen(chairmen, opts.Chairmen)
+	if len(selected) == 0 {
+		return AgentResult{}, nil, fmt.Errorf("all chairman agents failed")
+	}
+	names := make([]string, len(selected))
+	for i, c := range selected {
+		names[i] = c.Name()
+	}
+	fmt.Fprintf(os.Stderr, "  Synthesizing with %d chairmen: %s\n", len(selected), strings.Join(names, ", "))
+
+	syntheses := runStage1WithPrompt(ctx, selected, prompt)
+	var ok []AgentResult
+	for i, s := range syntheses {
+		if s.Err == nil && s.Output == "" {
+			syntheses[i].Err = asAgentError(s.Agent, fmt.Errorf("empty response"))
+		}
+		if syntheses[i].Err != nil {
+			fmt.Fprintf(os.Stderr, "  %s: %s\n", s.Agent, describeFailure(syntheses[i].Err))
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "  %s: synthesized\n", s.Agent)
+		ok = append(ok, s)
+	}
+	switch len(ok) {
+	case 0:
+		return AgentResult{}, syntheses, fmt.Errorf("all chairman agents failed")
+	case 1:
+		fmt.Fprintf(os.Stderr, "  Only %s produced a synthesis; skipping merge\n", ok[0].Agent)
+		if opts.StreamTo != nil {
+			io.WriteString(opts.StreamTo, ok[0].Output)
+		}
+		return ok[0], syntheses, nil
+	}
+
+	merger := opts.Merger
+	if merger == nil {
+		merger = findAgent(ok[0].Agent, selected...)
+	}
+	mergers := []Agent{merger}
+	for _, c := range selected {
+		if c.Name() != merger.Name() {
+			mergers = append(mergers, c)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "  Merging %d syntheses with %s (%ds timeout)...\n", len(ok), merger.Name(), timeout)
+	ctx3, cancel := context.WithTimeout(parent, time.Duration(timeout)*time.Second)
+	defer cancel()
+	merged, err := runStage2(ctx3, mergers, BuildMergePrompt(ok), budget, opts.StreamTo)
+	if err != nil {
+		return AgentResult{}, syntheses, fmt.Errorf("merge: %w", err)
+	}
+	return merged, syntheses, nil
+}
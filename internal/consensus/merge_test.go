@@ -0,0 +1,105 @@
+package consensus
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// echoMerger returns the merge prompt it receives, standing in for a merger
+// that carries every synthesis into its output.
+type echoMerger struct {
+	recordingAgent
+}
+
+func (e *echoMerger) Run(ctx context.Context, prompt string) (string, error) {
+	e.prompt = prompt
+	return "MERGED\n" + prompt, nil
+}
+
+func TestRunMergesMultipleChairmen(t *testing.T) {
+	agents := []Agent{
+		&mockAgent{name: "A", available: true, response: "analysis a"},
+		&mockAgent{name: "B", available: true, response: "analysis b"},
+	}
+	chairmen := []Agent{
+		&mockAgent{name: "Claude", available: true, response: "synthesis from claude: race in Publish"},
+		&mockAgent{name: "Gemini", available: true, response: "synthesis from gemini: missing fsync"},
+		&mockAgent{name: "Codex", available: true, response: "unused third chairman"},
+	}
+	merger := &echoMerger{recordingAgent{mockAgent: mockAgent{name: "Merger", available: true}}}
+
+	result, err := Run(context.Background(), agents, chairmen, []ChunkPrompt{{Prompt: "q"}},
+		func(r []AgentResult) string { return "synthesize" }, Options{Chairmen: 2, Merger: merger})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ChairmanName != "Merger" {
+		t.Errorf("ChairmanName = %q, want Merger", result.ChairmanName)
+	}
+	for _, want := range []string{"race in Publish", "missing fsync"} {
+		if !strings.Contains(result.ChairmanOutput, want) {
+			t.Errorf("merged output missing %q:\n%s", want, result.ChairmanOutput)
+		}
+	}
+	if strings.Contains(merger.prompt, "unused third chairman") {
+		t.Error("only the first 2 chairmen should synthesize")
+	}
+	if got := agentNames(result.Syntheses); strings.Join(got, ",") != "Claude,Gemini" {
+		t.Errorf("Syntheses = %v, want [Claude Gemini]", got)
+	}
+}
+
+func TestRunMultipleChairmenSkipsMergeWithOneSynthesis(t *testing.T) {
+	agents := []Agent{&mockAgent{name: "A", available: true, response: "analysis"}}
+	chairmen := []Agent{
+		&mockAgent{name: "Claude", available: true, err: errors.New("boom")},
+		&mockAgent{name: "Gemini", available: true, response: "only synthesis"},
+	}
+	merger := &echoMerger{recordingAgent{mockAgent: mockAgent{name: "Merger", available: true}}}
+
+	result, err := Run(context.Background(), agents, chairmen, []ChunkPrompt{{Prompt: "q"}},
+		func(r []AgentResult) string { return "synthesize" }, Options{Chairmen: 2, Merger: merger})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ChairmanName != "Gemini" || result.ChairmanOutput != "only synthesis" {
+		t.Errorf("got %s: %q, want Gemini's synthesis unmerged", result.ChairmanName, result.ChairmanOutput)
+	}
+	if merger.prompt != "" {
+		t.Error("merger should not run with a single synthesis")
+	}
+	if len(result.Syntheses) != 2 || result.Syntheses[0].Err == nil {
+		t.Errorf("Syntheses should record both chairmen, got %+v", result.Syntheses)
+	}
+}
+
+func TestRunMultipleChairmenAllFail(t *testing.T) {
+	agents := []Agent{&mockAgent{name: "A", available: true, response: "analysis"}}
+	chairmen := []Agent{
+		&mockAgent{name: "Claude", available: true, err: errors.New("boom")},
+		&mockAgent{name: "Gemini", available: true, response: ""},
+	}
+	_, err := Run(context.Background(), agents, chairmen, []ChunkPrompt{{Prompt: "q"}},
+		func(r []AgentResult) string { return "synthesize" }, Options{Chairmen: 2})
+	if err == nil {
+		t.Fatal("expected an error when no chairman synthesizes")
+	}
+}
+
+func TestBuildMergePrompt(t *testing.T) {
+	p := BuildMergePrompt([]AgentResult{
+		{Agent: "Claude", Output: "first"},
+		{Agent: "Gemini", Err: errors.New("failed")},
+		{Agent: "Codex", Output: "second"},
+	})
+	for _, want := range []string{"--- Claude Synthesis ---\nfirst", "--- Codex Synthesis ---\nsecond"} {
+		if !strings.Contains(p, want) {
+			t.Errorf("prompt missing %q", want)
+		}
+	}
+	if strings.Contains(p, "Gemini") {
+		t.Error("failed syntheses should be left out")
+	}
+}
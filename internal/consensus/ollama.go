@@ -0,0 +1,126 @@
+package consensus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/signalnine/conclave/internal/config"
+)
+
+// --- Ollama (local) ---
+
+// ollamaProbeTimeout bounds the reachability check behind Available, so an
+// unreachable endpoint costs a run at most this long.
+const ollamaProbeTimeout = 2 * time.Second
+
+// OllamaAgent runs prompts on a local Ollama server, so a consensus run can
+// include a model that never sends the code off the machine.
+type OllamaAgent struct {
+	cfg *config.Config
+
+	probeOnce sync.Once
+	probeErr  error
+
+	inputTokens  atomic.Int64
+	outputTokens atomic.Int64
+}
+
+func NewOllamaAgent(cfg *config.Config) *OllamaAgent {
+	return &OllamaAgent{cfg: cfg}
+}
+
+func (a *OllamaAgent) Name() string  { return "Ollama" }
+func (a *OllamaAgent) Model() string { return a.cfg.OllamaModel }
+
+// Available reports whether a model is configured and the endpoint answers.
+// The endpoint is probed once, on the first call.
+func (a *OllamaAgent) Available() bool { return a.UnavailableReason() == "" }
+
+func (a *OllamaAgent) UnavailableReason() string {
+	if a.cfg.OllamaModel == "" {
+		return "OLLAMA_MODEL not set"
+	}
+	a.probeOnce.Do(func() { a.probeErr = a.probe() })
+	if a.probeErr != nil {
+		return fmt.Sprintf("Ollama not reachable at %s: %v", a.baseURL(), a.probeErr)
+	}
+	return ""
+}
+
+func (a *OllamaAgent) baseURL() string { return strings.TrimRight(a.cfg.OllamaBaseURL, "/") }
+
+// probe checks the endpoint by listing its local models.
+func (a *OllamaAgent) probe() error {
+	ctx, cancel := context.WithTimeout(context.Background(), ollamaProbeTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", a.baseURL()+"/api/tags", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (a *OllamaAgent) Usage() TokenUsage {
+	return TokenUsage{InputTokens: a.inputTokens.Load(), OutputTokens: a.outputTokens.Load()}
+}
+
+// Run sends prompt to /api/chat without streaming. Local inference is slow,
+// so the request is bound to ctx: the agent's stage deadline aborts it.
+func (a *OllamaAgent) Run(ctx context.Context, prompt string) (string, error) {
+	data, _ := json.Marshal(map[string]any{
+		"model":    a.cfg.OllamaModel,
+		"messages": []map[string]any{{"role": "user", "content": prompt}},
+		"stream":   false,
+	})
+	req, err := http.NewRequestWithContext(ctx, "POST", a.baseURL()+"/api/chat", bytes.NewReader(data))
+	if err != nil {
+		return "", newAgentError(a.Name(), 0, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", newAgentError(a.Name(), 0, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", newAgentError(a.Name(), resp.StatusCode, err)
+	}
+	var result struct {
+		Message         struct{ Content string } `json:"message"`
+		PromptEvalCount int64                    `json:"prompt_eval_count"`
+		EvalCount       int64                    `json:"eval_count"`
+		Error           string                   `json:"error"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", responseError(a.Name(), resp, fmt.Errorf("parse response: %w", err))
+	}
+	if result.Error != "" {
+		return "", responseError(a.Name(), resp, fmt.Errorf("API error: %s", result.Error))
+	}
+	a.inputTokens.Add(result.PromptEvalCount)
+	a.outputTokens.Add(result.EvalCount)
+	recordUsage(ctx, result.PromptEvalCount, result.EvalCount)
+	if result.Message.Content == "" {
+		return "", responseError(a.Name(), resp, fmt.Errorf("empty response"))
+	}
+	return result.Message.Content, nil
+}
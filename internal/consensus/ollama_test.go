@@ -0,0 +1,92 @@
+package consensus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/signalnine/conclave/internal/config"
+)
+
+func TestOllamaAgent_Run(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["model"] != "llama3.1" || body["stream"] != false {
+			t.Errorf("body = %v", body)
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"message":           map[string]any{"role": "assistant", "content": "local response"},
+			"prompt_eval_count": 30,
+			"eval_count":        8,
+		})
+	}))
+	defer srv.Close()
+
+	a := NewOllamaAgent(&config.Config{OllamaBaseURL: srv.URL + "/", OllamaModel: "llama3.1"})
+	got, err := a.Run(context.Background(), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "local response" {
+		t.Errorf("got %q", got)
+	}
+	if u := a.Usage(); u.InputTokens != 30 || u.OutputTokens != 8 {
+		t.Errorf("usage = %+v, want 30 in / 8 out", u)
+	}
+}
+
+func TestOllamaAgent_Available(t *testing.T) {
+	probes := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/tags" {
+			probes++
+		}
+		w.Write([]byte(`{"models":[]}`))
+	}))
+	defer srv.Close()
+
+	if a := NewOllamaAgent(&config.Config{OllamaBaseURL: srv.URL}); a.Available() || a.UnavailableReason() != "OLLAMA_MODEL not set" {
+		t.Errorf("without a model: Available = %v, reason %q", a.Available(), a.UnavailableReason())
+	}
+	a := NewOllamaAgent(&config.Config{OllamaBaseURL: srv.URL, OllamaModel: "llama3.1"})
+	if !a.Available() || !a.Available() {
+		t.Errorf("reachable endpoint should be available: %s", a.UnavailableReason())
+	}
+	if probes != 1 {
+		t.Errorf("probes = %d, want the endpoint checked once", probes)
+	}
+
+	srv.Close()
+	down := NewOllamaAgent(&config.Config{OllamaBaseURL: srv.URL, OllamaModel: "llama3.1"})
+	if down.Available() || !strings.Contains(down.UnavailableReason(), "not reachable") {
+		t.Errorf("unreachable endpoint: Available = %v, reason %q", down.Available(), down.UnavailableReason())
+	}
+}
+
+func TestOllamaAgent_Deadline(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := NewOllamaAgent(&config.Config{OllamaBaseURL: srv.URL, OllamaModel: "llama3.1"}).Run(ctx, "test")
+	if ErrorKindOf(err) != KindTimeout {
+		t.Errorf("want timeout, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("Run took %v, should stop at the deadline", time.Since(start))
+	}
+}
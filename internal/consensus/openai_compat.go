@@ -0,0 +1,152 @@
+package consensus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/signalnine/conclave/internal/config"
+)
+
+// TokenUsage is the number of prompt and completion tokens an agent has
+// consumed, as reported by its API.
+type TokenUsage struct {
+	InputTokens  int64
+	OutputTokens int64
+}
+
+// UsageReporter is implemented by agents that track token usage across
+// calls. Usage is cumulative and safe to read while calls are in flight.
+type UsageReporter interface {
+	Usage() TokenUsage
+}
+
+// --- OpenAI-compatible (together, groq, vLLM, ...) ---
+
+// OpenAICompatAgent talks to any endpoint implementing the OpenAI
+// /chat/completions API. The key is read from apiKeyEnv (or its _FILE or
+// _CMD source) at call time so .env-loaded keys work.
+type OpenAICompatAgent struct {
+	name      string
+	baseURL   string
+	model     string
+	apiKeyEnv string
+	key       func() string // resolves the key instead of apiKeyEnv, if set
+	maxTokens int
+
+	inputTokens  atomic.Int64
+	outputTokens atomic.Int64
+}
+
+// NewOpenAICompatAgent returns an agent for the chat completions endpoint
+// under baseURL (e.g. https://api.groq.com/openai/v1). The agent is named
+// after its model; use WithName to override.
+func NewOpenAICompatAgent(cfg *config.Config, baseURL, model, apiKeyEnv string) *OpenAICompatAgent {
+	return &OpenAICompatAgent{
+		name:      model,
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		model:     model,
+		apiKeyEnv: apiKeyEnv,
+		maxTokens: cfg.OpenAIMaxTokens,
+	}
+}
+
+// WithName sets the agent's display name and returns it.
+func (a *OpenAICompatAgent) WithName(name string) *OpenAICompatAgent {
+	a.name = name
+	return a
+}
+
+func (a *OpenAICompatAgent) Name() string    { return a.name }
+func (a *OpenAICompatAgent) Model() string   { return a.model }
+func (a *OpenAICompatAgent) Available() bool { return a.apiKey() != "" }
+
+func (a *OpenAICompatAgent) UnavailableReason() string {
+	return config.KeyUnavailableReason(a.apiKeyEnv, config.NewKeySource(a.apiKeyEnv))
+}
+
+func (a *OpenAICompatAgent) apiKey() string {
+	if a.key != nil {
+		return a.key()
+	}
+	key, _ := config.LookupKey(a.apiKeyEnv)
+	return key
+}
+
+func (a *OpenAICompatAgent) Usage() TokenUsage {
+	return TokenUsage{InputTokens: a.inputTokens.Load(), OutputTokens: a.outputTokens.Load()}
+}
+
+func (a *OpenAICompatAgent) Run(ctx context.Context, prompt string) (string, error) {
+	body := map[string]any{
+		"model":    a.model,
+		"messages": []map[string]any{{"role": "user", "content": prompt}},
+	}
+	if a.maxTokens > 0 {
+		body["max_tokens"] = a.maxTokens
+	}
+	data, _ := json.Marshal(body)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", a.baseURL+"/chat/completions", bytes.NewReader(data))
+	if err != nil {
+		return "", newAgentError(a.Name(), 0, err)
+	}
+	req.Header.Set("Authorization", "Bearer "+a.apiKey())
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", newAgentError(a.Name(), 0, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+	var result struct {
+		Choices []struct {
+			Message struct{ Content string } `json:"message"`
+		} `json:"choices"`
+		Usage struct {
+			PromptTokens     int64 `json:"prompt_tokens"`
+			CompletionTokens int64 `json:"completion_tokens"`
+		} `json:"usage"`
+		Error *struct{ Message string } `json:"error"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", responseError(a.Name(), resp, fmt.Errorf("parse response: %w", err))
+	}
+	if result.Error != nil {
+		return "", responseError(a.Name(), resp, fmt.Errorf("API error: %s", result.Error.Message))
+	}
+	a.inputTokens.Add(result.Usage.PromptTokens)
+	a.outputTokens.Add(result.Usage.CompletionTokens)
+	recordUsage(ctx, result.Usage.PromptTokens, result.Usage.CompletionTokens)
+	if len(result.Choices) == 0 || result.Choices[0].Message.Content == "" {
+		return "", responseError(a.Name(), resp, fmt.Errorf("empty response"))
+	}
+	return result.Choices[0].Message.Content, nil
+}
+
+// --- OpenAI (GPT) ---
+
+// NewOpenAIAgent returns the "GPT" agent: OpenAI's chat completions API with
+// cfg.OpenAIChatModel, using the same key and base URL as the Codex agent.
+func NewOpenAIAgent(cfg *config.Config) *OpenAICompatAgent {
+	a := NewOpenAICompatAgent(cfg, strings.TrimRight(cfg.OpenAIBaseURL, "/")+"/v1", cfg.OpenAIChatModel, "OPENAI_API_KEY").WithName("GPT")
+	a.key = cfg.OpenAIKey
+	return a
+}
+
+// ExtraAgents builds the OpenAI-compatible agents configured in
+// cfg.ExtraAgents.
+func ExtraAgents(cfg *config.Config) []Agent {
+	var agents []Agent
+	for _, e := range cfg.ExtraAgents {
+		agents = append(agents, NewOpenAICompatAgent(cfg, e.BaseURL, e.Model, e.APIKeyEnv).WithName(e.Name))
+	}
+	return agents
+}
@@ -0,0 +1,154 @@
+package consensus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/signalnine/conclave/internal/config"
+)
+
+func TestOpenAICompatAgent_Run(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/openai/v1/chat/completions" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer gq-test" {
+			t.Error("missing auth header")
+		}
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["model"] != "llama-test" {
+			t.Errorf("model = %v", body["model"])
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{"message": map[string]any{"content": "compat response"}}},
+			"usage":   map[string]any{"prompt_tokens": 12, "completion_tokens": 5},
+		})
+	}))
+	defer srv.Close()
+
+	t.Setenv("TEST_COMPAT_KEY", "gq-test")
+	a := NewOpenAICompatAgent(&config.Config{OpenAIMaxTokens: 100}, srv.URL+"/openai/v1/", "llama-test", "TEST_COMPAT_KEY")
+	if a.Name() != "llama-test" || !a.Available() {
+		t.Fatalf("Name = %q, Available = %v", a.Name(), a.Available())
+	}
+	for i := 0; i < 2; i++ {
+		got, err := a.Run(context.Background(), "test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != "compat response" {
+			t.Errorf("got %q", got)
+		}
+	}
+	if u := a.Usage(); u.InputTokens != 24 || u.OutputTokens != 10 {
+		t.Errorf("usage = %+v, want 24 in / 10 out", u)
+	}
+}
+
+func TestOpenAICompatAgent_Available(t *testing.T) {
+	t.Setenv("TEST_COMPAT_KEY", "")
+	a := NewOpenAICompatAgent(&config.Config{}, "http://localhost", "m", "TEST_COMPAT_KEY")
+	if a.Available() {
+		t.Error("should not be available without the key env var")
+	}
+}
+
+func TestOpenAICompatAgent_Errors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{"message": "slow down"}})
+	}))
+	defer srv.Close()
+
+	t.Setenv("TEST_COMPAT_KEY", "k")
+	_, err := NewOpenAICompatAgent(&config.Config{}, srv.URL, "m", "TEST_COMPAT_KEY").WithName("groq").Run(context.Background(), "test")
+	if ErrorKindOf(err) != KindRateLimit {
+		t.Errorf("kind = %q, want ratelimit (err: %v)", ErrorKindOf(err), err)
+	}
+}
+
+func TestOpenAICompatAgent_Deadline(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	t.Setenv("TEST_COMPAT_KEY", "k")
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := NewOpenAICompatAgent(&config.Config{}, srv.URL, "m", "TEST_COMPAT_KEY").Run(ctx, "test")
+	if ErrorKindOf(err) != KindTimeout {
+		t.Errorf("want timeout, got %v", err)
+	}
+}
+
+func TestOpenAIAgent_Run(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer sk-test" {
+			t.Error("missing auth header")
+		}
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["model"] != "gpt-4o" {
+			t.Errorf("model = %v", body["model"])
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{"message": map[string]any{"content": "gpt response"}}},
+		})
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{OpenAIAPIKey: "sk-test", OpenAIBaseURL: srv.URL + "/", OpenAIChatModel: "gpt-4o"}
+	a := NewOpenAIAgent(cfg)
+	if a.Name() != "GPT" || a.Model() != "gpt-4o" || !a.Available() {
+		t.Fatalf("Name = %q, Model = %q, Available = %v", a.Name(), a.Model(), a.Available())
+	}
+	got, err := a.Run(context.Background(), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "gpt response" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestOpenAIAgent_Available(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "")
+	if NewOpenAIAgent(&config.Config{}).Available() {
+		t.Error("should not be available without OPENAI_API_KEY")
+	}
+}
+
+func TestOpenAIAgent_ContextCancellation(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := NewOpenAIAgent(&config.Config{OpenAIAPIKey: "sk-test", OpenAIBaseURL: srv.URL}).Run(ctx, "test")
+	if err == nil {
+		t.Error("expected error from cancelled context")
+	}
+}
+
+func TestExtraAgents(t *testing.T) {
+	cfg := &config.Config{ExtraAgents: []config.ExtraAgent{
+		{Name: "groq", BaseURL: "https://api.groq.com/openai/v1", Model: "llama", APIKeyEnv: "GROQ_API_KEY"},
+	}}
+	agents := ExtraAgents(cfg)
+	if len(agents) != 1 || agents[0].Name() != "groq" {
+		t.Fatalf("agents = %+v", agents)
+	}
+}
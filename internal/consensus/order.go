@@ -0,0 +1,67 @@
+package consensus
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"time"
+)
+
+// ResultOrder controls the order in which stage 1 results are presented to
+// the chairman. Always listing the same agent first invites position bias in
+// the synthesis.
+type ResultOrder string
+
+const (
+	OrderFixed   ResultOrder = ""        // roster order (default)
+	OrderShuffle ResultOrder = "shuffle" // seeded shuffle, see Options.Seed
+	OrderSorted  ResultOrder = "sorted"  // by agent name, then chunk
+)
+
+// ParseResultOrder validates an --order value; "fixed" and "" are the default.
+func ParseResultOrder(s string) (ResultOrder, error) {
+	switch o := ResultOrder(s); o {
+	case OrderFixed, "fixed":
+		return OrderFixed, nil
+	case OrderShuffle, OrderSorted:
+		return o, nil
+	}
+	return "", fmt.Errorf("invalid order %q: must be fixed, shuffle or sorted", s)
+}
+
+// orderResults returns a reordered copy of results. A shuffle with seed 0
+// picks a seed from the clock; the seed actually used is returned so the
+// order can be reproduced (0 for orders that don't shuffle).
+func orderResults(results []AgentResult, order ResultOrder, seed int64) ([]AgentResult, int64) {
+	out := append([]AgentResult(nil), results...)
+	switch order {
+	case OrderShuffle:
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		r := rand.New(rand.NewPCG(uint64(seed), 0))
+		r.Shuffle(len(out), func(i, j int) { out[i], out[j] = out[j], out[i] })
+		return out, seed
+	case OrderSorted:
+		sort.SliceStable(out, func(i, j int) bool {
+			if out[i].Agent != out[j].Agent {
+				return out[i].Agent < out[j].Agent
+			}
+			return out[i].Chunk < out[j].Chunk
+		})
+	}
+	return out, 0
+}
+
+// resultLabels names each result as the progress log does: the agent, with
+// the chunk in brackets for chunked runs.
+func resultLabels(results []AgentResult) []string {
+	labels := make([]string, len(results))
+	for i, r := range results {
+		labels[i] = r.Agent
+		if r.Chunk != "" {
+			labels[i] = fmt.Sprintf("%s [%s]", r.Agent, r.Chunk)
+		}
+	}
+	return labels
+}
@@ -0,0 +1,83 @@
+package consensus
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func namedResults(names ...string) []AgentResult {
+	results := make([]AgentResult, len(names))
+	for i, n := range names {
+		results[i] = AgentResult{Agent: n, Output: "out-" + n}
+	}
+	return results
+}
+
+func agentNames(results []AgentResult) []string {
+	var names []string
+	for _, r := range results {
+		names = append(names, r.Agent)
+	}
+	return names
+}
+
+func TestOrderResultsSeededShuffleIsStable(t *testing.T) {
+	results := namedResults("Claude", "Gemini", "Codex", "groq", "together")
+	first, seed := orderResults(results, OrderShuffle, 42)
+	if seed != 42 {
+		t.Errorf("seed = %d, want the given 42", seed)
+	}
+	for range 5 {
+		again, _ := orderResults(results, OrderShuffle, 42)
+		if !slices.Equal(agentNames(again), agentNames(first)) {
+			t.Fatalf("seed 42 gave %v then %v", agentNames(first), agentNames(again))
+		}
+	}
+	sorted := slices.Sorted(slices.Values(agentNames(first)))
+	if !slices.Equal(sorted, slices.Sorted(slices.Values(agentNames(results)))) {
+		t.Errorf("shuffle is not a permutation: %v", agentNames(first))
+	}
+	if got := agentNames(results); got[0] != "Claude" {
+		t.Errorf("input reordered in place: %v", got)
+	}
+
+	_, picked := orderResults(results, OrderShuffle, 0)
+	if picked == 0 {
+		t.Error("seed 0 should pick and report a seed")
+	}
+}
+
+func TestOrderResultsSortedAndFixed(t *testing.T) {
+	results := namedResults("Gemini", "Claude", "Codex")
+	if got, seed := orderResults(results, OrderSorted, 0); !slices.Equal(agentNames(got), []string{"Claude", "Codex", "Gemini"}) || seed != 0 {
+		t.Errorf("sorted = %v (seed %d)", agentNames(got), seed)
+	}
+	if got, _ := orderResults(results, OrderFixed, 7); !slices.Equal(agentNames(got), agentNames(results)) {
+		t.Errorf("fixed = %v, want roster order", agentNames(got))
+	}
+}
+
+func TestRunRecordsChairmanOrder(t *testing.T) {
+	agents := []Agent{
+		&mockAgent{name: "Claude", available: true, response: "a"},
+		&mockAgent{name: "Gemini", available: true, response: "b"},
+		&mockAgent{name: "Codex", available: true, response: "c"},
+	}
+	var seen []string
+	build := func(results []AgentResult) string {
+		seen = agentNames(results)
+		return "synthesize"
+	}
+	chairman := &mockAgent{name: "Chair", available: true, response: "done"}
+	result, err := Run(context.Background(), agents, []Agent{chairman}, []ChunkPrompt{{Prompt: "p"}}, build, Options{Order: OrderShuffle, Seed: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Seed != 7 || !slices.Equal(result.ChairmanOrder, seen) {
+		t.Errorf("recorded order %v (seed %d), chairman saw %v", result.ChairmanOrder, result.Seed, seen)
+	}
+	if got := agentNames(result.Stage1Results); !slices.Equal(got, []string{"Claude", "Gemini", "Codex"}) {
+		t.Errorf("Stage1Results = %v, want roster order", got)
+	}
+}
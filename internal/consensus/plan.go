@@ -0,0 +1,64 @@
+package consensus
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// DefaultPlanBudget caps the combined size (bytes) of plan documents included
+// in a code review prompt.
+const DefaultPlanBudget = 50000
+
+const planTruncatedMarker = "\n[... plan truncated to fit the size budget ...]\n"
+
+// PlanFile is one implementation plan document.
+type PlanFile struct {
+	Path    string
+	Content string
+}
+
+// ReadPlanFiles reads each plan file and combines them with CombinePlans.
+func ReadPlanFiles(paths []string, budget int) (string, []string, error) {
+	plans := make([]PlanFile, 0, len(paths))
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return "", nil, fmt.Errorf("read plan file: %w", err)
+		}
+		plans = append(plans, PlanFile{Path: p, Content: string(data)})
+	}
+	content, truncated := CombinePlans(plans, budget)
+	return content, truncated, nil
+}
+
+// CombinePlans concatenates plan documents into the planContent passed to the
+// code review prompt builders. A single plan is used as-is; several are each
+// introduced by a "### Plan: <path>" header. Content beyond budget bytes
+// (budget <= 0 means unlimited) is cut, and the paths of plans that were
+// shortened or left out are returned.
+func CombinePlans(plans []PlanFile, budget int) (string, []string) {
+	var b strings.Builder
+	var truncated []string
+	for i, p := range plans {
+		section := p.Content
+		if len(plans) > 1 {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			section = fmt.Sprintf("### Plan: %s\n\n%s", p.Path, strings.TrimRight(p.Content, "\n")+"\n")
+		}
+		if budget > 0 && b.Len()+len(section) > budget {
+			if room := budget - b.Len(); room > 0 {
+				b.WriteString(section[:room])
+				b.WriteString(planTruncatedMarker)
+			}
+			for _, rest := range plans[i:] {
+				truncated = append(truncated, rest.Path)
+			}
+			break
+		}
+		b.WriteString(section)
+	}
+	return b.String(), truncated
+}
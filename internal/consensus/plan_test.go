@@ -0,0 +1,62 @@
+package consensus
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadPlanFilesInPrompt(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "backend-plan.md")
+	b := filepath.Join(dir, "frontend-plan.md")
+	os.WriteFile(a, []byte("Add the /v2 endpoint.\n"), 0644)
+	os.WriteFile(b, []byte("Render the new dashboard.\n"), 0644)
+
+	content, truncated, err := ReadPlanFiles([]string{a, b}, DefaultPlanBudget)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(truncated) != 0 {
+		t.Errorf("truncated = %v", truncated)
+	}
+	prompt := BuildCodeReviewPrompt("desc", "+x", "a.go\n", content)
+	for _, want := range []string{"### Plan: " + a, "Add the /v2 endpoint.", "### Plan: " + b, "Render the new dashboard."} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q", want)
+		}
+	}
+}
+
+func TestCombinePlansSingleUnchanged(t *testing.T) {
+	content, _ := CombinePlans([]PlanFile{{Path: "p.md", Content: "just the plan"}}, 0)
+	if content != "just the plan" {
+		t.Errorf("single plan should be used as-is, got %q", content)
+	}
+}
+
+func TestCombinePlansBudget(t *testing.T) {
+	plans := []PlanFile{
+		{Path: "a.md", Content: strings.Repeat("a", 40)},
+		{Path: "b.md", Content: strings.Repeat("b", 40)},
+		{Path: "c.md", Content: strings.Repeat("c", 40)},
+	}
+	content, truncated := CombinePlans(plans, 80)
+	if !reflect.DeepEqual(truncated, []string{"b.md", "c.md"}) {
+		t.Errorf("truncated = %v", truncated)
+	}
+	if !strings.Contains(content, "plan truncated") || strings.Contains(content, "c.md") {
+		t.Errorf("content = %q", content)
+	}
+	if len(content)-len(planTruncatedMarker) > 80 {
+		t.Errorf("content exceeds budget: %d bytes", len(content))
+	}
+}
+
+func TestReadPlanFilesMissing(t *testing.T) {
+	if _, _, err := ReadPlanFiles([]string{filepath.Join(t.TempDir(), "nope.md")}, 0); err == nil {
+		t.Error("expected error for a missing plan file")
+	}
+}
@@ -0,0 +1,127 @@
+package consensus
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/signalnine/conclave/internal/bus"
+)
+
+// ProgressTopic is the bus topic consensus runs publish progress events to.
+const ProgressTopic = "consensus.progress"
+
+// Progress event types.
+const (
+	EventStart    = "consensus.start"
+	EventAgent    = "consensus.agent"
+	EventChairman = "consensus.chairman"
+	EventDone     = "consensus.done"
+)
+
+// ProgressEvent is the payload of every progress envelope. RunID ties the
+// events of one run to its ConsensusResult and output file.
+type ProgressEvent struct {
+	RunID  string `json:"run_id"`
+	Stage  int    `json:"stage,omitempty"`
+	Round  int    `json:"round,omitempty"` // stage 1 round, from 2, with Options.Rounds
+	Agent  string `json:"agent,omitempty"`
+	Chunk  string `json:"chunk,omitempty"`
+	Status string `json:"status,omitempty"` // "success" or "failed"
+	Error  string `json:"error,omitempty"`
+	Agents int    `json:"agents,omitempty"` // EventStart: available agents
+	Chunks int    `json:"chunks,omitempty"` // EventStart: stage 1 prompts
+}
+
+// progress publishes a run's events; a nil bus makes it a no-op.
+type progress struct {
+	bus   bus.MessageBus
+	runID string
+}
+
+func (p progress) emit(typ string, ev ProgressEvent) {
+	if p.bus == nil {
+		return
+	}
+	ev.RunID = p.runID
+	payload, _ := json.Marshal(ev)
+	p.bus.Publish(ProgressTopic, bus.Message{Type: typ, Sender: "consensus", Payload: payload})
+}
+
+func (p progress) result(stage int, r AgentResult) {
+	typ := EventAgent
+	if stage == 2 {
+		typ = EventChairman
+	}
+	p.emit(typ, resultEvent(ProgressEvent{Stage: stage}, r))
+}
+
+// roundResult reports an agent's answer in a later stage 1 round.
+func (p progress) roundResult(round int, r AgentResult) {
+	p.emit(EventAgent, resultEvent(ProgressEvent{Stage: 1, Round: round}, r))
+}
+
+// resultEvent fills ev in with r's agent, chunk and outcome.
+func resultEvent(ev ProgressEvent, r AgentResult) ProgressEvent {
+	ev.Agent, ev.Chunk, ev.Status = r.Agent, r.Chunk, "success"
+	if r.Err != nil {
+		ev.Status = "failed"
+		ev.Error = r.Err.Error()
+	}
+	return ev
+}
+
+// DefaultProgressInterval is how often stage 1 progress is printed when
+// Options.ProgressInterval is unset.
+const DefaultProgressInterval = 15 * time.Second
+
+// stage1Ticker prints how many stage 1 calls have returned, relative to the
+// stage 1 timeout, e.g. "2/3 agents done, 45s elapsed of 120s".
+type stage1Ticker struct {
+	done  atomic.Int32
+	total int
+	unit  string
+	stop  chan struct{}
+	wg    sync.WaitGroup
+}
+
+// startStage1Ticker starts printing progress to w every interval. A nil w
+// disables printing; the ticker still counts completions.
+func startStage1Ticker(w io.Writer, interval, timeout time.Duration, total int, unit string) *stage1Ticker {
+	t := &stage1Ticker{total: total, unit: unit, stop: make(chan struct{})}
+	if w == nil {
+		return t
+	}
+	if interval <= 0 {
+		interval = DefaultProgressInterval
+	}
+	start := time.Now()
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		tick := time.NewTicker(interval)
+		defer tick.Stop()
+		for {
+			select {
+			case <-t.stop:
+				return
+			case <-tick.C:
+				fmt.Fprintf(w, "  %d/%d %s done, %ds elapsed of %ds\n",
+					t.done.Load(), t.total, t.unit, int(time.Since(start).Seconds()), int(timeout.Seconds()))
+			}
+		}
+	}()
+	return t
+}
+
+// completed records one returned stage 1 call.
+func (t *stage1Ticker) completed(AgentResult) { t.done.Add(1) }
+
+// Stop ends the updates and waits for the printer to exit.
+func (t *stage1Ticker) Stop() {
+	close(t.stop)
+	t.wg.Wait()
+}
@@ -34,6 +34,30 @@ Focus on correctness, security, performance, and adherence to the plan (if provi
 	return b.String()
 }
 
+// BuildCodeReviewChunkPrompt builds a stage 1 prompt scoped to one chunk of a
+// larger diff. The chunk label tells the reviewer which part of the change it
+// is looking at so it can avoid flagging code that is reviewed elsewhere.
+func BuildCodeReviewChunkPrompt(description, modifiedFiles, planContent string, chunk DiffChunk) string {
+	prompt := BuildCodeReviewPrompt(description, chunk.Diff, modifiedFiles, planContent)
+	if chunk.Label == "" {
+		return prompt
+	}
+	scope := fmt.Sprintf("**Review Scope:** %s. This change was split into parts that are reviewed separately; limit your review to the diff below.\n\n", chunk.Label)
+	return strings.Replace(prompt, "**Diff:**", scope+"**Diff:**", 1)
+}
+
+// WrapPrompt places house-style framing around a built prompt, separated by
+// blank lines. Empty prefix or suffix leave that side untouched.
+func WrapPrompt(prefix, prompt, suffix string) string {
+	if p := strings.TrimSpace(prefix); p != "" {
+		prompt = p + "\n\n" + prompt
+	}
+	if s := strings.TrimSpace(suffix); s != "" {
+		prompt = strings.TrimRight(prompt, "\n") + "\n\n" + s
+	}
+	return prompt
+}
+
 func BuildGeneralPrompt(prompt, context string) string {
 	var b strings.Builder
 	b.WriteString("# General Analysis - Stage 1 Independent Analysis\n\n")
@@ -75,7 +99,7 @@ func BuildCodeReviewChairmanPrompt(description, modifiedFiles string, results []
 	b.WriteString("**CRITICAL:** Report all issues mentioned by any reviewer. Group similar issues together, but if reviewers disagree about an issue, report the disagreement explicitly.\n\n")
 	fmt.Fprintf(&b, "**Change Description:** %s\n\n", description)
 	fmt.Fprintf(&b, "**Modified Files:**\n%s\n\n", modifiedFiles)
-	fmt.Fprintf(&b, "**Reviews Received (%d of 3):**\n\n", succeeded)
+	fmt.Fprintf(&b, "**Reviews Received (%d of %d):**\n\n", succeeded, len(results))
 
 	for _, r := range results {
 		if r.Err == nil {
@@ -106,6 +130,63 @@ Be direct. Group similar issues but preserve different perspectives.
 	return b.String()
 }
 
+// BuildChunkedCodeReviewChairmanPrompt builds the chairman prompt for a review
+// whose stage 1 ran once per diff chunk. Reviews are grouped by chunk so the
+// chairman can merge findings per file before ranking them.
+func BuildChunkedCodeReviewChairmanPrompt(description, modifiedFiles string, results []AgentResult) string {
+	var order []string
+	byChunk := make(map[string][]AgentResult)
+	for _, r := range results {
+		if r.Err != nil {
+			continue
+		}
+		if _, ok := byChunk[r.Chunk]; !ok {
+			order = append(order, r.Chunk)
+		}
+		byChunk[r.Chunk] = append(byChunk[r.Chunk], r)
+	}
+
+	var b strings.Builder
+	b.WriteString("# Code Review Consensus - Stage 2 Chairman Synthesis\n\n")
+	b.WriteString("**Your Task:** Compile a consensus code review from multiple independent reviewers. The diff was too large to review at once, so each reviewer examined it part by part.\n\n")
+	b.WriteString("**CRITICAL:** Merge findings for the same file across parts before ranking them. Report all issues mentioned by any reviewer. If reviewers disagree about an issue, report the disagreement explicitly.\n\n")
+	fmt.Fprintf(&b, "**Change Description:** %s\n\n", description)
+	fmt.Fprintf(&b, "**Modified Files:**\n%s\n\n", modifiedFiles)
+
+	for _, chunk := range order {
+		label := chunk
+		if label == "" {
+			label = "full diff"
+		}
+		fmt.Fprintf(&b, "## Part: %s\n\n", label)
+		for _, r := range byChunk[chunk] {
+			fmt.Fprintf(&b, "--- %s Review ---\n%s\n\n", r.Agent, r.Output)
+		}
+	}
+
+	b.WriteString(`**Instructions:**
+Compile a consensus report with three tiers, grouping findings by file:
+
+## High Priority - Multiple Reviewers Agree
+[Issues mentioned by 2+ reviewers - group similar issues]
+
+## Medium Priority - Single Reviewer, Significant
+[Important/Critical issues from single reviewer]
+
+## Consider - Suggestions
+[Suggestions from any reviewer]
+
+## Final Recommendation
+- If High Priority issues exist: "Address high priority issues before merging"
+- If only Medium Priority: "Review medium priority concerns"
+- If only Consider tier: "Optional improvements suggested"
+- If no issues: "All reviewers approve - safe to merge"
+
+Be direct. Group similar issues but preserve different perspectives.
+`)
+	return b.String()
+}
+
 func BuildGeneralChairmanPrompt(originalPrompt string, results []AgentResult) string {
 	succeeded := 0
 	for _, r := range results {
@@ -119,7 +200,7 @@ func BuildGeneralChairmanPrompt(originalPrompt string, results []AgentResult) st
 	b.WriteString("**Your Task:** Compile consensus from multiple independent analyses.\n\n")
 	b.WriteString("**CRITICAL:** If analyses disagree or conflict, highlight disagreements explicitly. Do NOT smooth over conflicts.\n\n")
 	fmt.Fprintf(&b, "**Original Question:**\n%s\n\n", originalPrompt)
-	fmt.Fprintf(&b, "**Analyses Received (%d of 3):**\n\n", succeeded)
+	fmt.Fprintf(&b, "**Analyses Received (%d of %d):**\n\n", succeeded, len(results))
 
 	for _, r := range results {
 		if r.Err == nil {
@@ -193,3 +274,198 @@ func BuildDebateChairmanPrompt(originalPrompt string, analyses []AgentResult, re
 	b.WriteString("Output format:\n## Areas of Agreement\n## Areas of Disagreement\n## Confidence Level\n## Synthesized Recommendation")
 	return b.String()
 }
+
+// BuildCritiquePrompt asks an agent to review every stage 1 analysis,
+// including its own, for errors before synthesis.
+func BuildCritiquePrompt(question string, analyses []AgentResult) string {
+	var b strings.Builder
+	b.WriteString("# Cross-Critique - Review Independent Analyses\n\n")
+	b.WriteString("**Your Task:** Several agents analyzed the question below independently. Review all of their analyses, including your own, and flag mistakes before they are synthesized.\n\n")
+	fmt.Fprintf(&b, "**Question:**\n%s\n\n", question)
+	b.WriteString("**Analyses:**\n\n")
+	for _, r := range analyses {
+		if r.Err == nil {
+			fmt.Fprintf(&b, "--- %s Analysis ---\n%s\n\n", r.Agent, r.Output)
+		}
+	}
+	b.WriteString(`**Instructions:**
+For each analysis, list factual errors, unsupported claims, and important omissions, naming the agent each applies to. Write 'No issues' for an analysis you agree with. Do not restate points that are correct, and do not write your own full answer.
+`)
+	return b.String()
+}
+
+// BuildCritiqueChairmanPrompt creates the chairman prompt from the original
+// analyses and the cross-critiques of them.
+func BuildCritiqueChairmanPrompt(question string, analyses, critiques []AgentResult) string {
+	var b strings.Builder
+	b.WriteString("You are synthesizing a multi-agent analysis that included a cross-critique pass.\n\n")
+	fmt.Fprintf(&b, "Original question: %s\n\n", question)
+
+	b.WriteString("## Original Analyses\n\n")
+	for _, r := range analyses {
+		if r.Err == nil {
+			fmt.Fprintf(&b, "--- %s Analysis ---\n%s\n\n", r.Agent, r.Output)
+		}
+	}
+
+	b.WriteString("## Critiques (each agent reviewed all analyses)\n\n")
+	for _, r := range critiques {
+		if r.Err == nil {
+			fmt.Fprintf(&b, "--- %s Critique ---\n%s\n\n", r.Agent, r.Output)
+		}
+	}
+
+	b.WriteString("Synthesize all findings. Drop or correct any point a critique showed to be wrong, and note where critiques disagree with each other.\n\n")
+	b.WriteString("Output format:\n## Areas of Agreement\n## Areas of Disagreement\n## Corrections From Critique\n## Confidence Level\n## Synthesized Recommendation")
+	return b.String()
+}
+
+// BuildRoundPrompt creates the prompt for round (2 or later) of
+// RunConsensusRounds: the question and every agent's answer from the previous
+// round, for selfAgent to rebut the others and revise its own.
+func BuildRoundPrompt(question string, previous []AgentResult, selfAgent string, round int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Consensus Round %d - Rebut and Revise\n\n", round)
+	fmt.Fprintf(&b, "**Question:**\n%s\n\n", question)
+	fmt.Fprintf(&b, "**Answers from round %d:**\n\n", round-1)
+	for _, r := range previous {
+		if r.Err != nil {
+			continue
+		}
+		label := r.Agent
+		if r.Agent == selfAgent {
+			label += " (you)"
+		}
+		fmt.Fprintf(&b, "--- %s ---\n%s\n\n", label, r.Output)
+	}
+	b.WriteString(`**Instructions:**
+Point out specific errors, unsupported claims and missing considerations in the other agents' answers. Then give your revised answer in full, keeping what still holds and saying what you changed and why.
+`)
+	return b.String()
+}
+
+// BuildRoundsChairmanPrompt creates the chairman prompt for a multi-round
+// run from every round's answers, oldest first.
+func BuildRoundsChairmanPrompt(question string, rounds [][]AgentResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "You are synthesizing a multi-agent analysis that ran %d rounds; from round 2 on, each agent saw the others' previous answers and revised its own.\n\n", len(rounds))
+	fmt.Fprintf(&b, "Original question: %s\n\n", question)
+	for i, results := range rounds {
+		fmt.Fprintf(&b, "## Round %d\n\n", i+1)
+		for _, r := range results {
+			if r.Err == nil {
+				fmt.Fprintf(&b, "--- %s ---\n%s\n\n", r.Agent, r.Output)
+			}
+		}
+	}
+	b.WriteString("Synthesize all findings, weighting the final round most. Treat positions that converged across rounds as strong signals, and flag points still contested in the final round.\n\n")
+	b.WriteString("Output format:\n## Areas of Agreement\n## Areas of Disagreement\n## Confidence Level\n## Synthesized Recommendation")
+	return b.String()
+}
+
+// BuildMergePrompt asks a merger to reconcile the syntheses several chairmen
+// wrote independently from the same stage 1 analyses.
+func BuildMergePrompt(syntheses []AgentResult) string {
+	var b strings.Builder
+	b.WriteString("# Merge Chairman Syntheses\n\n")
+	fmt.Fprintf(&b, "**Your Task:** %d chairmen independently synthesized the same multi-agent analysis. Merge their syntheses into one final synthesis.\n\n", len(syntheses))
+	for _, r := range syntheses {
+		if r.Err == nil {
+			fmt.Fprintf(&b, "--- %s Synthesis ---\n%s\n\n", r.Agent, r.Output)
+		}
+	}
+	b.WriteString(`**Instructions:**
+Keep every finding that any synthesis supports, without duplicates. Where the syntheses disagree (on a finding, its severity or the recommendation), decide which is better supported and say so. Use the same section structure as the syntheses.
+`)
+	return b.String()
+}
+
+// BuildConsistencyCheckPrompt asks the chairman to check its own synthesis
+// for internal contradictions and end with a machine-readable verdict.
+func BuildConsistencyCheckPrompt(synthesis string) string {
+	var b strings.Builder
+	b.WriteString("# Consistency Check\n\n")
+	b.WriteString("**Your Task:** The synthesis below was written from several independent analyses. Check it for internal contradictions: conclusions that conflict with each other, recommendations that contradict stated findings, or confidence levels that don't match the evidence given.\n\n")
+	fmt.Fprintf(&b, "**Synthesis:**\n%s\n\n", synthesis)
+	b.WriteString(`**Instructions:**
+List each contradiction you find, quoting the conflicting statements. Do not judge whether the conclusions are correct, only whether they are consistent with each other. End with exactly one line:
+
+VERDICT: CONSISTENT
+or
+VERDICT: CONTRADICTIONS
+`)
+	return b.String()
+}
+
+// BuildRankPrompt asks an agent to rank numbered options and finish with a
+// machine-readable RANKING list.
+func BuildRankPrompt(prompt string, options []string) string {
+	var b strings.Builder
+	b.WriteString("# Ranking - Stage 1 Independent Analysis\n\n")
+	b.WriteString("**Your Task:** Independently evaluate the options below and rank them from best to worst.\n\n")
+	fmt.Fprintf(&b, "**Question:**\n%s\n\n", prompt)
+	b.WriteString("**Options:**\n")
+	for i, opt := range options {
+		fmt.Fprintf(&b, "[%d] %s\n", i+1, opt)
+	}
+	fmt.Fprintf(&b, `
+**Instructions:**
+Briefly weigh the strengths and weaknesses of each option, then end your response with your ranking in exactly this format, best first, listing every option once by its number:
+
+RANKING:
+1. [option number]
+2. [option number]
+...
+
+Rank all %d options.
+`, len(options))
+	return b.String()
+}
+
+// BuildRankChairmanPrompt asks the chairman to explain the aggregate ranking
+// using the agents' reasoning.
+func BuildRankChairmanPrompt(prompt string, options []string, scores []OptionScore, results []AgentResult) string {
+	succeeded := 0
+	for _, r := range results {
+		if r.Err == nil {
+			succeeded++
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("# Ranking Consensus - Stage 2 Chairman Synthesis\n\n")
+	b.WriteString("**Your Task:** Explain the aggregate ranking of the options using the independent evaluations below.\n\n")
+	b.WriteString("**CRITICAL:** The aggregate order is fixed by a Borda count. Do not re-rank; explain it, and call out where evaluators disagreed.\n\n")
+	fmt.Fprintf(&b, "**Original Question:**\n%s\n\n", prompt)
+	b.WriteString("**Options:**\n")
+	for i, opt := range options {
+		fmt.Fprintf(&b, "[%d] %s\n", i+1, opt)
+	}
+	b.WriteString("\n**Aggregate Ranking (Borda count):**\n")
+	for pos, s := range scores {
+		fmt.Fprintf(&b, "%d. [%d] %s - %d points, %d first-place vote(s)\n", pos+1, s.Index+1, s.Option, s.Score, s.FirstPlace)
+	}
+	fmt.Fprintf(&b, "\n**Evaluations Received (%d of %d):**\n\n", succeeded, len(results))
+
+	for _, r := range results {
+		if r.Err == nil {
+			fmt.Fprintf(&b, "--- %s Evaluation ---\n%s\n\n", r.Agent, r.Output)
+		}
+	}
+
+	b.WriteString(`**Instructions:**
+Provide the final ranking with rationale:
+
+## Final Ranking
+[The aggregate order above, one line of rationale per option]
+
+## Areas of Disagreement
+[Where evaluators ranked options differently, and why]
+
+## Confidence Level
+High / Medium / Low
+
+Be direct. Disagreement is valuable - report it clearly.
+`)
+	return b.String()
+}
@@ -56,3 +56,12 @@ func TestBuildDebateChairmanPrompt(t *testing.T) {
 		t.Error("should instruct chairman to weigh position changes")
 	}
 }
+
+func TestWrapPrompt(t *testing.T) {
+	if got := WrapPrompt("", "body", ""); got != "body" {
+		t.Errorf("no framing: %q", got)
+	}
+	if got := WrapPrompt("Be terse.", "body\n", " Cite files. "); got != "Be terse.\n\nbody\n\nCite files." {
+		t.Errorf("framed: %q", got)
+	}
+}
@@ -0,0 +1,185 @@
+package consensus
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AgentRanking is one agent's parsed ranking. Order holds option indices,
+// best first. Options the agent left out are listed in Skipped; repeated
+// entries and lines that matched no option are dropped and counted.
+type AgentRanking struct {
+	Agent      string
+	Order      []int
+	Skipped    []int
+	Duplicates int
+	Unknown    int
+}
+
+// OptionScore is an option's aggregate Borda score.
+type OptionScore struct {
+	Index      int
+	Option     string
+	Score      int // Borda points summed over all rankings
+	FirstPlace int // rankings that put this option first
+	Ranked     int // rankings that included this option
+}
+
+// RankResult is the outcome of a ranking consensus: the aggregate scores,
+// best first, alongside the chairman's narrative.
+type RankResult struct {
+	Options      []string
+	Scores       []OptionScore
+	Rankings     []AgentRanking
+	ChairmanName string
+	Narrative    string
+	Consensus    *ConsensusResult
+}
+
+// RunConsensusRank asks every agent to rank options for prompt, aggregates the
+// rankings with a Borda count and has a chairman explain the result. Agents
+// also serve as chairmen, in order.
+func RunConsensusRank(ctx context.Context, agents []Agent, options []string, prompt string) (*RankResult, error) {
+	return RunConsensusRankWithOptions(ctx, agents, agents, options, prompt, Options{})
+}
+
+// RunConsensusRankWithOptions is RunConsensusRank with an explicit chairman
+// order and run options.
+func RunConsensusRankWithOptions(ctx context.Context, agents, chairmen []Agent, options []string, prompt string, opts Options) (*RankResult, error) {
+	if len(options) < 2 {
+		return nil, fmt.Errorf("ranking needs at least 2 options, got %d", len(options))
+	}
+
+	var rankings []AgentRanking
+	var scores []OptionScore
+	buildChairman := func(results []AgentResult) string {
+		rankings = nil
+		for _, r := range results {
+			if r.Err == nil {
+				rankings = append(rankings, ParseRanking(r.Agent, r.Output, options))
+			}
+		}
+		scores = BordaCount(options, rankings)
+		return BuildRankChairmanPrompt(prompt, options, scores, results)
+	}
+
+	stage1 := []ChunkPrompt{{Prompt: BuildRankPrompt(prompt, options)}}
+	result, err := Run(ctx, agents, chairmen, stage1, buildChairman, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &RankResult{
+		Options:      options,
+		Scores:       scores,
+		Rankings:     rankings,
+		ChairmanName: result.ChairmanName,
+		Narrative:    result.ChairmanOutput,
+		Consensus:    result,
+	}, nil
+}
+
+var (
+	rankHeaderRe = regexp.MustCompile(`(?i)^[#*\s]*ranking[*\s]*:?[*\s]*$`)
+	rankLineRe   = regexp.MustCompile(`^\s*(\d+)\s*[.):]\s*(.+)$`)
+	optionRefRe  = regexp.MustCompile(`(?i)^\W*(?:option\s*)?\[?(\d+)\]?(?:\W|$)`)
+)
+
+// ParseRanking extracts an agent's ranking from the numbered list following
+// its last "RANKING:" line (or the first numbered list when there is none).
+// Entries may cite options by number ("[2]", "Option 2") or by text.
+func ParseRanking(agent, output string, options []string) AgentRanking {
+	lines := strings.Split(output, "\n")
+	start := 0
+	for i, line := range lines {
+		if rankHeaderRe.MatchString(line) {
+			start = i + 1
+		}
+	}
+
+	r := AgentRanking{Agent: agent}
+	seen := make(map[int]bool)
+	started := false
+	for _, line := range lines[start:] {
+		m := rankLineRe.FindStringSubmatch(line)
+		if m == nil {
+			if started && strings.TrimSpace(line) != "" {
+				break
+			}
+			continue
+		}
+		started = true
+		idx := matchOption(m[2], options)
+		switch {
+		case idx < 0:
+			r.Unknown++
+		case seen[idx]:
+			r.Duplicates++
+		default:
+			seen[idx] = true
+			r.Order = append(r.Order, idx)
+		}
+	}
+	for i := range options {
+		if !seen[i] {
+			r.Skipped = append(r.Skipped, i)
+		}
+	}
+	return r
+}
+
+// matchOption resolves a ranking entry to an option index, or -1.
+func matchOption(entry string, options []string) int {
+	entry = strings.TrimSpace(strings.Trim(entry, "*_` "))
+	if m := optionRefRe.FindStringSubmatch(entry); m != nil {
+		if n, err := strconv.Atoi(m[1]); err == nil && n >= 1 && n <= len(options) {
+			return n - 1
+		}
+	}
+	lower := strings.ToLower(entry)
+	for i, opt := range options {
+		if strings.EqualFold(entry, opt) {
+			return i
+		}
+	}
+	for i, opt := range options {
+		if o := strings.ToLower(strings.TrimSpace(opt)); o != "" && strings.HasPrefix(lower, o) {
+			return i
+		}
+	}
+	return -1
+}
+
+// BordaCount scores options across rankings: with n options, an option in
+// position p (0-based) earns n-1-p points. Options a ranking skipped earn
+// nothing from it. Scores are sorted best first; ties break on first-place
+// votes, then on the original option order.
+func BordaCount(options []string, rankings []AgentRanking) []OptionScore {
+	scores := make([]OptionScore, len(options))
+	for i, opt := range options {
+		scores[i] = OptionScore{Index: i, Option: opt}
+	}
+	n := len(options)
+	for _, r := range rankings {
+		for pos, idx := range r.Order {
+			if idx < 0 || idx >= n {
+				continue
+			}
+			scores[idx].Score += n - 1 - pos
+			scores[idx].Ranked++
+			if pos == 0 {
+				scores[idx].FirstPlace++
+			}
+		}
+	}
+	sort.SliceStable(scores, func(i, j int) bool {
+		if scores[i].Score != scores[j].Score {
+			return scores[i].Score > scores[j].Score
+		}
+		return scores[i].FirstPlace > scores[j].FirstPlace
+	})
+	return scores
+}
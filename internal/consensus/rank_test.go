@@ -0,0 +1,122 @@
+package consensus
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var rankOptions = []string{"Monolith", "Microservices", "Modular monolith"}
+
+func TestParseRanking(t *testing.T) {
+	output := "Some reasoning about 1. things.\n\nRANKING:\n1. [3]\n2. [1]\n3. [2]\n"
+	r := ParseRanking("A", output, rankOptions)
+	if !reflect.DeepEqual(r.Order, []int{2, 0, 1}) {
+		t.Errorf("Order = %v", r.Order)
+	}
+	if len(r.Skipped) != 0 || r.Duplicates != 0 || r.Unknown != 0 {
+		t.Errorf("unexpected problems: %+v", r)
+	}
+}
+
+func TestParseRankingByText(t *testing.T) {
+	output := "**Ranking:**\n1. Modular monolith - best tradeoff\n2) Option 1\n3. microservices\n"
+	r := ParseRanking("A", output, rankOptions)
+	if !reflect.DeepEqual(r.Order, []int{2, 0, 1}) {
+		t.Errorf("Order = %v", r.Order)
+	}
+}
+
+func TestParseRankingDefensive(t *testing.T) {
+	output := "RANKING:\n1. [2]\n2. [2]\n3. [9]\n4. Serverless\n\nTrailing notes 1. ignore\n"
+	r := ParseRanking("A", output, rankOptions)
+	if !reflect.DeepEqual(r.Order, []int{1}) {
+		t.Errorf("Order = %v", r.Order)
+	}
+	if r.Duplicates != 1 {
+		t.Errorf("Duplicates = %d, want 1", r.Duplicates)
+	}
+	if r.Unknown != 2 {
+		t.Errorf("Unknown = %d, want 2", r.Unknown)
+	}
+	if !reflect.DeepEqual(r.Skipped, []int{0, 2}) {
+		t.Errorf("Skipped = %v", r.Skipped)
+	}
+}
+
+func TestParseRankingNoList(t *testing.T) {
+	r := ParseRanking("A", "I can't decide.", rankOptions)
+	if len(r.Order) != 0 || len(r.Skipped) != 3 {
+		t.Errorf("got %+v", r)
+	}
+}
+
+func TestBordaCount(t *testing.T) {
+	rankings := []AgentRanking{
+		{Agent: "A", Order: []int{2, 0, 1}},
+		{Agent: "B", Order: []int{0, 2, 1}},
+		{Agent: "C", Order: []int{2}}, // skipped the rest
+	}
+	scores := BordaCount(rankOptions, rankings)
+	got := []int{scores[0].Index, scores[1].Index, scores[2].Index}
+	if !reflect.DeepEqual(got, []int{2, 0, 1}) {
+		t.Fatalf("order = %v, scores = %+v", got, scores)
+	}
+	if scores[0].Score != 5 || scores[0].FirstPlace != 2 || scores[0].Ranked != 3 {
+		t.Errorf("top = %+v", scores[0])
+	}
+	if scores[2].Score != 0 || scores[2].Ranked != 2 {
+		t.Errorf("last = %+v", scores[2])
+	}
+}
+
+func TestBordaCountTieBreak(t *testing.T) {
+	rankings := []AgentRanking{
+		{Order: []int{1, 0}},
+		{Order: []int{0, 1}},
+	}
+	scores := BordaCount([]string{"x", "y"}, rankings)
+	if scores[0].Index != 0 {
+		t.Errorf("ties should keep option order, got %+v", scores)
+	}
+}
+
+func TestRunConsensusRank(t *testing.T) {
+	agents := []Agent{
+		&mockAgent{name: "A", available: true, response: "RANKING:\n1. [3]\n2. [1]\n3. [2]"},
+		&mockAgent{name: "B", available: true, response: "RANKING:\n1. [1]\n2. [3]\n3. [2]"},
+		&mockAgent{name: "C", available: true, response: "RANKING:\n1. [3]\n2. [3]\n3. [2]"},
+	}
+	result, err := RunConsensusRank(context.Background(), agents, rankOptions, "Which architecture?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Scores[0].Option != "Modular monolith" {
+		t.Errorf("winner = %+v", result.Scores[0])
+	}
+	if len(result.Rankings) != 3 || result.Rankings[2].Duplicates != 1 {
+		t.Errorf("rankings = %+v", result.Rankings)
+	}
+	if result.Narrative == "" || result.ChairmanName != "A" {
+		t.Errorf("chairman = %q, narrative = %q", result.ChairmanName, result.Narrative)
+	}
+}
+
+func TestRunConsensusRankNeedsOptions(t *testing.T) {
+	agents := []Agent{&mockAgent{name: "A", available: true, response: "x"}}
+	if _, err := RunConsensusRank(context.Background(), agents, []string{"only"}, "q"); err == nil {
+		t.Error("expected error for a single option")
+	}
+}
+
+func TestBuildRankChairmanPrompt(t *testing.T) {
+	scores := []OptionScore{{Index: 2, Option: "Modular monolith", Score: 5, FirstPlace: 2}}
+	prompt := BuildRankChairmanPrompt("Which?", rankOptions, scores, []AgentResult{{Agent: "A", Output: "analysis"}})
+	if !strings.Contains(prompt, "1. [3] Modular monolith - 5 points, 2 first-place vote(s)") {
+		t.Errorf("missing aggregate line:\n%s", prompt)
+	}
+	if !strings.Contains(prompt, "--- A Evaluation ---") {
+		t.Error("missing evaluation")
+	}
+}
@@ -0,0 +1,56 @@
+package consensus
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// RawFileName is the file SaveRawResults writes an agent's responses to.
+func RawFileName(runID, agent string) string {
+	return fmt.Sprintf("%s-%s.txt", runID, unsafeFileChars.ReplaceAllString(agent, "_"))
+}
+
+// SaveRawResults writes each agent's stage 1 responses, unprocessed, to
+// <dir>/<runID>-<agent>.txt. An agent that answered several chunks gets one
+// file with a header per chunk; a failure is recorded with its error. It
+// returns the files written.
+func SaveRawResults(dir, runID string, results []AgentResult) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("save raw responses: %w", err)
+	}
+	var order []string
+	byAgent := make(map[string]*strings.Builder)
+	for _, r := range results {
+		b, ok := byAgent[r.Agent]
+		if !ok {
+			b = &strings.Builder{}
+			byAgent[r.Agent] = b
+			order = append(order, r.Agent)
+		}
+		if r.Chunk != "" {
+			fmt.Fprintf(b, "===== Chunk: %s =====\n", r.Chunk)
+		}
+		if r.Err != nil {
+			fmt.Fprintf(b, "ERROR: %v\n", r.Err)
+		}
+		b.WriteString(r.Output)
+		if r.Chunk != "" && !strings.HasSuffix(r.Output, "\n") {
+			b.WriteString("\n")
+		}
+	}
+
+	var paths []string
+	for _, agent := range order {
+		path := filepath.Join(dir, RawFileName(runID, agent))
+		if err := os.WriteFile(path, []byte(byAgent[agent].String()), 0644); err != nil {
+			return paths, fmt.Errorf("save raw responses: %w", err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
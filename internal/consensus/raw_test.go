@@ -0,0 +1,65 @@
+package consensus
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveRawResultsOneFilePerAgent(t *testing.T) {
+	agents := []Agent{
+		&mockAgent{name: "Claude", available: true, response: "## Findings\nraw claude text"},
+		&mockAgent{name: "Gemini", available: true, err: errors.New("quota exceeded")},
+		&mockAgent{name: "Local Llama/7B", available: true, response: "llama says hi"},
+	}
+	chairman := &mockAgent{name: "Chair", available: true, response: "synthesis"}
+	result, err := RunConsensusWithBuilder(context.Background(), agents, []Agent{chairman}, GeneralBuilder{}, PromptInput{Question: "q"}, Options{RunID: "run42"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "raw")
+	paths, err := SaveRawResults(dir, result.RunID, result.Stage1Results)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"run42-Claude.txt":         "## Findings\nraw claude text",
+		"run42-Gemini.txt":         "ERROR: quota exceeded\n",
+		"run42-Local_Llama_7B.txt": "llama says hi",
+	}
+	if len(paths) != len(want) {
+		t.Fatalf("wrote %v, want %d files", paths, len(want))
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != len(want) {
+		t.Errorf("dir has %d entries, want %d", len(entries), len(want))
+	}
+	for name, content := range want {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Errorf("missing %s: %v", name, err)
+			continue
+		}
+		if string(data) != content {
+			t.Errorf("%s = %q, want %q", name, data, content)
+		}
+	}
+}
+
+func TestSaveRawResultsChunks(t *testing.T) {
+	dir := t.TempDir()
+	results := []AgentResult{
+		{Agent: "Claude", Chunk: "a.go", Output: "first"},
+		{Agent: "Claude", Chunk: "b.go", Output: "second\n"},
+	}
+	if _, err := SaveRawResults(dir, "r", results); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "r-Claude.txt"))
+	if want := "===== Chunk: a.go =====\nfirst\n===== Chunk: b.go =====\nsecond\n"; string(data) != want {
+		t.Errorf("got %q, want %q", data, want)
+	}
+}
@@ -0,0 +1,146 @@
+package consensus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// RetryBudget is a retry allowance shared by every stage of a consensus run,
+// so transient failures are absorbed without letting the run retry forever.
+// A nil *RetryBudget allows no retries.
+type RetryBudget struct {
+	total     int
+	remaining atomic.Int64
+}
+
+func NewRetryBudget(n int) *RetryBudget {
+	b := &RetryBudget{total: n}
+	b.remaining.Store(int64(n))
+	return b
+}
+
+// Take consumes one retry, reporting false once the budget is exhausted.
+func (b *RetryBudget) Take() bool {
+	if b == nil {
+		return false
+	}
+	for {
+		n := b.remaining.Load()
+		if n <= 0 {
+			return false
+		}
+		if b.remaining.CompareAndSwap(n, n-1) {
+			return true
+		}
+	}
+}
+
+// Remaining returns the number of retries left.
+func (b *RetryBudget) Remaining() int {
+	if b == nil {
+		return 0
+	}
+	return int(b.remaining.Load())
+}
+
+// Total returns the budget's initial size.
+func (b *RetryBudget) Total() int {
+	if b == nil {
+		return 0
+	}
+	return b.total
+}
+
+// RetryPolicy retries each failed stage 1 call up to Max times, waiting
+// Backoff before the first retry and doubling the wait for each one after.
+// A provider's Retry-After, when longer, wins. Waits end at the stage
+// deadline, so retries never run past it. The zero policy adds no retries.
+type RetryPolicy struct {
+	Max     int
+	Backoff time.Duration
+}
+
+// backoff returns the wait before retry number n (1-based).
+func (p RetryPolicy) backoff(n int) time.Duration {
+	if p.Backoff <= 0 || n < 1 {
+		return 0
+	}
+	return p.Backoff << min(n-1, 30)
+}
+
+// retrier decides whether a failed stage 1 call is retried: within the
+// agent's RetryPolicy and, when there is one, the run's shared RetryBudget.
+// With neither, nothing is retried.
+type retrier struct {
+	budget *RetryBudget
+	policy RetryPolicy
+}
+
+// allow reports whether a call that has had retries retries may have one
+// more, consuming a budget retry if so.
+func (r retrier) allow(retries int) bool {
+	if r.policy.Max > 0 {
+		return retries < r.policy.Max && (r.budget == nil || r.budget.Take())
+	}
+	return r.budget.Take()
+}
+
+// delay returns how long to wait before retry number n after err, or false
+// when the wait would reach ctx's deadline, leaving no time for the retry.
+func (r retrier) delay(ctx context.Context, err error, n int) (time.Duration, bool) {
+	d := max(retryDelay(ctx, err), r.policy.backoff(n))
+	if dl, ok := ctx.Deadline(); ok && d >= time.Until(dl) {
+		return 0, false
+	}
+	return d, true
+}
+
+// describe summarizes what is left for retry n, for the retry log line.
+func (r retrier) describe(n int) string {
+	if r.budget != nil {
+		return fmt.Sprintf("retry budget: %d/%d remaining", r.budget.Remaining(), r.budget.Total())
+	}
+	return fmt.Sprintf("retry %d/%d", n, r.policy.Max)
+}
+
+// retryDelay returns how long to wait before retrying err: the provider's
+// Retry-After guidance when given, capped by the context deadline, otherwise
+// zero (retry immediately).
+func retryDelay(ctx context.Context, err error) time.Duration {
+	d := RetryAfterOf(err)
+	if dl, ok := ctx.Deadline(); ok && d > time.Until(dl) {
+		d = time.Until(dl)
+	}
+	return max(d, 0)
+}
+
+// sleepCtx waits for d, reporting false if ctx ends first.
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// retryable reports whether a failed call is worth retrying: the stage
+// deadline must not have passed, the error must not be a cancellation, and
+// auth failures never fix themselves.
+func retryable(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	if ErrorKindOf(err) == KindAuth {
+		return false
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,114 @@
+package consensus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/signalnine/conclave/internal/bus"
+)
+
+// StreamChunk is one piece of a streamed response. A chunk with Err set ends
+// the stream unsuccessfully.
+type StreamChunk struct {
+	Text string
+	Err  error
+}
+
+// StreamingAgent is an Agent that can deliver its response incrementally.
+// The channel is closed when the response is complete.
+type StreamingAgent interface {
+	Agent
+	RunStream(ctx context.Context, prompt string) (<-chan StreamChunk, error)
+}
+
+// runTo runs agent a, copying its response to w as it arrives when the agent
+// streams and w is set. Non-streaming agents, or a nil w, run as usual, with a
+// successful response written to w in one piece.
+func runTo(ctx context.Context, a Agent, prompt string, w io.Writer) (string, error) {
+	sa, ok := a.(StreamingAgent)
+	if !ok || w == nil {
+		out, err := a.Run(ctx, prompt)
+		if err == nil && out != "" && w != nil {
+			io.WriteString(w, out)
+		}
+		return out, err
+	}
+
+	chunks, err := sa.RunStream(ctx, prompt)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for c := range chunks {
+		if c.Err != nil {
+			return b.String(), c.Err
+		}
+		b.WriteString(c.Text)
+		if _, err := io.WriteString(w, c.Text); err != nil {
+			return b.String(), fmt.Errorf("write stream: %w", err)
+		}
+		if f, ok := w.(interface{ Sync() error }); ok {
+			f.Sync()
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return b.String(), err
+	}
+	return b.String(), nil
+}
+
+// StreamTopicPrefix prefixes the per-agent topics that streamed stage 1
+// output is published to, see StreamTopic.
+const StreamTopicPrefix = "consensus.stream"
+
+// EventDelta is the message type of a streamed stage 1 delta.
+const EventDelta = "consensus.delta"
+
+// StreamTopic returns the topic agent's stage 1 deltas are published to, e.g.
+// "consensus.stream.Claude". Subscribe to StreamTopicPrefix for every agent.
+func StreamTopic(agent string) string { return StreamTopicPrefix + "." + agent }
+
+// StreamDelta is the payload of an EventDelta envelope: the next piece of an
+// agent's stage 1 response. A retry streams its response from the start
+// under the next Attempt.
+type StreamDelta struct {
+	RunID   string `json:"run_id,omitempty"`
+	Agent   string `json:"agent"`
+	Chunk   string `json:"chunk,omitempty"`
+	Attempt int    `json:"attempt"`
+	Text    string `json:"text"`
+}
+
+// stage1Stream publishes stage 1 responses to the bus as they arrive.
+type stage1Stream struct {
+	bus   bus.MessageBus
+	runID string
+}
+
+// writer returns where to copy one stage 1 call's response, or nil when s
+// is nil so runTo runs the agent as usual.
+func (s *stage1Stream) writer(agent, chunk string, attempt int) io.Writer {
+	if s == nil {
+		return nil
+	}
+	return &deltaWriter{bus: s.bus, delta: StreamDelta{RunID: s.runID, Agent: agent, Chunk: chunk, Attempt: attempt}}
+}
+
+// deltaWriter publishes every write as one StreamDelta.
+type deltaWriter struct {
+	bus   bus.MessageBus
+	delta StreamDelta
+}
+
+func (w *deltaWriter) Write(p []byte) (int, error) {
+	d := w.delta
+	d.Text = string(p)
+	payload, _ := json.Marshal(d)
+	if err := w.bus.Publish(StreamTopic(d.Agent), bus.Message{Type: EventDelta, Sender: d.Agent, Payload: payload}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,211 @@
+package consensus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/signalnine/conclave/internal/bus"
+)
+
+// streamingAgent emits chunks one at a time, waiting for next between them.
+type streamingAgent struct {
+	mockAgent
+	chunks []string
+	err    error
+	next   chan struct{}
+}
+
+func (s *streamingAgent) RunStream(ctx context.Context, prompt string) (<-chan StreamChunk, error) {
+	ch := make(chan StreamChunk)
+	go func() {
+		defer close(ch)
+		for _, c := range s.chunks {
+			if s.next != nil {
+				<-s.next
+			}
+			ch <- StreamChunk{Text: c}
+		}
+		if s.err != nil {
+			ch <- StreamChunk{Err: s.err}
+		}
+	}()
+	return ch, nil
+}
+
+func fileSize(t *testing.T, path string) int64 {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info.Size()
+}
+
+func waitForSize(t *testing.T, path string, want int64) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for fileSize(t, path) < want {
+		if time.Now().After(deadline) {
+			t.Fatalf("file size %d, want at least %d", fileSize(t, path), want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestRun_StreamsChairmanToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.md")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	chairman := &streamingAgent{
+		mockAgent: mockAgent{name: "Chair", available: true},
+		chunks:    []string{"first part. ", "second part. ", "done."},
+		next:      make(chan struct{}),
+	}
+	agents := []Agent{&mockAgent{name: "A", available: true, response: "a"}}
+
+	done := make(chan *ConsensusResult)
+	go func() {
+		r, err := Run(context.Background(), agents, []Agent{chairman},
+			[]ChunkPrompt{{Prompt: "p"}}, func([]AgentResult) string { return "chair" },
+			Options{StreamTo: f})
+		if err != nil {
+			t.Error(err)
+		}
+		done <- r
+	}()
+
+	var size int64
+	for _, c := range chairman.chunks {
+		chairman.next <- struct{}{}
+		size += int64(len(c))
+		waitForSize(t, path, size)
+	}
+	r := <-done
+
+	if r.ChairmanOutput != "first part. second part. done." {
+		t.Errorf("ChairmanOutput = %q", r.ChairmanOutput)
+	}
+	got, _ := os.ReadFile(path)
+	if string(got) != r.ChairmanOutput {
+		t.Errorf("file = %q", got)
+	}
+}
+
+func TestRun_StreamFallbackNote(t *testing.T) {
+	var b strings.Builder
+	broken := &streamingAgent{
+		mockAgent: mockAgent{name: "Broken", available: true},
+		chunks:    []string{"partial "},
+		err:       errors.New("connection reset"),
+	}
+	backup := &mockAgent{name: "Backup", available: true, response: "full synthesis"}
+	agents := []Agent{&mockAgent{name: "A", available: true, response: "a"}}
+
+	r, err := Run(context.Background(), agents, []Agent{broken, backup},
+		[]ChunkPrompt{{Prompt: "p"}}, func([]AgentResult) string { return "chair" },
+		Options{StreamTo: &b})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.ChairmanName != "Backup" {
+		t.Errorf("ChairmanName = %q", r.ChairmanName)
+	}
+	out := b.String()
+	if !strings.HasPrefix(out, "partial ") || !strings.Contains(out, "falling back to Backup") || !strings.HasSuffix(out, "full synthesis") {
+		t.Errorf("stream output = %q", out)
+	}
+}
+
+func TestRunTo_NonStreamingAgentWritesOnce(t *testing.T) {
+	var b strings.Builder
+	out, err := runTo(context.Background(), &mockAgent{name: "A", available: true, response: "whole"}, "p", &b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "whole" || b.String() != "whole" {
+		t.Errorf("out = %q, written = %q", out, b.String())
+	}
+}
+
+func TestRunStreamsStage1ToBus(t *testing.T) {
+	b := bus.NewChannelBus()
+	defer b.Close()
+	deltas, _ := b.Subscribe(StreamTopicPrefix)
+
+	agents := []Agent{
+		&streamingAgent{mockAgent: mockAgent{name: "A", available: true}, chunks: []string{"hel", "lo"}},
+		&mockAgent{name: "B", available: true, response: "all at once"},
+	}
+	chair := []Agent{&mockAgent{name: "Chair", available: true, response: "synthesis"}}
+	result, err := Run(context.Background(), agents, chair, []ChunkPrompt{{Prompt: "q"}},
+		func(r []AgentResult) string { return "p" }, Options{Bus: b, StreamStage1: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Stage1Results[0].Output != "hello" {
+		t.Errorf("streamed output = %q, want the chunks joined", result.Stage1Results[0].Output)
+	}
+
+	got := make(map[string][]string)
+	for len(deltas) > 0 {
+		env := <-deltas
+		var d StreamDelta
+		if err := json.Unmarshal(env.Payload, &d); err != nil {
+			t.Fatal(err)
+		}
+		if env.Topic != StreamTopic(d.Agent) || env.Type != EventDelta || d.RunID != result.RunID || d.Attempt != 1 {
+			t.Errorf("delta %+v on %s (%s), want %s run %s attempt 1", d, env.Topic, env.Type, StreamTopic(d.Agent), result.RunID)
+		}
+		got[d.Agent] = append(got[d.Agent], d.Text)
+	}
+	if strings.Join(got["A"], "|") != "hel|lo" {
+		t.Errorf("A deltas = %q, want each chunk", got["A"])
+	}
+	if strings.Join(got["B"], "|") != "all at once" {
+		t.Errorf("B deltas = %q, want the response in one piece", got["B"])
+	}
+	if len(got) != 2 {
+		t.Errorf("deltas from %d agents, want stage 1 only", len(got))
+	}
+}
+
+func TestRunStage1Stream(t *testing.T) {
+	b := bus.NewChannelBus()
+	defer b.Close()
+	deltas, _ := b.Subscribe(StreamTopic("A"))
+
+	a := &streamingAgent{mockAgent: mockAgent{name: "A", available: true}, chunks: []string{"x", "y"}, err: errors.New("connection reset")}
+	results := RunStage1Stream(context.Background(), []Agent{a}, b)
+	if results[0].Err == nil || results[0].Output != "xy" {
+		t.Errorf("result = %+v, want the partial output and the stream error", results[0])
+	}
+	if n := len(deltas); n != 2 {
+		t.Errorf("published %d deltas, want 2", n)
+	}
+}
+
+func TestRunWithoutStreamStage1PublishesNoDeltas(t *testing.T) {
+	b := bus.NewChannelBus()
+	defer b.Close()
+	deltas, _ := b.Subscribe(StreamTopicPrefix)
+
+	agents := []Agent{&streamingAgent{mockAgent: mockAgent{name: "A", available: true, response: "batch"}, chunks: []string{"streamed"}}}
+	result, err := Run(context.Background(), agents, agents, []ChunkPrompt{{Prompt: "q"}},
+		func(r []AgentResult) string { return "p" }, Options{Bus: b})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Stage1Results[0].Output != "batch" || len(deltas) != 0 {
+		t.Errorf("output = %q with %d deltas, want the batch Run path", result.Stage1Results[0].Output, len(deltas))
+	}
+}
@@ -0,0 +1,81 @@
+package consensus
+
+import (
+	"regexp"
+	"strings"
+)
+
+// GateVerdict is the outcome of a unanimous gate (Options.RequireUnanimous).
+type GateVerdict string
+
+const (
+	GateApprove GateVerdict = "approve"
+	GateBlock   GateVerdict = "block"
+)
+
+const gateInstruction = "Finally, decide whether this should be approved as is. End your response with a line reading exactly GATE: APPROVE or GATE: BLOCK."
+
+var gateRe = regexp.MustCompile(`(?im)^[\s*_#` + "`" + `]*GATE\s*:?[\s*_` + "`" + `]*(APPROVE|BLOCK)\b`)
+
+// ParseGateSignal reads an agent's approve/block signal from its GATE line;
+// the last one wins. ok is false when output has none.
+func ParseGateSignal(output string) (verdict GateVerdict, ok bool) {
+	m := gateRe.FindAllStringSubmatch(output, -1)
+	if len(m) == 0 {
+		return GateBlock, false
+	}
+	return GateVerdict(strings.ToLower(m[len(m)-1][1])), true
+}
+
+// GateVote is one agent's signal in a unanimous gate.
+type GateVote struct {
+	Agent   string
+	Verdict GateVerdict
+	Missing bool // no GATE line; counted as a block
+}
+
+// UnanimousGate is a verdict computed from the stage 1 agents alone: approve
+// only if every agent that succeeded approved.
+type UnanimousGate struct {
+	Verdict GateVerdict
+	Votes   []GateVote
+}
+
+// Blockers names the agents whose votes blocked the gate.
+func (g *UnanimousGate) Blockers() []string {
+	var names []string
+	for _, v := range g.Votes {
+		if v.Verdict == GateBlock {
+			names = append(names, v.Agent)
+		}
+	}
+	return names
+}
+
+// EvaluateUnanimous computes the gate over the successful stage 1 results.
+// A chunked agent approves only if every chunk it answered approves, and an
+// agent without a GATE line blocks, so a malformed answer never passes.
+func EvaluateUnanimous(results []AgentResult) *UnanimousGate {
+	g := &UnanimousGate{Verdict: GateApprove}
+	index := make(map[string]int)
+	for _, r := range results {
+		if r.Err != nil {
+			continue
+		}
+		verdict, ok := ParseGateSignal(r.Output)
+		i, seen := index[r.Agent]
+		if !seen {
+			i = len(g.Votes)
+			index[r.Agent] = i
+			g.Votes = append(g.Votes, GateVote{Agent: r.Agent, Verdict: GateApprove})
+		}
+		if verdict == GateBlock {
+			g.Votes[i].Verdict = GateBlock
+			g.Votes[i].Missing = g.Votes[i].Missing || !ok
+		}
+	}
+	if len(g.Votes) == 0 || len(g.Blockers()) > 0 {
+		g.Verdict = GateBlock
+	}
+	return g
+}
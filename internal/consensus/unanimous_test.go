@@ -0,0 +1,102 @@
+package consensus
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRunRequireUnanimousBlocksOnOneObjection(t *testing.T) {
+	approver := &recordingAgent{mockAgent: mockAgent{name: "A", available: true, response: "Looks good.\nGATE: APPROVE"}}
+	agents := []Agent{
+		approver,
+		&mockAgent{name: "B", available: true, response: "Nil dereference in Load.\n**GATE: BLOCK**"},
+		&mockAgent{name: "C", available: true, response: "Fine by me.\nGATE: APPROVE"},
+		&mockAgent{name: "D", available: true, err: fmt.Errorf("timeout")},
+	}
+	// A lenient chairman approving anyway must not change the gate.
+	chairman := &mockAgent{name: "Chair", available: true, response: "All reviewers approve - safe to merge"}
+	result, err := Run(context.Background(), agents, []Agent{chairman}, []ChunkPrompt{{Prompt: "review"}}, func([]AgentResult) string { return "chair" }, Options{RequireUnanimous: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(approver.prompt, gateInstruction) {
+		t.Error("stage 1 prompt should ask for a GATE line")
+	}
+	if result.ChairmanOutput != "All reviewers approve - safe to merge" {
+		t.Errorf("synthesis should still run, got %q", result.ChairmanOutput)
+	}
+	g := result.Gate
+	if g == nil || g.Verdict != GateBlock {
+		t.Fatalf("gate = %+v, want block", g)
+	}
+	if blockers := g.Blockers(); len(blockers) != 1 || blockers[0] != "B" {
+		t.Errorf("blockers = %v, want [B]", blockers)
+	}
+	if len(g.Votes) != 3 {
+		t.Errorf("votes = %+v, want only the 3 successful agents", g.Votes)
+	}
+}
+
+func TestRunWithoutRequireUnanimousHasNoGate(t *testing.T) {
+	agents := []Agent{&mockAgent{name: "A", available: true, response: "GATE: BLOCK"}}
+	chairman := &mockAgent{name: "Chair", available: true, response: "synthesis"}
+	result, err := Run(context.Background(), agents, []Agent{chairman}, []ChunkPrompt{{Prompt: "q"}}, func([]AgentResult) string { return "chair" }, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Gate != nil {
+		t.Errorf("gate = %+v, want nil without Options.RequireUnanimous", result.Gate)
+	}
+}
+
+func TestEvaluateUnanimous(t *testing.T) {
+	approve := EvaluateUnanimous([]AgentResult{
+		{Agent: "A", Output: "ok\nGATE: APPROVE"},
+		{Agent: "B", Output: "gate: approve"},
+	})
+	if approve.Verdict != GateApprove {
+		t.Errorf("all approving: verdict = %s, want approve", approve.Verdict)
+	}
+
+	missing := EvaluateUnanimous([]AgentResult{
+		{Agent: "A", Output: "ok\nGATE: APPROVE"},
+		{Agent: "B", Output: "I would approve this"},
+	})
+	if missing.Verdict != GateBlock || !missing.Votes[1].Missing {
+		t.Errorf("missing signal: got %+v, want B blocking with Missing set", missing)
+	}
+
+	chunked := EvaluateUnanimous([]AgentResult{
+		{Agent: "A", Chunk: "a.go", Output: "GATE: APPROVE"},
+		{Agent: "A", Chunk: "b.go", Output: "GATE: BLOCK"},
+	})
+	if chunked.Verdict != GateBlock || len(chunked.Votes) != 1 {
+		t.Errorf("chunked: got %+v, want one blocking vote for A", chunked)
+	}
+
+	if none := EvaluateUnanimous(nil); none.Verdict != GateBlock {
+		t.Errorf("no votes: verdict = %s, want block", none.Verdict)
+	}
+}
+
+func TestParseGateSignal(t *testing.T) {
+	tests := []struct {
+		output string
+		want   GateVerdict
+		ok     bool
+	}{
+		{"fine\nGATE: APPROVE", GateApprove, true},
+		{"**GATE:** BLOCK", GateBlock, true},
+		{"GATE: BLOCK\non reflection\nGATE: APPROVE", GateApprove, true},
+		{"the gate: approve step", GateBlock, false},
+		{"no signal", GateBlock, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseGateSignal(tt.output)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ParseGateSignal(%q) = %s, %v; want %s, %v", tt.output, got, ok, tt.want, tt.ok)
+		}
+	}
+}
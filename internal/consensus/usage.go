@@ -0,0 +1,73 @@
+package consensus
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/signalnine/conclave/internal/config"
+)
+
+// usageKey is the context key of a usageSink.
+type usageKey struct{}
+
+// usageSink collects the tokens reported by the agent calls made with its
+// context, so a call's usage can be attributed even when the same agent
+// serves several calls at once.
+type usageSink struct {
+	prompt, completion atomic.Int64
+}
+
+func withUsageSink(ctx context.Context) (context.Context, *usageSink) {
+	s := &usageSink{}
+	return context.WithValue(ctx, usageKey{}, s), s
+}
+
+func (s *usageSink) usage() TokenUsage {
+	return TokenUsage{InputTokens: s.prompt.Load(), OutputTokens: s.completion.Load()}
+}
+
+// recordUsage adds the token counts an API reported for one call to ctx's
+// usage sink, if it has one. Agents call it after every response that
+// carries usage.
+func recordUsage(ctx context.Context, prompt, completion int64) {
+	if s, ok := ctx.Value(usageKey{}).(*usageSink); ok {
+		s.prompt.Add(prompt)
+		s.completion.Add(completion)
+	}
+}
+
+// Add returns the sum of two usages.
+func (u TokenUsage) Add(o TokenUsage) TokenUsage {
+	return TokenUsage{InputTokens: u.InputTokens + o.InputTokens, OutputTokens: u.OutputTokens + o.OutputTokens}
+}
+
+// EstimateCost prices usage of model, returning 0 for a model without a
+// price.
+func EstimateCost(prices map[string]config.ModelPrice, model string, u TokenUsage) float64 {
+	p, ok := config.PriceOf(prices, model)
+	if !ok {
+		return 0
+	}
+	return (float64(u.InputTokens)*p.Prompt + float64(u.OutputTokens)*p.Completion) / 1e6
+}
+
+// priced sets the CostUSD of each result from the model of the agent that
+// produced it, looked up by name among agents.
+func priced(results []AgentResult, prices map[string]config.ModelPrice, agents ...Agent) {
+	for i, r := range results {
+		if a := findAgent(r.Agent, agents...); a != nil {
+			results[i].CostUSD = EstimateCost(prices, agentModel(a), r.Tokens())
+		}
+	}
+}
+
+// usageOf sums the token counts and costs of results.
+func usageOf(results ...AgentResult) (TokenUsage, float64) {
+	var u TokenUsage
+	var cost float64
+	for _, r := range results {
+		u = u.Add(r.Tokens())
+		cost += r.CostUSD
+	}
+	return u, cost
+}
@@ -0,0 +1,86 @@
+package consensus
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"testing"
+
+	"github.com/signalnine/conclave/internal/config"
+)
+
+// usageAgent reports prompt and completion tokens for every call, failing
+// the first failures calls.
+type usageAgent struct {
+	mockAgent
+	model              string
+	prompt, completion int64
+	failures           int32
+	calls              atomic.Int32
+}
+
+func (u *usageAgent) Model() string { return u.model }
+
+func (u *usageAgent) Run(ctx context.Context, prompt string) (string, error) {
+	recordUsage(ctx, u.prompt, u.completion)
+	if u.calls.Add(1) <= u.failures {
+		return "", fmt.Errorf("transient error")
+	}
+	return u.mockAgent.Run(ctx, prompt)
+}
+
+func TestRunSumsTokensAndCost(t *testing.T) {
+	prices := map[string]config.ModelPrice{"big": {Prompt: 10, Completion: 30}}
+	a := &usageAgent{mockAgent: mockAgent{name: "A", available: true, response: "a"}, model: "big-2025", prompt: 1000, completion: 100}
+	b := &usageAgent{mockAgent: mockAgent{name: "B", available: true, response: "b"}, model: "unpriced", prompt: 500, completion: 50}
+	plain := &mockAgent{name: "C", available: true, response: "c"}
+	chair := &usageAgent{mockAgent: mockAgent{name: "Chair", available: true, response: "synthesis"}, model: "big", prompt: 2000, completion: 400}
+
+	result, err := Run(context.Background(), []Agent{a, b, plain}, []Agent{chair}, []ChunkPrompt{{Prompt: "q"}},
+		func([]AgentResult) string { return "chair" }, Options{Prices: prices})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := result.Stage1Results
+	if r[0].PromptTokens != 1000 || r[0].CompletionTokens != 100 || math.Abs(r[0].CostUSD-0.013) > 1e-9 {
+		t.Errorf("A = %+v, want 1000/100 tokens at $0.013", r[0])
+	}
+	if r[1].CostUSD != 0 || r[1].PromptTokens != 500 {
+		t.Errorf("B = %+v, want tokens but no cost for an unpriced model", r[1])
+	}
+	if r[2].PromptTokens != 0 || r[2].CostUSD != 0 {
+		t.Errorf("C = %+v, want zero usage from an agent that does not report it", r[2])
+	}
+	if want := (TokenUsage{InputTokens: 3500, OutputTokens: 550}); result.Tokens != want {
+		t.Errorf("total tokens = %+v, want %+v", result.Tokens, want)
+	}
+	if math.Abs(result.CostUSD-0.045) > 1e-9 {
+		t.Errorf("total cost = %f, want 0.045 (A + chairman)", result.CostUSD)
+	}
+}
+
+func TestRunCountsRetryTokens(t *testing.T) {
+	a := &usageAgent{mockAgent: mockAgent{name: "A", available: true, response: "a"}, prompt: 10, completion: 1, failures: 1}
+	chair := &mockAgent{name: "Chair", available: true, response: "synthesis"}
+
+	result, err := Run(context.Background(), []Agent{a}, []Agent{chair}, []ChunkPrompt{{Prompt: "q"}},
+		func([]AgentResult) string { return "chair" }, Options{RetryPolicy: RetryPolicy{Max: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := result.Stage1Results[0]; r.Retries != 1 || r.PromptTokens != 20 || r.CompletionTokens != 2 {
+		t.Errorf("result = %+v, want the failed call's tokens counted too", r)
+	}
+}
+
+func TestEstimateCost(t *testing.T) {
+	prices := map[string]config.ModelPrice{"gpt-4o": {Prompt: 2.5, Completion: 10}, "gpt-4o-mini": {Prompt: 0.15, Completion: 0.6}}
+	u := TokenUsage{InputTokens: 1_000_000, OutputTokens: 1_000_000}
+	if got := EstimateCost(prices, "gpt-4o-mini-2024-07-18", u); math.Abs(got-0.75) > 1e-9 {
+		t.Errorf("longest prefix: cost = %f, want 0.75", got)
+	}
+	if got := EstimateCost(prices, "llama3", u); got != 0 {
+		t.Errorf("unpriced: cost = %f, want 0", got)
+	}
+}
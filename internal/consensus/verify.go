@@ -0,0 +1,65 @@
+package consensus
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ConsistencyCheck is the chairman's review of its own synthesis for
+// internal contradictions (see Options.Verify).
+type ConsistencyCheck struct {
+	Agent   string
+	Output  string
+	Flagged bool  // the check reported contradictions
+	Err     error // the check itself failed; the synthesis still stands
+}
+
+var verdictRe = regexp.MustCompile(`(?im)^\s*\**VERDICT:?\**\s*:?\s*\**(CONSISTENT|CONTRADICTIONS)\b`)
+
+// parseVerdict reports whether a consistency check flagged contradictions.
+// The last VERDICT line wins; output without one is treated as flagged so a
+// malformed check never passes silently.
+func parseVerdict(output string) bool {
+	m := verdictRe.FindAllStringSubmatch(output, -1)
+	if len(m) == 0 {
+		return true
+	}
+	return strings.EqualFold(m[len(m)-1][1], "CONTRADICTIONS")
+}
+
+// verifySynthesis asks the chairman that wrote synthesis to check it for
+// internal contradictions.
+func verifySynthesis(ctx context.Context, chairman Agent, synthesis string, timeoutSec int) *ConsistencyCheck {
+	fmt.Fprintf(os.Stderr, "\nConsistency check: %s reviewing its synthesis...\n", chairman.Name())
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeoutSec)*time.Second)
+	defer cancel()
+
+	out, err := chairman.Run(ctx, BuildConsistencyCheckPrompt(synthesis))
+	check := &ConsistencyCheck{Agent: chairman.Name(), Output: out, Err: asAgentError(chairman.Name(), err)}
+	switch {
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "  Consistency check failed: %s\n", describeFailure(check.Err))
+	default:
+		check.Flagged = parseVerdict(out)
+		if check.Flagged {
+			fmt.Fprintln(os.Stderr, "  WARNING: consistency check flagged contradictions in the synthesis")
+		} else {
+			fmt.Fprintln(os.Stderr, "  Synthesis is internally consistent")
+		}
+	}
+	return check
+}
+
+// findAgent returns the agent named name among candidates, or nil.
+func findAgent(name string, candidates ...Agent) Agent {
+	for _, a := range candidates {
+		if a != nil && a.Name() == name {
+			return a
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package consensus
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// scriptedAgent returns its responses in order, one per call.
+type scriptedAgent struct {
+	mockAgent
+	mu        sync.Mutex
+	responses []string
+	prompts   []string
+}
+
+func (s *scriptedAgent) Run(ctx context.Context, prompt string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prompts = append(s.prompts, prompt)
+	if len(s.responses) == 0 {
+		return "", nil
+	}
+	out := s.responses[0]
+	s.responses = s.responses[1:]
+	return out, nil
+}
+
+func TestRunVerifyFlagsContradictions(t *testing.T) {
+	synthesis := "All reviewers agree the change is safe. Recommendation: do not merge, the change is unsafe."
+	chair := &scriptedAgent{
+		mockAgent: mockAgent{name: "Chair", available: true},
+		responses: []string{
+			synthesis,
+			"1. \"the change is safe\" conflicts with \"the change is unsafe\".\n\nVERDICT: CONTRADICTIONS",
+		},
+	}
+	agents := []Agent{&mockAgent{name: "A", available: true, response: "looks fine"}}
+
+	result, err := Run(context.Background(), agents, []Agent{chair}, []ChunkPrompt{{Prompt: "p"}},
+		func([]AgentResult) string { return "synthesize" }, Options{Verify: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ChairmanOutput != synthesis {
+		t.Errorf("ChairmanOutput = %q", result.ChairmanOutput)
+	}
+	c := result.Consistency
+	if c == nil || !c.Flagged || c.Agent != "Chair" {
+		t.Fatalf("Consistency = %+v, want flagged by Chair", c)
+	}
+	if len(chair.prompts) != 2 || !strings.Contains(chair.prompts[1], synthesis) {
+		t.Errorf("verify prompt should contain the synthesis: %q", chair.prompts)
+	}
+}
+
+func TestRunVerifyConsistent(t *testing.T) {
+	chair := &scriptedAgent{
+		mockAgent: mockAgent{name: "Chair", available: true},
+		responses: []string{"Merge it.", "No contradictions found.\n**VERDICT:** CONSISTENT"},
+	}
+	agents := []Agent{&mockAgent{name: "A", available: true, response: "fine"}}
+
+	result, err := Run(context.Background(), agents, []Agent{chair}, []ChunkPrompt{{Prompt: "p"}},
+		func([]AgentResult) string { return "synthesize" }, Options{Verify: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Consistency == nil || result.Consistency.Flagged {
+		t.Errorf("Consistency = %+v, want not flagged", result.Consistency)
+	}
+}
+
+func TestRunWithoutVerify(t *testing.T) {
+	chair := &scriptedAgent{mockAgent: mockAgent{name: "Chair", available: true}, responses: []string{"Merge it."}}
+	agents := []Agent{&mockAgent{name: "A", available: true, response: "fine"}}
+
+	result, err := Run(context.Background(), agents, []Agent{chair}, []ChunkPrompt{{Prompt: "p"}},
+		func([]AgentResult) string { return "synthesize" }, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Consistency != nil || len(chair.prompts) != 1 {
+		t.Errorf("verify should not run unless enabled: %+v, %d calls", result.Consistency, len(chair.prompts))
+	}
+}
+
+func TestParseVerdict(t *testing.T) {
+	tests := []struct {
+		output string
+		want   bool
+	}{
+		{"VERDICT: CONSISTENT", false},
+		{"verdict: consistent", false},
+		{"Found one.\nVERDICT: CONTRADICTIONS", true},
+		{"VERDICT: CONTRADICTIONS\nOn reflection:\nVERDICT: CONSISTENT", false},
+		{"no verdict line at all", true},
+	}
+	for _, tt := range tests {
+		if got := parseVerdict(tt.output); got != tt.want {
+			t.Errorf("parseVerdict(%q) = %v, want %v", tt.output, got, tt.want)
+		}
+	}
+}
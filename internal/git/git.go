@@ -1,23 +1,48 @@
 package git
 
 import (
+	"bytes"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 )
 
+// EmptyTree is the hash of git's empty tree, usable as a diff base for
+// changes that include the root commit.
+const EmptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// DefaultDiffContext is git's default number of context lines around hunks.
+const DefaultDiffContext = 3
+
+// Runner executes git with args in dir and returns its combined output.
+type Runner func(dir string, args ...string) ([]byte, error)
+
 type Git struct {
-	Dir string
+	Dir    string
+	Runner Runner // nil runs the git binary; tests inject a fake
 }
 
 func New(dir string) *Git {
 	return &Git{Dir: dir}
 }
 
-func (g *Git) run(args ...string) (string, error) {
+func execRunner(dir string, args ...string) ([]byte, error) {
 	cmd := exec.Command("git", args...)
-	cmd.Dir = g.Dir
-	out, err := cmd.CombinedOutput()
+	cmd.Dir = dir
+	return cmd.CombinedOutput()
+}
+
+func (g *Git) run(args ...string) (string, error) {
+	runner := g.Runner
+	if runner == nil {
+		runner = execRunner
+	}
+	out, err := runner(g.Dir, args...)
 	if err != nil {
 		return "", fmt.Errorf("git %s: %s %w", strings.Join(args, " "), string(out), err)
 	}
@@ -37,24 +62,167 @@ func (g *Git) WorktreeAdd(path, branch, base string) error {
 	return err
 }
 
-func (g *Git) WorktreeRemove(path string) error {
-	_, err := g.run("worktree", "remove", path, "--force")
-	return err
-}
-
 func (g *Git) WorktreePrune() error {
 	_, err := g.run("worktree", "prune")
 	return err
 }
 
+// WorktreeRemove removes the worktree at path, relative to the repository
+// directory like WorktreeAdd's, uncommitted changes and all. A worktree git
+// cannot remove (locked, a directory already half gone) has its directory
+// deleted and its registration pruned. It refuses the main worktree and a
+// directory that is not a worktree of this repository; a path that no
+// longer exists is only pruned.
+func (g *Git) WorktreeRemove(path string) error {
+	path = g.path(path)
+	abs := canonicalPath(path)
+	worktrees, err := g.worktreePaths()
+	if err != nil {
+		return err
+	}
+	if len(worktrees) > 0 && worktrees[0] == abs {
+		return fmt.Errorf("refusing to remove the main worktree %s", path)
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return g.WorktreePrune()
+	}
+	if !slices.Contains(worktrees, abs) {
+		return fmt.Errorf("%s is not a worktree of this repository", path)
+	}
+	// A second --force also removes a locked worktree.
+	_, err = g.run("worktree", "remove", "--force", "--force", path)
+	if err == nil {
+		return nil
+	}
+	if rmErr := os.RemoveAll(path); rmErr != nil {
+		return fmt.Errorf("%w; removing the directory: %v", err, rmErr)
+	}
+	return g.WorktreePrune()
+}
+
+// worktreePaths lists the repository's worktrees, the main one first.
+func (g *Git) worktreePaths() ([]string, error) {
+	out, err := g.run("worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, line := range strings.Split(out, "\n") {
+		if p, ok := strings.CutPrefix(line, "worktree "); ok {
+			paths = append(paths, canonicalPath(p))
+		}
+	}
+	return paths, nil
+}
+
+// path resolves a path relative to the repository directory, as git
+// commands run there do.
+func (g *Git) path(p string) string {
+	if filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(g.Dir, p)
+}
+
+// canonicalPath makes path absolute with symlinks resolved, as far as it
+// exists, so it compares equal to the paths git reports.
+func canonicalPath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	if real, err := filepath.EvalSymlinks(abs); err == nil {
+		return real
+	}
+	return abs
+}
+
+// CountCommits returns the number of commits reachable from head but not
+// from base.
+func (g *Git) CountCommits(base, head string) (int, error) {
+	out, err := g.run("rev-list", "--count", base+".."+head)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(out)
+}
+
 func (g *Git) MergeBase(a, b string) (string, error) {
 	return g.run("merge-base", a, b)
 }
 
+// FirstCommitSince returns the oldest commit reachable from head whose
+// committer date is at or after since.
+func (g *Git) FirstCommitSince(since time.Time, head string) (string, error) {
+	out, err := g.run("rev-list", "--reverse", "--since="+since.Format(time.RFC3339), head)
+	if err != nil {
+		return "", err
+	}
+	if out == "" {
+		return "", fmt.Errorf("no commits on %s since %s", head, since.Format(time.RFC3339))
+	}
+	first, _, _ := strings.Cut(out, "\n")
+	return first, nil
+}
+
+// ParentOf returns the first parent of sha, or EmptyTree for a root commit.
+func (g *Git) ParentOf(sha string) (string, error) {
+	if !g.HasCommit(sha) {
+		return "", fmt.Errorf("unknown commit %s", sha)
+	}
+	parent, err := g.run("rev-parse", "--verify", "--quiet", sha+"^")
+	if err != nil {
+		return EmptyTree, nil
+	}
+	return parent, nil
+}
+
 func (g *Git) Diff(base, head string) (string, error) {
 	return g.run("diff", base, head)
 }
 
+// DiffContext is Diff with lines of context around each hunk (git diff -U).
+func (g *Git) DiffContext(base, head string, lines int) (string, error) {
+	if lines < 0 {
+		return "", fmt.Errorf("diff context must be >= 0, got %d", lines)
+	}
+	return g.run("diff", fmt.Sprintf("-U%d", lines), base, head)
+}
+
+// WorkingDiff is the diff from base to the working tree, tracked changes
+// first, then each untracked (not ignored) text file as an added file, so
+// work that was never committed or staged is included.
+func (g *Git) WorkingDiff(base string) (string, error) {
+	diff, err := g.run("diff", base)
+	if err != nil {
+		return "", err
+	}
+	untracked, err := g.run("ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	b.WriteString(diff)
+	for _, path := range strings.Split(untracked, "\x00") {
+		if path == "" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(g.Dir, path))
+		if err != nil || bytes.IndexByte(data, 0) >= 0 {
+			continue // unreadable or binary
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "diff --git a/%s b/%s\nnew file\n--- /dev/null\n+++ b/%s\n", path, path, path)
+		for _, line := range strings.SplitAfter(strings.TrimSuffix(string(data), "\n"), "\n") {
+			b.WriteString("+" + line)
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
 func (g *Git) DiffNameOnly(base, head string) ([]string, error) {
 	out, err := g.run("diff", "--name-only", base, head)
 	if err != nil {
@@ -81,6 +249,12 @@ func (g *Git) ResetHard(ref string) error {
 	return err
 }
 
+// ResetSoft moves HEAD to ref, keeping the index and working tree.
+func (g *Git) ResetSoft(ref string) error {
+	_, err := g.run("reset", "--soft", ref)
+	return err
+}
+
 func (g *Git) Commit(msg string) error {
 	_, err := g.run("commit", "-m", msg)
 	return err
@@ -135,6 +309,59 @@ func (g *Git) StatusPorcelain() (string, error) {
 	return g.run("status", "--porcelain")
 }
 
+// ChangedPaths lists the files that differ from HEAD in the index or working
+// tree, plus untracked files, relative to the repository root. Renames list
+// both the new and the original path.
+func (g *Git) ChangedPaths() ([]string, error) {
+	out, err := g.run("status", "--porcelain=v2", "-z", "--untracked-files=all")
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	records := strings.Split(out, "\x00")
+	for i := 0; i < len(records); i++ {
+		rec := records[i]
+		// Porcelain v2: "1 XY sub mH mI mW hH hI path", "2 ... score path\0orig",
+		// "u XY sub m1 m2 m3 mW h1 h2 h3 path", "? path".
+		switch {
+		case strings.HasPrefix(rec, "1 "):
+			paths = append(paths, fieldAfter(rec, 8))
+		case strings.HasPrefix(rec, "2 "):
+			paths = append(paths, fieldAfter(rec, 9))
+			if i+1 < len(records) {
+				i++
+				paths = append(paths, records[i])
+			}
+		case strings.HasPrefix(rec, "u "):
+			paths = append(paths, fieldAfter(rec, 10))
+		case strings.HasPrefix(rec, "? "):
+			paths = append(paths, rec[2:])
+		}
+	}
+	return paths, nil
+}
+
+// fieldAfter returns what follows the first n space-separated fields of rec.
+func fieldAfter(rec string, n int) string {
+	parts := strings.SplitN(rec, " ", n+1)
+	return parts[len(parts)-1]
+}
+
 func (g *Git) Log(format string, n int) (string, error) {
 	return g.run("log", fmt.Sprintf("--format=%s", format), fmt.Sprintf("-n%d", n))
 }
+
+func (g *Git) RemoteURL(name string) (string, error) {
+	return g.run("remote", "get-url", name)
+}
+
+func (g *Git) Fetch(remote string, refspecs ...string) error {
+	args := append([]string{"fetch", remote}, refspecs...)
+	_, err := g.run(args...)
+	return err
+}
+
+func (g *Git) HasCommit(sha string) bool {
+	_, err := g.run("cat-file", "-e", sha+"^{commit}")
+	return err == nil
+}
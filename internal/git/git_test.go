@@ -1,10 +1,13 @@
 package git
 
 import (
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func setupTestRepo(t *testing.T) string {
@@ -91,6 +94,38 @@ func TestDiff(t *testing.T) {
 	}
 }
 
+func TestWorkingDiff(t *testing.T) {
+	dir := setupTestRepo(t)
+	g := New(dir)
+	base, err := g.RevParse("HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(dir, "committed.txt"), []byte("one\n"), 0644)
+	run(t, dir, "git", "add", "committed.txt")
+	run(t, dir, "git", "commit", "-m", "add committed")
+	os.WriteFile(filepath.Join(dir, "committed.txt"), []byte("one\ntwo\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "new.go"), []byte("package x\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "blob.bin"), []byte{0, 1, 2}, 0644)
+	os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("*.log\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "debug.log"), []byte("noise\n"), 0644)
+
+	diff, err := g.WorkingDiff(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"+one", "+two", "+++ b/new.go", "+package x"} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("diff missing %q:\n%s", want, diff)
+		}
+	}
+	for _, unwanted := range []string{"blob.bin", "debug.log"} {
+		if strings.Contains(diff, unwanted) {
+			t.Errorf("diff should skip %s:\n%s", unwanted, diff)
+		}
+	}
+}
+
 func TestDiffNameOnly(t *testing.T) {
 	dir := setupTestRepo(t)
 	g := New(dir)
@@ -106,6 +141,31 @@ func TestDiffNameOnly(t *testing.T) {
 	}
 }
 
+func TestChangedPaths(t *testing.T) {
+	dir := setupTestRepo(t)
+	g := New(dir)
+	os.WriteFile(filepath.Join(dir, "old.txt"), []byte("old"), 0644)
+	os.WriteFile(filepath.Join(dir, "mod.txt"), []byte("mod"), 0644)
+	run(t, dir, "git", "add", ".")
+	run(t, dir, "git", "commit", "-m", "add files")
+
+	os.WriteFile(filepath.Join(dir, "mod.txt"), []byte("changed"), 0644)
+	run(t, dir, "git", "mv", "old.txt", "renamed.txt")
+	os.MkdirAll(filepath.Join(dir, "sub"), 0755)
+	os.WriteFile(filepath.Join(dir, "sub", "with space.txt"), []byte("new"), 0644)
+
+	paths, err := g.ChangedPaths()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := strings.Join(paths, ",")
+	for _, want := range []string{"mod.txt", "renamed.txt", "old.txt", "sub/with space.txt"} {
+		if !strings.Contains(","+got+",", ","+want+",") {
+			t.Errorf("ChangedPaths() = %v, missing %q", paths, want)
+		}
+	}
+}
+
 func TestMergeSquash(t *testing.T) {
 	dir := setupTestRepo(t)
 	g := New(dir)
@@ -148,3 +208,199 @@ func TestRevParse(t *testing.T) {
 		t.Errorf("sha length = %d, want 40", len(sha))
 	}
 }
+
+func TestHasCommit(t *testing.T) {
+	dir := setupTestRepo(t)
+	g := New(dir)
+	sha, _ := g.RevParse("HEAD")
+	if !g.HasCommit(sha) {
+		t.Error("HEAD should exist")
+	}
+	if g.HasCommit("0123456789012345678901234567890123456789") {
+		t.Error("unknown SHA should not exist")
+	}
+}
+
+func TestRemoteURL(t *testing.T) {
+	dir := setupTestRepo(t)
+	g := New(dir)
+	run(t, dir, "git", "remote", "add", "origin", "git@github.com:o/r.git")
+	url, err := g.RemoteURL("origin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != "git@github.com:o/r.git" {
+		t.Errorf("got %q", url)
+	}
+}
+
+func commitAt(t *testing.T, dir, msg string, when time.Time) {
+	t.Helper()
+	cmd := exec.Command("git", "commit", "--allow-empty", "-m", msg)
+	cmd.Dir = dir
+	date := when.Format(time.RFC3339)
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("commit %q: %s %v", msg, out, err)
+	}
+}
+
+func TestFirstCommitSince(t *testing.T) {
+	dir := t.TempDir()
+	run(t, dir, "git", "init", "-b", "main")
+	run(t, dir, "git", "config", "user.email", "test@test.com")
+	run(t, dir, "git", "config", "user.name", "Test")
+	now := time.Now()
+	commitAt(t, dir, "last week", now.Add(-7*24*time.Hour))
+	commitAt(t, dir, "yesterday", now.Add(-26*time.Hour))
+	commitAt(t, dir, "this morning", now.Add(-3*time.Hour))
+	commitAt(t, dir, "just now", now.Add(-time.Minute))
+
+	g := New(dir)
+	first, err := g.FirstCommitSince(now.Add(-24*time.Hour), "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg, _ := g.run("log", "-1", "--format=%s", first); msg != "this morning" {
+		t.Errorf("first commit since 24h = %q, want this morning", msg)
+	}
+
+	parent, err := g.ParentOf(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg, _ := g.run("log", "-1", "--format=%s", parent); msg != "yesterday" {
+		t.Errorf("parent = %q, want yesterday", msg)
+	}
+
+	if _, err := g.FirstCommitSince(now.Add(time.Hour), "HEAD"); err == nil {
+		t.Error("expected error when no commits are recent enough")
+	}
+
+	root, _ := g.FirstCommitSince(now.Add(-30*24*time.Hour), "HEAD")
+	if p, _ := g.ParentOf(root); p != EmptyTree {
+		t.Errorf("ParentOf(root) = %q, want empty tree", p)
+	}
+}
+
+func TestDiffContextPassesUnifiedFlag(t *testing.T) {
+	var got []string
+	g := &Git{Dir: "repo", Runner: func(dir string, args ...string) ([]byte, error) {
+		if dir != "repo" {
+			t.Errorf("ran in %q, want repo", dir)
+		}
+		got = args
+		return []byte("diff --git a/x b/x\n"), nil
+	}}
+	if _, err := g.DiffContext("base", "head", 10); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"diff", "-U10", "base", "head"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("git args = %v, want %v", got, want)
+	}
+	if _, err := g.DiffContext("base", "head", -1); err == nil {
+		t.Error("negative context should be rejected")
+	}
+}
+
+func TestDiffContextWidensHunks(t *testing.T) {
+	dir := setupTestRepo(t)
+	var lines []string
+	for i := 1; i <= 20; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	os.WriteFile(filepath.Join(dir, "f.txt"), []byte(strings.Join(lines, "\n")+"\n"), 0o644)
+	run(t, dir, "git", "add", "f.txt")
+	run(t, dir, "git", "commit", "-m", "add f")
+	lines[9] = "changed"
+	os.WriteFile(filepath.Join(dir, "f.txt"), []byte(strings.Join(lines, "\n")+"\n"), 0o644)
+	run(t, dir, "git", "commit", "-am", "change f")
+
+	g := New(dir)
+	narrow, err := g.DiffContext("HEAD~1", "HEAD", DefaultDiffContext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wide, err := g.DiffContext("HEAD~1", "HEAD", 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(narrow, "line 3\n") || !strings.Contains(wide, "line 3\n") {
+		t.Errorf("-U8 should include line 3 and -U3 should not:\nnarrow:\n%s\nwide:\n%s", narrow, wide)
+	}
+}
+
+func TestWorktreeSharesHistory(t *testing.T) {
+	dir := setupTestRepo(t)
+	g := New(dir)
+	base, _ := g.RevParse("HEAD")
+
+	if err := g.WorktreeAdd("wt-task", "task", base); err != nil {
+		t.Fatal(err)
+	}
+	wt := New(filepath.Join(dir, "wt-task"))
+	os.WriteFile(filepath.Join(wt.Dir, "task.txt"), []byte("work\n"), 0o644)
+	run(t, wt.Dir, "git", "add", "task.txt")
+	run(t, wt.Dir, "git", "commit", "-m", "task work")
+
+	head, err := wt.RevParse("HEAD")
+	if err != nil || head == base {
+		t.Fatalf("worktree HEAD = %q (%v), want a new commit", head, err)
+	}
+	if mainHead, _ := g.RevParse("HEAD"); mainHead != base {
+		t.Errorf("main HEAD moved to %s", mainHead)
+	}
+	if mb, err := g.MergeBase(base, head); err != nil || mb != base {
+		t.Errorf("MergeBase from the main repo = %q (%v), want %s", mb, err, base)
+	}
+	diff, err := wt.Diff(base, "HEAD")
+	if err != nil || !strings.Contains(diff, "+work") {
+		t.Errorf("worktree diff = %q (%v)", diff, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "task.txt")); !os.IsNotExist(err) {
+		t.Error("worktree changes leaked into the main checkout")
+	}
+}
+
+func TestWorktreeRemoveCleansUp(t *testing.T) {
+	dir := setupTestRepo(t)
+	g := New(dir)
+
+	// Uncommitted changes and a lock don't keep a worktree around.
+	dirty := New(filepath.Join(dir, "wt-dirty"))
+	if err := g.WorktreeAdd(dirty.Dir, "dirty", "HEAD"); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(dirty.Dir, "half-done.txt"), []byte("x"), 0o644)
+	run(t, dir, "git", "worktree", "lock", dirty.Dir)
+	if err := g.WorktreeRemove(dirty.Dir); err != nil {
+		t.Fatalf("removing a dirty, locked worktree: %v", err)
+	}
+	if _, err := os.Stat(dirty.Dir); !os.IsNotExist(err) {
+		t.Error("dirty worktree still on disk")
+	}
+
+	// A worktree whose directory is already gone is pruned.
+	gone := New(filepath.Join(dir, "wt-gone"))
+	g.WorktreeAdd(gone.Dir, "gone", "HEAD")
+	os.RemoveAll(gone.Dir)
+	if err := g.WorktreeRemove(gone.Dir); err != nil {
+		t.Fatal(err)
+	}
+	if out, _ := exec.Command("git", "-C", dir, "worktree", "list").Output(); strings.Contains(string(out), "wt-") {
+		t.Errorf("worktrees left registered:\n%s", out)
+	}
+
+	if err := g.WorktreeRemove(dir); err == nil {
+		t.Error("removing the main worktree should fail")
+	}
+	plain := filepath.Join(dir, "not-a-worktree")
+	os.Mkdir(plain, 0o755)
+	if err := g.WorktreeRemove(plain); err == nil {
+		t.Error("removing a plain directory should fail")
+	}
+	if _, err := os.Stat(plain); err != nil {
+		t.Errorf("plain directory was deleted: %v", err)
+	}
+}
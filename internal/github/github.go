@@ -0,0 +1,106 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// Client is a minimal GitHub REST API client.
+type Client struct {
+	BaseURL string
+	Token   string
+	HTTP    *http.Client
+}
+
+func New(baseURL, token string) *Client {
+	return &Client{BaseURL: baseURL, Token: token, HTTP: http.DefaultClient}
+}
+
+// PullRequest holds the fields of a pull request needed for review.
+type PullRequest struct {
+	Number  int
+	Title   string
+	Body    string
+	BaseSHA string
+	HeadSHA string
+	BaseRef string
+	HeadRef string
+}
+
+// PullRequest fetches a pull request by number.
+func (c *Client) PullRequest(ctx context.Context, owner, repo string, number int) (*PullRequest, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls/%d", strings.TrimRight(c.BaseURL, "/"), owner, repo, number)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		// The body is GitHub's JSON error if we are lucky, a proxy's HTML
+		// page if not; the status is what counts.
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		msg := apiErr.Message
+		if msg == "" {
+			msg = resp.Status
+		}
+		return nil, fmt.Errorf("GitHub API error (%d): %s", resp.StatusCode, msg)
+	}
+
+	var result struct {
+		Number int    `json:"number"`
+		Title  string `json:"title"`
+		Body   string `json:"body"`
+		Base   struct {
+			SHA string `json:"sha"`
+			Ref string `json:"ref"`
+		} `json:"base"`
+		Head struct {
+			SHA string `json:"sha"`
+			Ref string `json:"ref"`
+		} `json:"head"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+	if result.Base.SHA == "" || result.Head.SHA == "" {
+		return nil, fmt.Errorf("pull request #%d missing base or head SHA", number)
+	}
+	return &PullRequest{
+		Number:  result.Number,
+		Title:   result.Title,
+		Body:    result.Body,
+		BaseSHA: result.Base.SHA,
+		HeadSHA: result.Head.SHA,
+		BaseRef: result.Base.Ref,
+		HeadRef: result.Head.Ref,
+	}, nil
+}
+
+var remoteRe = regexp.MustCompile(`github\.com[:/]([^/]+)/([^/]+?)(?:\.git)?/?$`)
+
+// ParseRemote extracts owner and repo from a GitHub remote URL
+// (https://github.com/o/r.git or git@github.com:o/r.git).
+func ParseRemote(url string) (owner, repo string, err error) {
+	m := remoteRe.FindStringSubmatch(strings.TrimSpace(url))
+	if m == nil {
+		return "", "", fmt.Errorf("not a GitHub remote: %q", url)
+	}
+	return m[1], m[2], nil
+}
@@ -0,0 +1,89 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseRemote(t *testing.T) {
+	tests := []struct {
+		url       string
+		wantOwner string
+		wantRepo  string
+		wantErr   bool
+	}{
+		{"https://github.com/signalnine/conclave.git", "signalnine", "conclave", false},
+		{"https://github.com/signalnine/conclave", "signalnine", "conclave", false},
+		{"git@github.com:signalnine/conclave.git", "signalnine", "conclave", false},
+		{"ssh://git@github.com/signalnine/conclave.git", "signalnine", "conclave", false},
+		{"https://gitlab.com/someone/else.git", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			owner, repo, err := ParseRemote(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if owner != tt.wantOwner || repo != tt.wantRepo {
+				t.Errorf("got %s/%s, want %s/%s", owner, repo, tt.wantOwner, tt.wantRepo)
+			}
+		})
+	}
+}
+
+func TestPullRequest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/o/r/pulls/123" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer gh-test" {
+			t.Error("missing auth header")
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"number": 123,
+			"title":  "Add feature",
+			"body":   "Details",
+			"base":   map[string]any{"sha": "aaa", "ref": "main"},
+			"head":   map[string]any{"sha": "bbb", "ref": "feature"},
+		})
+	}))
+	defer srv.Close()
+
+	pr, err := New(srv.URL, "gh-test").PullRequest(context.Background(), "o", "r", 123)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr.BaseSHA != "aaa" || pr.HeadSHA != "bbb" || pr.Title != "Add feature" {
+		t.Errorf("pr = %+v", pr)
+	}
+}
+
+func TestPullRequestNotFound(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]any{"message": "Not Found"})
+	}))
+	defer srv.Close()
+
+	_, err := New(srv.URL, "").PullRequest(context.Background(), "o", "r", 9)
+	if err == nil || !strings.Contains(err.Error(), "Not Found") {
+		t.Errorf("err = %v, want GitHub's message for a missing PR", err)
+	}
+}
+
+func TestPullRequestNonJSONError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte("<html><body>502 Bad Gateway</body></html>"))
+	}))
+	defer srv.Close()
+
+	_, err := New(srv.URL, "").PullRequest(context.Background(), "o", "r", 9)
+	if err == nil || !strings.Contains(err.Error(), "502") || strings.Contains(err.Error(), "decode") {
+		t.Errorf("err = %v, want the HTTP status rather than a decode error", err)
+	}
+}
@@ -0,0 +1,141 @@
+package hook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/signalnine/conclave/internal/bus"
+)
+
+// MaxToolResultBytes bounds how much of a tool's result is copied into a
+// board entry.
+const MaxToolResultBytes = 500
+
+// maxCommandBytes bounds the shell command quoted in a summary.
+const maxCommandBytes = 200
+
+// ToolUse is the PostToolUse hook input sent on stdin.
+type ToolUse struct {
+	SessionID    string          `json:"session_id"`
+	Cwd          string          `json:"cwd"`
+	ToolName     string          `json:"tool_name"`
+	ToolInput    json.RawMessage `json:"tool_input"`
+	ToolResponse json.RawMessage `json:"tool_response"`
+}
+
+// toolUsePayload is the board.context payload recorded for a tool use.
+type toolUsePayload struct {
+	Text   string   `json:"text"`
+	Tool   string   `json:"tool"`
+	Files  []string `json:"files,omitempty"`
+	Result string   `json:"result,omitempty"`
+}
+
+// PostToolUse records the tool use described by the hook input in r as a
+// board.context entry on topic in boardDir, and returns the hook output.
+// At most maxWriters publishers write to the board at once; see
+// bus.FileBus.SetMaxWriters. With an empty boardDir nothing is recorded.
+func PostToolUse(r io.Reader, boardDir, topic string, maxWriters int) (string, error) {
+	var u ToolUse
+	if err := json.NewDecoder(r).Decode(&u); err != nil {
+		return "", fmt.Errorf("parsing PostToolUse input: %w", err)
+	}
+	if boardDir != "" && u.ToolName != "" {
+		if err := recordToolUse(boardDir, topic, maxWriters, u); err != nil {
+			return "", err
+		}
+	}
+	return hookOutput("PostToolUse", "")
+}
+
+func recordToolUse(boardDir, topic string, maxWriters int, u ToolUse) error {
+	text, files := SummarizeToolUse(u)
+	payload, err := json.Marshal(toolUsePayload{
+		Text:   text,
+		Tool:   u.ToolName,
+		Files:  files,
+		Result: toolResult(u.ToolResponse),
+	})
+	if err != nil {
+		return err
+	}
+	sender := "session"
+	if u.SessionID != "" {
+		sender = "session-" + truncate(u.SessionID, 8)
+	}
+	fileBus, err := bus.NewBoardBus(boardDir, maxWriters)
+	if err != nil {
+		return err
+	}
+	defer fileBus.Close()
+	return fileBus.Publish(topic, bus.Message{Type: "board.context", Sender: sender, Payload: payload})
+}
+
+// SummarizeToolUse describes a tool use in a few words, e.g. "edited
+// internal/bus/bus.go", and lists the files it touched. Paths are shown
+// relative to the session's working directory.
+func SummarizeToolUse(u ToolUse) (string, []string) {
+	var in struct {
+		FilePath     string `json:"file_path"`
+		NotebookPath string `json:"notebook_path"`
+		Command      string `json:"command"`
+		Pattern      string `json:"pattern"`
+	}
+	json.Unmarshal(u.ToolInput, &in)
+	path := in.FilePath
+	if path == "" {
+		path = in.NotebookPath
+	}
+	if path != "" {
+		path = relativeTo(u.Cwd, path)
+	}
+
+	switch {
+	case path != "" && (u.ToolName == "Edit" || u.ToolName == "MultiEdit" || u.ToolName == "NotebookEdit"):
+		return "edited " + path, []string{path}
+	case path != "" && u.ToolName == "Write":
+		return "wrote " + path, []string{path}
+	case path != "" && u.ToolName == "Read":
+		return "read " + path, []string{path}
+	case in.Command != "" && u.ToolName == "Bash":
+		return "ran `" + truncate(strings.TrimSpace(in.Command), maxCommandBytes) + "`", nil
+	case in.Pattern != "" && u.ToolName == "Grep":
+		return fmt.Sprintf("searched for %q", in.Pattern), nil
+	case in.Pattern != "" && u.ToolName == "Glob":
+		return "listed files matching " + in.Pattern, nil
+	}
+	return "used " + u.ToolName, nil
+}
+
+// toolResult returns a bounded excerpt of a tool response, unquoting a plain
+// string response.
+func toolResult(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+	var s string
+	if json.Unmarshal(raw, &s) != nil {
+		s = string(raw)
+	}
+	return truncate(strings.TrimSpace(s), MaxToolResultBytes)
+}
+
+func relativeTo(dir, path string) string {
+	if dir == "" || !filepath.IsAbs(path) {
+		return path
+	}
+	if rel, err := filepath.Rel(dir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,115 @@
+package hook
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/signalnine/conclave/internal/bus"
+	"github.com/signalnine/conclave/internal/ralph"
+)
+
+func runPostToolUse(t *testing.T, input string) (string, []string) {
+	t.Helper()
+	dir := t.TempDir()
+	output, err := PostToolUse(strings.NewReader(input), dir, "session", bus.DefaultMaxWriters)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result struct {
+		HookSpecificOutput map[string]any `json:"hookSpecificOutput"`
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("invalid JSON: %v\noutput: %s", err, output)
+	}
+	if result.HookSpecificOutput["hookEventName"] != "PostToolUse" {
+		t.Errorf("hookEventName = %v", result.HookSpecificOutput["hookEventName"])
+	}
+
+	entries, _, err := ralph.ReadBoardSince(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var payloads []string
+	for _, e := range entries {
+		if e.Type != "board.context" {
+			t.Errorf("entry type = %q, want board.context", e.Type)
+		}
+		payloads = append(payloads, string(e.Payload))
+	}
+	return output, payloads
+}
+
+func TestPostToolUse_EditTool(t *testing.T) {
+	_, payloads := runPostToolUse(t, `{
+		"session_id": "abc123456789",
+		"cwd": "/repo",
+		"tool_name": "Edit",
+		"tool_input": {"file_path": "/repo/internal/bus/bus.go", "old_string": "a", "new_string": "b"},
+		"tool_response": {"filePath": "/repo/internal/bus/bus.go", "success": true}
+	}`)
+	if len(payloads) != 1 {
+		t.Fatalf("got %d entries, want 1", len(payloads))
+	}
+	var p toolUsePayload
+	json.Unmarshal([]byte(payloads[0]), &p)
+	if p.Text != "edited internal/bus/bus.go" {
+		t.Errorf("text = %q", p.Text)
+	}
+	if p.Tool != "Edit" || len(p.Files) != 1 || p.Files[0] != "internal/bus/bus.go" {
+		t.Errorf("payload = %+v", p)
+	}
+}
+
+func TestPostToolUse_ReadToolBoundsResult(t *testing.T) {
+	content := strings.Repeat("x", 10*MaxToolResultBytes)
+	input, _ := json.Marshal(map[string]any{
+		"cwd":           "/repo",
+		"tool_name":     "Read",
+		"tool_input":    map[string]any{"file_path": "/repo/README.md"},
+		"tool_response": content,
+	})
+	_, payloads := runPostToolUse(t, string(input))
+	if len(payloads) != 1 {
+		t.Fatalf("got %d entries, want 1", len(payloads))
+	}
+	var p toolUsePayload
+	json.Unmarshal([]byte(payloads[0]), &p)
+	if p.Text != "read README.md" {
+		t.Errorf("text = %q", p.Text)
+	}
+	if len(p.Result) > MaxToolResultBytes+len("...") {
+		t.Errorf("result not bounded: %d bytes", len(p.Result))
+	}
+	if !strings.HasPrefix(p.Result, "xxx") {
+		t.Errorf("result = %q", p.Result[:10])
+	}
+}
+
+func TestPostToolUse_NoBoardDir(t *testing.T) {
+	output, err := PostToolUse(strings.NewReader(`{"tool_name":"Read","tool_input":{"file_path":"a.go"}}`), "", "session", bus.DefaultMaxWriters)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(output, "PostToolUse") {
+		t.Errorf("output = %s", output)
+	}
+}
+
+func TestSummarizeToolUse(t *testing.T) {
+	tests := []struct {
+		tool, input, want string
+	}{
+		{"Write", `{"file_path":"/repo/new.go"}`, "wrote new.go"},
+		{"Bash", `{"command":"go test ./..."}`, "ran `go test ./...`"},
+		{"Grep", `{"pattern":"TODO"}`, `searched for "TODO"`},
+		{"WebFetch", `{"url":"https://example.com"}`, "used WebFetch"},
+		{"Read", `{"file_path":"/elsewhere/x.go"}`, "read /elsewhere/x.go"},
+	}
+	for _, tt := range tests {
+		got, _ := SummarizeToolUse(ToolUse{Cwd: "/repo", ToolName: tt.tool, ToolInput: json.RawMessage(tt.input)})
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.tool, got, tt.want)
+		}
+	}
+}
@@ -8,12 +8,17 @@ import (
 	"strings"
 )
 
+// SessionStart builds the SessionStart hook output. A missing, unreadable or
+// empty using-conclave skill is logged to stderr and replaced by a short note,
+// so a broken skill install never blocks the session from starting.
 func SessionStart(pluginRoot string) (string, error) {
 	// Read using-conclave skill content
 	skillPath := filepath.Join(pluginRoot, "skills", "using-conclave", "SKILL.md")
-	content, err := os.ReadFile(skillPath)
+	skill, err := readSkill(skillPath)
 	if err != nil {
-		return "", fmt.Errorf("reading using-conclave skill: %w", err)
+		fmt.Fprintf(os.Stderr, "conclave: session-start: %v; continuing without it\n", err)
+		skill = "**Note:** The using-conclave skill could not be loaded (" + err.Error() + "). " +
+			"Reinstall the conclave plugin to restore it. Other conclave skills are still available through the 'Skill' tool."
 	}
 
 	// Check for legacy skills directory
@@ -36,18 +41,35 @@ func SessionStart(pluginRoot string) (string, error) {
 		"**Below is the full content of your 'conclave:using-conclave' skill - "+
 		"your introduction to using skills. For all other skills, use the 'Skill' tool:**\n\n"+
 		"%s\n\n%s\n</EXTREMELY_IMPORTANT>",
-		binaryPath, strings.TrimSpace(string(content)), warning)
+		binaryPath, skill, warning)
 
-	output := map[string]any{
-		"hookSpecificOutput": map[string]any{
-			"hookEventName":     "SessionStart",
-			"additionalContext": ctx,
-		},
-	}
+	return hookOutput("SessionStart", ctx)
+}
 
-	data, err := json.Marshal(output)
+// hookOutput builds the JSON envelope Claude Code expects from a hook,
+// omitting additionalContext when there is none.
+func hookOutput(event, additionalContext string) (string, error) {
+	specific := map[string]any{"hookEventName": event}
+	if additionalContext != "" {
+		specific["additionalContext"] = additionalContext
+	}
+	data, err := json.Marshal(map[string]any{"hookSpecificOutput": specific})
 	if err != nil {
 		return "", err
 	}
 	return string(data), nil
 }
+
+// readSkill returns the trimmed content of a skill file, failing when it is
+// missing, unreadable or empty.
+func readSkill(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("reading using-conclave skill: %w", err)
+	}
+	skill := strings.TrimSpace(string(content))
+	if skill == "" {
+		return "", fmt.Errorf("using-conclave skill %s is empty", path)
+	}
+	return skill, nil
+}
@@ -68,3 +68,42 @@ func TestSessionStart_LegacyWarning(t *testing.T) {
 		t.Error("expected non-empty context")
 	}
 }
+
+func TestSessionStart_MissingOrEmptySkill(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(skillDir string)
+	}{
+		{"missing SKILL.md", func(skillDir string) { os.MkdirAll(skillDir, 0755) }},
+		{"empty SKILL.md", func(skillDir string) {
+			os.MkdirAll(skillDir, 0755)
+			os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte("  \n"), 0644)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			tt.setup(filepath.Join(dir, "skills", "using-conclave"))
+
+			output, err := SessionStart(dir)
+			if err != nil {
+				t.Fatalf("SessionStart failed on a broken skill install: %v", err)
+			}
+			var result struct {
+				HookSpecificOutput struct {
+					HookEventName     string `json:"hookEventName"`
+					AdditionalContext string `json:"additionalContext"`
+				} `json:"hookSpecificOutput"`
+			}
+			if err := json.Unmarshal([]byte(output), &result); err != nil {
+				t.Fatalf("invalid JSON: %v\noutput: %s", err, output)
+			}
+			if result.HookSpecificOutput.HookEventName != "SessionStart" {
+				t.Errorf("hookEventName = %q", result.HookSpecificOutput.HookEventName)
+			}
+			if !strings.Contains(result.HookSpecificOutput.AdditionalContext, "could not be loaded") {
+				t.Errorf("additionalContext lacks the fallback note:\n%s", result.HookSpecificOutput.AdditionalContext)
+			}
+		})
+	}
+}
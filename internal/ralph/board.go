@@ -2,19 +2,119 @@ package ralph
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/signalnine/conclave/internal/bus"
 )
 
+// Severity grades a board finding. Payloads carry it as "severity"; entries
+// without one default to SeverityInfo, except legacy board.warning entries,
+// which count as SeverityMajor.
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityMinor    Severity = "minor"
+	SeverityMajor    Severity = "major"
+	SeverityCritical Severity = "critical"
+)
+
+var severityRank = map[Severity]int{
+	SeverityInfo:     0,
+	SeverityMinor:    1,
+	SeverityMajor:    2,
+	SeverityCritical: 3,
+}
+
+// ParseSeverity returns the severity named by s (case-insensitive) and
+// whether it is one of the known levels.
+func ParseSeverity(s string) (Severity, bool) {
+	sev := Severity(strings.ToLower(strings.TrimSpace(s)))
+	_, ok := severityRank[sev]
+	return sev, ok
+}
+
+// EntrySeverity returns an entry's severity, applying the defaults above.
+func EntrySeverity(e bus.Envelope) Severity {
+	var payload struct {
+		Severity string `json:"severity"`
+	}
+	json.Unmarshal(e.Payload, &payload)
+	if sev, ok := ParseSeverity(payload.Severity); ok {
+		return sev
+	}
+	if e.Type == "board.warning" {
+		return SeverityMajor
+	}
+	return SeverityInfo
+}
+
+var shaRe = regexp.MustCompile(`^[0-9a-fA-F]{4,64}$`)
+
+// ValidSHA loosely checks a git commit ID: 4 to 64 hex digits, so
+// abbreviated, SHA-1 and SHA-256 IDs all pass.
+func ValidSHA(sha string) bool { return shaRe.MatchString(sha) }
+
+// EntrySHA returns the commit an entry's payload "sha" field pins it to, in
+// lower case, or "" when it has none or it is not a plausible commit ID.
+func EntrySHA(e bus.Envelope) string {
+	var payload struct {
+		SHA string `json:"sha"`
+	}
+	json.Unmarshal(e.Payload, &payload)
+	if !ValidSHA(payload.SHA) {
+		return ""
+	}
+	return strings.ToLower(payload.SHA)
+}
+
+// shortRef abbreviates a commit ID the way git log --oneline does.
+func shortRef(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// EntryExpired reports whether an entry's payload "expires_at" timestamp
+// (RFC 3339) is at or before now. Entries without one never expire.
+func EntryExpired(e bus.Envelope, now time.Time) bool {
+	var payload struct {
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+	if err := json.Unmarshal(e.Payload, &payload); err != nil || payload.ExpiresAt == nil {
+		return false
+	}
+	return !now.Before(*payload.ExpiresAt)
+}
+
+// alwaysInclude reports whether an entry survives ReadBoard's cap.
+func alwaysInclude(e bus.Envelope) bool {
+	return severityRank[EntrySeverity(e)] >= severityRank[SeverityMajor]
+}
+
 // ReadBoard reads all messages from board JSONL files in a directory.
-// Returns at most maxMessages entries, but always includes all warnings.
+// Returns at most maxMessages entries, but always includes every major and
+// critical entry (including legacy warnings). Expired entries are dropped
+// before the cap is applied.
 func ReadBoard(dir string, maxMessages int) ([]bus.Envelope, error) {
+	return ReadBoardCtx(context.Background(), dir, maxMessages)
+}
+
+// ReadBoardCtx is ReadBoard with cancellation: ctx is checked between files
+// and periodically while scanning one, and ctx.Err() is returned once it is
+// done, so a huge board cannot hold up shutdown.
+func ReadBoardCtx(ctx context.Context, dir string, maxMessages int) ([]bus.Envelope, error) {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -23,34 +123,37 @@ func ReadBoard(dir string, maxMessages int) ([]bus.Envelope, error) {
 		return nil, err
 	}
 
+	now := time.Now()
 	var all []bus.Envelope
 	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".jsonl") {
 			continue
 		}
-		f, err := os.Open(filepath.Join(dir, entry.Name()))
+		envs, err := readBoardFileCtx(ctx, filepath.Join(dir, entry.Name()))
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
 		if err != nil {
 			continue
 		}
-		scanner := bufio.NewScanner(f)
-		for scanner.Scan() {
-			var env bus.Envelope
-			if err := json.Unmarshal(scanner.Bytes(), &env); err != nil {
-				continue
+		for _, env := range envs {
+			if !EntryExpired(env, now) {
+				all = append(all, env)
 			}
-			all = append(all, env)
 		}
-		f.Close()
 	}
 
 	if len(all) == 0 {
 		return nil, nil
 	}
 
-	// Separate warnings (always included) from others
+	// Separate major/critical entries (always included) from others
 	var warnings, others []bus.Envelope
 	for _, e := range all {
-		if e.Type == "board.warning" {
+		if alwaysInclude(e) {
 			warnings = append(warnings, e)
 		} else {
 			others = append(others, e)
@@ -70,8 +173,253 @@ func ReadBoard(dir string, maxMessages int) ([]bus.Envelope, error) {
 	return result, nil
 }
 
-// FormatBoardContext formats board entries as markdown for injection into .ralph_context.md.
+// ReadBoardSince returns the board entries with Seq greater than sinceSeq,
+// ordered by Seq, together with the highest Seq seen (sinceSeq when nothing
+// is newer) so incremental readers can advance their cursor. Sequence
+// numbers are persisted by FileBus, so a cursor stays valid across restarts.
+// Expired entries are skipped but still advance the cursor.
+func ReadBoardSince(dir string, sinceSeq uint64) ([]bus.Envelope, uint64, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, sinceSeq, nil
+		}
+		return nil, sinceSeq, err
+	}
+
+	now := time.Now()
+	maxSeq := sinceSeq
+	var newer []bus.Envelope
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".jsonl") {
+			continue
+		}
+		envs, err := readBoardFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		for _, env := range envs {
+			if env.Seq <= sinceSeq {
+				continue
+			}
+			if env.Seq > maxSeq {
+				maxSeq = env.Seq
+			}
+			if !EntryExpired(env, now) {
+				newer = append(newer, env)
+			}
+		}
+	}
+	sort.SliceStable(newer, func(i, j int) bool { return newer[i].Seq < newer[j].Seq })
+	return newer, maxSeq, nil
+}
+
+// Board files are shared by every writer in a wave (FileBus publishers, board
+// commands, compaction), so all access goes through flock on the file itself.
+const (
+	boardLockAttempts = 10
+	boardLockWait     = 20 * time.Millisecond
+)
+
+// boardCtxCheckLines is how many lines readBoardFileCtx scans between
+// cancellation checks.
+const boardCtxCheckLines = 1000
+
+// readBoardFile parses one board JSONL file under a shared lock. If a writer
+// holds the lock past the retry window, the file is read anyway; a torn final
+// line fails to parse and is skipped like any other malformed line.
+func readBoardFile(path string) ([]bus.Envelope, error) {
+	return readBoardFileCtx(context.Background(), path)
+}
+
+// readBoardFileCtx is readBoardFile, giving up with ctx.Err() when ctx is
+// done.
+func readBoardFileCtx(ctx context.Context, path string) ([]bus.Envelope, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if lockBoardFile(f, syscall.LOCK_SH) {
+		defer syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+	}
+
+	var envs []bus.Envelope
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		if n%boardCtxCheckLines == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		var env bus.Envelope
+		if err := json.Unmarshal(scanner.Bytes(), &env); err != nil {
+			continue
+		}
+		envs = append(envs, env)
+	}
+	return envs, nil
+}
+
+// lockBoardFile tries a non-blocking flock, retrying briefly while another
+// process holds it. Reports whether the lock was acquired.
+func lockBoardFile(f *os.File, how int) bool {
+	for i := 0; i < boardLockAttempts; i++ {
+		if err := syscall.Flock(int(f.Fd()), how|syscall.LOCK_NB); err == nil {
+			return true
+		}
+		time.Sleep(boardLockWait)
+	}
+	return false
+}
+
+// AppendBoard appends envelopes to a board JSONL file under an exclusive
+// lock, so concurrent writers never interleave partial lines.
+func AppendBoard(path string, envs ...bus.Envelope) error {
+	buf, err := encodeBoard(envs)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("open board file: %w", err)
+	}
+	defer f.Close()
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		return fmt.Errorf("flock: %w", err)
+	}
+	defer syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+
+	if _, err := f.Write(buf); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+	return nil
+}
+
+// RewriteBoard replaces the contents of a board file with envs while holding
+// an exclusive lock. The file is rewritten in place rather than renamed over,
+// so appenders blocked on the lock write to the new contents instead of an
+// unlinked inode.
+func RewriteBoard(path string, envs []bus.Envelope) error {
+	buf, err := encodeBoard(envs)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return fmt.Errorf("open board file: %w", err)
+	}
+	defer f.Close()
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		return fmt.Errorf("flock: %w", err)
+	}
+	defer syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+
+	if err := f.Truncate(0); err != nil {
+		return fmt.Errorf("truncate: %w", err)
+	}
+	if _, err := f.WriteAt(buf, 0); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+	return f.Sync()
+}
+
+func encodeBoard(envs []bus.Envelope) ([]byte, error) {
+	var buf []byte
+	for _, env := range envs {
+		data, err := json.Marshal(env)
+		if err != nil {
+			return nil, fmt.Errorf("marshal envelope: %w", err)
+		}
+		buf = append(append(buf, data...), '\n')
+	}
+	return buf, nil
+}
+
+// defaultBoardPrefixes are the display prefixes for the built-in board types.
+var defaultBoardPrefixes = map[string]string{
+	"board.discovery": "DISCOVERY",
+	"board.warning":   "WARNING",
+	"board.intent":    "INTENT",
+	"board.context":   "CONTEXT",
+}
+
+var (
+	boardPrefixMu sync.RWMutex
+	boardPrefixes = copyPrefixes(defaultBoardPrefixes)
+)
+
+func copyPrefixes(m map[string]string) map[string]string {
+	c := make(map[string]string, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+// RegisterBoardPrefix sets the prefix FormatBoardContext shows for entries of
+// type typ, e.g. RegisterBoardPrefix("board.question", "QUESTION"). An empty
+// prefix restores the default.
+func RegisterBoardPrefix(typ, prefix string) {
+	boardPrefixMu.Lock()
+	defer boardPrefixMu.Unlock()
+	if prefix == "" {
+		if def, ok := defaultBoardPrefixes[typ]; ok {
+			boardPrefixes[typ] = def
+		} else {
+			delete(boardPrefixes, typ)
+		}
+		return
+	}
+	boardPrefixes[typ] = prefix
+}
+
+// BoardPrefix returns the display prefix for a board type, "INFO" when the
+// type has none registered.
+func BoardPrefix(typ string) string {
+	boardPrefixMu.RLock()
+	defer boardPrefixMu.RUnlock()
+	if p, ok := boardPrefixes[typ]; ok {
+		return p
+	}
+	return "INFO"
+}
+
+// BoardType resolves name, an entry type with or without its "board."
+// prefix such as "warning", to a type with a registered display prefix.
+func BoardType(name string) (string, bool) {
+	typ := NormalizeBoardType(name)
+	boardPrefixMu.RLock()
+	defer boardPrefixMu.RUnlock()
+	_, ok := boardPrefixes[typ]
+	return typ, ok
+}
+
+// BoardTypes returns the types with a registered display prefix, sorted.
+func BoardTypes() []string {
+	boardPrefixMu.RLock()
+	defer boardPrefixMu.RUnlock()
+	types := make([]string, 0, len(boardPrefixes))
+	for typ := range boardPrefixes {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// FormatBoardContext formats board entries as markdown for injection into
+// .ralph_context.md. Expired entries are omitted.
 func FormatBoardContext(entries []bus.Envelope) string {
+	now := time.Now()
+	var live []bus.Envelope
+	for _, e := range entries {
+		if !EntryExpired(e, now) {
+			live = append(live, e)
+		}
+	}
+	entries = live
 	if len(entries) == 0 {
 		return ""
 	}
@@ -85,57 +433,150 @@ func FormatBoardContext(entries []bus.Envelope) string {
 		}
 		json.Unmarshal(e.Payload, &payload)
 
-		prefix := "INFO"
-		switch e.Type {
-		case "board.discovery":
-			prefix = "DISCOVERY"
-		case "board.warning":
-			prefix = "WARNING"
-		case "board.intent":
-			prefix = "INTENT"
-		case "board.context":
-			prefix = "CONTEXT"
+		prefix := BoardPrefix(e.Type)
+		badge := ""
+		if sev := EntrySeverity(e); sev != SeverityInfo {
+			badge = fmt.Sprintf(" `%s`", sev)
+		}
+		source := e.Sender
+		if sha := EntrySHA(e); sha != "" {
+			source += " @ " + shortRef(sha)
 		}
-		b.WriteString(fmt.Sprintf("- **[%s]** (%s): %s\n", prefix, e.Sender, payload.Text))
+		b.WriteString(fmt.Sprintf("- **[%s]**%s (%s): %s\n", prefix, badge, source, payload.Text))
 	}
 	return b.String()
 }
 
+// DefaultBoardPromptBudget caps the board context folded into a consensus
+// prompt so peer findings don't crowd out the question itself.
+const DefaultBoardPromptBudget = 8000
+
+// BoardPromptContext reads up to maxEntries board entries from dir and formats
+// them for inclusion in a prompt, cut at an entry boundary to fit maxBytes
+// (0 = unlimited). Returns "" when the board is empty.
+func BoardPromptContext(dir string, maxEntries, maxBytes int) (string, error) {
+	entries, err := ReadBoard(dir, maxEntries)
+	if err != nil {
+		return "", err
+	}
+	md := FormatBoardContext(entries)
+	if maxBytes <= 0 || len(md) <= maxBytes {
+		return md, nil
+	}
+
+	lines := strings.SplitAfter(md, "\n")
+	var b strings.Builder
+	kept := 0
+	for _, line := range lines {
+		if b.Len()+len(line) > maxBytes {
+			break
+		}
+		b.WriteString(line)
+		if strings.HasPrefix(line, "- ") {
+			kept++
+		}
+	}
+	if omitted := len(entries) - kept; omitted > 0 {
+		fmt.Fprintf(&b, "- _(%d more board entries omitted)_\n", omitted)
+	}
+	return b.String(), nil
+}
+
 // BusMarker represents a structured marker extracted from LLM output.
 type BusMarker struct {
-	Type string // "board.discovery", "board.warning", "board.intent"
-	Text string
+	Type     string   // "board.discovery", "board.warning", "board.intent"
+	Severity Severity // optional, from <!-- BUS:type severity=level -->
+	SHA      string   // optional commit the finding is about, from sha=<id>
+	Text     string
 }
 
-var busMarkerRe = regexp.MustCompile(`(?s)<!-- BUS:(discovery|warning|intent) -->(.*?)<!-- /BUS -->`)
+var (
+	busMarkerRe   = regexp.MustCompile(`(?s)<!-- BUS:(discovery|warning|intent)((?:\s+\w+=[^\s>]+)*) -->(.*?)<!-- /BUS -->`)
+	markerAttrsRe = regexp.MustCompile(`(\w+)=([^\s>]+)`)
+)
 
 // ExtractBusMarkers extracts structured BUS markers from LLM output.
 func ExtractBusMarkers(output string) []BusMarker {
 	matches := busMarkerRe.FindAllStringSubmatch(output, -1)
 	var markers []BusMarker
 	for _, m := range matches {
-		markers = append(markers, BusMarker{
+		marker := BusMarker{
 			Type: "board." + m[1],
-			Text: strings.TrimSpace(m[2]),
-		})
+			Text: strings.TrimSpace(m[3]),
+		}
+		for _, attr := range markerAttrsRe.FindAllStringSubmatch(m[2], -1) {
+			switch attr[1] {
+			case "severity":
+				if sev, ok := ParseSeverity(attr[2]); ok {
+					marker.Severity = sev
+				}
+			case "sha":
+				if ValidSHA(attr[2]) {
+					marker.SHA = strings.ToLower(attr[2])
+				}
+			}
+		}
+		markers = append(markers, marker)
 	}
 	return markers
 }
 
-// PublishMarkers publishes extracted markers to the message bus.
+// BoardComplete is the entry type a ralph task posts when it finishes an
+// objective. Peers racing on the same objective stop when they see it.
+const BoardComplete = "board.complete"
+
+type completionPayload struct {
+	Objective string `json:"objective"`
+	Text      string `json:"text,omitempty"`
+}
+
+// PeerCompletion returns the earliest unexpired board.complete entry for
+// objective posted by a sender other than self.
+func PeerCompletion(dir, objective, self string) (bus.Envelope, bool, error) {
+	entries, _, err := ReadBoardSince(dir, 0)
+	if err != nil {
+		return bus.Envelope{}, false, err
+	}
+	for _, e := range entries {
+		if e.Type != BoardComplete || e.Sender == self {
+			continue
+		}
+		var p completionPayload
+		if json.Unmarshal(e.Payload, &p) == nil && p.Objective == objective {
+			return e, true, nil
+		}
+	}
+	return bus.Envelope{}, false, nil
+}
+
+// PublishCompletion announces that sender finished objective.
+func PublishCompletion(b bus.MessageBus, topic, sender, objective string) error {
+	payload, _ := json.Marshal(completionPayload{
+		Objective: objective,
+		Text:      "Completed objective " + objective,
+	})
+	return b.Publish(topic, bus.Message{
+		Type:    BoardComplete,
+		Sender:  sender,
+		Payload: json.RawMessage(payload),
+	})
+}
+
+// PublishMarkers publishes extracted markers to the message bus, as one
+// batch when the bus supports it.
 func PublishMarkers(b bus.MessageBus, topic, sender string, markers []BusMarker) error {
+	msgs := make([]bus.Message, 0, len(markers))
 	for _, m := range markers {
 		payload, _ := json.Marshal(struct {
-			Text string `json:"text"`
-		}{Text: m.Text})
-		err := b.Publish(topic, bus.Message{
+			Text     string   `json:"text"`
+			Severity Severity `json:"severity,omitempty"`
+			SHA      string   `json:"sha,omitempty"`
+		}{Text: m.Text, Severity: m.Severity, SHA: m.SHA})
+		msgs = append(msgs, bus.Message{
 			Type:    m.Type,
 			Sender:  sender,
 			Payload: json.RawMessage(payload),
 		})
-		if err != nil {
-			return err
-		}
 	}
-	return nil
+	return bus.PublishAll(b, topic, msgs)
 }
@@ -1,14 +1,20 @@
 package ralph
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"syscall"
 	"testing"
 	"time"
 
 	"github.com/signalnine/conclave/internal/bus"
+	"github.com/signalnine/conclave/internal/consensus"
 )
 
 func TestReadBoardEmpty(t *testing.T) {
@@ -187,3 +193,483 @@ func TestPublishMarkers(t *testing.T) {
 		t.Fatalf("got %d lines, want 2", len(lines))
 	}
 }
+
+func TestAppendBoardConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "board.jsonl")
+	fileBus, err := bus.NewFileBus(dir, 10*time.Millisecond, 50*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fileBus.Close()
+
+	// Large payloads make torn writes likely without locking
+	text := strings.Repeat("x", 64*1024)
+	payload, _ := json.Marshal(map[string]string{"text": text})
+	const perWriter = 50
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*perWriter)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < perWriter; i++ {
+			env := bus.NewEnvelope("board", bus.Message{Type: "board.discovery", Sender: "cli", Payload: payload})
+			errs <- AppendBoard(path, env)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < perWriter; i++ {
+			errs <- fileBus.Publish("board", bus.Message{Type: "board.discovery", Sender: "ralph", Payload: payload})
+		}
+	}()
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 2*perWriter {
+		t.Fatalf("got %d lines, want %d", len(lines), 2*perWriter)
+	}
+	for i, line := range lines {
+		var env bus.Envelope
+		if err := json.Unmarshal([]byte(line), &env); err != nil {
+			t.Fatalf("line %d corrupted: %v", i, err)
+		}
+	}
+}
+
+func TestRewriteBoard(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "board.jsonl")
+	writeBoardFile(t, dir, "board.jsonl", []bus.Envelope{
+		{Type: "board.discovery", Sender: "a", Payload: json.RawMessage(`{"text":"old 1"}`)},
+		{Type: "board.discovery", Sender: "a", Payload: json.RawMessage(`{"text":"old 2"}`)},
+	})
+
+	keep := []bus.Envelope{{Type: "board.warning", Sender: "b", Payload: json.RawMessage(`{"text":"kept"}`)}}
+	if err := RewriteBoard(path, keep); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := ReadBoard(dir, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Sender != "b" {
+		t.Errorf("entries = %+v", entries)
+	}
+}
+
+func TestReadBoardWaitsForWriterLock(t *testing.T) {
+	dir := t.TempDir()
+	writeBoardFile(t, dir, "board.jsonl", []bus.Envelope{
+		{Type: "board.discovery", Sender: "a", Payload: json.RawMessage(`{"text":"hi"}`)},
+	})
+
+	f, err := os.OpenFile(filepath.Join(dir, "board.jsonl"), os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		t.Fatal(err)
+	}
+	// Simulate a writer finishing its line while the reader retries
+	done := make(chan struct{})
+	defer func() { <-done }() // before f.Close
+	go func() {
+		defer close(done)
+		time.Sleep(3 * boardLockWait)
+		f.WriteString(`{"type":"board.discovery","sender":"b","payload":{"text":"late"}}` + "\n")
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+	}()
+
+	entries, err := ReadBoard(dir, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("got %d entries, want 2 (reader should wait for the writer)", len(entries))
+	}
+}
+
+func TestEntrySeverity(t *testing.T) {
+	tests := []struct {
+		typ, payload string
+		want         Severity
+	}{
+		{"board.discovery", `{"text":"x"}`, SeverityInfo},
+		{"board.warning", `{"text":"x"}`, SeverityMajor},
+		{"board.warning", `{"text":"x","severity":"minor"}`, SeverityMinor},
+		{"board.discovery", `{"text":"x","severity":"CRITICAL"}`, SeverityCritical},
+		{"board.discovery", `{"text":"x","severity":"bogus"}`, SeverityInfo},
+	}
+	for _, tt := range tests {
+		got := EntrySeverity(bus.Envelope{Type: tt.typ, Payload: json.RawMessage(tt.payload)})
+		if got != tt.want {
+			t.Errorf("EntrySeverity(%s, %s) = %q, want %q", tt.typ, tt.payload, got, tt.want)
+		}
+	}
+}
+
+func TestReadBoardCapPreservesCritical(t *testing.T) {
+	dir := t.TempDir()
+	var envs []bus.Envelope
+	envs = append(envs, bus.Envelope{Type: "board.discovery", Sender: "s", Payload: json.RawMessage(`{"text":"blocker","severity":"critical"}`)})
+	envs = append(envs, bus.Envelope{Type: "board.discovery", Sender: "s", Payload: json.RawMessage(`{"text":"nit","severity":"minor"}`)})
+	for i := 0; i < 20; i++ {
+		envs = append(envs, bus.Envelope{Type: "board.discovery", Sender: "s", Payload: json.RawMessage(`{"text":"fyi","severity":"info"}`)})
+	}
+	writeBoardFile(t, dir, "board.jsonl", envs)
+
+	entries, err := ReadBoard(dir, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 5 {
+		t.Fatalf("got %d entries, want 5", len(entries))
+	}
+	if EntrySeverity(entries[0]) != SeverityCritical {
+		t.Errorf("critical entry should survive capping, got %+v", entries[0])
+	}
+	for _, e := range entries[1:] {
+		if EntrySeverity(e) == SeverityMinor {
+			t.Error("minor entry is not always included and should have been capped away")
+		}
+	}
+}
+
+func TestFormatBoardContextSeverityBadges(t *testing.T) {
+	md := FormatBoardContext([]bus.Envelope{
+		{Type: "board.discovery", Sender: "t1", Payload: json.RawMessage(`{"text":"plain"}`)},
+		{Type: "board.warning", Sender: "t2", Payload: json.RawMessage(`{"text":"legacy"}`)},
+		{Type: "board.warning", Sender: "t3", Payload: json.RawMessage(`{"text":"stop","severity":"critical"}`)},
+	})
+	for _, want := range []string{
+		"- **[DISCOVERY]** (t1): plain",
+		"- **[WARNING]** `major` (t2): legacy",
+		"- **[WARNING]** `critical` (t3): stop",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("missing %q in:\n%s", want, md)
+		}
+	}
+}
+
+func TestExtractBusMarkersSeverity(t *testing.T) {
+	markers := ExtractBusMarkers(`<!-- BUS:warning severity=critical -->Schema migration drops data<!-- /BUS -->
+<!-- BUS:discovery -->plain<!-- /BUS -->`)
+	if len(markers) != 2 {
+		t.Fatalf("got %d markers", len(markers))
+	}
+	if markers[0].Severity != SeverityCritical || markers[0].Text != "Schema migration drops data" {
+		t.Errorf("marker = %+v", markers[0])
+	}
+	if markers[1].Severity != "" {
+		t.Errorf("unmarked severity should stay empty, got %q", markers[1].Severity)
+	}
+
+	b := bus.NewChannelBus()
+	defer b.Close()
+	ch, _ := b.Subscribe("board")
+	PublishMarkers(b, "board", "task-1", markers[:1])
+	env := <-ch
+	if EntrySeverity(env) != SeverityCritical {
+		t.Errorf("published severity = %q", EntrySeverity(env))
+	}
+}
+
+func TestReadBoardDropsExpired(t *testing.T) {
+	dir := t.TempDir()
+	past := time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+	future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	writeBoardFile(t, dir, "board.jsonl", []bus.Envelope{
+		{Type: "board.warning", Sender: "t1", Payload: json.RawMessage(`{"text":"tests flaky right now","severity":"critical","expires_at":"` + past + `"}`)},
+		{Type: "board.discovery", Sender: "t2", Payload: json.RawMessage(`{"text":"cache is warm","expires_at":"` + future + `"}`)},
+	})
+
+	entries, err := ReadBoard(dir, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Sender != "t2" {
+		t.Fatalf("got %+v, want only the non-expired entry", entries)
+	}
+}
+
+func TestFormatBoardContextOmitsExpired(t *testing.T) {
+	past := time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+	md := FormatBoardContext([]bus.Envelope{
+		{Type: "board.discovery", Sender: "t1", Payload: json.RawMessage(`{"text":"durable"}`)},
+		{Type: "board.discovery", Sender: "t2", Payload: json.RawMessage(`{"text":"transient","expires_at":"` + past + `"}`)},
+	})
+	if !strings.Contains(md, "durable") || strings.Contains(md, "transient") {
+		t.Errorf("unexpected context:\n%s", md)
+	}
+	if FormatBoardContext([]bus.Envelope{{Type: "board.discovery", Payload: json.RawMessage(`{"text":"x","expires_at":"` + past + `"}`)}}) != "" {
+		t.Error("all-expired entries should format to empty string")
+	}
+}
+
+func TestBoardPromptContextInGeneralPrompt(t *testing.T) {
+	dir := t.TempDir()
+	writeBoardFile(t, dir, "board.jsonl", []bus.Envelope{
+		{Type: "board.discovery", Sender: "task-1", Payload: json.RawMessage(`{"text":"auth uses JWT"}`)},
+	})
+
+	boardCtx, err := BoardPromptContext(dir, 20, DefaultBoardPromptBudget)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prompt := consensus.BuildGeneralPrompt("Which auth library?", boardCtx+"\nuser context")
+	for _, want := range []string{"## Peer Task Findings", "(task-1): auth uses JWT", "user context"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q:\n%s", want, prompt)
+		}
+	}
+}
+
+func TestBoardPromptContextBudget(t *testing.T) {
+	dir := t.TempDir()
+	var envs []bus.Envelope
+	for i := 0; i < 50; i++ {
+		envs = append(envs, bus.Envelope{Type: "board.discovery", Sender: "t", Payload: json.RawMessage(`{"text":"` + strings.Repeat("x", 80) + `"}`)})
+	}
+	writeBoardFile(t, dir, "board.jsonl", envs)
+
+	got, err := BoardPromptContext(dir, 50, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) > 1100 {
+		t.Errorf("context is %d bytes, want about 1000", len(got))
+	}
+	if !strings.Contains(got, "more board entries omitted") {
+		t.Errorf("missing omission note:\n%s", got)
+	}
+	if empty, _ := BoardPromptContext(t.TempDir(), 20, 1000); empty != "" {
+		t.Errorf("empty board should give empty context, got %q", empty)
+	}
+}
+
+func TestReadBoardSince(t *testing.T) {
+	dir := t.TempDir()
+	writeBoardFile(t, dir, "a.jsonl", []bus.Envelope{
+		{Seq: 1, Type: "board.discovery", Sender: "t1", Payload: json.RawMessage(`{"text":"old"}`)},
+		{Seq: 4, Type: "board.discovery", Sender: "t1", Payload: json.RawMessage(`{"text":"newest"}`)},
+	})
+	writeBoardFile(t, dir, "b.jsonl", []bus.Envelope{
+		{Seq: 2, Type: "board.warning", Sender: "t2", Payload: json.RawMessage(`{"text":"seen"}`)},
+		{Seq: 3, Type: "board.intent", Sender: "t2", Payload: json.RawMessage(`{"text":"newer"}`)},
+	})
+
+	got, cursor, err := ReadBoardSince(dir, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Seq != 3 || got[1].Seq != 4 {
+		t.Fatalf("got %+v, want seqs 3 and 4 in order", got)
+	}
+	if cursor != 4 {
+		t.Errorf("cursor = %d, want 4", cursor)
+	}
+
+	got, cursor, _ = ReadBoardSince(dir, cursor)
+	if len(got) != 0 || cursor != 4 {
+		t.Errorf("caught-up read returned %d entries, cursor %d", len(got), cursor)
+	}
+}
+
+func TestReadBoardSinceAcrossRestart(t *testing.T) {
+	dir := t.TempDir()
+	b, err := bus.NewFileBus(dir, 10*time.Millisecond, 50*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.Publish("board", bus.Message{Type: "board.discovery", Sender: "t1", Payload: json.RawMessage(`{"text":"first"}`)})
+	b.Close()
+
+	_, cursor, err := ReadBoardSince(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err = bus.NewFileBus(dir, 10*time.Millisecond, 50*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.Publish("board", bus.Message{Type: "board.discovery", Sender: "t1", Payload: json.RawMessage(`{"text":"after restart"}`)})
+	b.Close()
+
+	got, _, err := ReadBoardSince(dir, cursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || !strings.Contains(string(got[0].Payload), "after restart") {
+		t.Errorf("got %+v, want only the post-restart entry", got)
+	}
+}
+
+func TestRegisterBoardPrefix(t *testing.T) {
+	RegisterBoardPrefix("board.question", "QUESTION")
+	defer RegisterBoardPrefix("board.question", "")
+
+	md := FormatBoardContext([]bus.Envelope{
+		{Type: "board.question", Sender: "t1", Payload: json.RawMessage(`{"text":"which DB?"}`)},
+		{Type: "board.unknown", Sender: "t2", Payload: json.RawMessage(`{"text":"misc"}`)},
+	})
+	if !strings.Contains(md, "- **[QUESTION]** (t1): which DB?") {
+		t.Errorf("custom prefix not rendered:\n%s", md)
+	}
+	if !strings.Contains(md, "- **[INFO]** (t2): misc") {
+		t.Errorf("unknown type should fall back to INFO:\n%s", md)
+	}
+
+	RegisterBoardPrefix("board.warning", "CAUTION")
+	if BoardPrefix("board.warning") != "CAUTION" {
+		t.Error("built-in prefix should be overridable")
+	}
+	RegisterBoardPrefix("board.warning", "")
+	if BoardPrefix("board.warning") != "WARNING" {
+		t.Errorf("empty prefix should restore default, got %q", BoardPrefix("board.warning"))
+	}
+}
+
+func TestPeerCompletion(t *testing.T) {
+	dir := t.TempDir()
+	writeBoardFile(t, dir, "wave.jsonl", []bus.Envelope{
+		{Seq: 1, Type: BoardComplete, Sender: "me", Payload: json.RawMessage(`{"objective":"fix-login"}`)},
+		{Seq: 2, Type: BoardComplete, Sender: "t2", Payload: json.RawMessage(`{"objective":"other"}`)},
+		{Seq: 3, Type: "board.discovery", Sender: "t3", Payload: json.RawMessage(`{"objective":"fix-login"}`)},
+	})
+	if _, ok, err := PeerCompletion(dir, "fix-login", "me"); err != nil || ok {
+		t.Fatalf("own, unrelated and non-complete entries matched (ok=%v, err=%v)", ok, err)
+	}
+
+	b, err := bus.NewFileBus(dir, 10*time.Millisecond, 50*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := PublishCompletion(b, "wave", "t4", "fix-login"); err != nil {
+		t.Fatal(err)
+	}
+	b.Close()
+
+	peer, ok, err := PeerCompletion(dir, "fix-login", "me")
+	if err != nil || !ok {
+		t.Fatalf("peer completion not found (ok=%v, err=%v)", ok, err)
+	}
+	if peer.Sender != "t4" {
+		t.Errorf("sender = %q, want t4", peer.Sender)
+	}
+}
+
+// cancelAfterChecks is a context that reports Canceled once Err has been
+// consulted n times, so a test can cancel at a deterministic point mid-read.
+type cancelAfterChecks struct {
+	context.Context
+	n      int
+	checks int
+}
+
+func (c *cancelAfterChecks) Err() error {
+	c.checks++
+	if c.checks > c.n {
+		return context.Canceled
+	}
+	return nil
+}
+
+func TestReadBoardCtxCanceledMidRead(t *testing.T) {
+	dir := t.TempDir()
+	for f := 0; f < 3; f++ {
+		envs := make([]bus.Envelope, 5*boardCtxCheckLines)
+		for i := range envs {
+			envs[i] = bus.Envelope{Seq: uint64(f*len(envs) + i + 1), Type: "board.discovery", Sender: "t", Payload: json.RawMessage(`{"text":"x"}`)}
+		}
+		writeBoardFile(t, dir, fmt.Sprintf("big-%d.jsonl", f), envs)
+	}
+
+	if _, err := ReadBoardCtx(context.Background(), dir, 10); err != nil {
+		t.Fatalf("uncanceled read: %v", err)
+	}
+
+	// Survive the first file boundary check and one in-file check, then cancel
+	// partway through the first file.
+	ctx := &cancelAfterChecks{Context: context.Background(), n: 2}
+	entries, err := ReadBoardCtx(ctx, dir, 10)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if entries != nil {
+		t.Errorf("canceled read returned %d entries", len(entries))
+	}
+	if ctx.checks > 4 {
+		t.Errorf("read kept going after cancellation (%d checks)", ctx.checks)
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ReadBoardCtx(canceled, dir, 10); !errors.Is(err, context.Canceled) {
+		t.Errorf("pre-canceled read: err = %v", err)
+	}
+}
+
+func TestBoardEntrySHA(t *testing.T) {
+	md := FormatBoardContext([]bus.Envelope{
+		{Type: "board.discovery", Sender: "t1", Payload: json.RawMessage(`{"text":"retry loop is unbounded","sha":"9F2C4E1A7B3D5E6F8091A2B3C4D5E6F708192A3B"}`)},
+		{Type: "board.discovery", Sender: "t2", Payload: json.RawMessage(`{"text":"bogus ref","sha":"not-a-sha"}`)},
+	})
+	for _, want := range []string{
+		"- **[DISCOVERY]** (t1 @ 9f2c4e1): retry loop is unbounded",
+		"- **[DISCOVERY]** (t2): bogus ref",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("missing %q in:\n%s", want, md)
+		}
+	}
+
+	markers := ExtractBusMarkers(`<!-- BUS:warning severity=critical sha=abc1234 -->Off by one in pager<!-- /BUS -->
+<!-- BUS:discovery sha=zzz -->ignored ref<!-- /BUS -->`)
+	if len(markers) != 2 || markers[0].SHA != "abc1234" || markers[0].Severity != SeverityCritical || markers[1].SHA != "" {
+		t.Fatalf("markers = %+v", markers)
+	}
+	b := bus.NewChannelBus()
+	defer b.Close()
+	ch, _ := b.Subscribe("board")
+	PublishMarkers(b, "board", "task-1", markers[:1])
+	if env := <-ch; EntrySHA(env) != "abc1234" {
+		t.Errorf("published sha = %q", EntrySHA(env))
+	}
+
+	for sha, want := range map[string]bool{"abc1": true, "ABC1234": true, "abc": false, "abc123g": false, strings.Repeat("a", 64): true, strings.Repeat("a", 65): false} {
+		if ValidSHA(sha) != want {
+			t.Errorf("ValidSHA(%q) = %v, want %v", sha, !want, want)
+		}
+	}
+}
+
+func TestBoardType(t *testing.T) {
+	for _, name := range []string{"warning", "board.warning"} {
+		if typ, ok := BoardType(name); !ok || typ != "board.warning" {
+			t.Errorf("BoardType(%q) = %q, %v", name, typ, ok)
+		}
+	}
+	if _, ok := BoardType("question"); ok {
+		t.Error("unregistered type accepted")
+	}
+	RegisterBoardPrefix("board.question", "QUESTION")
+	defer RegisterBoardPrefix("board.question", "")
+	if typ, ok := BoardType("question"); !ok || typ != "board.question" {
+		t.Errorf("registered type: BoardType = %q, %v", typ, ok)
+	}
+}
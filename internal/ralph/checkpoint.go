@@ -0,0 +1,120 @@
+package ralph
+
+import (
+	"fmt"
+	"path/filepath"
+
+	gitpkg "github.com/signalnine/conclave/internal/git"
+)
+
+// loopFiles are the loop's own bookkeeping files, never checkpointed.
+var loopFiles = []string{lockFileName, stateFileName, contextFileName, iterationsFileName}
+
+// Checkpointer commits the loop's progress after each successful
+// implementation, so a crash loses at most one iteration, and can squash
+// those commits into one when the task completes.
+type Checkpointer struct {
+	git     *gitpkg.Git
+	taskID  string
+	base    string // HEAD when the loop started
+	commits int
+}
+
+// NewCheckpointer checkpoints the repository containing dir. It refuses a
+// working tree that already has uncommitted changes, which a checkpoint
+// would otherwise sweep into the loop's commits, unless resumed: changes
+// left by an interrupted run of the same task are its own.
+//
+// base is the loop's starting commit (State.BaseSHA), "" for HEAD. A
+// resumed loop passes the one its first run recorded, so the checkpoints
+// committed before the interruption count, and squash, with the new ones.
+func NewCheckpointer(dir, taskID, base string, resumed bool) (*Checkpointer, error) {
+	g := gitpkg.New(dir)
+	head, err := g.RevParse("HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("no commit to checkpoint from: %w", err)
+	}
+	cp := &Checkpointer{git: g, taskID: taskID, base: head}
+	if resumed && base != "" && base != head {
+		n, err := g.CountCommits(base, head)
+		if err != nil {
+			return nil, fmt.Errorf("loop start %s: %w", base, err)
+		}
+		cp.base, cp.commits = base, n
+	}
+	if !resumed {
+		changed, err := UncommittedWork(dir)
+		if err != nil {
+			return nil, err
+		}
+		if len(changed) > 0 {
+			return nil, fmt.Errorf("working tree has uncommitted changes (%s)", changed[0])
+		}
+	}
+	return cp, nil
+}
+
+// UncommittedWork lists the paths changed in dir's working tree besides
+// the loop's own files: work that no commit holds yet.
+func UncommittedWork(dir string) ([]string, error) {
+	changed, err := gitpkg.New(dir).ChangedPaths()
+	if err != nil {
+		return nil, err
+	}
+	var work []string
+	for _, path := range changed {
+		if !isLoopFile(path) {
+			work = append(work, path)
+		}
+	}
+	return work, nil
+}
+
+func isLoopFile(path string) bool {
+	for _, f := range loopFiles {
+		if filepath.Base(path) == f {
+			return true
+		}
+	}
+	return false
+}
+
+// Commits returns the number of checkpoint commits made.
+func (c *Checkpointer) Commits() int { return c.commits }
+
+// Commit records the working tree as a checkpoint of iteration. It reports
+// false when there was nothing to commit.
+func (c *Checkpointer) Commit(iteration int) (bool, error) {
+	args := []string{"-A", "--", ":/"}
+	for _, f := range loopFiles {
+		args = append(args, ":(exclude,glob)**/"+f)
+	}
+	if err := c.git.Add(args...); err != nil {
+		return false, err
+	}
+	if !c.git.HasStagedChanges() {
+		return false, nil
+	}
+	if err := c.git.Commit(fmt.Sprintf("Ralph Loop checkpoint: %s iteration %d", c.taskID, iteration)); err != nil {
+		return false, err
+	}
+	c.commits++
+	return true, nil
+}
+
+// Squash replaces two or more checkpoint commits with a single commit of
+// their combined changes.
+func (c *Checkpointer) Squash(iterations int) error {
+	if c.commits < 2 {
+		return nil
+	}
+	if err := c.git.ResetSoft(c.base); err != nil {
+		return err
+	}
+	msg := fmt.Sprintf("Ralph Loop: %s\n\nSquashed %d checkpoints over %d iterations", c.taskID, c.commits, iterations)
+	if err := c.git.Commit(msg); err != nil {
+		return err
+	}
+	c.commits = 1
+	return nil
+}
@@ -0,0 +1,109 @@
+package ralph
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func gitLog(t *testing.T, dir string) []string {
+	t.Helper()
+	out, err := exec.Command("git", "-C", dir, "log", "--format=%s").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSpace(string(out)), "\n")
+}
+
+func TestCheckpointCommitsEachIteration(t *testing.T) {
+	repo, app := setupGuardRepo(t)
+	NewStateManager(app).Init("task", 5) // loop files must not block or be committed
+	cp, err := NewCheckpointer(app, "ralph-1", "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	os.WriteFile(filepath.Join(app, "a.go"), []byte("package main\n"), 0644)
+	if ok, err := cp.Commit(1); !ok || err != nil {
+		t.Fatalf("Commit(1) = %v, %v", ok, err)
+	}
+	if ok, err := cp.Commit(2); ok || err != nil {
+		t.Errorf("Commit with no changes = %v, %v; want nothing committed", ok, err)
+	}
+	os.WriteFile(filepath.Join(repo, "README.md"), []byte("updated\n"), 0644)
+	if ok, err := cp.Commit(3); !ok || err != nil {
+		t.Fatalf("Commit(3) = %v, %v", ok, err)
+	}
+
+	log := gitLog(t, repo)
+	if len(log) != 3 || log[0] != "Ralph Loop checkpoint: ralph-1 iteration 3" || log[1] != "Ralph Loop checkpoint: ralph-1 iteration 1" {
+		t.Errorf("log = %q", log)
+	}
+	out, _ := exec.Command("git", "-C", repo, "ls-files").Output()
+	if strings.Contains(string(out), stateFileName) || strings.Contains(string(out), contextFileName) {
+		t.Errorf("loop files were committed:\n%s", out)
+	}
+
+	if err := cp.Squash(3); err != nil {
+		t.Fatal(err)
+	}
+	log = gitLog(t, repo)
+	if len(log) != 2 || log[0] != "Ralph Loop: ralph-1" || log[1] != "initial" {
+		t.Errorf("after squash log = %q", log)
+	}
+	out, _ = exec.Command("git", "-C", repo, "show", "--stat", "--format=", "HEAD").Output()
+	if !strings.Contains(string(out), "app/a.go") || !strings.Contains(string(out), "README.md") {
+		t.Errorf("squashed commit should carry every checkpoint's changes:\n%s", out)
+	}
+}
+
+func TestCheckpointerRefusesDirtyTree(t *testing.T) {
+	_, app := setupGuardRepo(t)
+	os.WriteFile(filepath.Join(app, "main.go"), []byte("package main // edited\n"), 0644)
+	if _, err := NewCheckpointer(app, "ralph-1", "", false); err == nil || !strings.Contains(err.Error(), "uncommitted changes") {
+		t.Errorf("err = %v, want uncommitted changes refused", err)
+	}
+}
+
+func TestCheckpointerResumesAcrossEarlierCheckpoints(t *testing.T) {
+	repo, app := setupGuardRepo(t)
+	sm := NewStateManager(app)
+	sm.Init("ralph-1", 5)
+	state, _ := sm.Load()
+	start, _ := exec.Command("git", "-C", repo, "rev-parse", "HEAD").Output()
+	if state.BaseSHA != strings.TrimSpace(string(start)) {
+		t.Fatalf("Init recorded base %q, want HEAD %s", state.BaseSHA, start)
+	}
+
+	// The first run checkpoints iteration 1, then is interrupted.
+	first, _ := NewCheckpointer(app, "ralph-1", state.BaseSHA, false)
+	os.WriteFile(filepath.Join(app, "a.go"), []byte("package main\n"), 0644)
+	if ok, err := first.Commit(1); !ok || err != nil {
+		t.Fatalf("Commit(1) = %v, %v", ok, err)
+	}
+
+	resumed, err := NewCheckpointer(app, "ralph-1", state.BaseSHA, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resumed.Commits() != 1 {
+		t.Errorf("resumed Commits() = %d, want the earlier run's checkpoint counted", resumed.Commits())
+	}
+	os.WriteFile(filepath.Join(app, "b.go"), []byte("package main\n"), 0644)
+	if ok, err := resumed.Commit(2); !ok || err != nil {
+		t.Fatalf("Commit(2) = %v, %v", ok, err)
+	}
+	if err := resumed.Squash(2); err != nil {
+		t.Fatal(err)
+	}
+	log := gitLog(t, repo)
+	if len(log) != 2 || log[0] != "Ralph Loop: ralph-1" || log[1] != "initial" {
+		t.Errorf("after squash log = %q, want both runs' checkpoints squashed onto the loop's start", log)
+	}
+	out, _ := exec.Command("git", "-C", repo, "show", "--stat", "--format=", "HEAD").Output()
+	if !strings.Contains(string(out), "app/a.go") || !strings.Contains(string(out), "app/b.go") {
+		t.Errorf("squashed commit should carry both runs' changes:\n%s", out)
+	}
+}
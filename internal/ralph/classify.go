@@ -0,0 +1,76 @@
+package ralph
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// FailureClass is the kind of failure a gate's output shows, recorded in
+// state so stuck detection and the stuck directive can key off it.
+type FailureClass string
+
+const (
+	FailureCompile   FailureClass = "compile"   // the code does not build
+	FailureAssertion FailureClass = "assertion" // tests ran and failed
+	FailureTimeout   FailureClass = "timeout"   // the gate or a test ran out of time
+	FailurePanic     FailureClass = "panic"     // a crash: panic, segfault
+	FailureOther     FailureClass = "other"
+)
+
+// Patterns per class, checked in classifyOrder. A Go test timeout panics,
+// so timeouts come before panics; a build failure can mention FAIL, so
+// compile errors come before assertions.
+var (
+	timeoutRe = regexp.MustCompile(`(?i)(test timed out after|timed out|deadline exceeded|exceeded timeout|timeout of \d+m?s exceeded|signal: killed)`)
+	compileRe = regexp.MustCompile(`(?m)(\[build failed\]|^# \S+\n\S+\.go:\d+:\d+: |\.go:\d+:\d+: (undefined|cannot use|missing return|syntax error|declared and not used)|error\[E\d{4}\]|error TS\d+:|\bSyntaxError\b|\bIndentationError\b|ModuleNotFoundError|ImportError|compilation failed|could not compile|Compilation failed)`)
+	panicRe   = regexp.MustCompile(`(?m)(^panic: |^fatal error: |thread '.*' panicked at|Segmentation fault|SIGSEGV|core dumped)`)
+	assertRe  = regexp.MustCompile(`(?m)(^--- FAIL: |^FAIL\b|AssertionError|\bassert(ion)? failed|^E +assert |Expected:?\s|expected .* (got|but was|to (be|equal))|\d+ failed|Tests: +\d+ failed)`)
+)
+
+var classifyOrder = []struct {
+	class FailureClass
+	re    *regexp.Regexp
+}{
+	{FailureTimeout, timeoutRe},
+	{FailureCompile, compileRe},
+	{FailurePanic, panicRe},
+	{FailureAssertion, assertRe},
+}
+
+// ClassifyFailure tells the kind of failure from a gate's output and error.
+func ClassifyFailure(output string, err error) FailureClass {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return FailureTimeout
+	}
+	text := output
+	if err != nil {
+		text += "\n" + err.Error()
+	}
+	for _, c := range classifyOrder {
+		if c.re.MatchString(text) {
+			return c.class
+		}
+	}
+	return FailureOther
+}
+
+// classHints tailor the stuck directive to the failure class.
+var classHints = map[FailureClass]string{
+	FailureCompile:   `The code keeps failing to compile with the same kind of error. Stop adding code: read the compiler error, fix the type, signature or import it names, and get the build green before anything else.`,
+	FailureAssertion: `The same tests keep failing their assertions. Re-read what each failing test expects and compare it with what the code returns; question your assumption about the intended behavior rather than tweaking the same lines.`,
+	FailureTimeout:   `The tests keep timing out. Look for an infinite loop, a deadlock, a blocking call without a timeout or an unbounded input, and reduce the scope of the change until the tests finish.`,
+	FailurePanic:     `The code keeps crashing. Find the nil dereference, out-of-range index or unchecked error in the stack trace and guard against it before changing the design.`,
+}
+
+// StuckDirectiveFor returns the stuck directive for a loop stuck on
+// failures of class, StuckDirective with a class-specific hint.
+func StuckDirectiveFor(class FailureClass) string {
+	hint, ok := classHints[class]
+	if !ok {
+		return StuckDirective
+	}
+	return strings.Replace(StuckDirective, "Your previous approach does not work.\n",
+		"Your previous approach does not work.\n\n"+hint+"\n", 1)
+}
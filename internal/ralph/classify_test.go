@@ -0,0 +1,49 @@
+package ralph
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestClassifyFailure(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		err    error
+		want   FailureClass
+	}{
+		{"go build", "# example.com/app\napp/main.go:12:3: undefined: Foo\nFAIL\texample.com/app [build failed]", nil, FailureCompile},
+		{"rust", "error[E0308]: mismatched types", nil, FailureCompile},
+		{"typescript", "src/a.ts(3,1): error TS2304: Cannot find name 'x'.", nil, FailureCompile},
+		{"python import", "ModuleNotFoundError: No module named 'foo'", nil, FailureCompile},
+		{"go assertion", "--- FAIL: TestLogin (0.00s)\n    login_test.go:20: got 401, want 200\nFAIL", nil, FailureAssertion},
+		{"pytest", "E       assert 1 == 2\n1 failed, 3 passed", nil, FailureAssertion},
+		{"jest", "Expected: 3\nReceived: 4\nTests:       1 failed, 2 passed", nil, FailureAssertion},
+		{"go test timeout", "panic: test timed out after 10m0s\n\ngoroutine 1 [running]:", nil, FailureTimeout},
+		{"killed by gate timeout", "", errors.New("signal: killed"), FailureTimeout},
+		{"deadline", "", context.DeadlineExceeded, FailureTimeout},
+		{"go panic", "panic: runtime error: invalid memory address or nil pointer dereference\n[signal SIGSEGV]", nil, FailurePanic},
+		{"rust panic", "thread 'main' panicked at src/main.rs:4:5", nil, FailurePanic},
+		{"unknown", "something went wrong", errors.New("exit status 1"), FailureOther},
+	}
+	for _, tt := range tests {
+		if got := ClassifyFailure(tt.output, tt.err); got != tt.want {
+			t.Errorf("%s: ClassifyFailure = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestStuckDirectiveFor(t *testing.T) {
+	if got := StuckDirectiveFor(FailureOther); got != StuckDirective {
+		t.Errorf("unclassified failures should get the generic directive, got:\n%s", got)
+	}
+	got := StuckDirectiveFor(FailureTimeout)
+	if !strings.Contains(got, "keep timing out") || !strings.Contains(got, "fundamentally different approach") {
+		t.Errorf("timeout directive = %s", got)
+	}
+	if StuckDirectiveFor(FailureCompile) == StuckDirectiveFor(FailureAssertion) {
+		t.Error("directives should differ by failure class")
+	}
+}
@@ -0,0 +1,164 @@
+package ralph
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/signalnine/conclave/internal/bus"
+)
+
+// DefaultBoardKeep is how many recent entries, besides the ones ReadBoard
+// always keeps, board compaction retains.
+const DefaultBoardKeep = 200
+
+// maxBoardLine bounds a board line during compaction; a longer line aborts
+// the file's compaction rather than being dropped unread.
+const maxBoardLine = 16 * 1024 * 1024
+
+// CompactStats summarizes a board compaction.
+type CompactStats struct {
+	Files   int   // board files rewritten
+	Kept    int   // entries retained
+	Dropped int   // entries (and malformed lines) removed
+	Freed   int64 // bytes removed
+}
+
+// CompactBoard shrinks the board files in dir to their keep most recent
+// entries by sequence number, plus every major and critical entry (legacy
+// warnings included), mirroring what ReadBoard would return. Expired
+// entries and malformed lines are dropped.
+//
+// It is safe to run while tasks append: each file is filtered and
+// rewritten in place under the exclusive lock appenders take, and entries
+// appended after the cutoff is chosen are newer than it, so they are kept.
+// FileBus subscribers see the file shrink and re-read it from the start,
+// skipping the entries they already delivered.
+func CompactBoard(dir string, keep int) (CompactStats, error) {
+	var stats CompactStats
+	if keep < 0 {
+		return stats, fmt.Errorf("keep must be >= 0, got %d", keep)
+	}
+	files, err := boardFiles(dir)
+	if err != nil {
+		return stats, err
+	}
+	cutoff, err := compactCutoff(files, keep)
+	if err != nil {
+		return stats, err
+	}
+	now := time.Now()
+	retain := func(e bus.Envelope) bool {
+		return !EntryExpired(e, now) && (alwaysInclude(e) || e.Seq >= cutoff)
+	}
+	for _, path := range files {
+		kept, dropped, freed, err := compactBoardFile(path, retain)
+		if err != nil {
+			return stats, fmt.Errorf("%s: %w", filepath.Base(path), err)
+		}
+		stats.Kept += kept
+		stats.Dropped += dropped
+		if dropped > 0 {
+			stats.Files++
+			stats.Freed += freed
+		}
+	}
+	return stats, nil
+}
+
+// boardFiles lists the .jsonl files in dir.
+func boardFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".jsonl") {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	return files, nil
+}
+
+// compactCutoff returns the lowest Seq among the keep most recent entries
+// that are not always kept; entries below it are dropped.
+func compactCutoff(files []string, keep int) (uint64, error) {
+	now := time.Now()
+	var seqs []uint64
+	for _, path := range files {
+		envs, err := readBoardFile(path)
+		if err != nil {
+			return 0, err
+		}
+		for _, e := range envs {
+			if !EntryExpired(e, now) && !alwaysInclude(e) {
+				seqs = append(seqs, e.Seq)
+			}
+		}
+	}
+	if len(seqs) <= keep {
+		return 0, nil
+	}
+	if keep == 0 {
+		return ^uint64(0), nil
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] > seqs[j] })
+	return seqs[keep-1], nil
+}
+
+// compactBoardFile rewrites path in place, under an exclusive lock, with
+// only the lines whose envelope retain accepts. Kept lines are copied
+// verbatim. They are written over the old lines before the file is cut to
+// their length, so a crash midway cannot leave an empty file.
+func compactBoardFile(path string, retain func(bus.Envelope) bool) (kept, dropped int, freed int64, err error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0644)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	defer f.Close()
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		return 0, 0, 0, fmt.Errorf("flock: %w", err)
+	}
+	defer syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+
+	info, err := f.Stat()
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	var out []byte
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), maxBoardLine)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(strings.TrimSpace(string(line))) == 0 {
+			continue
+		}
+		var env bus.Envelope
+		if json.Unmarshal(line, &env) != nil || !retain(env) {
+			dropped++
+			continue
+		}
+		out = append(append(out, line...), '\n')
+		kept++
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, 0, 0, err
+	}
+	if dropped == 0 {
+		return kept, 0, 0, nil
+	}
+	if _, err := f.WriteAt(out, 0); err != nil {
+		return 0, 0, 0, fmt.Errorf("write: %w", err)
+	}
+	if err := f.Truncate(int64(len(out))); err != nil {
+		return 0, 0, 0, fmt.Errorf("truncate: %w", err)
+	}
+	return kept, dropped, info.Size() - int64(len(out)), f.Sync()
+}
@@ -0,0 +1,174 @@
+package ralph
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/signalnine/conclave/internal/bus"
+)
+
+func boardSeqs(t *testing.T, dir string) []uint64 {
+	t.Helper()
+	var seqs []uint64
+	files, err := boardFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range files {
+		envs, err := readBoardFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range envs {
+			seqs = append(seqs, e.Seq)
+		}
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+	return seqs
+}
+
+func TestCompactBoard(t *testing.T) {
+	dir := t.TempDir()
+	entry := func(seq uint64, typ, payload string) bus.Envelope {
+		return bus.Envelope{ID: fmt.Sprintf("e-%d", seq), Seq: seq, Type: typ, Payload: json.RawMessage(payload)}
+	}
+	expired := time.Now().Add(-time.Hour).Format(time.RFC3339)
+	writeBoardFile(t, dir, "wave-0.jsonl", []bus.Envelope{
+		entry(1, "board.discovery", `{"text":"old"}`),
+		entry(2, "board.warning", `{"text":"old warning"}`),
+		entry(3, "board.discovery", `{"text":"old but critical","severity":"critical"}`),
+		entry(4, "board.discovery", `{"text":"old"}`),
+	})
+	writeBoardFile(t, dir, "wave-1.jsonl", []bus.Envelope{
+		entry(5, "board.discovery", `{"text":"old"}`),
+		entry(6, "board.intent", `{"text":"recent"}`),
+		entry(7, "board.context", `{"text":"expired","expires_at":"`+expired+`"}`),
+		entry(8, "board.discovery", `{"text":"recent"}`),
+	})
+	f, _ := os.OpenFile(filepath.Join(dir, "wave-1.jsonl"), os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString("{truncated\n")
+	f.Close()
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not a board file\n"), 0644)
+
+	stats, err := CompactBoard(dir, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(boardSeqs(t, dir)); got != "[2 3 6 8]" {
+		t.Errorf("kept seqs = %s, want warnings, the critical entry and the 2 most recent", got)
+	}
+	if stats.Kept != 4 || stats.Dropped != 5 || stats.Files != 2 || stats.Freed <= 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "notes.txt")); string(data) != "not a board file\n" {
+		t.Error("non-board file touched")
+	}
+
+	// Everything fits: nothing is rewritten.
+	if stats, err := CompactBoard(dir, 10); err != nil || stats.Files != 0 || stats.Kept != 4 {
+		t.Errorf("second compaction = %+v, %v", stats, err)
+	}
+}
+
+func TestCompactBoardKeepsConcurrentAppends(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "board.jsonl")
+	var old []bus.Envelope
+	for seq := uint64(1); seq <= 500; seq++ {
+		old = append(old, bus.Envelope{ID: fmt.Sprint(seq), Seq: seq, Type: "board.discovery", Payload: json.RawMessage(`{"text":"old"}`)})
+	}
+	writeBoardFile(t, dir, "board.jsonl", old)
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				seq := uint64(1000 + w*100 + i)
+				AppendBoard(path, bus.Envelope{ID: fmt.Sprint(seq), Seq: seq, Type: "board.discovery", Payload: json.RawMessage(`{"text":"new"}`)})
+			}
+		}(w)
+	}
+	// keep covers every append, so any loss is an append racing a rewrite.
+	for i := 0; i < 5; i++ {
+		if _, err := CompactBoard(dir, 200); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wg.Wait()
+
+	newer, stale := 0, 0
+	for _, seq := range boardSeqs(t, dir) {
+		if seq >= 1000 {
+			newer++
+		} else {
+			stale++
+		}
+	}
+	if newer != 200 {
+		t.Errorf("%d of 200 concurrent appends survived compaction", newer)
+	}
+	if stale == 500 {
+		t.Error("no old entries were compacted away")
+	}
+}
+
+func TestCompactBoardKeepsLiveSubscribersReading(t *testing.T) {
+	dir := t.TempDir()
+	fb, err := bus.NewFileBus(dir, 10*time.Millisecond, 50*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fb.Close()
+	sub, err := fb.Subscribe("wave.board")
+	if err != nil {
+		t.Fatal(err)
+	}
+	post := func(text string) {
+		t.Helper()
+		if err := fb.Publish("wave.board", bus.Message{Type: "board.discovery", Payload: json.RawMessage(`{"text":"` + text + `"}`)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	next := func() (bus.Envelope, bool) {
+		select {
+		case env := <-sub:
+			return env, true
+		case <-time.After(2 * time.Second):
+			return bus.Envelope{}, false
+		}
+	}
+	for i := 0; i < 50; i++ {
+		post("old")
+	}
+	for i := 0; i < 50; i++ {
+		if _, ok := next(); !ok {
+			t.Fatalf("received %d of 50 entries before compaction", i)
+		}
+	}
+
+	if _, err := CompactBoard(dir, 5); err != nil {
+		t.Fatal(err)
+	}
+	post("after")
+	post("after")
+	// The file is now shorter than the subscriber's offset; the new
+	// entries must still arrive, and the ones it already had must not
+	// arrive again.
+	for i := 0; i < 2; i++ {
+		env, ok := next()
+		if !ok {
+			t.Fatalf("received %d of 2 entries posted after compaction", i)
+		}
+		if string(env.Payload) != `{"text":"after"}` {
+			t.Errorf("entry %d after compaction = %s, want a new one", i, env.Payload)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package ralph
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// GateAction is an operator override for the running gate.
+type GateAction int
+
+const (
+	GateSkip    GateAction = iota + 1 // stop the gate and treat it as passed
+	GateRestart                       // stop the gate and run it again
+)
+
+func (a GateAction) String() string {
+	switch a {
+	case GateSkip:
+		return "skip"
+	case GateRestart:
+		return "restart"
+	}
+	return "none"
+}
+
+// GateControl lets a human skip or restart whichever gate is running, as an
+// escape hatch during long autonomous runs. Overrides sent while no gate is
+// running are ignored. The zero value is ready to use.
+type GateControl struct {
+	// OnAction, if set, is called with the gate's name whenever an override
+	// is applied to it, for the caller to report.
+	OnAction func(gate string, a GateAction)
+
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+	pending GateAction
+}
+
+// Skip cancels the running gate and counts it as passed.
+func (c *GateControl) Skip() { c.signal(GateSkip) }
+
+// Restart cancels the running gate and starts it again.
+func (c *GateControl) Restart() { c.signal(GateRestart) }
+
+func (c *GateControl) signal(a GateAction) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cancel != nil {
+		c.pending = a
+		c.cancel()
+	}
+}
+
+// run runs g under a context the control can cancel, applying any override
+// received while it ran. Cancellation of ctx itself always wins.
+func (c *GateControl) run(ctx context.Context, g Gate) (string, error) {
+	for {
+		gctx, cancel := context.WithCancel(ctx)
+		c.mu.Lock()
+		c.cancel, c.pending = cancel, 0
+		c.mu.Unlock()
+
+		out, err := g.Run(gctx)
+
+		c.mu.Lock()
+		action := c.pending
+		c.cancel, c.pending = nil, 0
+		c.mu.Unlock()
+		cancel()
+
+		if ctx.Err() != nil {
+			return out, err
+		}
+		if action != 0 && c.OnAction != nil {
+			c.OnAction(g.Name, action)
+		}
+		switch action {
+		case GateSkip:
+			return out, nil
+		case GateRestart:
+			continue
+		}
+		return out, err
+	}
+}
+
+// HandleGateSignals routes SIGUSR1 to Skip and SIGUSR2 to Restart until the
+// returned stop function is called.
+func (c *GateControl) HandleGateSignals() (stop func()) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGUSR1, syscall.SIGUSR2)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case sig := <-ch:
+				if sig == syscall.SIGUSR1 {
+					c.Skip()
+				} else {
+					c.Restart()
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(ch)
+		close(done)
+	}
+}
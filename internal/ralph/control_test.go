@@ -0,0 +1,117 @@
+package ralph
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// blockingGate blocks each run until its context is canceled, announcing
+// every start on started. Runs listed in pass return immediately instead.
+func blockingGate(name string, runs *atomic.Int32, started chan<- struct{}, pass map[int32]bool) Gate {
+	return Gate{Name: name, Run: func(ctx context.Context) (string, error) {
+		n := runs.Add(1)
+		if pass[n] {
+			return "", nil
+		}
+		started <- struct{}{}
+		<-ctx.Done()
+		return "", ctx.Err()
+	}}
+}
+
+func runControlled(t *testing.T, gates []Gate, ctl *GateControl) <-chan string {
+	t.Helper()
+	failed := make(chan string, 1)
+	go func() {
+		name, _, _ := RunGatesControlled(context.Background(), gates, "", ctl)
+		failed <- name
+	}()
+	return failed
+}
+
+func waitStarted(t *testing.T, started <-chan struct{}) {
+	t.Helper()
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("gate never started")
+	}
+}
+
+func TestSIGUSR1SkipsRunningGate(t *testing.T) {
+	var reported []string
+	ctl := GateControl{OnAction: func(gate string, a GateAction) { reported = append(reported, gate+" "+a.String()) }}
+	defer ctl.HandleGateSignals()()
+
+	var implRuns, testRuns atomic.Int32
+	started := make(chan struct{}, 1)
+	gates := []Gate{
+		blockingGate(GateImplement, &implRuns, started, nil),
+		{Name: GateTests, Run: func(ctx context.Context) (string, error) { testRuns.Add(1); return "", nil }},
+	}
+	failed := runControlled(t, gates, &ctl)
+
+	waitStarted(t, started)
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	select {
+	case name := <-failed:
+		if name != "" {
+			t.Fatalf("gate %s failed, want the skipped gate to count as passed", name)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("SIGUSR1 did not stop the gate")
+	}
+	if implRuns.Load() != 1 || testRuns.Load() != 1 {
+		t.Errorf("implement ran %d times, tests %d; want 1 and 1", implRuns.Load(), testRuns.Load())
+	}
+	if len(reported) != 1 || reported[0] != GateImplement+" skip" {
+		t.Errorf("reported %q, want one skip of the %s gate", reported, GateImplement)
+	}
+}
+
+func TestSIGUSR2RestartsRunningGate(t *testing.T) {
+	var reported []string
+	ctl := GateControl{OnAction: func(gate string, a GateAction) { reported = append(reported, gate+" "+a.String()) }}
+	defer ctl.HandleGateSignals()()
+
+	var runs atomic.Int32
+	started := make(chan struct{}, 1)
+	gates := []Gate{blockingGate(GateTests, &runs, started, map[int32]bool{2: true})}
+	failed := runControlled(t, gates, &ctl)
+
+	waitStarted(t, started)
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR2)
+	select {
+	case name := <-failed:
+		if name != "" {
+			t.Fatalf("gate %s failed after restart", name)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("SIGUSR2 did not restart the gate")
+	}
+	if runs.Load() != 2 {
+		t.Errorf("gate ran %d times, want 2", runs.Load())
+	}
+	if len(reported) != 1 || reported[0] != GateTests+" restart" {
+		t.Errorf("reported %q, want one restart of the %s gate", reported, GateTests)
+	}
+}
+
+func TestGateControlParentCancelWins(t *testing.T) {
+	var ctl GateControl
+	ctx, cancel := context.WithCancel(context.Background())
+	gate := Gate{Name: GateTests, Run: func(ctx context.Context) (string, error) {
+		ctl.Skip()
+		cancel()
+		<-ctx.Done()
+		return "", ctx.Err()
+	}}
+	failed, _, err := RunGatesControlled(ctx, []Gate{gate}, "", &ctl)
+	if failed != GateTests || !errors.Is(err, context.Canceled) {
+		t.Errorf("failed=%q err=%v, want the canceled run to fail", failed, err)
+	}
+}
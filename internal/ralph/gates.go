@@ -2,27 +2,166 @@ package ralph
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 )
 
+// Gate names used in state, logs and on-failure targets.
+const (
+	GateImplement = "implement"
+	GateTests     = "tests"
+	GateSpec      = "spec"
+)
+
+// ErrMaxIterations is returned when the loop exhausts its iterations without
+// every gate passing.
+var ErrMaxIterations = errors.New("max iterations reached")
+
+// ErrSuperseded is returned when a peer posted board.complete for the task's
+// objective, so further iterations would be redundant.
+var ErrSuperseded = errors.New("superseded by peer")
+
 type GateConfig struct {
 	ImplementTimeout int
 	TestTimeout      int
 	SpecTimeout      int
 	QualityTimeout   int
+
+	// OnFailure maps a gate name to the gate the loop restarts from when it
+	// fails, e.g. {"tests": "tests"} re-runs a flaky test gate without
+	// re-implementing. Gates without an entry restart from the first gate.
+	OnFailure map[string]string
+
+	// Env maps a gate name to extra environment variables for that gate's
+	// subprocess, merged over the process environment, e.g.
+	// {"tests": {"CI": "true"}}. Other gates don't see them.
+	Env map[string]map[string]string
+}
+
+// Environ returns the environment for gate's subprocess: the process
+// environment with the gate's Env entries added or replaced. Returns nil
+// (inherit unchanged) when the gate has no entries.
+func (c GateConfig) Environ(gate string) []string {
+	extra := c.Env[gate]
+	if len(extra) == 0 {
+		return nil
+	}
+	var env []string
+	for _, kv := range os.Environ() {
+		k, _, _ := strings.Cut(kv, "=")
+		if _, overridden := extra[k]; !overridden {
+			env = append(env, kv)
+		}
+	}
+	keys := make([]string, 0, len(extra))
+	for k := range extra {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		env = append(env, k+"="+extra[k])
+	}
+	return env
+}
+
+// Gate is one step of the ralph loop.
+type Gate struct {
+	Name string
+	Run  func(ctx context.Context) (string, error)
+}
+
+// RunGates runs gates in order, starting at the gate named from (empty starts
+// at the first). It stops at the first failure and returns the failed gate's
+// name with its output and error; an empty name means every gate passed.
+func RunGates(ctx context.Context, gates []Gate, from string) (string, string, error) {
+	return RunGatesControlled(ctx, gates, from, nil)
+}
+
+// RunGatesControlled is RunGates with operator overrides: ctl (if set) can
+// skip or restart the gate that is running.
+func RunGatesControlled(ctx context.Context, gates []Gate, from string, ctl *GateControl) (string, string, error) {
+	start := gateIndex(gates, from)
+	if start < 0 {
+		start = 0
+	}
+	for _, g := range gates[start:] {
+		run := g.Run
+		if ctl != nil {
+			run = func(ctx context.Context) (string, error) { return ctl.run(ctx, g) }
+		}
+		if out, err := run(ctx); err != nil {
+			return g.Name, out, err
+		}
+	}
+	return "", "", nil
+}
+
+// RetryFrom returns the gate the loop restarts from after failed fails.
+func (c GateConfig) RetryFrom(gates []Gate, failed string) string {
+	if target, ok := c.OnFailure[failed]; ok && gateIndex(gates, target) >= 0 {
+		return target
+	}
+	if len(gates) == 0 {
+		return ""
+	}
+	return gates[0].Name
+}
+
+// ValidateOnFailure checks that every on-failure entry names known gates and
+// loops back to the failing gate or an earlier one.
+func (c GateConfig) ValidateOnFailure(gates []Gate) error {
+	for from, to := range c.OnFailure {
+		fi, ti := gateIndex(gates, from), gateIndex(gates, to)
+		switch {
+		case fi < 0:
+			return fmt.Errorf("on-failure: unknown gate %q", from)
+		case ti < 0:
+			return fmt.Errorf("on-failure: unknown target gate %q for %s", to, from)
+		case ti > fi:
+			return fmt.Errorf("on-failure: %s cannot skip ahead to %s", from, to)
+		}
+	}
+	return nil
+}
+
+func gateIndex(gates []Gate, name string) int {
+	for i, g := range gates {
+		if g.Name == name {
+			return i
+		}
+	}
+	return -1
 }
 
 func RunTestGate(ctx context.Context, projectDir string, timeout int) (string, error) {
+	return RunTestGateEnv(ctx, projectDir, timeout, nil)
+}
+
+// RunTestGateEnv is RunTestGate with an explicit environment for the test
+// runner (nil inherits the process environment), see GateConfig.Environ.
+func RunTestGateEnv(ctx context.Context, projectDir string, timeout int, env []string) (string, error) {
+	return RunTestGateCmd(ctx, projectDir, "", timeout, env)
+}
+
+// RunTestGateCmd is RunTestGateEnv with an explicit test command, run by
+// sh -c in projectDir so it may use pipes and &&, e.g. "make check". An
+// empty command auto-detects the test runner. The command's exit status
+// decides the gate.
+func RunTestGateCmd(ctx context.Context, projectDir, command string, timeout int, env []string) (string, error) {
 	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
 	defer cancel()
 
 	// Auto-detect test runner
 	var cmd *exec.Cmd
 	switch {
+	case strings.TrimSpace(command) != "":
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
 	case fileExists(filepath.Join(projectDir, "package.json")):
 		cmd = exec.CommandContext(ctx, "npm", "test", "--prefix", projectDir)
 	case fileExists(filepath.Join(projectDir, "Cargo.toml")):
@@ -38,23 +177,39 @@ func RunTestGate(ctx context.Context, projectDir string, timeout int) (string, e
 		return "WARNING: No test runner detected, skipping test gate", nil
 	}
 	cmd.Dir = projectDir
+	cmd.Env = env
+	// Children of a killed runner (sh -c "sleep ...") can hold the output
+	// pipe open; stop waiting on them so the timeout still ends the gate.
+	cmd.WaitDelay = time.Second
 	out, err := cmd.CombinedOutput()
 	return string(out), err
 }
 
-func RunSpecGate(ctx context.Context, taskPromptFile, contextFile string, timeout int) (string, error) {
-	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
-	defer cancel()
-
-	taskPrompt, _ := os.ReadFile(taskPromptFile)
-	ctxContent, _ := os.ReadFile(contextFile)
-
-	prompt := fmt.Sprintf("Review this implementation for spec compliance.\n\n## Task Spec\n%s\n\n## Current State\n%s\n\n## Instructions\nCheck if the implementation satisfies ALL requirements in the spec.\nOutput 'SPEC_PASS' if compliant, or list missing/extra items if not.",
-		string(taskPrompt), string(ctxContent))
+// PromptPlaceholder marks where an implement command template takes the
+// prompt. A template without it gets the prompt on stdin.
+const PromptPlaceholder = "{prompt}"
 
-	cmd := exec.CommandContext(ctx, "claude", "-p", prompt)
-	out, err := cmd.CombinedOutput()
-	return string(out), err
+// ImplementCommand builds the implementation gate's command from template,
+// a whitespace-separated command line such as "claude -p {prompt}". Each
+// PromptPlaceholder is replaced by the prompt as part of a single argument,
+// so "--prompt={prompt}" works too.
+func ImplementCommand(ctx context.Context, template, prompt string) (*exec.Cmd, error) {
+	fields := strings.Fields(template)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty implement command")
+	}
+	placed := false
+	for i, f := range fields {
+		if strings.Contains(f, PromptPlaceholder) {
+			fields[i] = strings.ReplaceAll(f, PromptPlaceholder, prompt)
+			placed = true
+		}
+	}
+	cmd := exec.CommandContext(ctx, fields[0], fields[1:]...)
+	if !placed {
+		cmd.Stdin = strings.NewReader(prompt)
+	}
+	return cmd, nil
 }
 
 func fileExists(path string) bool {
@@ -0,0 +1,190 @@
+package ralph
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type gateCounter struct {
+	runs  map[string]int
+	fails map[string]int // remaining failures per gate
+}
+
+func (c *gateCounter) gate(name string) Gate {
+	return Gate{Name: name, Run: func(ctx context.Context) (string, error) {
+		c.runs[name]++
+		if c.fails[name] > 0 {
+			c.fails[name]--
+			return name + " failed", fmt.Errorf("%s failed", name)
+		}
+		return "", nil
+	}}
+}
+
+func newGateCounter(fails map[string]int) (*gateCounter, []Gate) {
+	c := &gateCounter{runs: map[string]int{}, fails: fails}
+	return c, []Gate{c.gate(GateImplement), c.gate(GateTests), c.gate(GateSpec)}
+}
+
+// runLoop drives gates the way ralph-run does until they pass.
+func runLoop(t *testing.T, cfg GateConfig, gates []Gate) {
+	t.Helper()
+	from := GateImplement
+	for i := 0; i < 10; i++ {
+		failed, _, _ := RunGates(context.Background(), gates, from)
+		if failed == "" {
+			return
+		}
+		from = cfg.RetryFrom(gates, failed)
+	}
+	t.Fatal("gates never passed")
+}
+
+func TestTestFailureRerunsOnlyTestGate(t *testing.T) {
+	c, gates := newGateCounter(map[string]int{GateTests: 1})
+	cfg := GateConfig{OnFailure: map[string]string{GateTests: GateTests}}
+	if err := cfg.ValidateOnFailure(gates); err != nil {
+		t.Fatal(err)
+	}
+	runLoop(t, cfg, gates)
+
+	if c.runs[GateImplement] != 1 {
+		t.Errorf("implement ran %d times, want 1", c.runs[GateImplement])
+	}
+	if c.runs[GateTests] != 2 {
+		t.Errorf("tests ran %d times, want 2", c.runs[GateTests])
+	}
+	if c.runs[GateSpec] != 1 {
+		t.Errorf("spec ran %d times, want 1", c.runs[GateSpec])
+	}
+}
+
+func TestDefaultFailureRestartsFromImplement(t *testing.T) {
+	c, gates := newGateCounter(map[string]int{GateTests: 1})
+	runLoop(t, GateConfig{}, gates)
+	if c.runs[GateImplement] != 2 || c.runs[GateTests] != 2 {
+		t.Errorf("runs = %v, want implement and tests twice", c.runs)
+	}
+}
+
+func TestValidateOnFailure(t *testing.T) {
+	_, gates := newGateCounter(nil)
+	tests := []struct {
+		onFailure map[string]string
+		wantErr   bool
+	}{
+		{map[string]string{GateSpec: GateImplement}, false},
+		{map[string]string{GateTests: GateTests}, false},
+		{map[string]string{"lint": GateTests}, true},
+		{map[string]string{GateTests: "deploy"}, true},
+		{map[string]string{GateImplement: GateSpec}, true},
+	}
+	for _, tt := range tests {
+		err := GateConfig{OnFailure: tt.onFailure}.ValidateOnFailure(gates)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidateOnFailure(%v) err = %v, wantErr %v", tt.onFailure, err, tt.wantErr)
+		}
+	}
+}
+
+func TestGateEnvOnlyReachesItsGate(t *testing.T) {
+	dir := t.TempDir()
+	script := "#!/bin/sh\necho \"RALPH_GATE_PROBE=$RALPH_GATE_PROBE\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "test.sh"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := GateConfig{Env: map[string]map[string]string{GateTests: {"RALPH_GATE_PROBE": "tests-only"}}}
+
+	out, err := RunTestGateEnv(context.Background(), dir, 10, cfg.Environ(GateTests))
+	if err != nil {
+		t.Fatalf("test gate: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "RALPH_GATE_PROBE=tests-only") {
+		t.Errorf("test gate output = %q, want the gate env var", out)
+	}
+
+	impl := exec.Command("sh", "-c", "echo \"RALPH_GATE_PROBE=$RALPH_GATE_PROBE\"")
+	impl.Env = cfg.Environ(GateImplement)
+	implOut, err := impl.CombinedOutput()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(string(implOut)) != "RALPH_GATE_PROBE=" {
+		t.Errorf("implement env leaked the tests var: %q", implOut)
+	}
+}
+
+func TestEnvironOverridesProcessEnv(t *testing.T) {
+	t.Setenv("RALPH_GATE_PROBE", "process")
+	cfg := GateConfig{Env: map[string]map[string]string{GateTests: {"RALPH_GATE_PROBE": "gate"}}}
+	var got []string
+	for _, kv := range cfg.Environ(GateTests) {
+		if strings.HasPrefix(kv, "RALPH_GATE_PROBE=") {
+			got = append(got, kv)
+		}
+	}
+	if len(got) != 1 || got[0] != "RALPH_GATE_PROBE=gate" {
+		t.Errorf("RALPH_GATE_PROBE entries = %v, want the gate value only", got)
+	}
+	if env := cfg.Environ(GateSpec); env != nil {
+		t.Errorf("gate without entries should inherit (nil), got %d vars", len(env))
+	}
+}
+
+func TestImplementCommand(t *testing.T) {
+	prompt := "fix the bug\nin main.go"
+	cmd, err := ImplementCommand(context.Background(), "my-agent --quiet {prompt} --prompt={prompt}", prompt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"my-agent", "--quiet", prompt, "--prompt=" + prompt}
+	if fmt.Sprint(cmd.Args) != fmt.Sprint(want) || cmd.Stdin != nil {
+		t.Errorf("args = %q, stdin %v; want the prompt substituted as whole arguments", cmd.Args, cmd.Stdin)
+	}
+
+	// Without a placeholder the prompt arrives on stdin.
+	cmd, err = ImplementCommand(context.Background(), "cat", prompt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != prompt {
+		t.Errorf("stdin = %q, want the prompt", out)
+	}
+
+	if _, err := ImplementCommand(context.Background(), "  ", prompt); err == nil {
+		t.Error("empty template should be rejected")
+	}
+}
+
+func TestRunTestGateCmd(t *testing.T) {
+	dir := t.TempDir()
+	// A go.mod would otherwise pick go test; the command wins over detection.
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out, err := RunTestGateCmd(context.Background(), dir, "pwd && echo checked", 10, nil)
+	if err != nil {
+		t.Fatalf("passing command: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, dir) || !strings.Contains(out, "checked") {
+		t.Errorf("output = %q, want the command run in the project dir", out)
+	}
+
+	out, err = RunTestGateCmd(context.Background(), dir, "echo boom >&2; exit 3", 10, nil)
+	if err == nil || !strings.Contains(out, "boom") {
+		t.Errorf("failing command: err = %v, output %q; want failure with stderr captured", err, out)
+	}
+
+	if _, err := RunTestGateCmd(context.Background(), dir, "sleep 5", 1, nil); err == nil {
+		t.Error("command past the timeout should fail the gate")
+	}
+}
@@ -0,0 +1,121 @@
+package ralph
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	gitpkg "github.com/signalnine/conclave/internal/git"
+)
+
+// ErrUnsafePath is returned when an iteration changed files outside the
+// loop's allowed paths.
+var ErrUnsafePath = errors.New("changes outside the allowed paths")
+
+// PathGuard is a safety rail for unattended runs: it flags working tree
+// changes that resolve (following symlinks) outside a set of allowed roots,
+// so they are never committed by the loop.
+type PathGuard struct {
+	git      *gitpkg.Git
+	top      string          // repository top level; changed paths are relative to it
+	allowed  []string        // resolved allowed roots
+	baseline map[string]bool // paths already changed when the guard was created
+}
+
+// NewPathGuard guards the repository containing dir. Allowed roots are
+// resolved against dir; none allows dir itself. Paths already changed when
+// the guard is created are not flagged later.
+func NewPathGuard(dir string, allowed []string) (*PathGuard, error) {
+	g := gitpkg.New(dir)
+	top, err := g.TopLevel()
+	if err != nil {
+		return nil, err
+	}
+	if len(allowed) == 0 {
+		allowed = []string{"."}
+	}
+	p := &PathGuard{git: g, top: resolvePath(top), baseline: make(map[string]bool)}
+	for _, root := range allowed {
+		if !filepath.IsAbs(root) {
+			root = filepath.Join(dir, root)
+		}
+		p.allowed = append(p.allowed, resolvePath(root))
+	}
+	changed, err := g.ChangedPaths()
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range changed {
+		p.baseline[path] = true
+	}
+	return p, nil
+}
+
+// Allowed returns the resolved allowed roots.
+func (p *PathGuard) Allowed() []string { return p.allowed }
+
+// Check returns the paths changed since the guard was created that resolve
+// outside every allowed root, relative to the repository root.
+func (p *PathGuard) Check() ([]string, error) {
+	changed, err := p.git.ChangedPaths()
+	if err != nil {
+		return nil, err
+	}
+	var outside []string
+	for _, path := range changed {
+		if p.baseline[path] {
+			continue
+		}
+		if !p.isAllowed(resolvePath(filepath.Join(p.top, path))) {
+			outside = append(outside, path)
+		}
+	}
+	return outside, nil
+}
+
+// Enforce is Check reporting any violation as an ErrUnsafePath error.
+func (p *PathGuard) Enforce() error {
+	outside, err := p.Check()
+	if err != nil {
+		return fmt.Errorf("path guard: %w", err)
+	}
+	if len(outside) > 0 {
+		return fmt.Errorf("%w (%s): %s", ErrUnsafePath, strings.Join(p.allowed, ", "), strings.Join(outside, ", "))
+	}
+	return nil
+}
+
+func (p *PathGuard) isAllowed(path string) bool {
+	for _, root := range p.allowed {
+		rel, err := filepath.Rel(root, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolvePath returns path with symlinks evaluated. For a path that no longer
+// exists (e.g. a deleted file), its nearest existing ancestor is resolved.
+func resolvePath(path string) string {
+	path = filepath.Clean(path)
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	parent := filepath.Dir(path)
+	if parent == path {
+		return path
+	}
+	if _, err := os.Lstat(path); err == nil {
+		// A dangling symlink: judge it by where it points.
+		if target, err := os.Readlink(path); err == nil {
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(parent, target)
+			}
+			return filepath.Clean(target)
+		}
+	}
+	return filepath.Join(resolvePath(parent), filepath.Base(path))
+}
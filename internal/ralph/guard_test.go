@@ -0,0 +1,103 @@
+package ralph
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// setupGuardRepo creates a repository with an app/ subdirectory, the loop's
+// working directory in these tests.
+func setupGuardRepo(t *testing.T) (repo, app string) {
+	t.Helper()
+	repo = t.TempDir()
+	app = filepath.Join(repo, "app")
+	os.MkdirAll(app, 0755)
+	os.WriteFile(filepath.Join(app, "main.go"), []byte("package main\n"), 0644)
+	os.WriteFile(filepath.Join(repo, "README.md"), []byte("readme\n"), 0644)
+	for _, args := range [][]string{
+		{"git", "init", "-b", "main"},
+		{"git", "config", "user.email", "test@test.com"},
+		{"git", "config", "user.name", "Test"},
+		{"git", "add", "."},
+		{"git", "commit", "-m", "initial"},
+	} {
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("%v failed: %s %v", args, out, err)
+		}
+	}
+	return repo, app
+}
+
+func TestPathGuardFlagsOutOfTreeChanges(t *testing.T) {
+	repo, app := setupGuardRepo(t)
+	guard, err := NewPathGuard(app, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// In-tree edits pass.
+	os.WriteFile(filepath.Join(app, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644)
+	os.WriteFile(filepath.Join(app, "new.go"), []byte("package main\n"), 0644)
+	if err := guard.Enforce(); err != nil {
+		t.Fatalf("in-tree changes flagged: %v", err)
+	}
+
+	// A confused implementer edits a file outside the working directory.
+	os.WriteFile(filepath.Join(repo, "README.md"), []byte("rewritten\n"), 0644)
+	outside, err := guard.Check()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(outside, []string{"README.md"}) {
+		t.Errorf("Check() = %v, want [README.md]", outside)
+	}
+	if err := guard.Enforce(); !errors.Is(err, ErrUnsafePath) {
+		t.Errorf("Enforce() = %v, want ErrUnsafePath", err)
+	}
+}
+
+func TestPathGuardFlagsSymlinkEscape(t *testing.T) {
+	_, app := setupGuardRepo(t)
+	guard, err := NewPathGuard(app, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(t.TempDir(), filepath.Join(app, "escape")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	outside, err := guard.Check()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(outside, []string{"app/escape"}) {
+		t.Errorf("Check() = %v, want [app/escape]", outside)
+	}
+}
+
+func TestPathGuardAllowedRootsAndBaseline(t *testing.T) {
+	repo, app := setupGuardRepo(t)
+	os.MkdirAll(filepath.Join(repo, "docs"), 0755)
+	// Already dirty before the run: not the loop's doing.
+	os.WriteFile(filepath.Join(repo, "README.md"), []byte("local edit\n"), 0644)
+
+	guard, err := NewPathGuard(app, []string{".", "../docs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(repo, "docs", "guide.md"), []byte("guide\n"), 0644)
+	if err := guard.Enforce(); err != nil {
+		t.Errorf("allowed root or baseline change flagged: %v", err)
+	}
+
+	os.WriteFile(filepath.Join(repo, "Makefile"), []byte("all:\n"), 0644)
+	outside, _ := guard.Check()
+	if !reflect.DeepEqual(outside, []string{"Makefile"}) {
+		t.Errorf("Check() = %v, want [Makefile]", outside)
+	}
+}
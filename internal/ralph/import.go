@@ -0,0 +1,94 @@
+package ralph
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/signalnine/conclave/internal/bus"
+)
+
+// BoardImportFilter selects which entries of a prior run's board are carried
+// forward into a new one.
+type BoardImportFilter struct {
+	Types  []string      // board types to keep, e.g. "board.warning" (all when empty)
+	MaxAge time.Duration // drop entries older than this (0 keeps any age)
+}
+
+// BoardImport is the outcome of reading a source board for import.
+type BoardImport struct {
+	Messages  []bus.Message // entries to publish, in source order
+	Filtered  int           // valid entries excluded by the filter or expired
+	Malformed int           // lines that are not board envelopes
+}
+
+// NormalizeBoardType accepts a board type with or without its "board."
+// prefix.
+func NormalizeBoardType(typ string) string {
+	typ = strings.TrimSpace(typ)
+	if typ == "" || strings.HasPrefix(typ, "board.") {
+		return typ
+	}
+	return "board." + typ
+}
+
+// ReadBoardImport reads board envelopes from a prior run's JSONL and selects
+// those matching f. Selected entries keep their type and payload; the sender
+// is re-stamped "<sender> (imported from <run>)" so readers can tell carried
+// forward knowledge from the current wave's. Blank lines are ignored; lines
+// that fail to parse or lack a type are counted as malformed and skipped.
+func ReadBoardImport(r io.Reader, run string, f BoardImportFilter, now time.Time) (BoardImport, error) {
+	types := make([]string, 0, len(f.Types))
+	for _, t := range f.Types {
+		types = append(types, NormalizeBoardType(t))
+	}
+
+	var res BoardImport
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var env bus.Envelope
+		if err := json.Unmarshal([]byte(line), &env); err != nil || env.Type == "" || !json.Valid(env.Payload) {
+			res.Malformed++
+			continue
+		}
+		tooOld := f.MaxAge > 0 && !env.Timestamp.IsZero() && now.Sub(env.Timestamp) > f.MaxAge
+		wrongType := len(types) > 0 && !slices.Contains(types, env.Type)
+		if tooOld || wrongType || EntryExpired(env, now) {
+			res.Filtered++
+			continue
+		}
+		sender := env.Sender
+		if sender == "" {
+			sender = "unknown"
+		}
+		res.Messages = append(res.Messages, bus.Message{
+			Type:    env.Type,
+			Sender:  fmt.Sprintf("%s (imported from %s)", sender, run),
+			Payload: env.Payload,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return res, fmt.Errorf("read source board: %w", err)
+	}
+	return res, nil
+}
+
+// PublishImport publishes imported entries to topic. Each gets a fresh ID,
+// sequence number and timestamp from the bus.
+func PublishImport(b bus.MessageBus, topic string, msgs []bus.Message) error {
+	for _, m := range msgs {
+		if err := b.Publish(topic, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package ralph
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadBoardImportFilters(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	src := strings.Join([]string{
+		`{"seq":1,"timestamp":"2025-06-01T11:00:00Z","sender":"task-1","type":"board.warning","payload":{"text":"recent warning"}}`,
+		`{"seq":2,"timestamp":"2025-05-01T11:00:00Z","sender":"task-1","type":"board.warning","payload":{"text":"stale warning"}}`,
+		`{"seq":3,"timestamp":"2025-06-01T11:30:00Z","sender":"task-2","type":"board.discovery","payload":{"text":"a discovery"}}`,
+		`not json`,
+		`{"seq":4,"sender":"task-2","payload":{"text":"no type"}}`,
+		``,
+	}, "\n")
+
+	imp, err := ReadBoardImport(strings.NewReader(src), "wave-7", BoardImportFilter{Types: []string{"warning"}, MaxAge: 24 * time.Hour}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(imp.Messages) != 1 || !strings.Contains(string(imp.Messages[0].Payload), "recent warning") {
+		t.Fatalf("messages = %+v, want only the recent warning", imp.Messages)
+	}
+	if got := imp.Messages[0].Sender; got != "task-1 (imported from wave-7)" {
+		t.Errorf("sender = %q", got)
+	}
+	if imp.Filtered != 2 || imp.Malformed != 2 {
+		t.Errorf("filtered %d, malformed %d; want 2 and 2", imp.Filtered, imp.Malformed)
+	}
+}
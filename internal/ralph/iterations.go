@@ -0,0 +1,123 @@
+package ralph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// iterationsFileName is the per-iteration metrics log kept beside the state
+// file. Unlike the state it outlives the run, so iterations can be analyzed
+// across many runs.
+const iterationsFileName = ".ralph_iterations.jsonl"
+
+// GateTiming is one gate run within an iteration.
+type GateTiming struct {
+	Gate    string  `json:"gate"`
+	Passed  bool    `json:"passed"`
+	Seconds float64 `json:"seconds"`
+}
+
+// IterationRecord is one line of the iterations file.
+type IterationRecord struct {
+	TaskID         string       `json:"task_id"`
+	Iteration      int          `json:"iteration"`
+	Approach       int          `json:"approach"`
+	StartedAt      time.Time    `json:"started_at"`
+	Seconds        float64      `json:"seconds"`
+	Gates          []GateTiming `json:"gates"`
+	Passed         bool         `json:"passed"`
+	FailedGate     string       `json:"failed_gate,omitempty"`
+	FailureClass   string       `json:"failure_class,omitempty"`
+	StuckCount     int          `json:"stuck_count"`
+	StrategyShifts int          `json:"strategy_shifts"`
+}
+
+// IterationTimer times the gates run in the current iteration.
+type IterationTimer struct {
+	mu      sync.Mutex
+	started time.Time
+	gates   []GateTiming
+}
+
+// Start begins timing a new iteration.
+func (t *IterationTimer) Start() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.started = time.Now()
+	t.gates = nil
+}
+
+// TimeGates returns gates with every run recorded in the current
+// iteration.
+func (t *IterationTimer) TimeGates(gates []Gate) []Gate {
+	timed := make([]Gate, len(gates))
+	for i, g := range gates {
+		name, run := g.Name, g.Run
+		timed[i] = Gate{Name: name, Run: func(ctx context.Context) (string, error) {
+			start := time.Now()
+			out, err := run(ctx)
+			t.mu.Lock()
+			t.gates = append(t.gates, GateTiming{Gate: name, Passed: err == nil, Seconds: time.Since(start).Seconds()})
+			t.mu.Unlock()
+			return out, err
+		}}
+	}
+	return timed
+}
+
+// Record describes the timed iteration: start is the state loaded when it
+// started, after the state once its outcome was recorded, and failed names
+// the gate that failed, "" if all passed.
+func (t *IterationTimer) Record(start, after *State, failed string) IterationRecord {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	rec := IterationRecord{
+		TaskID:         start.TaskID,
+		Iteration:      start.Iteration,
+		Approach:       start.CurrentApproach(),
+		StartedAt:      t.started,
+		Seconds:        time.Since(t.started).Seconds(),
+		Gates:          append([]GateTiming(nil), t.gates...),
+		Passed:         failed == "",
+		FailedGate:     failed,
+		StuckCount:     after.StuckCount,
+		StrategyShifts: after.StrategyShifts,
+	}
+	if failed != "" {
+		rec.FailureClass = after.FailureClass
+	}
+	return rec
+}
+
+func (s *StateManager) iterationsPath() string { return filepath.Join(s.dir, iterationsFileName) }
+
+// IterationsFile returns the path of the per-iteration metrics log.
+func (s *StateManager) IterationsFile() string { return s.iterationsPath() }
+
+// AppendIteration appends rec to the iterations file as one JSON line,
+// locked the way board appends are.
+func (s *StateManager) AppendIteration(rec IterationRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("marshal iteration: %w", err)
+	}
+	f, err := os.OpenFile(s.iterationsPath(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("open iterations file: %w", err)
+	}
+	defer f.Close()
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		return fmt.Errorf("flock: %w", err)
+	}
+	defer syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package ralph
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestIterationRecords(t *testing.T) {
+	dir := t.TempDir()
+	sm := NewStateManager(dir)
+	sm.Init("task-1", 5)
+
+	var timer IterationTimer
+	fail := true
+	gates := timer.TimeGates([]Gate{
+		{Name: GateImplement, Run: func(context.Context) (string, error) { return "", nil }},
+		{Name: GateTests, Run: func(context.Context) (string, error) {
+			if fail {
+				return "--- FAIL: TestLogin", errors.New("exit status 1")
+			}
+			return "", nil
+		}},
+	})
+
+	// A failing iteration, then a passing one.
+	for i := 0; i < 2; i++ {
+		start, _ := sm.Load()
+		timer.Start()
+		failed, out, err := RunGates(context.Background(), gates, GateImplement)
+		if failed != "" {
+			sm.UpdateFailure(failed, 1, out, ClassifyFailure(out, err))
+		}
+		after, _ := sm.Load()
+		if err := sm.AppendIteration(timer.Record(start, after, failed)); err != nil {
+			t.Fatal(err)
+		}
+		fail = false
+	}
+
+	f, err := os.Open(sm.IterationsFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var recs []IterationRecord
+	for scanner := bufio.NewScanner(f); scanner.Scan(); {
+		var rec IterationRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			t.Fatalf("bad line %q: %v", scanner.Text(), err)
+		}
+		recs = append(recs, rec)
+	}
+	if len(recs) != 2 {
+		t.Fatalf("got %d records, want 2", len(recs))
+	}
+	first, second := recs[0], recs[1]
+	if first.TaskID != "task-1" || first.Iteration != 1 || first.Approach != 1 || first.Passed {
+		t.Errorf("first record = %+v", first)
+	}
+	if first.FailedGate != GateTests || first.FailureClass != string(FailureAssertion) {
+		t.Errorf("first failure = %q/%q, want tests/assertion", first.FailedGate, first.FailureClass)
+	}
+	if len(first.Gates) != 2 || !first.Gates[0].Passed || first.Gates[1].Passed || first.Gates[1].Gate != GateTests {
+		t.Errorf("first gates = %+v", first.Gates)
+	}
+	if second.Iteration != 2 || !second.Passed || second.FailedGate != "" || second.FailureClass != "" || len(second.Gates) != 2 {
+		t.Errorf("second record = %+v, want a passing iteration 2 with its own gates", second)
+	}
+
+	sm.Cleanup()
+	if _, err := os.Stat(sm.IterationsFile()); err != nil {
+		t.Errorf("iterations file should outlive the run: %v", err)
+	}
+}
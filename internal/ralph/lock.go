@@ -1,15 +1,23 @@
 package ralph
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"syscall"
+	"time"
 )
 
 const lockFileName = ".ralph.lock"
 
+// ErrLockHeld is returned by Acquire when another live Ralph loop holds the
+// lock for the directory.
+var ErrLockHeld = errors.New("another Ralph loop is active")
+
 type Lock struct {
 	dir string
 }
@@ -26,7 +34,7 @@ func (l *Lock) Acquire() error {
 		pid, _ := strconv.Atoi(string(data))
 		if pid > 0 {
 			if err := syscall.Kill(pid, 0); err == nil {
-				return fmt.Errorf("another Ralph loop is active (PID %d)", pid)
+				return fmt.Errorf("%w (PID %d)", ErrLockHeld, pid)
 			}
 		}
 		fmt.Fprintf(os.Stderr, "WARNING: Removing stale lock (PID %d no longer running)\n", pid)
@@ -35,6 +43,36 @@ func (l *Lock) Acquire() error {
 	return os.WriteFile(l.path(), []byte(strconv.Itoa(os.Getpid())), 0644)
 }
 
+// lockPollInterval is how often AcquireWithTimeout retries a held lock.
+var lockPollInterval = 200 * time.Millisecond
+
+// AcquireWithTimeout retries Acquire while another live loop holds the lock,
+// until it is released or ctx is done. Stale locks are taken over as in
+// Acquire. On timeout it returns the last ErrLockHeld error.
+func (l *Lock) AcquireWithTimeout(ctx context.Context) error {
+	for {
+		err := l.Acquire()
+		if err == nil || !errors.Is(err, ErrLockHeld) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
+
+// Holder returns the PID recorded in the lock file, or 0 if there is none.
+func (l *Lock) Holder() int {
+	data, err := os.ReadFile(l.path())
+	if err != nil {
+		return 0
+	}
+	pid, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+	return pid
+}
+
 func (l *Lock) Release() {
 	os.Remove(l.path())
 }
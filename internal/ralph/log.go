@@ -0,0 +1,134 @@
+package ralph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Log formats accepted by NewEventLog.
+const (
+	LogText = "text"
+	LogJSON = "json"
+)
+
+// Events a loop reports in the JSON log format.
+const (
+	EventIterationStart = "iteration_start"
+	EventGateResult     = "gate_result"
+	EventStuckDetected  = "stuck_detected"
+	EventStrategyShift  = "strategy_shift"
+	EventWarning        = "warning"
+	EventOutcome        = "outcome"
+)
+
+// Fields are an event's attributes besides its time, name, task and
+// iteration.
+type Fields map[string]any
+
+// EventLog reports a loop's progress either as the human-readable lines of
+// the text format or as one JSON object per event, stamped with the time,
+// the task ID and the current iteration. In the JSON format narrative lines
+// are dropped, so the output is only events.
+type EventLog struct {
+	w    io.Writer
+	json bool
+
+	mu        sync.Mutex
+	task      string
+	iteration int
+	now       func() time.Time
+}
+
+// NewEventLog writes a log for task to w in format, LogText or LogJSON.
+func NewEventLog(w io.Writer, format, task string) (*EventLog, error) {
+	switch format {
+	case LogText, LogJSON:
+	default:
+		return nil, fmt.Errorf("unknown log format %q (want %s or %s)", format, LogText, LogJSON)
+	}
+	return &EventLog{w: w, json: format == LogJSON, task: task, now: time.Now}, nil
+}
+
+// Printf writes a narrative line in the text format only.
+func (l *EventLog) Printf(format string, args ...any) {
+	if l.json {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fmt.Fprintf(l.w, format, args...)
+}
+
+// Warnf reports a problem that does not stop the loop: a "Warning: " line
+// in the text format, a warning event in JSON.
+func (l *EventLog) Warnf(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	l.Event(EventWarning, Fields{"message": msg}, "Warning: "+msg+"\n")
+}
+
+// Event reports event with fields. The text format writes text, if any,
+// instead.
+func (l *EventLog) Event(event string, fields Fields, text string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.json {
+		if text != "" {
+			fmt.Fprint(l.w, text)
+		}
+		return
+	}
+	obj := make(map[string]any, len(fields)+4)
+	for k, v := range fields {
+		obj[k] = v
+	}
+	obj["time"] = l.now().UTC().Format(time.RFC3339Nano)
+	obj["event"] = event
+	obj["task"] = l.task
+	if l.iteration > 0 {
+		obj["iteration"] = l.iteration
+	}
+	line, err := json.Marshal(obj)
+	if err != nil {
+		line, _ = json.Marshal(map[string]any{"event": event, "task": l.task, "error": err.Error()})
+	}
+	l.w.Write(append(line, '\n'))
+}
+
+// StartIteration reports the start of an iteration, which later events are
+// stamped with.
+func (l *EventLog) StartIteration(approach, iteration, max int, text string) {
+	l.mu.Lock()
+	l.iteration = iteration
+	l.mu.Unlock()
+	l.Event(EventIterationStart, Fields{"approach": approach, "max_iterations": max}, text)
+}
+
+// LogGates returns gates with the result of every run reported as a
+// gate_result event: whether it passed, how long it took and, for a
+// failure, the error and its failure class.
+func (l *EventLog) LogGates(gates []Gate) []Gate {
+	logged := make([]Gate, len(gates))
+	for i, g := range gates {
+		name, run := g.Name, g.Run
+		logged[i] = Gate{Name: name, Run: func(ctx context.Context) (string, error) {
+			start := time.Now()
+			out, err := run(ctx)
+			fields := Fields{
+				"gate":             name,
+				"passed":           err == nil,
+				"duration_seconds": time.Since(start).Seconds(),
+			}
+			if err != nil {
+				fields["error"] = err.Error()
+				fields["class"] = ClassifyFailure(out, err)
+			}
+			l.Event(EventGateResult, fields, "")
+			return out, err
+		}}
+	}
+	return logged
+}
@@ -0,0 +1,79 @@
+package ralph
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventLogText(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := NewEventLog(&buf, LogText, "ralph-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.StartIteration(1, 2, 5, "\n=== Ralph Loop: Iteration 2/5 ===\n")
+	l.Printf("Gate 2: Tests...\n")
+	l.Warnf("path guard disabled (%v)", errors.New("not a git repo"))
+	l.Event(EventOutcome, Fields{"outcome": OutcomeSuccess}, "")
+
+	want := "\n=== Ralph Loop: Iteration 2/5 ===\nGate 2: Tests...\nWarning: path guard disabled (not a git repo)\n"
+	if buf.String() != want {
+		t.Errorf("text log = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestEventLogJSON(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := NewEventLog(&buf, LogJSON, "ralph-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.now = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+	l.Printf("Gate 2: Tests...\n")
+	l.StartIteration(1, 2, 5, "banner")
+	gates := l.LogGates([]Gate{
+		{Name: GateImplement, Run: func(context.Context) (string, error) { return "done", nil }},
+		{Name: GateTests, Run: func(context.Context) (string, error) { return "--- FAIL: TestLogin", errors.New("exit status 1") }},
+	})
+	RunGates(context.Background(), gates, GateImplement)
+	l.Event(EventOutcome, Fields{"outcome": OutcomeMaxIterations}, "text only")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("got %d events, want 4 (narrative lines dropped):\n%s", len(lines), buf.String())
+	}
+	var events []map[string]any
+	for _, line := range lines {
+		var e map[string]any
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("not JSON: %q", line)
+		}
+		if e["task"] != "ralph-1" || e["time"] != "2026-01-02T03:04:05Z" || e["iteration"] != 2.0 {
+			t.Errorf("event missing task, time or iteration: %v", e)
+		}
+		events = append(events, e)
+	}
+	if events[0]["event"] != EventIterationStart || events[0]["max_iterations"] != 5.0 {
+		t.Errorf("iteration event = %v", events[0])
+	}
+	if events[1]["gate"] != GateImplement || events[1]["passed"] != true {
+		t.Errorf("implement result = %v", events[1])
+	}
+	if events[2]["gate"] != GateTests || events[2]["passed"] != false || events[2]["class"] != string(FailureAssertion) || events[2]["error"] != "exit status 1" {
+		t.Errorf("tests result = %v", events[2])
+	}
+	if events[3]["event"] != EventOutcome || events[3]["outcome"] != OutcomeMaxIterations {
+		t.Errorf("outcome = %v", events[3])
+	}
+}
+
+func TestNewEventLogRejectsUnknownFormat(t *testing.T) {
+	if _, err := NewEventLog(&bytes.Buffer{}, "yaml", "t"); err == nil {
+		t.Error("want an error for an unknown format")
+	}
+}
@@ -0,0 +1,203 @@
+package ralph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Run outcomes reported by the ralph_outcome metric.
+const (
+	OutcomeRunning       = "running"
+	OutcomeSuccess       = "success"
+	OutcomeMaxIterations = "max_iterations"
+	OutcomeSuperseded    = "superseded"
+	OutcomeUnsafePath    = "unsafe_path"
+	OutcomeInterrupted   = "interrupted"
+	OutcomeError         = "error"
+)
+
+var outcomes = []string{OutcomeRunning, OutcomeSuccess, OutcomeMaxIterations, OutcomeSuperseded, OutcomeUnsafePath, OutcomeInterrupted, OutcomeError}
+
+// OutcomeOf names the outcome of a run that returned err.
+func OutcomeOf(err error) string {
+	switch {
+	case err == nil:
+		return OutcomeSuccess
+	case errors.Is(err, ErrMaxIterations):
+		return OutcomeMaxIterations
+	case errors.Is(err, ErrSuperseded):
+		return OutcomeSuperseded
+	case errors.Is(err, ErrUnsafePath):
+		return OutcomeUnsafePath
+	case errors.Is(err, context.Canceled):
+		return OutcomeInterrupted
+	default:
+		return OutcomeError
+	}
+}
+
+type gateStats struct {
+	name     string
+	runs     int
+	failures int
+	seconds  float64
+}
+
+// Metrics collects what a run's state does not record, gate timings and the
+// outcome, and exports them with the state in the Prometheus text format.
+type Metrics struct {
+	task    string
+	started time.Time
+
+	mu      sync.Mutex
+	gates   []*gateStats
+	outcome string
+}
+
+// NewMetrics starts collecting metrics for a run labeled task.
+func NewMetrics(task string) *Metrics {
+	return &Metrics{task: task, started: time.Now(), outcome: OutcomeRunning}
+}
+
+// TimeGates returns gates with every run timed.
+func (m *Metrics) TimeGates(gates []Gate) []Gate {
+	timed := make([]Gate, len(gates))
+	for i, g := range gates {
+		stats := m.gate(g.Name)
+		run := g.Run
+		timed[i] = Gate{Name: g.Name, Run: func(ctx context.Context) (string, error) {
+			start := time.Now()
+			out, err := run(ctx)
+			m.mu.Lock()
+			stats.runs++
+			stats.seconds += time.Since(start).Seconds()
+			if err != nil {
+				stats.failures++
+			}
+			m.mu.Unlock()
+			return out, err
+		}}
+	}
+	return timed
+}
+
+func (m *Metrics) gate(name string) *gateStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, s := range m.gates {
+		if s.name == name {
+			return s
+		}
+	}
+	s := &gateStats{name: name}
+	m.gates = append(m.gates, s)
+	return s
+}
+
+// Finish records the outcome of the run.
+func (m *Metrics) Finish(outcome string) {
+	m.mu.Lock()
+	m.outcome = outcome
+	m.mu.Unlock()
+}
+
+// WritePrometheus writes the metrics for state (nil when the run has none)
+// in the Prometheus text exposition format.
+func (m *Metrics) WritePrometheus(w io.Writer, state *State) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b strings.Builder
+	task := "task=" + quoteLabel(m.task)
+	metric := func(name, typ, help string) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+	}
+	if state != nil {
+		// Every failed iteration is an attempt; a successful one ends the run.
+		used := len(state.Attempts)
+		if m.outcome == OutcomeSuccess {
+			used++
+		}
+		metric("ralph_iterations_used", "gauge", "Iterations run, across all approaches.")
+		fmt.Fprintf(&b, "ralph_iterations_used{%s} %d\n", task, used)
+		metric("ralph_max_iterations", "gauge", "Iteration budget of each approach.")
+		fmt.Fprintf(&b, "ralph_max_iterations{%s} %d\n", task, state.MaxIterations)
+		metric("ralph_approaches", "gauge", "Approaches started, including meta-retries.")
+		fmt.Fprintf(&b, "ralph_approaches{%s} %d\n", task, max(len(state.Approaches), 1))
+		metric("ralph_stuck_count", "gauge", "Consecutive iterations that failed with the same error.")
+		fmt.Fprintf(&b, "ralph_stuck_count{%s} %d\n", task, state.StuckCount)
+		metric("ralph_strategy_shifts", "gauge", "Strategy shifts forced by stuck detection.")
+		fmt.Fprintf(&b, "ralph_strategy_shifts{%s} %d\n", task, state.StrategyShifts)
+	}
+
+	metric("ralph_gate_duration_seconds", "summary", "Time spent running each gate.")
+	for _, g := range m.gates {
+		fmt.Fprintf(&b, "ralph_gate_duration_seconds_sum{%s,gate=%s} %g\n", task, quoteLabel(g.name), g.seconds)
+		fmt.Fprintf(&b, "ralph_gate_duration_seconds_count{%s,gate=%s} %d\n", task, quoteLabel(g.name), g.runs)
+	}
+	metric("ralph_gate_failures_total", "counter", "Gate runs that failed.")
+	for _, g := range m.gates {
+		fmt.Fprintf(&b, "ralph_gate_failures_total{%s,gate=%s} %d\n", task, quoteLabel(g.name), g.failures)
+	}
+
+	metric("ralph_outcome", "gauge", "Outcome of the run: 1 for the current outcome, 0 otherwise.")
+	for _, o := range outcomes {
+		v := 0
+		if o == m.outcome {
+			v = 1
+		}
+		fmt.Fprintf(&b, "ralph_outcome{%s,outcome=%s} %d\n", task, quoteLabel(o), v)
+	}
+	metric("ralph_run_duration_seconds", "gauge", "Time since the run started.")
+	fmt.Fprintf(&b, "ralph_run_duration_seconds{%s} %g\n", task, time.Since(m.started).Seconds())
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteFile writes the metrics to path, replacing it atomically so a
+// collector such as node_exporter's textfile collector never reads a
+// partial file.
+func (m *Metrics) WriteFile(path string, state *State) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := m.WritePrometheus(tmp, state); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Handler serves the metrics, reading the run's current state from sm.
+func (m *Metrics) Handler(sm *StateManager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		state, err := sm.Load()
+		if err != nil {
+			state = nil
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		m.WritePrometheus(w, state)
+	})
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+// quoteLabel quotes a label value the way the exposition format expects.
+func quoteLabel(v string) string { return `"` + labelEscaper.Replace(v) + `"` }
@@ -0,0 +1,124 @@
+package ralph
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMetricsAfterRun(t *testing.T) {
+	dir := t.TempDir()
+	sm := NewStateManager(dir)
+	if err := sm.Init("task", 5); err != nil {
+		t.Fatal(err)
+	}
+	m := NewMetrics("auth-refactor")
+
+	// Two failed iterations with the same test error, then a pass.
+	testRuns := 0
+	gates := m.TimeGates([]Gate{
+		{Name: GateImplement, Run: func(context.Context) (string, error) { return "", nil }},
+		{Name: GateTests, Run: func(context.Context) (string, error) {
+			testRuns++
+			if testRuns < 3 {
+				return "FAIL TestLogin", errors.New("exit 1")
+			}
+			return "ok", nil
+		}},
+	})
+	for {
+		failed, out, _ := RunGates(context.Background(), gates, "")
+		if failed == "" {
+			break
+		}
+		if err := sm.Update(failed, 1, out); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m.Finish(OutcomeOf(nil))
+	state, err := sm.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "ralph.prom")
+	if err := m.WriteFile(path, state); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+	for _, want := range []string{
+		"# TYPE ralph_iterations_used gauge\n",
+		`ralph_iterations_used{task="auth-refactor"} 3` + "\n",
+		`ralph_max_iterations{task="auth-refactor"} 5` + "\n",
+		`ralph_stuck_count{task="auth-refactor"} 1` + "\n",
+		`ralph_strategy_shifts{task="auth-refactor"} 0` + "\n",
+		"# TYPE ralph_gate_duration_seconds summary\n",
+		`ralph_gate_duration_seconds_count{task="auth-refactor",gate="implement"} 3` + "\n",
+		`ralph_gate_duration_seconds_count{task="auth-refactor",gate="tests"} 3` + "\n",
+		`ralph_gate_failures_total{task="auth-refactor",gate="tests"} 2` + "\n",
+		`ralph_gate_failures_total{task="auth-refactor",gate="implement"} 0` + "\n",
+		`ralph_outcome{task="auth-refactor",outcome="success"} 1` + "\n",
+		`ralph_outcome{task="auth-refactor",outcome="max_iterations"} 0` + "\n",
+		`ralph_gate_duration_seconds_sum{task="auth-refactor",gate="tests"} `,
+		`ralph_run_duration_seconds{task="auth-refactor"} `,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("metrics missing %q:\n%s", want, got)
+		}
+	}
+	if matches, _ := filepath.Glob(path + ".tmp-*"); len(matches) > 0 {
+		t.Errorf("temp files left behind: %v", matches)
+	}
+}
+
+func TestMetricsHandlerServesLiveState(t *testing.T) {
+	sm := NewStateManager(t.TempDir())
+	if err := sm.Init("task", 4); err != nil {
+		t.Fatal(err)
+	}
+	m := NewMetrics(`a "quoted" task`)
+	rec := httptest.NewRecorder()
+	m.Handler(sm).ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		`ralph_max_iterations{task="a \"quoted\" task"} 4`,
+		`ralph_outcome{task="a \"quoted\" task",outcome="running"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("response missing %q:\n%s", want, body)
+		}
+	}
+
+	// Without state (e.g. after cleanup) only the run's own metrics remain.
+	var b bytes.Buffer
+	m.WritePrometheus(&b, nil)
+	if strings.Contains(b.String(), "ralph_iterations_used") || !strings.Contains(b.String(), "ralph_outcome") {
+		t.Errorf("metrics without state:\n%s", b.String())
+	}
+}
+
+func TestOutcomeOf(t *testing.T) {
+	tests := map[error]string{
+		nil:                                   OutcomeSuccess,
+		ErrMaxIterations:                      OutcomeMaxIterations,
+		fmt.Errorf("%w: peer", ErrSuperseded): OutcomeSuperseded,
+		ErrUnsafePath:                         OutcomeUnsafePath,
+		context.Canceled:                      OutcomeInterrupted,
+		errors.New("boom"):                    OutcomeError,
+	}
+	for err, want := range tests {
+		if got := OutcomeOf(err); got != want {
+			t.Errorf("OutcomeOf(%v) = %q, want %q", err, got, want)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package ralph
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/signalnine/conclave/internal/bus"
+)
+
+// BoardQuery filters board entries with a small mini-language of
+// space-separated clauses, all of which must match:
+//
+//	type=warning sender=task-3 since=1h text~=timeout
+//
+// key=value tests equality and key~=regex tests a regular expression. Keys:
+//
+//	type    entry type; the "board." prefix is optional for =
+//	sender  sender ID
+//	since   entries newer than a duration ago (1h, 30m) or an RFC 3339 time; = only
+//	text    the payload's "text" field
+//	sha     the commit the entry is pinned to; = matches by prefix, so an
+//	        abbreviated ID finds the full one and vice versa
+//
+// Values containing spaces can be double-quoted: text~="connection reset".
+type BoardQuery struct {
+	clauses []queryClause
+}
+
+type queryClause struct {
+	key   string
+	regex *regexp.Regexp // set for ~=
+	value string         // set for =
+	since time.Duration  // since=<duration>
+	at    time.Time      // since=<timestamp>
+}
+
+var queryKeys = map[string]bool{"type": true, "sender": true, "since": true, "text": true, "sha": true}
+
+// ParseBoardQuery parses a query string. An empty query matches everything.
+func ParseBoardQuery(s string) (*BoardQuery, error) {
+	tokens, err := splitQuery(s)
+	if err != nil {
+		return nil, err
+	}
+	q := &BoardQuery{}
+	for _, tok := range tokens {
+		c, err := parseClause(tok)
+		if err != nil {
+			return nil, err
+		}
+		q.clauses = append(q.clauses, c)
+	}
+	return q, nil
+}
+
+func parseClause(tok string) (queryClause, error) {
+	i := strings.Index(tok, "=")
+	if i <= 0 {
+		return queryClause{}, fmt.Errorf("query clause %q: want key=value or key~=regex", tok)
+	}
+	key, regex, raw := tok[:i], false, tok[i+1:]
+	if strings.HasSuffix(key, "~") {
+		key, regex = strings.TrimSuffix(key, "~"), true
+	}
+	if !queryKeys[key] {
+		return queryClause{}, fmt.Errorf("query clause %q: unknown key %q (want type, sender, since, text or sha)", tok, key)
+	}
+	value, err := unquoteQueryValue(raw)
+	if err != nil {
+		return queryClause{}, fmt.Errorf("query clause %q: %w", tok, err)
+	}
+	if value == "" {
+		return queryClause{}, fmt.Errorf("query clause %q: empty value", tok)
+	}
+
+	c := queryClause{key: key}
+	switch {
+	case key == "since" && regex:
+		return queryClause{}, fmt.Errorf("query clause %q: since supports only =", tok)
+	case key == "since":
+		if d, err := time.ParseDuration(value); err == nil && d > 0 {
+			c.since = d
+		} else if t, err := time.Parse(time.RFC3339, value); err == nil {
+			c.at = t
+		} else {
+			return queryClause{}, fmt.Errorf("query clause %q: since wants a positive duration (1h) or RFC 3339 time", tok)
+		}
+	case regex:
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return queryClause{}, fmt.Errorf("query clause %q: %w", tok, err)
+		}
+		c.regex = re
+	case key == "type":
+		c.value = NormalizeBoardType(value)
+	case key == "sha" && !ValidSHA(value):
+		return queryClause{}, fmt.Errorf("query clause %q: sha wants 4 to 64 hex digits", tok)
+	default:
+		c.value = value
+	}
+	return c, nil
+}
+
+// splitQuery splits s on whitespace, keeping double-quoted runs together.
+func splitQuery(s string) ([]string, error) {
+	var tokens []string
+	var cur strings.Builder
+	inQuote, escaped := false, false
+	for _, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case inQuote && r == '\\':
+			escaped = true
+		case r == '"':
+			inQuote = !inQuote
+		case !inQuote && (r == ' ' || r == '\t' || r == '\n'):
+			if cur.Len() > 0 {
+				tokens = append(tokens, cur.String())
+				cur.Reset()
+			}
+			continue
+		}
+		cur.WriteRune(r)
+	}
+	if inQuote {
+		return nil, fmt.Errorf("query %q: unterminated quote", s)
+	}
+	if cur.Len() > 0 {
+		tokens = append(tokens, cur.String())
+	}
+	return tokens, nil
+}
+
+func unquoteQueryValue(v string) (string, error) {
+	if !strings.HasPrefix(v, `"`) {
+		if strings.Contains(v, `"`) {
+			return "", fmt.Errorf("stray quote in value")
+		}
+		return v, nil
+	}
+	u, err := strconv.Unquote(v)
+	if err != nil {
+		return "", fmt.Errorf("bad quoted value %s", v)
+	}
+	return u, nil
+}
+
+// Match reports whether e satisfies every clause.
+func (q *BoardQuery) Match(e bus.Envelope, now time.Time) bool {
+	for _, c := range q.clauses {
+		if !c.match(e, now) {
+			return false
+		}
+	}
+	return true
+}
+
+// Filter returns the entries that match, in order.
+func (q *BoardQuery) Filter(entries []bus.Envelope, now time.Time) []bus.Envelope {
+	var out []bus.Envelope
+	for _, e := range entries {
+		if q.Match(e, now) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func (c queryClause) match(e bus.Envelope, now time.Time) bool {
+	var field string
+	switch c.key {
+	case "since":
+		cutoff := c.at
+		if c.since > 0 {
+			cutoff = now.Add(-c.since)
+		}
+		return !e.Timestamp.Before(cutoff)
+	case "type":
+		field = e.Type
+	case "sender":
+		field = e.Sender
+	case "text":
+		var payload struct {
+			Text string `json:"text"`
+		}
+		json.Unmarshal(e.Payload, &payload)
+		field = payload.Text
+	case "sha":
+		field = EntrySHA(e)
+		if c.regex == nil {
+			want := strings.ToLower(c.value)
+			return field != "" && (strings.HasPrefix(field, want) || strings.HasPrefix(want, field))
+		}
+	}
+	if c.regex != nil {
+		return c.regex.MatchString(field)
+	}
+	return field == c.value
+}
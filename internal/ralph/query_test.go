@@ -0,0 +1,92 @@
+package ralph
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/signalnine/conclave/internal/bus"
+)
+
+func queryEntries(now time.Time) []bus.Envelope {
+	return []bus.Envelope{
+		{Seq: 1, Timestamp: now.Add(-3 * time.Hour), Type: "board.warning", Sender: "task-3", Payload: json.RawMessage(`{"text":"old timeout in CI"}`)},
+		{Seq: 2, Timestamp: now.Add(-10 * time.Minute), Type: "board.warning", Sender: "task-3", Payload: json.RawMessage(`{"text":"request timeout on /login"}`)},
+		{Seq: 3, Timestamp: now.Add(-5 * time.Minute), Type: "board.discovery", Sender: "task-12", Payload: json.RawMessage(`{"text":"connection reset by peer","sha":"9f2c4e1a7b3d"}`)},
+		{Seq: 4, Timestamp: now.Add(-time.Minute), Type: "board.warning", Sender: "task-4", Payload: json.RawMessage(`{"text":"timeout again"}`)},
+	}
+}
+
+func TestBoardQueryOperators(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		query string
+		want  []uint64
+	}{
+		{"", []uint64{1, 2, 3, 4}},
+		{"type=warning", []uint64{1, 2, 4}},
+		{"type=board.discovery", []uint64{3}},
+		{"type~=disc", []uint64{3}},
+		{"sender=task-3", []uint64{1, 2}},
+		{"sender~=^task-1", []uint64{3}},
+		{"since=1h", []uint64{2, 3, 4}},
+		{"since=" + now.Add(-7*time.Minute).Format(time.RFC3339), []uint64{3, 4}},
+		{"text=timeout again", nil}, // unquoted space makes two clauses; "again" is malformed
+		{`text="timeout again"`, []uint64{4}},
+		{`text~="reset by"`, []uint64{3}},
+		{"type=warning sender=task-3 since=1h text~=timeout", []uint64{2}},
+		{"sha=9F2C4E1", []uint64{3}},
+		{"sha=9f2c4e1a7b3d5e6f", []uint64{3}},
+		{"sha=abcd", []uint64{}},
+		{"sha~=^9f2", []uint64{3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := ParseBoardQuery(tt.query)
+			if tt.want == nil {
+				if err == nil {
+					t.Fatal("want a parse error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []uint64
+			for _, e := range q.Filter(queryEntries(now), now) {
+				got = append(got, e.Seq)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("matched %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("matched %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestBoardQueryMalformed(t *testing.T) {
+	tests := map[string]string{
+		"type":               "want key=value",
+		"=warning":           "want key=value",
+		"color=red":          `unknown key "color"`,
+		"type=":              "empty value",
+		"since~=1h":          "since supports only =",
+		"since=yesterday":    "since wants a positive duration",
+		"since=-1h":          "since wants a positive duration",
+		"text~=(unclosed":    "error parsing regexp",
+		`text="unterminated`: "unterminated quote",
+		`sender=ta"s"k`:      "stray quote",
+		"sha=xyz123":         "sha wants 4 to 64 hex digits",
+	}
+	for query, want := range tests {
+		_, err := ParseBoardQuery(query)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ParseBoardQuery(%q) error = %v, want %q", query, err, want)
+		}
+	}
+}
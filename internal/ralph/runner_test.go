@@ -1,7 +1,11 @@
 package ralph
 
 import (
+	"context"
+	"errors"
+	"os"
 	"testing"
+	"time"
 )
 
 func TestLock_AcquireRelease(t *testing.T) {
@@ -46,3 +50,67 @@ func TestStateManager_FullLifecycle(t *testing.T) {
 		t.Error("state still exists after cleanup")
 	}
 }
+
+func TestLock_HeldByLiveProcess(t *testing.T) {
+	dir := t.TempDir()
+	l := NewLock(dir)
+	if err := l.Acquire(); err != nil {
+		t.Fatal(err)
+	}
+	defer l.Release()
+	// Our own PID is alive, so a second acquire must fail
+	err := NewLock(dir).Acquire()
+	if !errors.Is(err, ErrLockHeld) {
+		t.Errorf("err = %v, want ErrLockHeld", err)
+	}
+}
+
+func TestLock_AcquireWithTimeoutWaitsForRelease(t *testing.T) {
+	old := lockPollInterval
+	lockPollInterval = 10 * time.Millisecond
+	defer func() { lockPollInterval = old }()
+
+	dir := t.TempDir()
+	holder := NewLock(dir)
+	if err := holder.Acquire(); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		holder.Release()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	start := time.Now()
+	if err := NewLock(dir).AcquireWithTimeout(ctx); err != nil {
+		t.Fatalf("should acquire once the holder releases: %v", err)
+	}
+	if waited := time.Since(start); waited < 40*time.Millisecond {
+		t.Errorf("acquired after %s, before the holder released", waited)
+	}
+	NewLock(dir).Release()
+}
+
+func TestLock_AcquireWithTimeoutGivesUp(t *testing.T) {
+	old := lockPollInterval
+	lockPollInterval = 10 * time.Millisecond
+	defer func() { lockPollInterval = old }()
+
+	dir := t.TempDir()
+	holder := NewLock(dir)
+	if err := holder.Acquire(); err != nil {
+		t.Fatal(err)
+	}
+	defer holder.Release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := NewLock(dir).AcquireWithTimeout(ctx)
+	if !errors.Is(err, ErrLockHeld) {
+		t.Errorf("err = %v, want ErrLockHeld", err)
+	}
+	if holder.Holder() != os.Getpid() {
+		t.Errorf("Holder() = %d, want %d", holder.Holder(), os.Getpid())
+	}
+}
@@ -0,0 +1,148 @@
+package ralph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/signalnine/conclave/internal/bus"
+)
+
+// BoardSnapshotFile is the default snapshot name inside a board directory.
+// It lacks the .jsonl suffix so board readers ignore it.
+const BoardSnapshotFile = ".board-snapshot.json"
+
+// BoardSnapshot is the aggregated board state (deduplicated, ordered by Seq)
+// up to the Seq watermark. A coordinator that restarts loads the snapshot
+// and reads only entries newer than Seq instead of reprocessing the board.
+type BoardSnapshot struct {
+	Seq     uint64         `json:"seq"`
+	TakenAt time.Time      `json:"taken_at"`
+	Entries []bus.Envelope `json:"entries"`
+}
+
+// LoadBoardSnapshot reads a snapshot file. A missing file yields an empty
+// snapshot with a zero watermark.
+func LoadBoardSnapshot(path string) (*BoardSnapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &BoardSnapshot{}, nil
+		}
+		return nil, err
+	}
+	var s BoardSnapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parse board snapshot %s: %w", path, err)
+	}
+	return &s, nil
+}
+
+// Write saves the snapshot atomically, so a crash mid-write leaves the
+// previous snapshot intact.
+func (s *BoardSnapshot) Write(path string) error {
+	s.TakenAt = time.Now().UTC()
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".board-snapshot-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Update folds board entries newer than the watermark into the snapshot,
+// drops entries that have since expired, and advances the watermark.
+func (s *BoardSnapshot) Update(dir string) error {
+	newer, seq, err := ReadBoardSince(dir, s.Seq)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	seen := make(map[string]bool, len(s.Entries))
+	kept := s.Entries[:0]
+	for _, e := range s.Entries {
+		seen[snapshotKey(e)] = true
+		if !EntryExpired(e, now) {
+			kept = append(kept, e)
+		}
+	}
+	s.Entries = kept
+	for _, e := range newer {
+		if k := snapshotKey(e); !seen[k] {
+			seen[k] = true
+			s.Entries = append(s.Entries, e)
+		}
+	}
+	sort.SliceStable(s.Entries, func(i, j int) bool { return s.Entries[i].Seq < s.Entries[j].Seq })
+	s.Seq = seq
+	return nil
+}
+
+// snapshotKey identifies an entry for deduplication; envelopes without an
+// ID fall back to their sequence number.
+func snapshotKey(e bus.Envelope) string {
+	if e.ID != "" {
+		return e.ID
+	}
+	return fmt.Sprintf("seq-%d", e.Seq)
+}
+
+// RestoreBoard rebuilds the full board view from the snapshot at path plus
+// any entries written to dir since it was taken.
+func RestoreBoard(dir, path string) (*BoardSnapshot, error) {
+	s, err := LoadBoardSnapshot(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Update(dir); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// SnapshotBoardEvery restores the board view for dir and rewrites the
+// snapshot at path every interval until ctx ends, with a final snapshot on
+// the way out. Errors are reported to onErr (if set) and do not stop the loop.
+func SnapshotBoardEvery(ctx context.Context, dir, path string, interval time.Duration, onErr func(error)) {
+	report := func(err error) {
+		if err != nil && onErr != nil {
+			onErr(err)
+		}
+	}
+	s, err := RestoreBoard(dir, path)
+	if err != nil {
+		report(err)
+		s = &BoardSnapshot{}
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			report(s.Update(dir))
+			report(s.Write(path))
+			return
+		case <-t.C:
+			if err := s.Update(dir); err != nil {
+				report(err)
+				continue
+			}
+			report(s.Write(path))
+		}
+	}
+}
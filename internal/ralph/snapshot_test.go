@@ -0,0 +1,97 @@
+package ralph
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/signalnine/conclave/internal/bus"
+)
+
+func TestBoardSnapshotRestore(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, BoardSnapshotFile)
+	writeBoardFile(t, dir, "a.jsonl", []bus.Envelope{
+		{ID: "a-1", Seq: 1, Type: "board.discovery", Sender: "t1", Payload: json.RawMessage(`{"text":"one"}`)},
+		{ID: "a-3", Seq: 3, Type: "board.warning", Sender: "t1", Payload: json.RawMessage(`{"text":"three"}`)},
+	})
+	writeBoardFile(t, dir, "b.jsonl", []bus.Envelope{
+		{ID: "b-2", Seq: 2, Type: "board.intent", Sender: "t2", Payload: json.RawMessage(`{"text":"two"}`)},
+	})
+
+	snap, err := RestoreBoard(dir, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap.Seq != 3 || len(snap.Entries) != 3 {
+		t.Fatalf("snapshot seq %d with %d entries, want 3 and 3", snap.Seq, len(snap.Entries))
+	}
+	if err := snap.Write(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// New entries arrive after the snapshot; a.jsonl is rewritten whole, so
+	// entries the snapshot already holds reappear and must not be duplicated.
+	writeBoardFile(t, dir, "a.jsonl", []bus.Envelope{
+		{ID: "a-1", Seq: 1, Type: "board.discovery", Sender: "t1", Payload: json.RawMessage(`{"text":"one"}`)},
+		{ID: "a-3", Seq: 3, Type: "board.warning", Sender: "t1", Payload: json.RawMessage(`{"text":"three"}`)},
+		{ID: "a-5", Seq: 5, Type: "board.discovery", Sender: "t1", Payload: json.RawMessage(`{"text":"five"}`)},
+	})
+	writeBoardFile(t, dir, "b.jsonl", []bus.Envelope{
+		{ID: "b-2", Seq: 2, Type: "board.intent", Sender: "t2", Payload: json.RawMessage(`{"text":"two"}`)},
+		{ID: "b-4", Seq: 4, Type: "board.discovery", Sender: "t2", Payload: json.RawMessage(`{"text":"four"}`)},
+	})
+
+	loaded, err := LoadBoardSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Seq != 3 || loaded.TakenAt.IsZero() {
+		t.Fatalf("persisted watermark = %d (taken %v), want 3", loaded.Seq, loaded.TakenAt)
+	}
+	restored, err := RestoreBoard(dir, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.Seq != 5 || len(restored.Entries) != 5 {
+		t.Fatalf("restored seq %d with %d entries, want 5 and 5", restored.Seq, len(restored.Entries))
+	}
+	for i, e := range restored.Entries {
+		if e.Seq != uint64(i+1) {
+			t.Errorf("entry %d has seq %d, want ordered by seq", i, e.Seq)
+		}
+	}
+}
+
+func TestLoadBoardSnapshotMissing(t *testing.T) {
+	snap, err := LoadBoardSnapshot(filepath.Join(t.TempDir(), BoardSnapshotFile))
+	if err != nil || snap.Seq != 0 || len(snap.Entries) != 0 {
+		t.Errorf("missing snapshot = %+v, %v; want empty", snap, err)
+	}
+}
+
+func TestSnapshotBoardEveryWritesFinalSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, BoardSnapshotFile)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		SnapshotBoardEvery(ctx, dir, path, time.Hour, func(err error) { t.Error(err) })
+	}()
+	writeBoardFile(t, dir, "a.jsonl", []bus.Envelope{
+		{ID: "a-1", Seq: 1, Type: "board.discovery", Sender: "t1", Payload: json.RawMessage(`{"text":"one"}`)},
+	})
+	cancel()
+	<-done
+
+	snap, err := LoadBoardSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap.Seq != 1 || len(snap.Entries) != 1 {
+		t.Errorf("final snapshot seq %d with %d entries, want 1 and 1", snap.Seq, len(snap.Entries))
+	}
+}
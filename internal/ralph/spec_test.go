@@ -0,0 +1,85 @@
+package ralph
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseSpecVerdict(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    string
+		wantErr bool
+	}{
+		{"bare", `{"verdict": "pass", "reasons": []}`, "pass", false},
+		{"fenced with prose", "Looks incomplete.\n```json\n{\"verdict\": \"FAIL\", \"reasons\": [\"no tests\"]}\n```\n", "fail", false},
+		{"last verdict wins", `{"verdict": "pass"} on reflection {"verdict": "fail", "reasons": ["x"]}`, "fail", false},
+		{"literal marker only", "SPEC_PASS", "", true},
+		{"unknown verdict", `{"verdict": "maybe"}`, "", true},
+	}
+	for _, tt := range tests {
+		v, err := ParseSpecVerdict(tt.output)
+		if (err != nil) != tt.wantErr || v.Verdict != tt.want {
+			t.Errorf("%s: verdict %q, err %v; want %q, wantErr %v", tt.name, v.Verdict, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestBuildSpecPrompt(t *testing.T) {
+	p := BuildSpecPrompt("Add a --verbose flag", "+flag.Bool(\"verbose\")")
+	for _, want := range []string{"Add a --verbose flag", "+flag.Bool(\"verbose\")", `"verdict"`} {
+		if !strings.Contains(p, want) {
+			t.Errorf("prompt missing %q", want)
+		}
+	}
+	if !strings.Contains(BuildSpecPrompt("spec", ""), "(no changes)") {
+		t.Error("empty diff should be marked")
+	}
+}
+
+// reviewer writes a script that prints reply and returns an implement
+// command template running it with the prompt on stdin.
+func reviewer(t *testing.T, reply string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "reply.txt"), []byte(reply), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	script := filepath.Join(dir, "review.sh")
+	body := "#!/bin/sh\ncat > " + filepath.Join(dir, "prompt.txt") + "\ncat " + filepath.Join(dir, "reply.txt") + "\n"
+	if err := os.WriteFile(script, []byte(body), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return script
+}
+
+func TestRunSpecGate(t *testing.T) {
+	pass := reviewer(t, `{"verdict": "pass", "reasons": []}`)
+	if out, err := RunSpecGate(context.Background(), pass, t.TempDir(), "spec", "diff", 10, nil); err != nil {
+		t.Errorf("pass verdict: %v\n%s", err, out)
+	}
+	prompt, _ := os.ReadFile(filepath.Join(filepath.Dir(pass), "prompt.txt"))
+	if !strings.Contains(string(prompt), "## Task Spec\nspec") {
+		t.Errorf("reviewer did not get the spec prompt:\n%s", prompt)
+	}
+
+	fail := reviewer(t, `{"verdict": "fail", "reasons": ["flag is not documented", "no test"]}`)
+	out, err := RunSpecGate(context.Background(), fail, t.TempDir(), "spec", "diff", 10, nil)
+	if !errors.Is(err, ErrSpecNotMet) {
+		t.Errorf("fail verdict err = %v, want ErrSpecNotMet", err)
+	}
+	if !strings.Contains(out, "- flag is not documented\n- no test") {
+		t.Errorf("output = %q, want the reasons for the next iteration", out)
+	}
+
+	// Echoing the old marker is not a verdict.
+	spoof := reviewer(t, "SPEC_PASS")
+	if _, err := RunSpecGate(context.Background(), spoof, t.TempDir(), "spec", "diff", 10, nil); err == nil {
+		t.Error("output without a verdict should fail the gate")
+	}
+}
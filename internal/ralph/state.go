@@ -3,11 +3,14 @@ package ralph
 import (
 	"crypto/md5"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
+
+	gitpkg "github.com/signalnine/conclave/internal/git"
 )
 
 const (
@@ -20,19 +23,43 @@ type Attempt struct {
 	Gate      string `json:"gate"`
 	Hash      string `json:"hash"`
 	Shift     bool   `json:"shift"`
+	Approach  int    `json:"approach,omitempty"`
+	Class     string `json:"class,omitempty"` // FailureClass of the gate output
+}
+
+// Approach is one run of the iteration budget. A meta-retry abandons the
+// current approach and starts the next with a task-level strategy.
+type Approach struct {
+	Number     int       `json:"number"`
+	Strategy   string    `json:"strategy"`
+	StartedAt  time.Time `json:"started_at"`
+	Iterations int       `json:"iterations"`          // spent when the approach ended
+	LastGate   string    `json:"last_gate,omitempty"` // gate that failed last
 }
 
 type State struct {
-	TaskID         string    `json:"task_id"`
-	Iteration      int       `json:"iteration"`
-	MaxIterations  int       `json:"max_iterations"`
-	LastGate       string    `json:"last_gate"`
-	ExitCode       int       `json:"exit_code"`
-	ErrorHash      string    `json:"error_hash"`
-	Timestamp      time.Time `json:"timestamp"`
-	StuckCount     int       `json:"stuck_count"`
-	StrategyShifts int       `json:"strategy_shifts"`
-	Attempts       []Attempt `json:"attempts"`
+	TaskID         string     `json:"task_id"`
+	BaseSHA        string     `json:"base_sha,omitempty"` // HEAD when the loop started
+	Iteration      int        `json:"iteration"`
+	MaxIterations  int        `json:"max_iterations"`
+	LastGate       string     `json:"last_gate"`
+	ExitCode       int        `json:"exit_code"`
+	ErrorHash      string     `json:"error_hash"`
+	FailureClass   string     `json:"failure_class,omitempty"`
+	Timestamp      time.Time  `json:"timestamp"`
+	StuckCount     int        `json:"stuck_count"`
+	StrategyShifts int        `json:"strategy_shifts"`
+	Attempts       []Attempt  `json:"attempts"`
+	Approaches     []Approach `json:"approaches,omitempty"`
+}
+
+// CurrentApproach returns the number of the approach in progress, 1 for the
+// first.
+func (s *State) CurrentApproach() int {
+	if len(s.Approaches) == 0 {
+		return 1
+	}
+	return s.Approaches[len(s.Approaches)-1].Number
 }
 
 type StateManager struct {
@@ -58,6 +85,11 @@ func (s *StateManager) Init(taskID string, maxIter int) error {
 		MaxIterations: maxIter,
 		Timestamp:     time.Now(),
 		Attempts:      []Attempt{},
+		Approaches:    []Approach{{Number: 1, Strategy: "initial", StartedAt: time.Now()}},
+	}
+	// Kept across --resume, by when the loop's checkpoints are in HEAD.
+	if head, err := gitpkg.New(s.dir).RevParse("HEAD"); err == nil {
+		state.BaseSHA = head
 	}
 	if err := s.save(state); err != nil {
 		return err
@@ -66,6 +98,22 @@ func (s *StateManager) Init(taskID string, maxIter int) error {
 	return os.WriteFile(s.contextPath(), []byte(ctx), 0644)
 }
 
+// Resume loads the saved state of taskID left by an interrupted loop, so it
+// continues from the saved iteration with its context file intact.
+func (s *StateManager) Resume(taskID string) (*State, error) {
+	state, err := s.Load()
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no saved ralph state to resume in %s", s.dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if state.TaskID != taskID {
+		return nil, fmt.Errorf("saved ralph state belongs to task %s, not %s", state.TaskID, taskID)
+	}
+	return state, nil
+}
+
 func (s *StateManager) Load() (*State, error) {
 	data, err := os.ReadFile(s.statePath())
 	if err != nil {
@@ -78,7 +126,14 @@ func (s *StateManager) Load() (*State, error) {
 	return &state, nil
 }
 
+// Update records a failed gate, classifying the failure from its output.
 func (s *StateManager) Update(gate string, exitCode int, output string) error {
+	return s.UpdateFailure(gate, exitCode, output, ClassifyFailure(output, nil))
+}
+
+// UpdateFailure is Update with the failure class given, e.g. from
+// ClassifyFailure with the gate's error.
+func (s *StateManager) UpdateFailure(gate string, exitCode int, output string, class FailureClass) error {
 	state, err := s.Load()
 	if err != nil {
 		return err
@@ -91,8 +146,10 @@ func (s *StateManager) Update(gate string, exitCode int, output string) error {
 	}
 	hash := fmt.Sprintf("%x", md5.Sum([]byte(strings.Join(lines, "\n"))))
 
-	// Stuck detection
-	if hash == state.ErrorHash && state.ErrorHash != "" {
+	// Stuck detection: the same output, or the same gate failing the same
+	// way (a compile error in another file is still a compile error).
+	sameClass := class != FailureOther && string(class) == state.FailureClass && gate == state.LastGate
+	if (hash == state.ErrorHash && state.ErrorHash != "") || sameClass {
 		state.StuckCount++
 	} else {
 		state.StuckCount = 0
@@ -111,11 +168,14 @@ func (s *StateManager) Update(gate string, exitCode int, output string) error {
 		Gate:      gate,
 		Hash:      hash[:8],
 		Shift:     state.StrategyShifts > 0,
+		Approach:  state.CurrentApproach(),
+		Class:     string(class),
 	})
 	state.Iteration++
 	state.LastGate = gate
 	state.ExitCode = exitCode
 	state.ErrorHash = hash
+	state.FailureClass = string(class)
 	state.Timestamp = time.Now()
 
 	if err := s.save(state); err != nil {
@@ -123,11 +183,60 @@ func (s *StateManager) Update(gate string, exitCode int, output string) error {
 	}
 
 	// Update context file
-	ctx := fmt.Sprintf("# Ralph Loop Context: %s\n\n## Status\n- Iteration: %d of %d\n- Last gate failed: %s\n- Stuck count: %d (threshold: 3)\n\n## Last Error Output (verbatim)\n```\n%s\n```\n",
-		state.TaskID, state.Iteration, state.MaxIterations, gate, state.StuckCount, truncated)
+	ctx := fmt.Sprintf("# Ralph Loop Context: %s\n\n## Status\n- Iteration: %d of %d\n- Last gate failed: %s\n- Failure class: %s\n- Stuck count: %d (threshold: 3)\n\n%s## Last Error Output (verbatim)\n```\n%s\n```\n",
+		state.TaskID, state.Iteration, state.MaxIterations, gate, class, state.StuckCount, abandonedApproaches(state), truncated)
 	return os.WriteFile(s.contextPath(), []byte(ctx), 0644)
 }
 
+// StartApproach closes the current approach and starts the next one with a
+// fresh iteration budget and context. The attempt history is kept; the stuck
+// counters and the previous approach's error output are not.
+func (s *StateManager) StartApproach(strategy string) (*State, error) {
+	state, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+	if len(state.Approaches) == 0 {
+		state.Approaches = []Approach{{Number: 1, Strategy: "initial", StartedAt: state.Timestamp}}
+	}
+	cur := &state.Approaches[len(state.Approaches)-1]
+	cur.Iterations = state.Iteration - 1
+	cur.LastGate = state.LastGate
+	state.Approaches = append(state.Approaches, Approach{
+		Number:    cur.Number + 1,
+		Strategy:  strategy,
+		StartedAt: time.Now(),
+	})
+	state.Iteration = 1
+	state.LastGate = ""
+	state.ExitCode = 0
+	state.ErrorHash = ""
+	state.FailureClass = ""
+	state.StuckCount = 0
+	state.Timestamp = time.Now()
+	if err := s.save(state); err != nil {
+		return nil, err
+	}
+
+	ctx := fmt.Sprintf("# Ralph Loop Context: %s\n\n## Status\n- Approach: %d (%s)\n- Iteration: 1 of %d\n- Last gate: (none yet)\n\n%s## Previous Output\n(Fresh approach - previous output discarded)\n",
+		state.TaskID, state.CurrentApproach(), strategy, state.MaxIterations, abandonedApproaches(state))
+	return state, os.WriteFile(s.contextPath(), []byte(ctx), 0644)
+}
+
+// abandonedApproaches summarizes the approaches before the current one as a
+// context file section, empty during the first approach.
+func abandonedApproaches(state *State) string {
+	if len(state.Approaches) < 2 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("## Abandoned Approaches\n")
+	for _, a := range state.Approaches[:len(state.Approaches)-1] {
+		fmt.Fprintf(&b, "- Approach %d (%s): %d iterations, last failed gate: %s\n", a.Number, a.Strategy, a.Iterations, a.LastGate)
+	}
+	return b.String() + "\n"
+}
+
 func (s *StateManager) IncrementStrategyShift() error {
 	state, err := s.Load()
 	if err != nil {
@@ -151,3 +260,46 @@ func (s *StateManager) save(state *State) error {
 }
 
 func (s *StateManager) ContextFile() string { return s.contextPath() }
+
+// lastErrorHeading starts the context file section with the latest gate
+// output, the part a capped context keeps first.
+const lastErrorHeading = "## Last Error Output"
+
+// ReadContext returns the context file cut to at most limit bytes (0 = no
+// limit) for the next prompt. A missing file reads as empty.
+func (s *StateManager) ReadContext(limit int) (string, error) {
+	data, err := os.ReadFile(s.contextPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return capContext(string(data), limit), nil
+}
+
+// capContext cuts content to about limit bytes. The latest error section is
+// kept whole when it fits, shortening the sections before it; an error
+// section that is itself too long keeps its start and end, where compilers
+// and test runners put their summaries.
+func capContext(content string, limit int) string {
+	if limit <= 0 || len(content) <= limit {
+		return content
+	}
+	head, errSection := content, ""
+	if i := strings.Index(content, lastErrorHeading); i >= 0 {
+		head, errSection = content[:i], content[i:]
+	}
+	const marker = "\n[... context truncated ...]\n"
+	var cut string
+	if budget := limit - len(errSection) - len(marker); budget >= 0 {
+		cut = head[:budget] + marker + errSection
+	} else if budget = limit - len(marker); budget > 0 {
+		keepEnd := budget / 3
+		cut = errSection[:budget-keepEnd] + marker + errSection[len(errSection)-keepEnd:]
+	} else {
+		cut = errSection[:limit]
+	}
+	// Byte cuts may split a multi-byte character.
+	return strings.ToValidUTF8(cut, "")
+}
@@ -3,6 +3,7 @@ package ralph
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -60,6 +61,73 @@ func TestUpdateState_StuckDetection(t *testing.T) {
 	}
 }
 
+func TestUpdateState_StuckOnRepeatedClass(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStateManager(dir)
+	s.Init("task-1", 10)
+
+	// Different compile errors are still the same kind of failure.
+	s.Update("tests", 1, "a.go:1:2: undefined: Foo\nFAIL\tx [build failed]")
+	s.Update("tests", 1, "b.go:7:9: cannot use x (type int) as string\nFAIL\tx [build failed]")
+	state, _ := s.Load()
+	if state.FailureClass != string(FailureCompile) || state.StuckCount != 1 {
+		t.Errorf("class %q, stuck count %d; want compile, 1", state.FailureClass, state.StuckCount)
+	}
+	if state.Attempts[0].Class != string(FailureCompile) {
+		t.Errorf("attempt class = %q", state.Attempts[0].Class)
+	}
+
+	// Unclassified failures only count as stuck when identical.
+	s.Update("tests", 1, "error one")
+	s.Update("tests", 1, "error two")
+	state, _ = s.Load()
+	if state.StuckCount != 0 {
+		t.Errorf("distinct unclassified failures: stuck count %d, want 0", state.StuckCount)
+	}
+	ctx, _ := os.ReadFile(s.ContextFile())
+	if !strings.Contains(string(ctx), "- Failure class: other") {
+		t.Errorf("context file missing the failure class:\n%s", ctx)
+	}
+}
+
+func TestCapContext(t *testing.T) {
+	head := "# Ralph Loop Context: t\n\n## Abandoned Approaches\n" + strings.Repeat("- approach summary\n", 50)
+	errSection := "## Last Error Output (verbatim)\n```\nFAIL: TestLogin\n```\n"
+	content := head + errSection
+
+	if got := capContext(content, 0); got != content {
+		t.Error("limit 0 should not cut")
+	}
+	got := capContext(content, 200)
+	if len(got) > 200 || !strings.HasSuffix(got, errSection) || !strings.HasPrefix(got, "# Ralph Loop Context") {
+		t.Errorf("capped context (%d bytes) should keep its start and the whole latest error:\n%s", len(got), got)
+	}
+
+	// An error section longer than the limit keeps its start and end.
+	long := "## Last Error Output (verbatim)\nfirst line\n" + strings.Repeat("noise\n", 500) + "last line\n"
+	got = capContext(long, 300)
+	if len(got) > 300 || !strings.Contains(got, "first line") || !strings.Contains(got, "last line") || !strings.Contains(got, "truncated") {
+		t.Errorf("long error capped to %d bytes:\n%s", len(got), got)
+	}
+}
+
+func TestReadContextCapsTheFile(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStateManager(dir)
+	if got, err := s.ReadContext(100); got != "" || err != nil {
+		t.Errorf("missing context = %q, %v", got, err)
+	}
+	s.Init("task-1", 5)
+	s.Update("tests", 1, strings.Repeat("x", 5000)+"\nFAIL: TestX")
+	got, err := s.ReadContext(1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) > 1000 || !strings.Contains(got, "FAIL: TestX") {
+		t.Errorf("ReadContext(1000) = %d bytes, want the capped context ending in the latest error", len(got))
+	}
+}
+
 func TestCleanup(t *testing.T) {
 	dir := t.TempDir()
 	s := NewStateManager(dir)
@@ -72,6 +140,32 @@ func TestCleanup(t *testing.T) {
 	}
 }
 
+func TestResume(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStateManager(dir)
+	if _, err := s.Resume("task-1"); err == nil || !strings.Contains(err.Error(), "no saved ralph state") {
+		t.Errorf("resume without state: err = %v", err)
+	}
+	s.Init("task-1", 5)
+	s.Update("tests", 1, "FAIL: TestX")
+
+	// A new manager, as after a restart, picks up the saved iteration.
+	state, err := NewStateManager(dir).Resume("task-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Iteration != 2 || state.LastGate != "tests" {
+		t.Errorf("resumed state = iteration %d, last gate %q; want 2, tests", state.Iteration, state.LastGate)
+	}
+	ctx, _ := os.ReadFile(s.ContextFile())
+	if !strings.Contains(string(ctx), "FAIL: TestX") {
+		t.Errorf("context file lost on resume:\n%s", ctx)
+	}
+	if _, err := s.Resume("task-2"); err == nil || !strings.Contains(err.Error(), "belongs to task task-1") {
+		t.Errorf("resume of another task: err = %v", err)
+	}
+}
+
 func TestExists(t *testing.T) {
 	dir := t.TempDir()
 	s := NewStateManager(dir)
@@ -83,3 +177,47 @@ func TestExists(t *testing.T) {
 		t.Error("should exist after init")
 	}
 }
+
+func TestStartApproachAfterExhaustingIterations(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStateManager(dir)
+	s.Init("task-1", 2)
+	s.Update("tests", 1, "identical error output")
+	s.Update("tests", 1, "identical error output")
+	state, _ := s.Load()
+	if state.Iteration <= state.MaxIterations {
+		t.Fatalf("Iteration = %d, want the budget of %d exhausted", state.Iteration, state.MaxIterations)
+	}
+
+	state, err := s.StartApproach("rethink")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.CurrentApproach() != 2 || state.Iteration != 1 || state.StuckCount != 0 || state.ErrorHash != "" {
+		t.Errorf("after StartApproach: approach %d, iteration %d, stuck %d, hash %q; want a fresh approach 2",
+			state.CurrentApproach(), state.Iteration, state.StuckCount, state.ErrorHash)
+	}
+	first := state.Approaches[0]
+	if first.Strategy != "initial" || first.Iterations != 2 || first.LastGate != "tests" {
+		t.Errorf("first approach recorded as %+v", first)
+	}
+	if state.Approaches[1].Strategy != "rethink" {
+		t.Errorf("second approach strategy = %q", state.Approaches[1].Strategy)
+	}
+
+	ctx, _ := os.ReadFile(s.ContextFile())
+	if strings.Contains(string(ctx), "identical error output") {
+		t.Error("context still carries the abandoned approach's error output")
+	}
+
+	s.Update("implement", 1, "new error")
+	state, _ = s.Load()
+	if len(state.Attempts) != 3 || state.Attempts[0].Approach != 1 || state.Attempts[2].Approach != 2 {
+		t.Errorf("attempts = %+v, want history kept and tagged by approach", state.Attempts)
+	}
+
+	ctx, _ = os.ReadFile(s.ContextFile())
+	if !strings.Contains(string(ctx), "Approach 1 (initial): 2 iterations") {
+		t.Errorf("context does not list the abandoned approach:\n%s", ctx)
+	}
+}
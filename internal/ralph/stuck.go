@@ -16,3 +16,60 @@ You MUST try a fundamentally different approach:
 
 Do NOT repeat the same approach that failed.
 `
+
+// TaskStrategy is a task-level recovery strategy for a meta-retry: rather
+// than nudging the failing approach, the next approach starts over with it.
+type TaskStrategy struct {
+	Name      string
+	Directive string
+}
+
+// TaskStrategies are tried in order by successive meta-retries.
+var TaskStrategies = []TaskStrategy{
+	{
+		Name: "rethink",
+		Directive: `## IMPORTANT: Previous Approach Abandoned
+
+A previous approach to this task used its whole iteration budget without passing the gates. Its changes have been set aside.
+
+Start over with a fundamentally different design. Before writing code, state in one or two sentences why the previous approach failed and how this one avoids that.
+`,
+	},
+	{
+		Name: "minimal",
+		Directive: `## IMPORTANT: Previous Approaches Abandoned
+
+Earlier approaches to this task used their whole iteration budget without passing the gates. Their changes have been set aside.
+
+Make the smallest change that could possibly satisfy the task. Avoid refactoring, new abstractions and new dependencies; get the tests passing first.
+`,
+	},
+	{
+		Name: "decompose",
+		Directive: `## IMPORTANT: Previous Approaches Abandoned
+
+Earlier approaches to this task used their whole iteration budget without passing the gates. Their changes have been set aside.
+
+Break the task into the smallest independently testable steps and complete them one at a time, running the tests after each step.
+`,
+	},
+}
+
+// NextTaskStrategy returns the strategy for the n-th meta-retry (1-based),
+// cycling through TaskStrategies.
+func NextTaskStrategy(n int) TaskStrategy {
+	if n < 1 {
+		n = 1
+	}
+	return TaskStrategies[(n-1)%len(TaskStrategies)]
+}
+
+// TaskStrategyNamed returns the task strategy called name.
+func TaskStrategyNamed(name string) (TaskStrategy, bool) {
+	for _, ts := range TaskStrategies {
+		if ts.Name == name {
+			return ts, true
+		}
+	}
+	return TaskStrategy{}, false
+}
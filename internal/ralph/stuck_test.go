@@ -23,3 +23,29 @@ func TestIsStuck(t *testing.T) {
 		})
 	}
 }
+
+func TestNextTaskStrategyCycles(t *testing.T) {
+	if got := NextTaskStrategy(1).Name; got != TaskStrategies[0].Name {
+		t.Errorf("first meta-retry strategy = %q", got)
+	}
+	if got := NextTaskStrategy(len(TaskStrategies) + 1).Name; got != TaskStrategies[0].Name {
+		t.Errorf("strategies should cycle, got %q", got)
+	}
+	seen := map[string]bool{}
+	for i := 1; i <= len(TaskStrategies); i++ {
+		seen[NextTaskStrategy(i).Name] = true
+	}
+	if len(seen) != len(TaskStrategies) {
+		t.Errorf("a meta-retry repeated a strategy before trying them all: %v", seen)
+	}
+}
+
+func TestTaskStrategyNamed(t *testing.T) {
+	want := TaskStrategies[len(TaskStrategies)-1]
+	if got, ok := TaskStrategyNamed(want.Name); !ok || got.Directive != want.Directive {
+		t.Errorf("TaskStrategyNamed(%q) = %q, %v", want.Name, got.Name, ok)
+	}
+	if _, ok := TaskStrategyNamed("initial"); ok {
+		t.Error("the initial approach has no task strategy")
+	}
+}
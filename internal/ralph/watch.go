@@ -0,0 +1,143 @@
+package ralph
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/signalnine/conclave/internal/bus"
+)
+
+// DefaultWatchDebounce is how long a BoardWatcher waits for the board to go
+// quiet before acting on collected triggers.
+const DefaultWatchDebounce = 10 * time.Second
+
+// WatchTrigger decides whether a board entry should trigger the watch
+// action, returning the files it references.
+type WatchTrigger func(e bus.Envelope) (files []string, ok bool)
+
+// WatchAction handles one debounced batch: the triggering entries, in order,
+// and the union of the files they reference.
+type WatchAction func(ctx context.Context, entries []bus.Envelope, files []string) error
+
+// BoardWatcher turns a board subscription into actions, e.g. a consensus
+// review of the files a new warning is about. Bursts of triggers within
+// Debounce of each other are batched into a single Action call.
+type BoardWatcher struct {
+	Trigger  WatchTrigger // nil uses CodeWarning
+	Action   WatchAction
+	Debounce time.Duration // <= 0 uses DefaultWatchDebounce
+	After    uint64        // ignore entries with Seq <= After (already on the board)
+	OnError  func(error)   // called when Action fails; the watch continues
+}
+
+// Watch consumes entries until ctx is done or the channel closes. Triggers
+// still pending when the channel closes are acted on before returning.
+func (w *BoardWatcher) Watch(ctx context.Context, entries <-chan bus.Envelope) error {
+	trigger := w.Trigger
+	if trigger == nil {
+		trigger = CodeWarning
+	}
+	debounce := w.Debounce
+	if debounce <= 0 {
+		debounce = DefaultWatchDebounce
+	}
+
+	var pending []bus.Envelope
+	var files []string
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	flush := func() {
+		if len(pending) == 0 {
+			return
+		}
+		if err := w.Action(ctx, pending, files); err != nil && w.OnError != nil {
+			w.OnError(err)
+		}
+		pending, files = nil, nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			flush()
+		case e, ok := <-entries:
+			if !ok {
+				flush()
+				return nil
+			}
+			if e.Seq <= w.After {
+				continue
+			}
+			refs, hit := trigger(e)
+			if !hit {
+				continue
+			}
+			pending = append(pending, e)
+			for _, f := range refs {
+				if !slices.Contains(files, f) {
+					files = append(files, f)
+				}
+			}
+			timer.Reset(debounce)
+		}
+	}
+}
+
+// CodeWarning triggers on board.warning entries that reference source files,
+// either in a "files" payload field or by path in the text.
+func CodeWarning(e bus.Envelope) ([]string, bool) {
+	if e.Type != "board.warning" {
+		return nil, false
+	}
+	files := EntryFiles(e)
+	return files, len(files) > 0
+}
+
+// MatchingWarning returns a trigger for code warnings whose text matches re.
+func MatchingWarning(re *regexp.Regexp) WatchTrigger {
+	return func(e bus.Envelope) ([]string, bool) {
+		files, ok := CodeWarning(e)
+		return files, ok && re.MatchString(EntryText(e))
+	}
+}
+
+var filePathRe = regexp.MustCompile(`(?:[\w.-]+/)*[\w-][\w.-]*\.(?:go|py|js|jsx|ts|tsx|rs|java|kt|rb|php|c|h|cc|cpp|hpp|cs|swift|scala|sh|sql|proto|tf|ya?ml|toml|json|vue|css|html)\b`)
+
+// EntryFiles lists the files a board entry references: its payload's
+// "files" field when present, otherwise file paths found in its text
+// (line suffixes such as ":42" are dropped).
+func EntryFiles(e bus.Envelope) []string {
+	var payload struct {
+		Text  string   `json:"text"`
+		Files []string `json:"files"`
+	}
+	json.Unmarshal(e.Payload, &payload)
+	if len(payload.Files) > 0 {
+		return payload.Files
+	}
+	var files []string
+	for _, m := range filePathRe.FindAllString(payload.Text, -1) {
+		m = strings.TrimPrefix(m, "./")
+		if !slices.Contains(files, m) {
+			files = append(files, m)
+		}
+	}
+	return files
+}
+
+// EntryText returns the text field of e's payload, or "" if it has none.
+func EntryText(e bus.Envelope) string {
+	var payload struct {
+		Text string `json:"text"`
+	}
+	json.Unmarshal(e.Payload, &payload)
+	return payload.Text
+}
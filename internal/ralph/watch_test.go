@@ -0,0 +1,113 @@
+package ralph
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/signalnine/conclave/internal/bus"
+)
+
+func watchEntry(seq uint64, typ, sender, text string) bus.Envelope {
+	payload, _ := json.Marshal(map[string]string{"text": text})
+	return bus.Envelope{Seq: seq, Type: typ, Sender: sender, Payload: payload}
+}
+
+type actionCalls struct {
+	entries [][]bus.Envelope
+	files   [][]string
+}
+
+func (c *actionCalls) action(ctx context.Context, entries []bus.Envelope, files []string) error {
+	c.entries = append(c.entries, entries)
+	c.files = append(c.files, files)
+	return nil
+}
+
+func TestBoardWatcherWarningTriggersAction(t *testing.T) {
+	type batch struct {
+		entries []bus.Envelope
+		files   []string
+	}
+	got := make(chan batch, 4)
+	w := &BoardWatcher{
+		Action: func(ctx context.Context, entries []bus.Envelope, files []string) error {
+			got <- batch{entries, files}
+			return nil
+		},
+		Debounce: 20 * time.Millisecond,
+		After:    1,
+	}
+	ch := make(chan bus.Envelope, 4)
+	ch <- watchEntry(1, "board.warning", "task-0", "old warning about internal/old.go")
+	ch <- watchEntry(2, "board.discovery", "task-1", "internal/auth/login.go uses bcrypt")
+	ch <- watchEntry(3, "board.warning", "task-2", "internal/auth/login.go:42 leaks the session token")
+	ch <- watchEntry(4, "board.warning", "task-3", "CI is slow today")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- w.Watch(ctx, ch) }()
+
+	var b batch
+	select {
+	case b = <-got:
+	case <-time.After(2 * time.Second):
+		t.Fatal("warning did not trigger the action")
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Watch() = %v, want context.Canceled", err)
+	}
+	if len(b.entries) != 1 || b.entries[0].Sender != "task-2" {
+		t.Fatalf("action got %+v, want only the new code warning from task-2", b.entries)
+	}
+	if !slices.Equal(b.files, []string{"internal/auth/login.go"}) {
+		t.Errorf("files = %v", b.files)
+	}
+	if len(got) != 0 {
+		t.Error("action ran more than once")
+	}
+}
+
+func TestBoardWatcherDebouncesBurst(t *testing.T) {
+	var calls actionCalls
+	w := &BoardWatcher{Action: calls.action, Debounce: time.Hour}
+	ch := make(chan bus.Envelope, 3)
+	ch <- watchEntry(1, "board.warning", "task-1", "race in scheduler.go")
+	ch <- watchEntry(2, "board.warning", "task-2", "scheduler.go and lock.go disagree on the lock path")
+	close(ch)
+
+	if err := w.Watch(context.Background(), ch); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls.entries) != 1 || len(calls.entries[0]) != 2 {
+		t.Fatalf("got %d action calls, want one batch of both warnings", len(calls.entries))
+	}
+	if !slices.Equal(calls.files[0], []string{"scheduler.go", "lock.go"}) {
+		t.Errorf("files = %v, want the deduplicated union", calls.files[0])
+	}
+}
+
+func TestMatchingWarning(t *testing.T) {
+	trigger := MatchingWarning(regexp.MustCompile(`(?i)security|leak`))
+	if _, ok := trigger(watchEntry(1, "board.warning", "t", "token leak in auth.go")); !ok {
+		t.Error("matching code warning should trigger")
+	}
+	if _, ok := trigger(watchEntry(2, "board.warning", "t", "auth.go is slow")); ok {
+		t.Error("non-matching warning should not trigger")
+	}
+}
+
+func TestEntryFiles(t *testing.T) {
+	payload := json.RawMessage(`{"text":"see notes","files":["a.go","b.go"]}`)
+	if got := EntryFiles(bus.Envelope{Payload: payload}); !slices.Equal(got, []string{"a.go", "b.go"}) {
+		t.Errorf("files field: got %v", got)
+	}
+	got := EntryFiles(watchEntry(1, "board.warning", "t", "e.g. ./cmd/main.go and web/app.tsx, not foo.golang"))
+	if !slices.Equal(got, []string{"cmd/main.go", "web/app.tsx"}) {
+		t.Errorf("text paths: got %v", got)
+	}
+}
@@ -0,0 +1,90 @@
+package ralph
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WaveTask is one task of a ralph wave.
+type WaveTask struct {
+	ID   string // task-1, task-2, ... in list order
+	Text string // the task given to ralph-run --task
+}
+
+// WaveResult is how a wave task's loop ended.
+type WaveResult struct {
+	Task     WaveTask
+	Outcome  string // one of the Outcome constants
+	Branch   string
+	Worktree string
+	Log      string
+	Duration time.Duration
+	Err      error // why the loop could not run, for OutcomeError
+}
+
+// ParseTaskList reads a wave's task list: one task per line, either the
+// task itself or the path, relative to dir, of a file holding it. Blank
+// lines and lines starting with # are skipped.
+func ParseTaskList(r io.Reader, dir string) ([]WaveTask, error) {
+	var tasks []WaveTask
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		text := line
+		path := line
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			text = strings.TrimSpace(string(data))
+			if text == "" {
+				return nil, fmt.Errorf("task file %s is empty", line)
+			}
+		}
+		tasks = append(tasks, WaveTask{ID: fmt.Sprintf("task-%d", len(tasks)+1), Text: text})
+	}
+	return tasks, scanner.Err()
+}
+
+// RunWave runs every task with run, at most concurrency at a time, and
+// returns the results in task order. Tasks not started before ctx is done
+// are reported as interrupted.
+func RunWave(ctx context.Context, tasks []WaveTask, concurrency int, run func(context.Context, WaveTask) WaveResult) []WaveResult {
+	results := make([]WaveResult, len(tasks))
+	sem := make(chan struct{}, max(concurrency, 1))
+	var wg sync.WaitGroup
+	for i, t := range tasks {
+		if ctx.Err() == nil {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+			}
+		}
+		if ctx.Err() != nil {
+			results[i] = WaveResult{Task: t, Outcome: OutcomeInterrupted}
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = run(ctx, t)
+		}()
+	}
+	wg.Wait()
+	return results
+}
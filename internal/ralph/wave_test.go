@@ -0,0 +1,83 @@
+package ralph
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseTaskList(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "auth.md"), []byte("# Add auth\n\nAdd login and logout.\n"), 0o644)
+	list := "# wave 1\nFix the login redirect\n\nauth.md\n  Add a health endpoint  \n"
+
+	tasks, err := ParseTaskList(strings.NewReader(list), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []WaveTask{
+		{ID: "task-1", Text: "Fix the login redirect"},
+		{ID: "task-2", Text: "# Add auth\n\nAdd login and logout."},
+		{ID: "task-3", Text: "Add a health endpoint"},
+	}
+	if len(tasks) != len(want) {
+		t.Fatalf("tasks = %+v, want %+v", tasks, want)
+	}
+	for i := range want {
+		if tasks[i] != want[i] {
+			t.Errorf("tasks[%d] = %+v, want %+v", i, tasks[i], want[i])
+		}
+	}
+
+	os.WriteFile(filepath.Join(dir, "empty.md"), nil, 0o644)
+	if _, err := ParseTaskList(strings.NewReader("empty.md\n"), dir); err == nil {
+		t.Error("an empty task file should be an error")
+	}
+}
+
+func TestRunWaveBoundsConcurrency(t *testing.T) {
+	tasks := make([]WaveTask, 6)
+	for i := range tasks {
+		tasks[i] = WaveTask{ID: "task-" + string(rune('1'+i))}
+	}
+	var running, peak atomic.Int32
+	results := RunWave(context.Background(), tasks, 2, func(_ context.Context, task WaveTask) WaveResult {
+		n := running.Add(1)
+		for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+		}
+		time.Sleep(20 * time.Millisecond)
+		running.Add(-1)
+		return WaveResult{Task: task, Outcome: OutcomeSuccess}
+	})
+	if peak.Load() != 2 {
+		t.Errorf("peak concurrency = %d, want 2", peak.Load())
+	}
+	for i, r := range results {
+		if r.Task.ID != tasks[i].ID || r.Outcome != OutcomeSuccess {
+			t.Errorf("results[%d] = %+v, want task order", i, r)
+		}
+	}
+}
+
+func TestRunWaveStopsStartingOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	tasks := []WaveTask{{ID: "task-1"}, {ID: "task-2"}, {ID: "task-3"}}
+	var started atomic.Int32
+	results := RunWave(ctx, tasks, 1, func(ctx context.Context, task WaveTask) WaveResult {
+		started.Add(1)
+		cancel()
+		return WaveResult{Task: task, Outcome: OutcomeInterrupted}
+	})
+	if started.Load() != 1 {
+		t.Errorf("started %d tasks after cancel, want 1", started.Load())
+	}
+	for i, r := range results {
+		if r.Outcome != OutcomeInterrupted || r.Task.ID != tasks[i].ID {
+			t.Errorf("results[%d] = %+v, want interrupted", i, r)
+		}
+	}
+}